@@ -352,6 +352,8 @@ namespace DeeMusic.Desktop.Models
                     return string.Empty;
                 
                 var suffix = IsPartialSuccess ? " ⚠️" : (IsCompleted ? " ✓" : "");
+                if (IsDownloading && !string.IsNullOrEmpty(ETA))
+                    suffix = $" · {ETA} left";
                 return $"{CompletedTracks}/{TotalTracks} tracks{suffix}";
             }
         }
@@ -526,7 +528,25 @@ namespace DeeMusic.Desktop.Models
         
         public string CoverUrl { get; set; } = string.Empty;
         public string Speed { get; set; } = string.Empty;
-        public string ETA { get; set; } = string.Empty;
+
+        private string _eta = string.Empty;
+
+        /// <summary>
+        /// Estimated time left for an album/playlist, from the backend's parent progress callback
+        /// </summary>
+        public string ETA
+        {
+            get => _eta;
+            set
+            {
+                if (_eta != value)
+                {
+                    _eta = value;
+                    OnPropertyChanged();
+                    OnPropertyChanged(nameof(TrackProgressText));
+                }
+            }
+        }
 
         private static string FormatBytes(long bytes)
         {
@@ -115,6 +115,9 @@ namespace DeeMusic.Desktop.Models
         [JsonPropertyName("singles_folder_structure")]
         public bool SinglesFolderStructure { get; set; } = false;
 
+        [JsonPropertyName("group_artist_singles")]
+        public bool GroupArtistSingles { get; set; } = false;
+
         // Folder name templates
         [JsonPropertyName("playlist_folder_template")]
         public string PlaylistFolderTemplate { get; set; } = "{playlist}";
@@ -1,5 +1,6 @@
 using System;
 using System.Text.Json;
+using System.Text.Json.Serialization;
 using System.Windows;
 using System.Windows.Threading;
 using Microsoft.Extensions.Logging;
@@ -19,6 +20,7 @@ namespace DeeMusic.Desktop.Services
         private readonly ProgressCallback _progressCallback;
         private readonly StatusCallback _statusCallback;
         private readonly QueueUpdateCallback _queueUpdateCallback;
+        private readonly ParentProgressCallback _parentProgressCallback;
 
         #region Events
 
@@ -37,6 +39,11 @@ namespace DeeMusic.Desktop.Services
         /// </summary>
         public event EventHandler<QueueStatsEventArgs>? QueueStatsUpdated;
 
+        /// <summary>
+        /// Raised when an album or playlist's progress and ETA are updated
+        /// </summary>
+        public event EventHandler<ParentProgressEventArgs>? ParentProgressUpdated;
+
         #endregion
 
         public BackendCallbackHandler(ILogger<BackendCallbackHandler>? logger = null)
@@ -48,6 +55,7 @@ namespace DeeMusic.Desktop.Services
             _progressCallback = OnProgressCallback;
             _statusCallback = OnStatusCallback;
             _queueUpdateCallback = OnQueueUpdateCallback;
+            _parentProgressCallback = OnParentProgressCallback;
 
             // Register callbacks with Go backend
             RegisterCallbacks();
@@ -66,6 +74,7 @@ namespace DeeMusic.Desktop.Services
                 GoBackend.SetProgressCallback(_progressCallback);
                 GoBackend.SetStatusCallback(_statusCallback);
                 GoBackend.SetQueueUpdateCallback(_queueUpdateCallback);
+                GoBackend.SetParentProgressCallback(_parentProgressCallback);
                 
                 _logger?.LogInformation("Backend callbacks registered successfully");
                 LoggingService.Instance.LogInfo("Backend callbacks registered successfully");
@@ -204,6 +213,42 @@ namespace DeeMusic.Desktop.Services
             }
         }
 
+        /// <summary>
+        /// Called by Go backend when an album or playlist's progress and ETA are updated
+        /// </summary>
+        private void OnParentProgressCallback(string progressJson)
+        {
+            try
+            {
+                // Marshal to UI thread
+                _dispatcher.BeginInvoke(() =>
+                {
+                    try
+                    {
+                        var progress = JsonSerializer.Deserialize<ParentProgressEventArgs>(progressJson, new JsonSerializerOptions
+                        {
+                            PropertyNameCaseInsensitive = true
+                        });
+
+                        if (progress != null)
+                        {
+                            ParentProgressUpdated?.Invoke(this, progress);
+                        }
+                    }
+                    catch (Exception ex)
+                    {
+                        _logger?.LogError(ex, "Error handling parent progress update");
+                        LoggingService.Instance.LogError("Error handling parent progress update", ex);
+                    }
+                });
+            }
+            catch (Exception ex)
+            {
+                _logger?.LogError(ex, "Error marshaling parent progress callback to UI thread");
+                LoggingService.Instance.LogError("Error marshaling parent progress callback to UI thread", ex);
+            }
+        }
+
         #endregion
 
         #region Helper Methods
@@ -239,6 +284,7 @@ namespace DeeMusic.Desktop.Services
                 GoBackend.SetProgressCallback(null!);
                 GoBackend.SetStatusCallback(null!);
                 GoBackend.SetQueueUpdateCallback(null!);
+                GoBackend.SetParentProgressCallback(null!);
                 
                 _logger?.LogInformation("Backend callbacks unregistered");
                 LoggingService.Instance.LogInfo("Backend callbacks unregistered");
@@ -287,5 +333,32 @@ namespace DeeMusic.Desktop.Services
         public required QueueStats Stats { get; init; }
     }
 
+    /// <summary>
+    /// Event arguments for album/playlist progress updates, deserialized from the backend's JSON
+    /// </summary>
+    public class ParentProgressEventArgs : EventArgs
+    {
+        [JsonPropertyName("item_id")]
+        public string ItemID { get; init; } = string.Empty;
+
+        [JsonPropertyName("progress")]
+        public int Progress { get; init; }
+
+        [JsonPropertyName("completed_tracks")]
+        public int CompletedTracks { get; init; }
+
+        [JsonPropertyName("total_tracks")]
+        public int TotalTracks { get; init; }
+
+        [JsonPropertyName("tracks_per_minute")]
+        public double TracksPerMinute { get; init; }
+
+        [JsonPropertyName("eta_seconds")]
+        public int EtaSeconds { get; init; }
+
+        [JsonPropertyName("eta")]
+        public string ETA { get; init; } = string.Empty;
+    }
+
     #endregion
 }
@@ -40,6 +40,12 @@ namespace DeeMusic.Desktop.Services
             remove => _callbackHandler.QueueStatsUpdated -= value;
         }
 
+        public event EventHandler<ParentProgressEventArgs>? ParentProgressUpdated
+        {
+            add => _callbackHandler.ParentProgressUpdated += value;
+            remove => _callbackHandler.ParentProgressUpdated -= value;
+        }
+
         #endregion
 
         public DeeMusicService(ILogger<DeeMusicService>? logger = null)
@@ -320,6 +326,27 @@ namespace DeeMusic.Desktop.Services
             });
         }
 
+        /// <summary>
+        /// Download an album as part of its artist's discography
+        /// </summary>
+        public async Task DownloadDiscographyAlbumAsync(string albumID, string? quality = null)
+        {
+            EnsureInitialized();
+
+            await ExecuteWithRetryAsync(async () =>
+            {
+                await Task.Run(() =>
+                {
+                    var result = GoBackend.DownloadDiscographyAlbum(albumID, quality);
+                    if (result != 0)
+                    {
+                        var errorMsg = GoBackend.GetErrorMessage(result);
+                        throw new BackendException($"Failed to download album: {errorMsg}", result);
+                    }
+                });
+            });
+        }
+
         /// <summary>
         /// Download a playlist
         /// </summary>
@@ -50,6 +50,12 @@ namespace DeeMusic.Desktop.Services
         [DllImport(DllName, CallingConvention = CallingConvention.Cdecl)]
         public static extern void SetQueueUpdateCallback(QueueUpdateCallback callback);
 
+        /// <summary>
+        /// Set the parent progress callback for album/playlist progress with ETA
+        /// </summary>
+        [DllImport(DllName, CallingConvention = CallingConvention.Cdecl)]
+        public static extern void SetParentProgressCallback(ParentProgressCallback callback);
+
         #endregion
 
         #region Search and Browse
@@ -135,6 +141,15 @@ namespace DeeMusic.Desktop.Services
         [DllImport(DllName, CallingConvention = CallingConvention.Cdecl, CharSet = CharSet.Ansi)]
         public static extern int DownloadAlbum(string albumID, string? quality);
 
+        /// <summary>
+        /// Download an album as part of its artist's discography (see group_artist_singles)
+        /// </summary>
+        /// <param name="albumID">Deezer album ID</param>
+        /// <param name="quality">Quality setting (e.g., "MP3_320", "FLAC")</param>
+        /// <returns>0 on success, negative error code on failure</returns>
+        [DllImport(DllName, CallingConvention = CallingConvention.Cdecl, CharSet = CharSet.Ansi)]
+        public static extern int DownloadDiscographyAlbum(string albumID, string? quality);
+
         /// <summary>
         /// Download an entire playlist
         /// </summary>
@@ -411,5 +426,13 @@ namespace DeeMusic.Desktop.Services
     public delegate void QueueUpdateCallback(
         [MarshalAs(UnmanagedType.LPStr)] string statsJson);
 
+    /// <summary>
+    /// Callback delegate for album/playlist progress updates
+    /// </summary>
+    /// <param name="progressJson">Album/playlist progress with ETA as JSON string</param>
+    [UnmanagedFunctionPointer(CallingConvention.Cdecl)]
+    public delegate void ParentProgressCallback(
+        [MarshalAs(UnmanagedType.LPStr)] string progressJson);
+
     #endregion
 }
@@ -355,7 +355,7 @@ namespace DeeMusic.Desktop.ViewModels
             {
                 try
                 {
-                    await _service.DownloadAlbumAsync(album.Id);
+                    await _service.DownloadDiscographyAlbumAsync(album.Id);
                     addedCount++;
                 }
                 catch (Exception ex)
@@ -419,7 +419,7 @@ namespace DeeMusic.Desktop.ViewModels
             {
                 try
                 {
-                    await _service.DownloadAlbumAsync(single.Id);
+                    await _service.DownloadDiscographyAlbumAsync(single.Id);
                     addedCount++;
                 }
                 catch (Exception ex)
@@ -483,7 +483,7 @@ namespace DeeMusic.Desktop.ViewModels
             {
                 try
                 {
-                    await _service.DownloadAlbumAsync(ep.Id);
+                    await _service.DownloadDiscographyAlbumAsync(ep.Id);
                     addedCount++;
                 }
                 catch (Exception ex)
@@ -306,6 +306,7 @@ namespace DeeMusic.Desktop.ViewModels
             _service.ProgressUpdated += OnProgressUpdated;
             _service.StatusChanged += OnStatusChanged;
             _service.QueueStatsUpdated += OnQueueStatsUpdated;
+            _service.ParentProgressUpdated += OnParentProgressUpdated;
             
             // Initialize periodic refresh timer
             InitializeRefreshTimer();
@@ -1094,6 +1095,22 @@ namespace DeeMusic.Desktop.ViewModels
             });
         }
 
+        /// <summary>
+        /// Handle album/playlist ETA updates from the backend; their track counts arrive
+        /// through OnProgressUpdated
+        /// </summary>
+        private void OnParentProgressUpdated(object? sender, ParentProgressEventArgs e)
+        {
+            System.Windows.Application.Current?.Dispatcher.InvokeAsync(() =>
+            {
+                var item = QueueItems.FirstOrDefault(i => i.Id == e.ItemID);
+                if (item != null)
+                {
+                    item.ETA = e.ETA;
+                }
+            });
+        }
+
         /// <summary>
         /// Handle status changes from the backend
         /// </summary>
@@ -1268,6 +1285,7 @@ namespace DeeMusic.Desktop.ViewModels
             _service.ProgressUpdated -= OnProgressUpdated;
             _service.StatusChanged -= OnStatusChanged;
             _service.QueueStatsUpdated -= OnQueueStatsUpdated;
+            _service.ParentProgressUpdated -= OnParentProgressUpdated;
 
             _disposed = true;
         }
@@ -7,6 +7,8 @@ package main
 typedef void (*ProgressCallback)(char* itemID, int progress, long long bytesProcessed, long long totalBytes);
 typedef void (*StatusCallback)(char* itemID, char* status, char* errorMsg);
 typedef void (*QueueUpdateCallback)(char* statsJson);
+typedef void (*CompletionCallback)(char* summaryJson);
+typedef void (*ParentProgressCallback)(char* progressJson);
 
 // Helper functions to call function pointers
 static inline void call_progress_callback(ProgressCallback cb, char* itemID, int progress, long long bytesProcessed, long long totalBytes) {
@@ -26,15 +28,29 @@ static inline void call_queue_update_callback(QueueUpdateCallback cb, char* stat
 		cb(statsJson);
 	}
 }
+
+static inline void call_completion_callback(CompletionCallback cb, char* summaryJson) {
+	if (cb != NULL) {
+		cb(summaryJson);
+	}
+}
+
+static inline void call_parent_progress_callback(ParentProgressCallback cb, char* progressJson) {
+	if (cb != NULL) {
+		cb(progressJson);
+	}
+}
 */
 import "C"
 import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"sync"
@@ -62,11 +78,16 @@ var (
 	mu           sync.RWMutex
 	debugLog     *os.File
 	shutdownFlag bool // Flag to track if shutdown was intentional
+	settingsMu   sync.Mutex // Serializes settings read-modify-write so concurrent updates don't lose writes
+	configWatcher *config.Watcher // Reloads settings.json edited outside the app (system.watch_config); guarded by settingsMu
 	
 	// Callbacks
 	progressCb     C.ProgressCallback
 	statusCb       C.StatusCallback
 	queueUpdateCb  C.QueueUpdateCallback
+	completionCb   C.CompletionCallback
+	completionPerAlbum bool // Also call completionCb for each finished album/playlist, not just an idle queue
+	parentProgressCb C.ParentProgressCallback
 	callbackMu     sync.RWMutex
 )
 
@@ -83,9 +104,16 @@ func logDebug(format string, args ...interface{}) {
 }
 
 // CallbackNotifier implements the Notifier interface using C callbacks
-type CallbackNotifier struct{}
+// It also tracks live speed/ETA so GetActiveDownloads reflects the real download path
+type CallbackNotifier struct {
+	stats *download.StatsTracker
+}
 
 func (n *CallbackNotifier) NotifyProgress(itemID string, progress int, bytesProcessed, totalBytes int64) {
+	if n.stats != nil {
+		n.stats.Update(itemID, bytesProcessed, totalBytes)
+	}
+	
 	callbackMu.RLock()
 	cb := progressCb
 	callbackMu.RUnlock()
@@ -99,7 +127,34 @@ func (n *CallbackNotifier) NotifyProgress(itemID string, progress int, bytesProc
 	}
 }
 
+// NotifyParentProgress reports an album or playlist's track counts through the progress
+// callback as before, then the whole progress with its estimated time left as JSON through
+// the parent progress callback, which the progress callback has no fields for
+func (n *CallbackNotifier) NotifyParentProgress(progress *download.ParentProgress) {
+	n.NotifyProgress(progress.ItemID, progress.Progress, int64(progress.CompletedTracks), int64(progress.TotalTracks))
+	
+	callbackMu.RLock()
+	cb := parentProgressCb
+	callbackMu.RUnlock()
+	
+	if cb == nil {
+		return
+	}
+	progressJSON, err := json.Marshal(progress)
+	if err != nil {
+		return
+	}
+	cProgress := C.CString(string(progressJSON))
+	defer C.free(unsafe.Pointer(cProgress))
+	
+	C.call_parent_progress_callback(cb, cProgress)
+}
+
 func (n *CallbackNotifier) NotifyStarted(itemID string) {
+	if n.stats != nil {
+		n.stats.Start(itemID)
+	}
+	
 	callbackMu.RLock()
 	cb := statusCb
 	callbackMu.RUnlock()
@@ -116,6 +171,10 @@ func (n *CallbackNotifier) NotifyStarted(itemID string) {
 }
 
 func (n *CallbackNotifier) NotifyCompleted(itemID string) {
+	if n.stats != nil {
+		n.stats.Remove(itemID)
+	}
+	
 	callbackMu.RLock()
 	cb := statusCb
 	callbackMu.RUnlock()
@@ -135,6 +194,10 @@ func (n *CallbackNotifier) NotifyCompleted(itemID string) {
 }
 
 func (n *CallbackNotifier) NotifyFailed(itemID string, err error) {
+	if n.stats != nil {
+		n.stats.Remove(itemID)
+	}
+	
 	callbackMu.RLock()
 	cb := statusCb
 	callbackMu.RUnlock()
@@ -155,7 +218,111 @@ func (n *CallbackNotifier) NotifyFailed(itemID string, err error) {
 	n.notifyQueueUpdate()
 }
 
+// NotifySchedule reports download.schedule pausing or resuming dispatch through the status
+// callback as item "schedule" with status "paused" or "resumed"; the message (e.g.
+// "paused until 1:00") is passed in place of the error
+func (n *CallbackNotifier) NotifySchedule(status *download.ScheduleStatus) {
+	callbackMu.RLock()
+	cb := statusCb
+	callbackMu.RUnlock()
+	
+	if cb != nil {
+		state := "resumed"
+		if status.Paused {
+			state = "paused"
+		}
+		cItemID := C.CString("schedule")
+		cStatus := C.CString(state)
+		cMessage := C.CString(status.Message)
+		defer C.free(unsafe.Pointer(cItemID))
+		defer C.free(unsafe.Pointer(cStatus))
+		defer C.free(unsafe.Pointer(cMessage))
+		
+		C.call_status_callback(cb, cItemID, cStatus, cMessage)
+	}
+}
+
+// NotifyDiskSpace reports download.min_free_space_mb pausing or resuming dispatch through
+// the status callback as item "disk_space" with status "paused" or "resumed"; the message
+// (e.g. "low disk space: 120 MB free, 500 MB required") is passed in place of the error
+func (n *CallbackNotifier) NotifyDiskSpace(status *download.DiskSpaceStatus) {
+	callbackMu.RLock()
+	cb := statusCb
+	callbackMu.RUnlock()
+	
+	if cb != nil {
+		state := "resumed"
+		if status.Paused {
+			state = "paused"
+		}
+		cItemID := C.CString("disk_space")
+		cStatus := C.CString(state)
+		cMessage := C.CString(status.Message)
+		defer C.free(unsafe.Pointer(cItemID))
+		defer C.free(unsafe.Pointer(cStatus))
+		defer C.free(unsafe.Pointer(cMessage))
+		
+		C.call_status_callback(cb, cItemID, cStatus, cMessage)
+	}
+}
+
+// NotifyDiscovery reports an album or playlist's tracks being looked up before they are
+// queued, through the status callback with status "resolving" and a message such as
+// "resolving 120/300 tracks" in place of the error
+func (n *CallbackNotifier) NotifyDiscovery(itemID string, resolved, total int) {
+	callbackMu.RLock()
+	cb := statusCb
+	callbackMu.RUnlock()
+	
+	if cb != nil {
+		cItemID := C.CString(itemID)
+		cStatus := C.CString("resolving")
+		cMessage := C.CString(download.DiscoveryMessage(resolved, total))
+		defer C.free(unsafe.Pointer(cItemID))
+		defer C.free(unsafe.Pointer(cStatus))
+		defer C.free(unsafe.Pointer(cMessage))
+		
+		C.call_status_callback(cb, cItemID, cStatus, cMessage)
+	}
+}
+
+// NotifyCompletion passes a finished batch to the completion callback as JSON, for a toast
+// or system notification. Per-album summaries are only sent when SetCompletionCallback asked for them.
+func (n *CallbackNotifier) NotifyCompletion(summary *download.CompletionSummary) {
+	callbackMu.RLock()
+	cb := completionCb
+	perAlbum := completionPerAlbum
+	callbackMu.RUnlock()
+	
+	if cb == nil || (summary.Kind != "queue" && !perAlbum) {
+		return
+	}
+	
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return
+	}
+	cSummary := C.CString(string(summaryJSON))
+	defer C.free(unsafe.Pointer(cSummary))
+	
+	C.call_completion_callback(cb, cSummary)
+}
+
+// GetAllDownloadStats returns live stats for all tracked downloads
+func (n *CallbackNotifier) GetAllDownloadStats() []*download.DownloadStats {
+	if n.stats == nil {
+		return []*download.DownloadStats{}
+	}
+	return n.stats.GetAllDownloadStats()
+}
+
 func (n *CallbackNotifier) notifyQueueUpdate() {
+	sendQueueUpdate()
+}
+
+// sendQueueUpdate passes the current queue stats to the queue update callback, for changes
+// made outside the download manager
+func sendQueueUpdate() {
 	callbackMu.RLock()
 	cb := queueUpdateCb
 	callbackMu.RUnlock()
@@ -297,7 +464,7 @@ func InitializeApp(configPath *C.char) C.int {
 	
 	// Initialize components
 	queueStore = store.NewQueueStore(db)
-	deezerAPI = api.NewDeezerClient(30 * time.Second)
+	deezerAPI = api.NewDeezerClient(time.Duration(cfg.Network.APITimeout) * time.Second)
 	
 	// Cleanup: Fix albums that were incorrectly marked as completed with 0 tracks
 	logDebug("Running database cleanup for incomplete albums...")
@@ -308,15 +475,6 @@ func InitializeApp(configPath *C.char) C.int {
 		fmt.Fprintf(os.Stderr, "[INFO] Fixed %d incomplete albums in database\n", cleanupCount)
 	}
 	
-	// Cleanup: Fix albums stuck in "downloading" status where all tracks are finished
-	logDebug("Running database cleanup for stuck albums...")
-	if stuckCount, err := queueStore.FixStuckAlbums(); err != nil {
-		logDebug("Stuck album cleanup failed: %v", err)
-	} else if stuckCount > 0 {
-		logDebug("Database cleanup: Fixed %d stuck albums", stuckCount)
-		fmt.Fprintf(os.Stderr, "[INFO] Fixed %d stuck albums in database\n", stuckCount)
-	}
-	
 	// Authenticate with Deezer
 	logDebug("Checking Deezer ARL configuration...")
 	if cfg.Deezer.ARL != "" {
@@ -329,6 +487,11 @@ func InitializeApp(configPath *C.char) C.int {
 		} else {
 			logDebug("Deezer authentication SUCCESSFUL")
 			fmt.Fprintf(os.Stderr, "[INFO] Deezer authentication successful\n")
+			// Settings saved before the account lost HiFi are kept, but downloads will be MP3
+			if err := cfg.ValidateForAccount(deezerAPI.MaxQuality()); err != nil {
+				logDebug("WARNING: %v", err)
+				fmt.Fprintf(os.Stderr, "[WARN] %v\n", err)
+			}
 		}
 	} else {
 		logDebug("No Deezer ARL configured!")
@@ -336,7 +499,7 @@ func InitializeApp(configPath *C.char) C.int {
 	}
 	
 	// Create download manager with callback notifier
-	notifier := &CallbackNotifier{}
+	notifier := &CallbackNotifier{stats: download.NewStatsTracker()}
 	downloadMgr = download.NewManager(cfg, queueStore, deezerAPI, notifier)
 	
 	// Start download manager with application-lifetime context
@@ -350,6 +513,17 @@ func InitializeApp(configPath *C.char) C.int {
 	logDebug("Download manager started successfully with context that will live until shutdown")
 	fmt.Fprintf(os.Stderr, "[INFO] Download manager started successfully\n")
 	
+	// Cleanup: Fix albums stuck in "downloading" status where all tracks are finished. This
+	// runs after Start has re-enqueued interrupted albums, so a stale album that crashed
+	// mid-submission is resumed rather than completed with tracks missing
+	logDebug("Running database cleanup for stuck albums...")
+	if stuckCount, err := queueStore.FixStuckAlbums(); err != nil {
+		logDebug("Stuck album cleanup failed: %v", err)
+	} else if stuckCount > 0 {
+		logDebug("Database cleanup: Fixed %d stuck albums", stuckCount)
+		fmt.Fprintf(os.Stderr, "[INFO] Fixed %d stuck albums in database\n", stuckCount)
+	}
+	
 	// Add a goroutine to monitor context cancellation (for debugging)
 	// This goroutine will also attempt to recover from panics
 	fmt.Fprintf(os.Stderr, "[DEBUG] About to start context monitor goroutine\n")
@@ -397,6 +571,10 @@ func InitializeApp(configPath *C.char) C.int {
 	}()
 	fmt.Fprintf(os.Stderr, "[DEBUG] Context monitor goroutine started\n")
 	
+	settingsMu.Lock()
+	syncConfigWatcher()
+	settingsMu.Unlock()
+	
 	initialized = true
 	fmt.Fprintf(os.Stderr, "[INFO] Backend initialized successfully\n")
 	return 0
@@ -431,11 +609,25 @@ func ShutdownApp() {
 		logDebug("  %s:%d %s", file, line, fn.Name())
 	}
 	
+	// Stop reloading settings before the manager goes away
+	settingsMu.Lock()
+	if configWatcher != nil {
+		configWatcher.Close()
+		configWatcher = nil
+	}
+	settingsMu.Unlock()
+	
 	// Stop download manager
 	if downloadMgr != nil {
 		logDebug("[INFO] Stopping download manager...")
 		fmt.Fprintf(os.Stderr, "[INFO] Stopping download manager...\n")
-		downloadMgr.Stop()
+		
+		// Give in-flight downloads and tagging time to finish before the database goes away
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := downloadMgr.Stop(stopCtx); err != nil {
+			logDebug("[WARN] Download manager did not stop cleanly: %v", err)
+		}
+		stopCancel()
 	}
 	
 	// Close database
@@ -477,6 +669,21 @@ func SetQueueUpdateCallback(callback C.QueueUpdateCallback) {
 	callbackMu.Unlock()
 }
 
+//export SetCompletionCallback
+func SetCompletionCallback(callback C.CompletionCallback, perAlbum C.int) {
+	callbackMu.Lock()
+	completionCb = callback
+	completionPerAlbum = perAlbum != 0
+	callbackMu.Unlock()
+}
+
+//export SetParentProgressCallback
+func SetParentProgressCallback(callback C.ParentProgressCallback) {
+	callbackMu.Lock()
+	parentProgressCb = callback
+	callbackMu.Unlock()
+}
+
 //export FreeString
 func FreeString(str *C.char) {
 	C.free(unsafe.Pointer(str))
@@ -500,61 +707,97 @@ func Search(query *C.char, searchType *C.char, limit C.int) *C.char {
 		return C.CString(`{"error": "Backend not initialized"}`)
 	}
 	
-	goQuery := C.GoString(query)
-	goSearchType := C.GoString(searchType)
-	goLimit := int(limit)
+	return C.CString(runSearch(C.GoString(query), C.GoString(searchType), int(limit), 0, false))
+}
+
+//export SearchFiltered
+func SearchFiltered(query *C.char, searchType *C.char, limit C.int, hideExplicit C.int) *C.char {
+	if !checkInitialized() {
+		logDebug("SearchFiltered: Backend not initialized")
+		return C.CString(`{"error": "Backend not initialized"}`)
+	}
+	
+	return C.CString(runSearch(C.GoString(query), C.GoString(searchType), int(limit), 0, hideExplicit != 0))
+}
+
+//export SearchWithOffset
+func SearchWithOffset(query *C.char, searchType *C.char, limit C.int, offset C.int, hideExplicit C.int) *C.char {
+	if !checkInitialized() {
+		logDebug("SearchWithOffset: Backend not initialized")
+		return C.CString(`{"error": "Backend not initialized"}`)
+	}
 	
+	return C.CString(runSearch(C.GoString(query), C.GoString(searchType), int(limit), int(offset), hideExplicit != 0))
+}
+
+// runSearch performs a search for one page of results and returns the JSON response,
+// optionally dropping explicit tracks/albums
+func runSearch(goQuery, goSearchType string, goLimit, goOffset int, hideExplicit bool) string {
 	if goLimit <= 0 {
 		goLimit = 50
 	}
+	if goOffset < 0 {
+		goOffset = 0
+	}
 	
-	logDebug("Search called: query='%s', type='%s', limit=%d", goQuery, goSearchType, goLimit)
-	fmt.Fprintf(os.Stderr, "[INFO] Search: query='%s', type='%s', limit=%d\n", goQuery, goSearchType, goLimit)
+	logDebug("Search called: query='%s', type='%s', limit=%d, offset=%d, hideExplicit=%v", goQuery, goSearchType, goLimit, goOffset, hideExplicit)
+	fmt.Fprintf(os.Stderr, "[INFO] Search: query='%s', type='%s', limit=%d, offset=%d\n", goQuery, goSearchType, goLimit, goOffset)
 	
 	var results interface{}
+	var page *api.SearchPage
 	var err error
 	
 	switch goSearchType {
-	case "track":
-		results, err = deezerAPI.SearchTracks(ctx, goQuery, goLimit)
 	case "album":
-		results, err = deezerAPI.SearchAlbums(ctx, goQuery, goLimit)
+		results, page, err = deezerAPI.SearchAlbumsPage(ctx, goQuery, goLimit, goOffset)
 	case "artist":
-		results, err = deezerAPI.SearchArtists(ctx, goQuery, goLimit)
+		results, page, err = deezerAPI.SearchArtistsPage(ctx, goQuery, goLimit, goOffset)
 	case "playlist":
-		results, err = deezerAPI.SearchPlaylists(ctx, goQuery, goLimit)
+		results, page, err = deezerAPI.SearchPlaylistsPage(ctx, goQuery, goLimit, goOffset)
 	default:
-		results, err = deezerAPI.SearchTracks(ctx, goQuery, goLimit)
+		results, page, err = deezerAPI.SearchTracksPage(ctx, goQuery, goLimit, goOffset)
 	}
 	
 	if err != nil {
 		logDebug("Search failed: %v", err)
 		fmt.Fprintf(os.Stderr, "[ERROR] Search failed: %v\n", err)
 		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
-		return C.CString(string(errJSON))
+		return string(errJSON)
 	}
 	
-	// Wrap results in SearchResponse format expected by C#
-	// C# expects: {"data": [...], "total": N}
-	var total int
+	// Hide explicit content if requested (artists and playlists carry no reliable flag)
+	if hideExplicit {
+		switch v := results.(type) {
+		case []*api.Track:
+			results = api.FilterExplicitTracks(v)
+		case []*api.Album:
+			results = api.FilterExplicitAlbums(v)
+		}
+	}
+	
+	var count int
 	switch v := results.(type) {
 	case []*api.Track:
-		total = len(v)
-		logDebug("Search returned %d tracks", total)
+		count = len(v)
 	case []*api.Album:
-		total = len(v)
-		logDebug("Search returned %d albums", total)
+		count = len(v)
 	case []*api.Artist:
-		total = len(v)
-		logDebug("Search returned %d artists", total)
+		count = len(v)
 	case []*api.Playlist:
-		total = len(v)
-		logDebug("Search returned %d playlists", total)
+		count = len(v)
 	}
+	logDebug("Search returned %d %ss (offset %d, %d in total)", count, goSearchType, page.Offset, page.Total)
 	
+	// Wrap results in SearchResponse format expected by C#
+	// C# expects: {"data": [...], "total": N}; total is Deezer's full match count, count the
+	// size of this page after filtering
 	response := map[string]interface{}{
-		"data":  results,
-		"total": total,
+		"data":        results,
+		"total":       page.Total,
+		"count":       count,
+		"offset":      page.Offset,
+		"has_more":    page.HasMore,
+		"next_offset": page.NextOffset,
 	}
 	
 	jsonData, err := json.Marshal(response)
@@ -562,12 +805,12 @@ func Search(query *C.char, searchType *C.char, limit C.int) *C.char {
 		logDebug("Failed to marshal search results: %v", err)
 		fmt.Fprintf(os.Stderr, "[ERROR] Failed to marshal search results: %v\n", err)
 		errJSON, _ := json.Marshal(map[string]string{"error": "Failed to marshal results"})
-		return C.CString(string(errJSON))
+		return string(errJSON)
 	}
 	
-	logDebug("Search completed successfully, returning %d results (JSON length: %d)", total, len(jsonData))
-	fmt.Fprintf(os.Stderr, "[INFO] Search completed successfully, returning %d results\n", total)
-	return C.CString(string(jsonData))
+	logDebug("Search completed successfully, returning %d results (JSON length: %d)", count, len(jsonData))
+	fmt.Fprintf(os.Stderr, "[INFO] Search completed successfully, returning %d results\n", count)
+	return string(jsonData)
 }
 
 //export GetAlbum
@@ -616,6 +859,30 @@ func GetArtist(artistID *C.char) *C.char {
 	return C.CString(string(jsonData))
 }
 
+//export GetRelatedArtists
+func GetRelatedArtists(artistID *C.char, limit C.int) *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	goArtistID := C.GoString(artistID)
+	
+	artists, err := deezerAPI.GetRelatedArtists(ctx, goArtistID, int(limit))
+	if err != nil {
+		logDebug("GetRelatedArtists error: %v", err)
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	jsonData, err := json.Marshal(artists)
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": "failed to marshal artists"})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
 //export GetArtistAlbums
 func GetArtistAlbums(artistID *C.char, limit C.int) *C.char {
 	if !checkInitialized() {
@@ -654,6 +921,52 @@ func GetArtistAlbums(artistID *C.char, limit C.int) *C.char {
 	return C.CString(string(jsonData))
 }
 
+//export GetLyrics
+func GetLyrics(trackID *C.char) *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	goTrackID := C.GoString(trackID)
+	
+	lyrics, err := deezerAPI.GetLyrics(ctx, goTrackID)
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	// A track without lyrics is an empty result with has_lyrics false, not an error.
+	// Deezer doesn't report the lyrics' language, so this is the one used when embedding them.
+	jsonData, err := json.Marshal(struct {
+		*api.Lyrics
+		HasLyrics bool   `json:"has_lyrics"`
+		Language  string `json:"language"`
+	}{lyrics, lyrics.HasLyrics(), cfg.Lyrics.Language})
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": "failed to marshal lyrics"})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export FetchLyricsForFile
+func FetchLyricsForFile(filePath *C.char, trackID *C.char) C.int {
+	if !checkInitialized() {
+		return -1
+	}
+	
+	goFilePath := C.GoString(filePath)
+	goTrackID := C.GoString(trackID)
+	
+	if err := downloadMgr.FetchLyricsForFile(ctx, goFilePath, goTrackID); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to fetch lyrics for %s: %v\n", goFilePath, err)
+		return -2
+	}
+	
+	return 0
+}
+
 //export GetPlaylist
 func GetPlaylist(playlistID *C.char) *C.char {
 	if !checkInitialized() {
@@ -734,6 +1047,59 @@ func GetEditorialReleases(limit C.int) *C.char {
 	return C.CString(string(jsonData))
 }
 
+//export GetUserPlaylists
+func GetUserPlaylists() *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	playlists, err := deezerAPI.GetUserPlaylists(ctx)
+	return userLibraryJSON(playlists, len(playlists), err)
+}
+
+//export GetUserFavoriteTracks
+func GetUserFavoriteTracks() *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	tracks, err := deezerAPI.GetUserFavoriteTracks(ctx)
+	return userLibraryJSON(tracks, len(tracks), err)
+}
+
+//export GetUserFavoriteAlbums
+func GetUserFavoriteAlbums() *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	albums, err := deezerAPI.GetUserFavoriteAlbums(ctx)
+	return userLibraryJSON(albums, len(albums), err)
+}
+
+// userLibraryJSON wraps a user library listing as {"data": [...], "total": n}. A session
+// that can't see the library is flagged with "permission": true so the UI can ask for a new ARL.
+func userLibraryJSON(data interface{}, total int, err error) *C.char {
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]interface{}{
+			"error":      err.Error(),
+			"permission": errors.Is(err, api.ErrLibraryPermission) || errors.Is(err, api.ErrNotLoggedIn),
+		})
+		return C.CString(string(errJSON))
+	}
+	
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"data":  data,
+		"total": total,
+	})
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": "failed to marshal user library"})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
 //export DownloadTrack
 func DownloadTrack(trackID *C.char, quality *C.char) C.int {
 	if !checkInitialized() {
@@ -755,6 +1121,10 @@ func DownloadTrack(trackID *C.char, quality *C.char) C.int {
 	fmt.Fprintf(os.Stderr, "[INFO] Downloading track: %s\n", goTrackID)
 	err := downloadMgr.DownloadTrack(ctx, goTrackID)
 	if err != nil {
+		if errors.Is(err, download.ErrAlreadyDownloaded) {
+			fmt.Fprintf(os.Stderr, "[INFO] Track %s already downloaded\n", goTrackID)
+			return -16
+		}
 		fmt.Fprintf(os.Stderr, "[ERROR] Failed to download track %s: %v\n", goTrackID, err)
 		return -2
 	}
@@ -765,18 +1135,29 @@ func DownloadTrack(trackID *C.char, quality *C.char) C.int {
 
 //export DownloadAlbum
 func DownloadAlbum(albumID *C.char, quality *C.char) C.int {
+	return queueAlbum("DownloadAlbum", albumID, quality, false)
+}
+
+//export DownloadDiscographyAlbum
+func DownloadDiscographyAlbum(albumID *C.char, quality *C.char) C.int {
+	return queueAlbum("DownloadDiscographyAlbum", albumID, quality, true)
+}
+
+// queueAlbum backs DownloadAlbum and DownloadDiscographyAlbum, which differ only in whether
+// the album is queued as part of its artist's discography
+func queueAlbum(name string, albumID *C.char, quality *C.char, discography bool) C.int {
 	if !checkInitialized() {
-		logDebug("DownloadAlbum: Backend not initialized")
+		logDebug("%s: Backend not initialized", name)
 		return -1
 	}
 	
 	goAlbumID := C.GoString(albumID)
 	
 	// Log the album ID being downloaded
-	logDebug("DownloadAlbum called with ID: '%s'", goAlbumID)
+	logDebug("%s called with ID: '%s'", name, goAlbumID)
 	
 	if goAlbumID == "" {
-		logDebug("DownloadAlbum: Album ID is empty!")
+		logDebug("%s: Album ID is empty!", name)
 		return -3
 	}
 	
@@ -785,22 +1166,30 @@ func DownloadAlbum(albumID *C.char, quality *C.char) C.int {
 		goQuality := C.GoString(quality)
 		if goQuality != "" {
 			cfg.Download.Quality = goQuality
-			logDebug("DownloadAlbum: Quality set to %s", goQuality)
+			logDebug("%s: Quality set to %s", name, goQuality)
 		}
 	}
 	
-	logDebug("DownloadAlbum: Calling downloadMgr.DownloadAlbum...")
-	err := downloadMgr.DownloadAlbum(ctx, goAlbumID)
+	logDebug("%s: Calling downloadMgr.%s...", name, name)
+	var err error
+	if discography {
+		err = downloadMgr.DownloadDiscographyAlbum(ctx, goAlbumID)
+	} else {
+		err = downloadMgr.DownloadAlbum(ctx, goAlbumID)
+	}
 	if err != nil {
-		logDebug("DownloadAlbum: Failed to download album %s: %v", goAlbumID, err)
+		logDebug("%s: Failed to download album %s: %v", name, goAlbumID, err)
 		// Check if it's a duplicate album error
 		if strings.Contains(err.Error(), "already in queue") {
 			return -15 // Specific error code for duplicate
 		}
+		if errors.Is(err, download.ErrAlreadyDownloaded) {
+			return -16 // Every track is already on disk
+		}
 		return -2
 	}
 	
-	logDebug("DownloadAlbum: Album %s download initiated successfully", goAlbumID)
+	logDebug("%s: Album %s download initiated successfully", name, goAlbumID)
 	return 0
 }
 
@@ -829,6 +1218,47 @@ func DownloadPlaylist(playlistID *C.char, quality *C.char) C.int {
 	return 0
 }
 
+//export SyncPlaylist
+func SyncPlaylist(playlistID *C.char) C.int {
+	if !checkInitialized() {
+		return -1
+	}
+	
+	goPlaylistID := C.GoString(playlistID)
+	
+	added, err := downloadMgr.SyncPlaylist(ctx, goPlaylistID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to sync playlist: %v\n", err)
+		return -2
+	}
+	
+	logDebug("SyncPlaylist %s queued %d new tracks", goPlaylistID, added)
+	return 0
+}
+
+//export PrepareAlbumFolders
+func PrepareAlbumFolders(albumID *C.char, create C.int) *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	goAlbumID := C.GoString(albumID)
+	
+	plan, err := downloadMgr.PrepareAlbumFolders(ctx, goAlbumID, create != 0)
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	jsonData, err := json.Marshal(plan)
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": "failed to marshal folder plan"})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
 //export DownloadCustomPlaylist
 func DownloadCustomPlaylist(playlistJSON *C.char, quality *C.char) C.int {
 	if !checkInitialized() {
@@ -854,7 +1284,49 @@ func DownloadCustomPlaylist(playlistJSON *C.char, quality *C.char) C.int {
 	return 0
 }
 
-//export ConvertSpotifyURL
+//export DownloadTrackList
+func DownloadTrackList(idsJSON *C.char, name *C.char) C.int {
+	if !checkInitialized() {
+		return -1
+	}
+	
+	var trackIDs []string
+	if err := json.Unmarshal([]byte(C.GoString(idsJSON)), &trackIDs); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to parse track IDs: %v\n", err)
+		return -2
+	}
+	
+	goName := ""
+	if name != nil {
+		goName = C.GoString(name)
+	}
+	
+	err := downloadMgr.DownloadTrackList(ctx, trackIDs, goName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to download track list: %v\n", err)
+		return -2
+	}
+	
+	return 0
+}
+
+//export ResolveURL
+func ResolveURL(url *C.char) *C.char {
+	// Parsing needs no session, so this works before Initialize
+	resolveCtx, resolveCancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer resolveCancel()
+	
+	kind, id, err := api.ParseDeezerURL(resolveCtx, C.GoString(url))
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	jsonData, _ := json.Marshal(map[string]string{"type": kind, "id": id})
+	return C.CString(string(jsonData))
+}
+
+//export ConvertSpotifyURL
 func ConvertSpotifyURL(url *C.char) *C.char {
 	if !checkInitialized() {
 		return C.CString(`{"error": "not initialized"}`)
@@ -871,7 +1343,7 @@ func ConvertSpotifyURL(url *C.char) *C.char {
 	}
 	
 	// Create Spotify client
-	spotifyClient := api.NewSpotifyClient(cfg.Spotify.ClientID, cfg.Spotify.ClientSecret, 30*time.Second)
+	spotifyClient := api.NewSpotifyClient(cfg.Spotify.ClientID, cfg.Spotify.ClientSecret, time.Duration(cfg.Network.APITimeout)*time.Second)
 	
 	// Authenticate
 	if err := spotifyClient.Authenticate(ctx); err != nil {
@@ -907,6 +1379,17 @@ func ConvertSpotifyURL(url *C.char) *C.char {
 
 //export GetQueue
 func GetQueue(offset C.int, limit C.int, filter *C.char) *C.char {
+	return getQueue(offset, limit, filter, nil)
+}
+
+//export GetQueueSorted
+func GetQueueSorted(offset C.int, limit C.int, filter *C.char, order *C.char) *C.char {
+	return getQueue(offset, limit, filter, order)
+}
+
+// getQueue backs GetQueue and GetQueueSorted. Items are ordered by when they were added:
+// oldest first, or newest first when order is "newest".
+func getQueue(offset C.int, limit C.int, filter *C.char, order *C.char) *C.char {
 	if !checkInitialized() {
 		logDebug("GetQueue: Backend not initialized")
 		return C.CString(`{"error": "not initialized"}`)
@@ -918,8 +1401,9 @@ func GetQueue(offset C.int, limit C.int, filter *C.char) *C.char {
 	if filter != nil {
 		goFilter = C.GoString(filter)
 	}
+	newestFirst := order != nil && C.GoString(order) == "newest"
 	
-	logDebug("GetQueue called: offset=%d, limit=%d, filter='%s'", goOffset, goLimit, goFilter)
+	logDebug("GetQueue called: offset=%d, limit=%d, filter='%s', newestFirst=%v", goOffset, goLimit, goFilter, newestFirst)
 	
 	// Default limit
 	if goLimit <= 0 {
@@ -938,27 +1422,32 @@ func GetQueue(offset C.int, limit C.int, filter *C.char) *C.char {
 	// Get items based on filter
 	switch goFilter {
 	case "pending":
-		items, err = queueStore.GetByStatus("pending", goOffset, goLimit)
+		items, err = queueStore.GetByStatusOrdered("pending", goOffset, goLimit, newestFirst)
 		if err == nil {
 			totalCount, _ = queueStore.GetCountByStatus("pending")
 		}
 	case "downloading":
-		items, err = queueStore.GetByStatus("downloading", goOffset, goLimit)
+		items, err = queueStore.GetByStatusOrdered("downloading", goOffset, goLimit, newestFirst)
 		if err == nil {
 			totalCount, _ = queueStore.GetCountByStatus("downloading")
 		}
 	case "completed":
-		items, err = queueStore.GetByStatus("completed", goOffset, goLimit)
+		items, err = queueStore.GetByStatusOrdered("completed", goOffset, goLimit, newestFirst)
 		if err == nil {
 			totalCount, _ = queueStore.GetCountByStatus("completed")
 		}
 	case "failed":
-		items, err = queueStore.GetByStatus("failed", goOffset, goLimit)
+		items, err = queueStore.GetByStatusOrdered("failed", goOffset, goLimit, newestFirst)
 		if err == nil {
 			totalCount, _ = queueStore.GetCountByStatus("failed")
 		}
+	case "cancelled":
+		items, err = queueStore.GetByStatusOrdered("cancelled", goOffset, goLimit, newestFirst)
+		if err == nil {
+			totalCount, _ = queueStore.GetCountByStatus("cancelled")
+		}
 	default:
-		items, err = queueStore.GetAll(goOffset, goLimit)
+		items, err = queueStore.GetAllOrdered(goOffset, goLimit, newestFirst)
 		if err == nil {
 			totalCount, _ = queueStore.GetCount()
 		}
@@ -980,11 +1469,16 @@ func GetQueue(offset C.int, limit C.int, filter *C.char) *C.char {
 	}
 	
 	// Return paginated response with metadata
+	sortOrder := "oldest"
+	if newestFirst {
+		sortOrder = "newest"
+	}
 	response := map[string]interface{}{
 		"items":  items,
 		"total":  totalCount,
 		"offset": goOffset,
 		"limit":  goLimit,
+		"order":  sortOrder,
 	}
 	
 	jsonData, err := json.Marshal(response)
@@ -1022,6 +1516,107 @@ func GetQueueStats() *C.char {
 	return C.CString(string(jsonData))
 }
 
+//export GetQueueSnapshot
+func GetQueueSnapshot() *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	items, err := queueStore.GetSnapshot()
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"items": items,
+		"total": len(items),
+	})
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": "failed to marshal queue snapshot"})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export GetActiveDownloads
+func GetActiveDownloads() *C.char {
+	if !checkInitialized() {
+		return C.CString(`[]`)
+	}
+	
+	jsonData, err := json.Marshal(downloadMgr.GetActiveDownloads())
+	if err != nil {
+		logDebug("Failed to marshal active downloads: %v", err)
+		return C.CString(`[]`)
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export GetScheduleStatus
+func GetScheduleStatus() *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	jsonData, err := json.Marshal(downloadMgr.GetScheduleStatus())
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export GetDiskSpaceStatus
+func GetDiskSpaceStatus() *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	jsonData, err := json.Marshal(downloadMgr.GetDiskSpaceStatus())
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export GetThroughputStats
+func GetThroughputStats() *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	jsonData, err := json.Marshal(downloadMgr.GetThroughputReport())
+	if err != nil {
+		logDebug("Failed to marshal throughput stats: %v", err)
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export GetWorkerPoolStatus
+func GetWorkerPoolStatus() *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	jsonData, err := json.Marshal(downloadMgr.GetWorkerPoolStatus())
+	if err != nil {
+		logDebug("Failed to marshal worker pool status: %v", err)
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
 //export GetFailedTracks
 func GetFailedTracks(parentID *C.char) *C.char {
 	if !checkInitialized() {
@@ -1045,6 +1640,76 @@ func GetFailedTracks(parentID *C.char) *C.char {
 	return C.CString(string(jsonData))
 }
 
+//export GetDiscProgress
+func GetDiscProgress(albumItemID *C.char) *C.char {
+	if !checkInitialized() {
+		return C.CString(`[]`)
+	}
+	
+	goAlbumItemID := C.GoString(albumItemID)
+	
+	progress, err := downloadMgr.GetDiscProgress(goAlbumItemID)
+	if err != nil {
+		logDebug("Failed to get disc progress for %s: %v", goAlbumItemID, err)
+		return C.CString(`[]`)
+	}
+	
+	jsonData, err := json.Marshal(progress)
+	if err != nil {
+		logDebug("Failed to marshal disc progress: %v", err)
+		return C.CString(`[]`)
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export GetLibraryArtists
+func GetLibraryArtists() *C.char {
+	if !checkInitialized() {
+		return C.CString(`[]`)
+	}
+	
+	artists, err := queueStore.GetHistoryArtists()
+	if err != nil {
+		logDebug("Failed to get library artists: %v", err)
+		return C.CString(`[]`)
+	}
+	
+	jsonData, err := json.Marshal(artists)
+	if err != nil {
+		logDebug("Failed to marshal library artists: %v", err)
+		return C.CString(`[]`)
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export GetLibraryAlbums
+func GetLibraryAlbums(artist *C.char) *C.char {
+	if !checkInitialized() {
+		return C.CString(`[]`)
+	}
+	
+	goArtist := ""
+	if artist != nil {
+		goArtist = C.GoString(artist)
+	}
+	
+	albums, err := queueStore.GetHistoryAlbums(goArtist)
+	if err != nil {
+		logDebug("Failed to get library albums for %q: %v", goArtist, err)
+		return C.CString(`[]`)
+	}
+	
+	jsonData, err := json.Marshal(albums)
+	if err != nil {
+		logDebug("Failed to marshal library albums: %v", err)
+		return C.CString(`[]`)
+	}
+	
+	return C.CString(string(jsonData))
+}
+
 //export PauseDownload
 func PauseDownload(itemID *C.char) C.int {
 	if !checkInitialized() {
@@ -1096,6 +1761,115 @@ func CancelDownload(itemID *C.char) C.int {
 	return 0
 }
 
+//export RemoveItem
+func RemoveItem(itemID *C.char) C.int {
+	if !checkInitialized() {
+		return -1
+	}
+	
+	goItemID := C.GoString(itemID)
+	
+	err := downloadMgr.RemoveItem(goItemID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to remove queue item: %v\n", err)
+		return -2
+	}
+	
+	return 0
+}
+
+//export DeleteDownloadFiles
+func DeleteDownloadFiles(itemID *C.char) *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	result, err := downloadMgr.DeleteWithFiles(C.GoString(itemID))
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	logDebug("DeleteDownloadFiles %s: %d files, %d folders, %d bytes freed, %d skipped", result.ItemID, result.FilesRemoved, result.FoldersRemoved, result.BytesFreed, len(result.Skipped))
+	jsonData, err := json.Marshal(result)
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export SetItemQuality
+func SetItemQuality(itemID *C.char, quality *C.char) C.int {
+	if !checkInitialized() {
+		return -1
+	}
+	
+	err := downloadMgr.SetItemQuality(C.GoString(itemID), C.GoString(quality))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set item quality: %v\n", err)
+		return -2
+	}
+	
+	return 0
+}
+
+//export SetItemOutputPath
+func SetItemOutputPath(itemID *C.char, path *C.char) C.int {
+	if !checkInitialized() {
+		return -1
+	}
+	
+	err := downloadMgr.SetItemOutputPath(C.GoString(itemID), C.GoString(path))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set item output path: %v\n", err)
+		return -2
+	}
+	
+	return 0
+}
+
+//export CancelByStatus
+func CancelByStatus(status *C.char) C.int {
+	if !checkInitialized() {
+		return -1
+	}
+	
+	goStatus := C.GoString(status)
+	
+	cancelled, err := downloadMgr.CancelByStatus(goStatus)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to cancel %s downloads: %v\n", goStatus, err)
+		return -2
+	}
+	
+	logDebug("CancelByStatus removed %d %s items", cancelled, goStatus)
+	return 0
+}
+
+//export GetPausedDownloads
+func GetPausedDownloads() *C.char {
+	if !checkInitialized() {
+		return C.CString(`[]`)
+	}
+	
+	parents, err := downloadMgr.GetPausedDownloads()
+	if err != nil {
+		logDebug("Failed to get paused downloads: %v", err)
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	jsonData, err := json.Marshal(parents)
+	if err != nil {
+		logDebug("Failed to marshal paused downloads: %v", err)
+		return C.CString(`[]`)
+	}
+	
+	return C.CString(string(jsonData))
+}
+
 //export RetryDownload
 func RetryDownload(itemID *C.char) C.int {
 	if !checkInitialized() {
@@ -1150,11 +1924,28 @@ func RetryDownload(itemID *C.char) C.int {
 		
 		logDebug("Reset %d failed tracks for %s", retriedCount, item.ID)
 	} else {
+		// A cancelled album or playlist gets its cancelled tracks back
+		if item.Status == "cancelled" {
+			if reset, err := queueStore.ResetCancelledChildren(goItemID); err != nil {
+				logDebug("Failed to reset cancelled tracks: %v", err)
+			} else {
+				logDebug("Reset %d cancelled tracks for %s", reset, item.ID)
+			}
+		}
+		
+		// An album or playlist's tracks get a fresh set of attempts too
+		if item.Type == "album" || item.Type == "playlist" {
+			if _, err := queueStore.ResetRetries(goItemID); err != nil {
+				logDebug("Failed to reset track retries: %v", err)
+			}
+		}
+		
 		// For single tracks or fully failed items, reset normally
 		item.Status = "pending"
 		item.ErrorMessage = ""
 		item.Progress = 0
 	}
+	item.RetryCount = 0
 	
 	err = queueStore.Update(item)
 	if err != nil {
@@ -1165,6 +1956,39 @@ func RetryDownload(itemID *C.char) C.int {
 	return 0
 }
 
+//export ResetRetries
+func ResetRetries(itemID *C.char) C.int {
+	if !checkInitialized() {
+		return -1
+	}
+	
+	goItemID := C.GoString(itemID)
+	reset, err := queueStore.ResetRetries(goItemID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to reset retries: %v\n", err)
+		return -2
+	}
+	
+	logDebug("ResetRetries %s: reset %d items", goItemID, reset)
+	return C.int(reset)
+}
+
+//export ResetAllFailedRetries
+func ResetAllFailedRetries() C.int {
+	if !checkInitialized() {
+		return -1
+	}
+	
+	reset, err := queueStore.ResetFailedRetries()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to reset retries: %v\n", err)
+		return -2
+	}
+	
+	logDebug("ResetAllFailedRetries: reset %d items", reset)
+	return C.int(reset)
+}
+
 //export ClearCompleted
 func ClearCompleted() C.int {
 	if !checkInitialized() {
@@ -1204,6 +2028,131 @@ func ClearCompleted() C.int {
 	return 0
 }
 
+//export ExportQueue
+func ExportQueue() *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	data, err := queueStore.ExportQueue()
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(data))
+}
+
+//export ImportQueue
+func ImportQueue(exportJSON *C.char) C.int {
+	if !checkInitialized() {
+		return -1
+	}
+	
+	imported, err := queueStore.ImportQueue([]byte(C.GoString(exportJSON)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to import queue: %v\n", err)
+		return -2
+	}
+	
+	logDebug("ImportQueue imported %d items", imported)
+	return 0
+}
+
+//export RetagUntagged
+func RetagUntagged() C.int {
+	if !checkInitialized() {
+		return -1
+	}
+	
+	retagged, err := downloadMgr.RetagUntagged(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to re-tag untagged tracks: %v\n", err)
+		return -2
+	}
+	
+	logDebug("RetagUntagged re-tagged %d tracks", retagged)
+	return C.int(retagged)
+}
+
+//export RepairAlbum
+func RepairAlbum(itemID *C.char) *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	result, err := downloadMgr.RepairAlbum(C.GoString(itemID))
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	logDebug("RepairAlbum %s: status=%s, %d/%d tracks, resubmitted %d", result.ItemID, result.Status, result.CompletedTracks, result.TotalTracks, result.Resubmitted)
+	jsonData, err := json.Marshal(result)
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export RepairAllStuck
+func RepairAllStuck() C.int {
+	if !checkInitialized() {
+		return -1
+	}
+	
+	incomplete, stuck, err := runQueueMaintenance()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return -2
+	}
+	
+	logDebug("RepairAllStuck: reset %d incomplete, completed %d stuck", incomplete, stuck)
+	return C.int(incomplete + stuck)
+}
+
+//export RunQueueMaintenance
+func RunQueueMaintenance() *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	incomplete, stuck, err := runQueueMaintenance()
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	logDebug("RunQueueMaintenance: reset %d incomplete, completed %d stuck", incomplete, stuck)
+	jsonData, _ := json.Marshal(map[string]int{
+		"incomplete": incomplete,
+		"stuck":      stuck,
+		"fixed":      incomplete + stuck,
+	})
+	return C.CString(string(jsonData))
+}
+
+// runQueueMaintenance runs the recovery Initialize runs at startup, on demand: albums and
+// playlists marked completed with missing tracks go back to pending, and ones stuck
+// downloading with every track finished are completed. The UI is sent a queue update after.
+func runQueueMaintenance() (incomplete, stuck int, err error) {
+	incomplete, err = queueStore.FixIncompleteAlbums()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to fix incomplete albums: %w", err)
+	}
+	stuck, err = queueStore.FixStuckAlbums()
+	if err != nil {
+		return incomplete, 0, fmt.Errorf("failed to fix stuck albums: %w", err)
+	}
+	
+	if incomplete+stuck > 0 {
+		sendQueueUpdate()
+	}
+	return incomplete, stuck, nil
+}
+
 //export GetSettings
 func GetSettings() *C.char {
 	if !checkInitialized() {
@@ -1219,6 +2168,85 @@ func GetSettings() *C.char {
 	return C.CString(string(jsonData))
 }
 
+//export GetAccountInfo
+func GetAccountInfo() *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	maxQuality := deezerAPI.MaxQuality()
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"authenticated": deezerAPI.IsAuthenticated(),
+		"max_quality":   maxQuality,
+		"flac":          maxQuality == api.QualityFLAC,
+	})
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": "failed to marshal account info"})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export RunDiagnostics
+func RunDiagnostics() *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	
+	report := downloadMgr.RunDiagnostics(ctx)
+	logDebug("[INFO] Diagnostics finished (passed=%v)", report.Passed)
+	jsonData, err := json.Marshal(report)
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export GetSettingsSchema
+func GetSettingsSchema() *C.char {
+	// The schema is static, so it is available before Initialize
+	jsonData, err := json.Marshal(config.Schema())
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": "failed to marshal settings schema"})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export GetNetworkEndpoints
+func GetNetworkEndpoints() *C.char {
+	// The host list is static, so firewall rules can be set up before Initialize
+	jsonData, err := json.Marshal(api.NetworkEndpoints())
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": "failed to marshal network endpoints"})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export GetEffectiveTemplates
+func GetEffectiveTemplates() *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	jsonData, err := json.Marshal(cfg.Download.Templates())
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": "failed to marshal templates"})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
 //export UpdateSettings
 func UpdateSettings(settingsJSON *C.char) C.int {
 	if !checkInitialized() {
@@ -1227,6 +2255,9 @@ func UpdateSettings(settingsJSON *C.char) C.int {
 	
 	goSettingsJSON := C.GoString(settingsJSON)
 	
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	
 	// Log what we received
 	logDebug("UpdateSettings called, JSON length: %d", len(goSettingsJSON))
 	
@@ -1247,6 +2278,12 @@ func UpdateSettings(settingsJSON *C.char) C.int {
 		return -3
 	}
 	
+	if err := newCfg.ValidateForAccount(deezerAPI.MaxQuality()); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid settings: %v\n", err)
+		logDebug("Invalid settings: %v", err)
+		return -3
+	}
+	
 	// Save to file
 	configPath := config.GetConfigPath()
 	logDebug("Saving settings to: %s", configPath)
@@ -1267,11 +2304,120 @@ func UpdateSettings(settingsJSON *C.char) C.int {
 		logDebug("WARNING: downloadMgr is nil, cannot update config")
 	}
 	
+	syncConfigWatcher()
+	
 	logDebug("Settings updated successfully, quality=%s", newCfg.Download.Quality)
 	
 	return 0
 }
 
+// syncConfigWatcher starts or stops watching settings.json to match system.watch_config.
+// Callers hold settingsMu.
+func syncConfigWatcher() {
+	if cfg.System.WatchConfig && configWatcher == nil {
+		watcher, err := config.Watch(config.GetConfigPath(), applyWatchedConfig, func(err error) {
+			logDebug("Ignoring settings file change: %v", err)
+		})
+		if err != nil {
+			logDebug("Failed to watch settings file: %v", err)
+			return
+		}
+		configWatcher = watcher
+		logDebug("Watching settings file for external changes")
+	} else if !cfg.System.WatchConfig && configWatcher != nil {
+		configWatcher.Close()
+		configWatcher = nil
+		logDebug("Stopped watching settings file")
+	}
+}
+
+// applyWatchedConfig applies settings.json after it was edited outside the app
+func applyWatchedConfig(newCfg *config.Config) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	
+	// Saves from UpdateSettings/SetSetting trigger the watcher too
+	if reflect.DeepEqual(cfg, newCfg) {
+		return
+	}
+	
+	if err := newCfg.ValidateForAccount(deezerAPI.MaxQuality()); err != nil {
+		logDebug("WARNING: %v", err)
+	}
+	
+	cfg = newCfg
+	if downloadMgr != nil {
+		downloadMgr.UpdateConfig(newCfg)
+	}
+	logDebug("Settings reloaded from disk, quality=%s, concurrent=%d", newCfg.Download.Quality, newCfg.Download.ConcurrentDownloads)
+	
+	syncConfigWatcher()
+}
+
+//export GetSetting
+func GetSetting(keyPath *C.char) *C.char {
+	if !checkInitialized() {
+		return C.CString(`{"error": "not initialized"}`)
+	}
+	
+	settingsMu.Lock()
+	value, err := cfg.Get(C.GoString(keyPath))
+	settingsMu.Unlock()
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return C.CString(string(errJSON))
+	}
+	
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		errJSON, _ := json.Marshal(map[string]string{"error": "failed to marshal setting"})
+		return C.CString(string(errJSON))
+	}
+	
+	return C.CString(string(jsonData))
+}
+
+//export SetSetting
+func SetSetting(keyPath *C.char, valueJSON *C.char) C.int {
+	if !checkInitialized() {
+		return -1
+	}
+	
+	goKeyPath := C.GoString(keyPath)
+	
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	
+	newCfg, err := cfg.WithSetting(goKeyPath, []byte(C.GoString(valueJSON)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid setting %s: %v\n", goKeyPath, err)
+		logDebug("Invalid setting %s: %v", goKeyPath, err)
+		return -2
+	}
+	
+	if err := newCfg.ValidateForAccount(deezerAPI.MaxQuality()); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid setting %s: %v\n", goKeyPath, err)
+		logDebug("Invalid setting %s: %v", goKeyPath, err)
+		return -2
+	}
+	
+	if err := newCfg.Save(config.GetConfigPath()); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save settings: %v\n", err)
+		logDebug("Failed to save settings: %v", err)
+		return -3
+	}
+	
+	cfg = newCfg
+	if downloadMgr != nil {
+		downloadMgr.UpdateConfig(newCfg)
+	}
+	
+	syncConfigWatcher()
+	
+	logDebug("Setting %s updated", goKeyPath)
+	return 0
+}
+
 //export GetDownloadPath
 func GetDownloadPath() *C.char {
 	if !checkInitialized() {
@@ -1295,6 +2441,9 @@ func SetDownloadPath(path *C.char) C.int {
 		return -2
 	}
 	
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	
 	cfg.Download.OutputDir = goPath
 	
 	// Save config
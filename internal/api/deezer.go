@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -21,6 +22,24 @@ const (
 	deezerMediaURL   = "https://media.deezer.com"
 )
 
+// ErrRateLimited is returned when Deezer throttles requests: an HTTP 429, or the public API's
+// quota error once its own retries are used up
+var ErrRateLimited = errors.New("rate limited by Deezer")
+
+// NotFoundReason prefixes the error message of tracks that failed because of ErrNotFound
+const NotFoundReason = "NOT_FOUND"
+
+// ErrNotFound is returned when Deezer has no data for an ID, e.g. a track that was removed
+// or renumbered. Retrying the same ID can't succeed.
+var ErrNotFound = errors.New(NotFoundReason + ": Deezer has no data for this ID")
+
+// ErrPrivateNoData is returned when the private API answers with a DATA_ERROR: it has no data
+// of that kind for the ID, e.g. song.getLyrics for a track without lyrics
+var ErrPrivateNoData = errors.New("DATA_ERROR: Deezer has no data of this kind for the ID")
+
+// publicErrorNoData is the public API error code for an ID Deezer doesn't know ("no data")
+const publicErrorNoData = 800
+
 // DeezerClient handles all Deezer API interactions
 type DeezerClient struct {
 	httpClient   *http.Client
@@ -28,6 +47,7 @@ type DeezerClient struct {
 	apiToken     string
 	licenseToken string
 	userID       string
+	maxQuality   string // Best quality the account may stream, from getUserData
 	rateLimiter  *rate.Limiter
 	mu           sync.RWMutex
 	authenticated bool
@@ -149,7 +169,11 @@ func (c *DeezerClient) getLicenseToken(ctx context.Context) error {
 		Results struct {
 			User struct {
 				Options struct {
-					License string `json:"license_token"`
+					License        string `json:"license_token"`
+					WebLossless    bool   `json:"web_lossless"`
+					MobileLossless bool   `json:"mobile_lossless"`
+					WebHQ          bool   `json:"web_hq"`
+					MobileHQ       bool   `json:"mobile_hq"`
 				} `json:"OPTIONS"`
 			} `json:"USER"`
 		} `json:"results"`
@@ -159,10 +183,36 @@ func (c *DeezerClient) getLicenseToken(ctx context.Context) error {
 		return fmt.Errorf("failed to decode license response: %w", err)
 	}
 
-	c.licenseToken = result.Results.User.Options.License
+	options := result.Results.User.Options
+	c.licenseToken = options.License
+	c.maxQuality = accountMaxQuality(options.WebLossless || options.MobileLossless, options.WebHQ || options.MobileHQ)
 	return nil
 }
 
+// accountMaxQuality maps the account's streaming options to the best quality it can download.
+// Free accounts get neither option and are limited to 128 kbps MP3.
+func accountMaxQuality(lossless, hq bool) string {
+	switch {
+	case lossless:
+		return QualityFLAC
+	case hq:
+		return QualityMP3320
+	default:
+		return QualityMP3128
+	}
+}
+
+// MaxQuality returns the best quality the logged-in account can download, or "" before
+// authentication
+func (c *DeezerClient) MaxQuality() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.authenticated {
+		return ""
+	}
+	return c.maxQuality
+}
+
 // RefreshToken refreshes the authentication tokens
 func (c *DeezerClient) RefreshToken(ctx context.Context) error {
 	c.mu.Lock()
@@ -183,6 +233,20 @@ func (c *DeezerClient) IsAuthenticated() bool {
 	return c.authenticated
 }
 
+// Ping checks the public API is reachable and that Deezer is available from this
+// connection's country. Unlike the cached lookups it always makes a request.
+func (c *DeezerClient) Ping(ctx context.Context) error {
+	result, err := c.doPublicAPIRequest(ctx, "/infos", nil)
+	if err != nil {
+		return err
+	}
+	if open, ok := result["open"].(bool); ok && !open {
+		country, _ := result["country"].(string)
+		return fmt.Errorf("Deezer is not available in %s", country)
+	}
+	return nil
+}
+
 // doRequest performs an HTTP request with rate limiting
 func (c *DeezerClient) doRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
 	// Wait for rate limiter
@@ -195,6 +259,11 @@ func (c *DeezerClient) doRequest(ctx context.Context, req *http.Request) (*http.
 		return nil, err
 	}
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		resp.Body.Close()
+		return nil, ErrRateLimited
+	}
+
 	// Check for authentication errors
 	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
 		resp.Body.Close()
@@ -256,13 +325,22 @@ func (c *DeezerClient) doPrivateAPIRequest(ctx context.Context, method string, p
 	// Check for errors
 	if errData, ok := result["error"].(map[string]interface{}); ok && errData != nil {
 		if code, ok := errData["code"].(float64); ok && code != 0 {
-			return nil, fmt.Errorf("API error: %v", errData)
+			return nil, privateAPIError(errData)
 		}
 	}
 
 	return result, nil
 }
 
+// privateAPIError turns the private API's error object into an error, wrapping
+// ErrPrivateNoData when Deezer reports a DATA_ERROR
+func privateAPIError(errData map[string]interface{}) error {
+	if _, ok := errData["DATA_ERROR"]; ok {
+		return fmt.Errorf("API error: %w: %v", ErrPrivateNoData, errData)
+	}
+	return fmt.Errorf("API error: %v", errData)
+}
+
 // doPublicAPIRequest performs a request to Deezer's public API with retry on quota errors
 func (c *DeezerClient) doPublicAPIRequest(ctx context.Context, endpoint string, params url.Values) (map[string]interface{}, error) {
 	maxRetries := 3
@@ -314,6 +392,10 @@ func (c *DeezerClient) doPublicAPIRequest(ctx context.Context, endpoint string,
 						return nil, ctx.Err()
 					}
 				}
+				return nil, fmt.Errorf("%w: API error: %v", ErrRateLimited, errData)
+			}
+			if code, ok := errData["code"].(float64); ok && code == publicErrorNoData {
+				return nil, fmt.Errorf("%w: API error: %v", ErrNotFound, errData)
 			}
 			return nil, fmt.Errorf("API error: %v", errData)
 		}
@@ -321,5 +403,5 @@ func (c *DeezerClient) doPublicAPIRequest(ctx context.Context, endpoint string,
 		return result, nil
 	}
 	
-	return nil, fmt.Errorf("max retries exceeded for quota limit")
+	return nil, fmt.Errorf("%w: max retries exceeded for quota limit", ErrRateLimited)
 }
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 )
@@ -36,6 +37,23 @@ func TestAuthenticateEmptyARL(t *testing.T) {
 	}
 }
 
+func TestAccountMaxQuality(t *testing.T) {
+	if q := accountMaxQuality(true, true); q != QualityFLAC {
+		t.Errorf("Expected FLAC for a lossless account, got %s", q)
+	}
+	if q := accountMaxQuality(false, true); q != QualityMP3320 {
+		t.Errorf("Expected MP3_320 for an HQ account, got %s", q)
+	}
+	if q := accountMaxQuality(false, false); q != QualityMP3128 {
+		t.Errorf("Expected MP3_128 for a free account, got %s", q)
+	}
+
+	client := NewDeezerClient(30 * time.Second)
+	if q := client.MaxQuality(); q != "" {
+		t.Errorf("Expected no max quality before authentication, got %s", q)
+	}
+}
+
 func TestGetFormatCode(t *testing.T) {
 	tests := []struct {
 		quality  string
@@ -248,3 +266,85 @@ func TestCache(t *testing.T) {
 		t.Error("Expected key1 to be expired")
 	}
 }
+
+func TestFilterExplicitTracks(t *testing.T) {
+	tracks := []*Track{
+		{Title: "Clean"},
+		{Title: "Flagged", ExplicitLyrics: true},
+		{Title: "Advisory", ExplicitContent: ExplicitContentExplicit},
+		{Title: "Partial", ExplicitContent: ExplicitContentPartiallyExplicit},
+		{Title: "Edited", ExplicitContent: ExplicitContentEdited},
+		nil,
+	}
+
+	filtered := FilterExplicitTracks(tracks)
+	if len(filtered) != 2 {
+		t.Fatalf("Expected 2 tracks, got %d", len(filtered))
+	}
+	if filtered[0].Title != "Clean" || filtered[1].Title != "Edited" {
+		t.Errorf("Unexpected tracks kept: %s, %s", filtered[0].Title, filtered[1].Title)
+	}
+	if len(tracks) != 6 {
+		t.Error("Expected input slice to be left untouched")
+	}
+
+	albums := FilterExplicitAlbums([]*Album{{Title: "Clean"}, {Title: "Explicit", ExplicitContent: ExplicitContentExplicit}})
+	if len(albums) != 1 || albums[0].Title != "Clean" {
+		t.Errorf("Expected only the clean album, got %d albums", len(albums))
+	}
+}
+
+func TestDedupeRelatedArtists(t *testing.T) {
+	related := []*Artist{
+		{ID: "27", Name: "Daft Punk"},
+		{ID: "1", Name: "Justice"},
+		nil,
+		{ID: "1", Name: "Justice"},
+		{ID: "2", Name: "Cassius"},
+		{ID: "3", Name: "Air"},
+	}
+
+	artists := dedupeRelatedArtists(related, "27", 2)
+	if len(artists) != 2 || artists[0].Name != "Justice" || artists[1].Name != "Cassius" {
+		t.Errorf("Unexpected related artists %+v", artists)
+	}
+
+	client := NewDeezerClient(30 * time.Second)
+	if _, err := client.GetRelatedArtists(context.Background(), "", 10); err == nil {
+		t.Error("Expected an empty artist ID to fail")
+	}
+}
+
+func TestIsNoLyricsError(t *testing.T) {
+	if !isNoLyricsError(fmt.Errorf("failed: %w", privateAPIError(map[string]interface{}{"code": float64(800), "DATA_ERROR": "lyrics not found"}))) {
+		t.Error("Expected DATA_ERROR to mean the track has no lyrics")
+	}
+	if isNoLyricsError(privateAPIError(map[string]interface{}{"code": float64(2), "VALID_TOKEN_REQUIRED": "Invalid CSRF token"})) {
+		t.Error("Expected other private API errors to stay errors")
+	}
+	// Only the typed error counts, not the text
+	if isNoLyricsError(fmt.Errorf("API error: DATA_ERROR")) {
+		t.Error("Expected an untyped error mentioning DATA_ERROR to stay an error")
+	}
+	if isNoLyricsError(fmt.Errorf("API request failed with status: %d", 500)) {
+		t.Error("Expected a request failure to stay an error")
+	}
+}
+
+func TestPageSongID(t *testing.T) {
+	tests := []struct {
+		data map[string]interface{}
+		want string
+	}{
+		{map[string]interface{}{"SNG_ID": "3135556"}, "3135556"},
+		{map[string]interface{}{"SNG_ID": "-1234567"}, "-1234567"},
+		{map[string]interface{}{"SNG_ID": float64(1920371667)}, "1920371667"},
+		{map[string]interface{}{"SNG_ID": ""}, "42"},
+		{map[string]interface{}{}, "42"},
+	}
+	for _, tt := range tests {
+		if got := pageSongID(tt.data, "42"); got != tt.want {
+			t.Errorf("pageSongID(%v) = %s, want %s", tt.data, got, tt.want)
+		}
+	}
+}
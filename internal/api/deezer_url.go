@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// deezerShortLinkHosts serve share links that redirect to a full deezer.com URL
+var deezerShortLinkHosts = map[string]bool{
+	"deezer.page.link": true,
+	"dzr.page.link":    true,
+	"link.deezer.com":  true,
+}
+
+// deezerURLKinds are the path segments that precede an ID in a deezer.com URL
+var deezerURLKinds = map[string]bool{
+	"track":    true,
+	"album":    true,
+	"playlist": true,
+	"artist":   true,
+}
+
+// ParseDeezerURL returns the kind ("track", "album", "playlist" or "artist") and ID of a
+// pasted Deezer link. Full URLs with or without a locale prefix (deezer.com/en/album/123)
+// are parsed directly; share links (deezer.page.link, dzr.page.link) are resolved by
+// following their redirect.
+func ParseDeezerURL(ctx context.Context, rawURL string) (string, string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid URL: %w", err)
+	}
+
+	if deezerShortLinkHosts[strings.ToLower(u.Hostname())] {
+		resolved, err := resolveShortLink(ctx, u.String(), 15*time.Second)
+		if err != nil {
+			return "", "", err
+		}
+		u = resolved
+	}
+
+	kind, id, ok := parseDeezerPath(u)
+	if !ok {
+		return "", "", fmt.Errorf("unsupported Deezer URL: %s", rawURL)
+	}
+	return kind, id, nil
+}
+
+// resolveShortLink follows a share link's redirects until they reach a Deezer URL that
+// names a track/album/playlist/artist, without loading that final page
+func resolveShortLink(ctx context.Context, shortURL string, timeout time.Duration) (*url.URL, error) {
+	client := &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("too many redirects")
+			}
+			if _, _, ok := parseDeezerPath(req.URL); ok {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", shortURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve short link: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Stopped on a redirect: the target is in the Location header
+	if location := resp.Header.Get("Location"); location != "" {
+		target, err := resp.Request.URL.Parse(location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redirect target: %w", err)
+		}
+		return target, nil
+	}
+
+	return resp.Request.URL, nil
+}
+
+// parseDeezerPath extracts kind and ID from a deezer.com URL such as
+// https://www.deezer.com/en/album/123?utm_source=share
+func parseDeezerPath(u *url.URL) (string, string, bool) {
+	host := strings.ToLower(u.Hostname())
+	if deezerShortLinkHosts[host] || (host != "deezer.com" && !strings.HasSuffix(host, ".deezer.com")) {
+		return "", "", false
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		kind := strings.ToLower(segments[i])
+		if deezerURLKinds[kind] && isNumericID(segments[i+1]) {
+			return kind, segments[i+1], true
+		}
+	}
+	return "", "", false
+}
+
+// isNumericID reports whether s is a non-empty string of digits
+func isNumericID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseDeezerURL(t *testing.T) {
+	tests := []struct {
+		url          string
+		expectedKind string
+		expectedID   string
+	}{
+		{"https://www.deezer.com/album/302127", "album", "302127"},
+		{"https://www.deezer.com/en/track/3135556?utm_source=share", "track", "3135556"},
+		{"deezer.com/fr/playlist/908622995", "playlist", "908622995"},
+		{"http://deezer.com/us/artist/27/", "artist", "27"},
+	}
+
+	for _, tt := range tests {
+		kind, id, err := ParseDeezerURL(context.Background(), tt.url)
+		if err != nil {
+			t.Errorf("ParseDeezerURL(%q) failed: %v", tt.url, err)
+			continue
+		}
+		if kind != tt.expectedKind || id != tt.expectedID {
+			t.Errorf("ParseDeezerURL(%q) = %s/%s, expected %s/%s", tt.url, kind, id, tt.expectedKind, tt.expectedID)
+		}
+	}
+
+	for _, bad := range []string{"https://example.com/album/1", "https://www.deezer.com/en/", "https://www.deezer.com/album/abc"} {
+		if _, _, err := ParseDeezerURL(context.Background(), bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestResolveShortLink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/abc":
+			http.Redirect(w, r, "/hop", http.StatusFound)
+		case "/hop":
+			http.Redirect(w, r, "https://www.deezer.com/en/album/302127?deferredFl=1", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	target, err := resolveShortLink(context.Background(), server.URL+"/abc", 5*time.Second)
+	if err != nil {
+		t.Fatalf("resolveShortLink failed: %v", err)
+	}
+
+	kind, id, ok := parseDeezerPath(target)
+	if !ok || kind != "album" || id != "302127" {
+		t.Errorf("Expected album/302127, got %s/%s from %s", kind, id, target)
+	}
+}
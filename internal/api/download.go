@@ -5,6 +5,7 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,6 +17,17 @@ import (
 	"time"
 )
 
+// GeoBlockedReason prefixes the error message of tracks that failed because of ErrGeoBlocked
+const GeoBlockedReason = "GEO_BLOCKED"
+
+// ErrGeoBlocked is returned when Deezer won't serve a track in the account's country.
+// Retrying can't succeed, so callers should fail the track straight away.
+var ErrGeoBlocked = errors.New(GeoBlockedReason + ": track is not available in your country")
+
+// mediaErrorGeoBlocked is the media API error code for a track the account has no rights to
+// in its country ("Track token has no sufficient rights on requested media")
+const mediaErrorGeoBlocked = 2002
+
 // GetTrackDownloadURL retrieves the download URL for a track with specified quality
 // Automatically falls back to lower quality if requested quality is not available
 func (c *DeezerClient) GetTrackDownloadURL(ctx context.Context, trackID string, quality string) (*DownloadURL, error) {
@@ -38,8 +50,8 @@ func (c *DeezerClient) GetTrackDownloadURL(ctx context.Context, trackID string,
 		return nil, fmt.Errorf("track is not available for download")
 	}
 
-	// Get track token from private API
-	trackToken, err := c.getTrackToken(ctx, trackID)
+	// Get track token from private API, and the song ID it was issued for
+	trackToken, keyID, err := c.getTrackToken(ctx, trackID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get track token: %w", err)
 	}
@@ -71,6 +83,7 @@ func (c *DeezerClient) GetTrackDownloadURL(ctx context.Context, trackID string,
 			
 			return &DownloadURL{
 				TrackID: trackID,
+				KeyID:   keyID,
 				Quality: tryQuality, // Return actual quality used
 				URL:     mediaURL,
 				Format:  getFormatFromQuality(tryQuality),
@@ -78,6 +91,11 @@ func (c *DeezerClient) GetTrackDownloadURL(ctx context.Context, trackID string,
 		}
 		lastErr = err
 		
+		// Rights are per track, not per format - no other quality will work either
+		if errors.Is(err, ErrGeoBlocked) {
+			return nil, err
+		}
+		
 		// Log the attempt
 		if logFile, logErr := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); logErr == nil {
 			fmt.Fprintf(logFile, "[%s] Quality %s not available for track %s, trying next quality...\n", 
@@ -90,15 +108,16 @@ func (c *DeezerClient) GetTrackDownloadURL(ctx context.Context, trackID string,
 	return nil, fmt.Errorf("failed to get media URL (tried all qualities): %w", lastErr)
 }
 
-// getTrackToken retrieves the track token needed for download URL generation
-func (c *DeezerClient) getTrackToken(ctx context.Context, trackID string) (string, error) {
+// getTrackToken retrieves the track token needed for download URL generation, and the
+// song ID (SNG_ID) the token belongs to, which the media is encrypted with
+func (c *DeezerClient) getTrackToken(ctx context.Context, trackID string) (string, string, error) {
 	// Use doPrivateAPIRequest which handles authentication properly
 	result, err := c.doPrivateAPIRequest(ctx, "deezer.pageTrack", map[string]interface{}{
 		"sng_id": trackID,
 	})
 	
 	if err != nil {
-		return "", fmt.Errorf("pageTrack request failed: %w", err)
+		return "", "", fmt.Errorf("pageTrack request failed: %w", err)
 	}
 
 	// Log the response to debug file
@@ -111,13 +130,13 @@ func (c *DeezerClient) getTrackToken(ctx context.Context, trackID string) (strin
 	// Extract track token from results
 	results, ok := result["results"].(map[string]interface{})
 	if !ok {
-		return "", fmt.Errorf("invalid response format: missing results")
+		return "", "", fmt.Errorf("invalid response format: missing results")
 	}
 
 	// Get DATA object which contains track information
 	data, ok := results["DATA"].(map[string]interface{})
 	if !ok {
-		return "", fmt.Errorf("invalid response format: missing DATA in results")
+		return "", "", fmt.Errorf("invalid response format: missing DATA in results")
 	}
 
 	trackToken, ok := data["TRACK_TOKEN"].(string)
@@ -128,10 +147,27 @@ func (c *DeezerClient) getTrackToken(ctx context.Context, trackID string) (strin
 			fmt.Fprintf(logFile, "[%s] DATA object (no TRACK_TOKEN found):\n%s\n\n", time.Now().Format("2006-01-02 15:04:05"), string(dataJSON))
 			logFile.Close()
 		}
-		return "", fmt.Errorf("track token not found in response")
+		return "", "", fmt.Errorf("track token not found in response")
 	}
 
-	return trackToken, nil
+	return trackToken, pageSongID(data, trackID), nil
+}
+
+// pageSongID returns the SNG_ID of a pageTrack DATA object. Deezer can answer with a
+// different version of the requested track (a re-release, or a negative ID for a user
+// upload), and the media is encrypted with that ID rather than the one requested.
+func pageSongID(data map[string]interface{}, trackID string) string {
+	switch id := data["SNG_ID"].(type) {
+	case string:
+		if id != "" {
+			return id
+		}
+	case float64:
+		return strconv.FormatInt(int64(id), 10)
+	case json.Number:
+		return id.String()
+	}
+	return trackID
 }
 
 // getMediaURL retrieves the actual media URL for downloading
@@ -219,6 +255,9 @@ func (c *DeezerClient) getMediaURL(ctx context.Context, trackID, trackToken, qua
 		errorInfo := errors[0].(map[string]interface{})
 		errorCode := errorInfo["code"]
 		errorMsg := errorInfo["message"]
+		if code, ok := errorCode.(float64); ok && int(code) == mediaErrorGeoBlocked {
+			return "", fmt.Errorf("%w (track error %v: %v)", ErrGeoBlocked, errorCode, errorMsg)
+		}
 		return "", fmt.Errorf("track error %v: %v", errorCode, errorMsg)
 	}
 
@@ -358,6 +397,68 @@ func (c *DeezerClient) GetArtistTopTracks(ctx context.Context, artistID string,
 	return tracks, nil
 }
 
+// GetRelatedArtists retrieves artists similar to the given one, leaving out the
+// artist itself and any duplicates Deezer returns
+func (c *DeezerClient) GetRelatedArtists(ctx context.Context, artistID string, limit int) ([]*Artist, error) {
+	if artistID == "" {
+		return nil, fmt.Errorf("artist ID cannot be empty")
+	}
+
+	if limit <= 0 {
+		limit = 20
+	}
+
+	// Check cache
+	cacheKey := fmt.Sprintf("artist_related_%s_%d", artistID, limit)
+	if cached, ok := responseCache.get(cacheKey); ok {
+		return cached.([]*Artist), nil
+	}
+
+	params := url.Values{}
+	params.Set("limit", strconv.Itoa(limit))
+
+	result, err := c.doPublicAPIRequest(ctx, fmt.Sprintf("/artist/%s/related", artistID), params)
+	if err != nil {
+		return nil, fmt.Errorf("get related artists failed: %w", err)
+	}
+
+	// Parse artists
+	dataBytes, err := json.Marshal(result["data"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal artist data: %w", err)
+	}
+
+	var related []*Artist
+	if err := json.Unmarshal(dataBytes, &related); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal artists: %w", err)
+	}
+
+	artists := dedupeRelatedArtists(related, artistID, limit)
+
+	// Cache result
+	responseCache.set(cacheKey, artists)
+
+	return artists, nil
+}
+
+// dedupeRelatedArtists drops nil entries, the seed artist and repeated IDs, keeping at
+// most limit artists in Deezer's order
+func dedupeRelatedArtists(related []*Artist, seedID string, limit int) []*Artist {
+	seen := map[string]bool{seedID: true}
+	artists := make([]*Artist, 0, len(related))
+	for _, artist := range related {
+		if artist == nil || seen[artist.ID.String()] {
+			continue
+		}
+		seen[artist.ID.String()] = true
+		artists = append(artists, artist)
+		if len(artists) == limit {
+			break
+		}
+	}
+	return artists
+}
+
 // GetArtistAlbums retrieves ALL of an artist's releases (albums, singles, EPs)
 // Handles pagination to fetch all results
 func (c *DeezerClient) GetArtistAlbums(ctx context.Context, artistID string, limit int) ([]*Album, error) {
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/url"
+	"sort"
+)
+
+// NetworkEndpoint is a host the app connects to, for firewall allowlisting
+type NetworkEndpoint struct {
+	Host    string `json:"host"`  // Hostname, or *.domain when the subdomain varies
+	Group   string `json:"group"` // api, media, cdn or spotify
+	Purpose string `json:"purpose"`
+}
+
+// NetworkEndpoints lists every host the app contacts over HTTPS (port 443). The media API
+// hands out audio URLs on numbered dzcdn.net proxies, so that domain is listed as a wildcard.
+func NetworkEndpoints() []NetworkEndpoint {
+	endpoints := []NetworkEndpoint{
+		{Host: hostOf(deezerAPIURL), Group: "api", Purpose: "Public API: search, tracks, albums, artists and playlists"},
+		{Host: hostOf(deezerPrivateAPI), Group: "api", Purpose: "Gateway API: login, track tokens and lyrics"},
+		{Host: hostOf(deezerMediaURL), Group: "media", Purpose: "Media API: download URLs for each quality"},
+		{Host: "*.dzcdn.net", Group: "cdn", Purpose: "Encrypted audio streams (e.g. e-cdns-proxy-0.dzcdn.net)"},
+		{Host: "e-cdns-images.dzcdn.net", Group: "cdn", Purpose: "Album and playlist artwork"},
+		{Host: "cdn-images.dzcdn.net", Group: "cdn", Purpose: "Artist images"},
+		{Host: hostOf(spotifyAuthURL), Group: "spotify", Purpose: "Spotify login, only for Spotify playlist conversion"},
+		{Host: hostOf(spotifyAPIURL), Group: "spotify", Purpose: "Spotify playlists, only for Spotify playlist conversion"},
+	}
+
+	shortLinks := make([]string, 0, len(deezerShortLinkHosts))
+	for host := range deezerShortLinkHosts {
+		shortLinks = append(shortLinks, host)
+	}
+	sort.Strings(shortLinks)
+	for _, host := range shortLinks {
+		endpoints = append(endpoints, NetworkEndpoint{Host: host, Group: "api", Purpose: "Resolving pasted Deezer share links"})
+	}
+
+	return endpoints
+}
+
+// hostOf returns the hostname of a base URL constant
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Hostname()
+}
@@ -0,0 +1,31 @@
+package api
+
+import "testing"
+
+func TestNetworkEndpoints(t *testing.T) {
+	endpoints := NetworkEndpoints()
+
+	seen := make(map[string]bool)
+	groups := make(map[string]bool)
+	for _, endpoint := range endpoints {
+		if endpoint.Host == "" || endpoint.Purpose == "" {
+			t.Errorf("Incomplete endpoint: %+v", endpoint)
+		}
+		if seen[endpoint.Host] {
+			t.Errorf("Duplicate host %s", endpoint.Host)
+		}
+		seen[endpoint.Host] = true
+		groups[endpoint.Group] = true
+	}
+
+	for _, host := range []string{"api.deezer.com", "www.deezer.com", "media.deezer.com", "*.dzcdn.net", "accounts.spotify.com", "deezer.page.link"} {
+		if !seen[host] {
+			t.Errorf("Expected %s to be listed", host)
+		}
+	}
+	for _, group := range []string{"api", "media", "cdn", "spotify"} {
+		if !groups[group] {
+			t.Errorf("Expected an endpoint in group %s", group)
+		}
+	}
+}
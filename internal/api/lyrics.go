@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -25,12 +26,9 @@ func (c *DeezerClient) GetLyrics(ctx context.Context, trackID string) (*Lyrics,
 	}
 
 	result, err := c.doPrivateAPIRequest(ctx, "song.getLyrics", params)
-	if err != nil {
-		// Lyrics might not be available, return empty lyrics instead of error
-		return &Lyrics{
-			ID:      trackID,
-			TrackID: trackID,
-		}, nil
+	if err != nil && !isNoLyricsError(err) {
+		// Request failures are not cached so callers can retry
+		return nil, fmt.Errorf("failed to fetch lyrics: %w", err)
 	}
 
 	// Extract lyrics from results
@@ -71,7 +69,10 @@ func (c *DeezerClient) GetLyrics(ctx context.Context, trackID string) (*Lyrics,
 	if lyrics.SyncedLyrics == "" && lyrics.UnsyncedLyrics == "" {
 		// Try getting from track data
 		trackLyrics, err := c.getLyricsFromTrackData(ctx, trackID)
-		if err == nil && trackLyrics != nil {
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch lyrics: %w", err)
+		}
+		if trackLyrics != nil {
 			lyrics = trackLyrics
 		}
 	}
@@ -82,6 +83,12 @@ func (c *DeezerClient) GetLyrics(ctx context.Context, trackID string) (*Lyrics,
 	return lyrics, nil
 }
 
+// isNoLyricsError reports whether song.getLyrics failed only because Deezer has no lyrics
+// for the track, which it signals with a DATA_ERROR rather than an empty result
+func isNoLyricsError(err error) bool {
+	return errors.Is(err, ErrPrivateNoData)
+}
+
 // getLyricsFromTrackData attempts to get lyrics from track data
 func (c *DeezerClient) getLyricsFromTrackData(ctx context.Context, trackID string) (*Lyrics, error) {
 	params := map[string]interface{}{
@@ -30,11 +30,14 @@ type Track struct {
 	ReleaseDate     string    `json:"release_date"`
 	Available       bool      `json:"readable"`
 	Contributors    []*Artist `json:"contributors"`
+	Gain            float64   `json:"gain"` // Loudness in dB used by Deezer's volume normalization; 0 when not provided
 	
 	// Internal fields (not serialized)
 	IsMultiDiscAlbum bool      `json:"-"` // Used for folder structure decisions
 	TotalDiscs       int       `json:"-"` // Total number of discs in the album
+	TotalTracks      int       `json:"-"` // Total number of tracks in the album
 	AlbumArtist      string    `json:"-"` // Album artist (Various Artists for compilations/soundtracks)
+	IsCompilation    bool      `json:"-"` // Album was detected (or forced) as a compilation/soundtrack
 	Playlist         *Playlist `json:"-"` // Playlist this track belongs to (for playlist downloads)
 	PlaylistPosition int       `json:"-"` // Position in playlist (for playlist downloads)
 }
@@ -47,6 +50,27 @@ func (t *Track) GetTrackNumber() int {
 	return t.TrackPosition
 }
 
+// Deezer explicit_content_lyrics advisory values
+const (
+	ExplicitContentNone              = 0
+	ExplicitContentExplicit          = 1
+	ExplicitContentUnknown           = 2
+	ExplicitContentEdited            = 3
+	ExplicitContentPartiallyExplicit = 4
+	ExplicitContentPartiallyUnknown  = 5
+	ExplicitContentNoAdvice          = 6
+)
+
+// isExplicitAdvisory reports whether an explicit_content_lyrics value marks explicit content
+func isExplicitAdvisory(code int) bool {
+	return code == ExplicitContentExplicit || code == ExplicitContentPartiallyExplicit
+}
+
+// IsExplicit reports whether the track is flagged explicit by either the flag or the advisory code
+func (t *Track) IsExplicit() bool {
+	return t.ExplicitLyrics || isExplicitAdvisory(t.ExplicitContent)
+}
+
 // Album represents a Deezer album
 type Album struct {
 	ID              FlexibleID `json:"id"`
@@ -77,6 +101,11 @@ type Album struct {
 	Tracks          *Tracks   `json:"tracks"`
 }
 
+// IsExplicit reports whether the album is flagged explicit by either the flag or the advisory code
+func (a *Album) IsExplicit() bool {
+	return a.ExplicitLyrics || isExplicitAdvisory(a.ExplicitContent)
+}
+
 // Artist represents a Deezer artist
 type Artist struct {
 	ID            FlexibleID `json:"id"`
@@ -177,6 +206,7 @@ type LyricLine struct {
 // DownloadURL represents a track download URL
 type DownloadURL struct {
 	TrackID  string
+	KeyID    string // Song ID the media is encrypted with; can differ from TrackID when Deezer serves another version
 	Quality  string
 	URL      string
 	FileSize int64
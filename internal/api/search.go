@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -60,6 +61,13 @@ func (c *cache) set(key string, value interface{}) {
 	}
 }
 
+func (c *cache) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	delete(c.data, key)
+}
+
 func (c *cache) cleanup() {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
@@ -79,8 +87,22 @@ func (c *cache) cleanup() {
 // Initialize cache in DeezerClient
 var responseCache = newCache(10 * time.Minute)
 
-// SearchTracks searches for tracks on Deezer
-func (c *DeezerClient) SearchTracks(ctx context.Context, query string, limit int) ([]*Track, error) {
+// SearchPage describes where a page of search results sits in Deezer's full result list
+type SearchPage struct {
+	Offset     int  `json:"offset"`
+	Total      int  `json:"total"` // Deezer's total match count, not the size of this page
+	HasMore    bool `json:"has_more"`
+	NextOffset int  `json:"next_offset,omitempty"` // Offset of the following page when HasMore
+}
+
+// searchResponse is a cached page of a /search query, kept raw so each caller gets its own copies
+type searchResponse struct {
+	data []byte
+	page *SearchPage
+}
+
+// searchPage runs one page of a /search/{kind} query starting at offset
+func (c *DeezerClient) searchPage(ctx context.Context, kind, query string, limit, offset int) (*searchResponse, error) {
 	if query == "" {
 		return nil, fmt.Errorf("search query cannot be empty")
 	}
@@ -88,31 +110,76 @@ func (c *DeezerClient) SearchTracks(ctx context.Context, query string, limit int
 	if limit <= 0 {
 		limit = 25
 	}
+	if offset < 0 {
+		offset = 0
+	}
 	
 	// Check cache
-	cacheKey := fmt.Sprintf("search_tracks_%s_%d", query, limit)
+	cacheKey := fmt.Sprintf("search_%ss_%s_%d_%d", kind, query, limit, offset)
 	if cached, ok := responseCache.get(cacheKey); ok {
-		return cached.([]*Track), nil
+		return cached.(*searchResponse), nil
 	}
 	
 	params := url.Values{}
 	params.Set("q", query)
 	params.Set("limit", strconv.Itoa(limit))
+	if offset > 0 {
+		params.Set("index", strconv.Itoa(offset))
+	}
 	
-	result, err := c.doPublicAPIRequest(ctx, "/search/track", params)
+	result, err := c.doPublicAPIRequest(ctx, "/search/"+kind, params)
 	if err != nil {
-		return nil, fmt.Errorf("search tracks failed: %w", err)
+		return nil, fmt.Errorf("search %ss failed: %w", kind, err)
 	}
 	
-	// Parse tracks
 	dataBytes, err := json.Marshal(result["data"])
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal track data: %w", err)
+		return nil, fmt.Errorf("failed to marshal %s data: %w", kind, err)
 	}
 	
+	response := &searchResponse{data: dataBytes, page: searchPageInfo(result, offset)}
+	
+	// Cache result
+	responseCache.set(cacheKey, response)
+	
+	return response, nil
+}
+
+// searchPageInfo reads Deezer's total and the index of its "next" link from a search response
+func searchPageInfo(result map[string]interface{}, offset int) *SearchPage {
+	page := &SearchPage{Offset: offset}
+	if total, ok := result["total"].(float64); ok {
+		page.Total = int(total)
+	}
+	if next, ok := result["next"].(string); ok && next != "" {
+		if nextURL, err := url.Parse(next); err == nil {
+			if index, err := strconv.Atoi(nextURL.Query().Get("index")); err == nil && index > offset {
+				page.HasMore = true
+				page.NextOffset = index
+			}
+		}
+	}
+	return page
+}
+
+// SearchTracks searches for tracks on Deezer
+func (c *DeezerClient) SearchTracks(ctx context.Context, query string, limit int) ([]*Track, error) {
+	tracks, _, err := c.SearchTracksPage(ctx, query, limit, 0)
+	return tracks, err
+}
+
+// SearchTracksPage searches for tracks starting at offset, returning where the page sits in
+// the full result list
+func (c *DeezerClient) SearchTracksPage(ctx context.Context, query string, limit, offset int) ([]*Track, *SearchPage, error) {
+	response, err := c.searchPage(ctx, "track", query, limit, offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	// Parse tracks
 	var tracks []*Track
-	if err := json.Unmarshal(dataBytes, &tracks); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal tracks: %w", err)
+	if err := json.Unmarshal(response.data, &tracks); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal tracks: %w", err)
 	}
 	
 	// Normalize track numbers for all tracks
@@ -123,139 +190,157 @@ func (c *DeezerClient) SearchTracks(ctx context.Context, query string, limit int
 				track.TrackNumber = actualTrackNum
 			}
 			track.TrackPosition = 0 // Clear to avoid confusion
+			track.ExplicitLyrics = track.IsExplicit() // Surface the advisory code in the flag the UI badges
 		}
 	}
 	
-	// Cache result
-	responseCache.set(cacheKey, tracks)
-	
-	return tracks, nil
+	return tracks, response.page, nil
 }
 
-// SearchAlbums searches for albums on Deezer
-func (c *DeezerClient) SearchAlbums(ctx context.Context, query string, limit int) ([]*Album, error) {
-	if query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
+// FindTrack searches for a track by title and artist and returns the best match with its
+// confidence from 0 to 1, scored on title, artist and, when duration (seconds) isn't 0, length.
+// It returns a nil track when the search finds nothing.
+func (c *DeezerClient) FindTrack(ctx context.Context, title, artist string, duration int) (*Track, float64, error) {
+	tracks, err := c.SearchTracks(ctx, strings.TrimSpace(artist+" "+title), 10)
+	if err != nil {
+		return nil, 0, err
 	}
-	
-	if limit <= 0 {
-		limit = 25
+	var best *Track
+	bestScore := 0.0
+	for _, track := range tracks {
+		if track == nil || track.Artist == nil {
+			continue
+		}
+		if score := trackMatchScore(title, artist, duration, track); score > bestScore {
+			best, bestScore = track, score
+		}
 	}
-	
-	// Check cache
-	cacheKey := fmt.Sprintf("search_albums_%s_%d", query, limit)
-	if cached, ok := responseCache.get(cacheKey); ok {
-		return cached.([]*Album), nil
+	return best, bestScore, nil
+}
+
+// trackMatchScore scores a search result against a title, artist and duration, using the
+// same string similarity as the Spotify converter
+func trackMatchScore(title, artist string, duration int, track *Track) float64 {
+	var sc SpotifyConverter
+	titleScore := sc.stringSimilarity(sc.normalizeString(title), sc.normalizeString(track.Title))
+	artistScore := sc.stringSimilarity(sc.normalizeString(artist), sc.normalizeString(track.Artist.Name))
+	if duration <= 0 || track.Duration <= 0 {
+		return titleScore*0.55 + artistScore*0.45
 	}
-	
-	params := url.Values{}
-	params.Set("q", query)
-	params.Set("limit", strconv.Itoa(limit))
-	
-	result, err := c.doPublicAPIRequest(ctx, "/search/album", params)
-	if err != nil {
-		return nil, fmt.Errorf("search albums failed: %w", err)
+
+	durationScore := 1.0
+	if diff := abs(duration - track.Duration); diff > 5 {
+		durationScore = 1.0 - float64(diff)/float64(duration)
+		if durationScore < 0 {
+			durationScore = 0
+		}
 	}
-	
-	// Parse albums
-	dataBytes, err := json.Marshal(result["data"])
+	return titleScore*0.5 + artistScore*0.4 + durationScore*0.1
+}
+
+// SearchAlbums searches for albums on Deezer
+func (c *DeezerClient) SearchAlbums(ctx context.Context, query string, limit int) ([]*Album, error) {
+	albums, _, err := c.SearchAlbumsPage(ctx, query, limit, 0)
+	return albums, err
+}
+
+// SearchAlbumsPage searches for albums starting at offset, returning where the page sits in
+// the full result list
+func (c *DeezerClient) SearchAlbumsPage(ctx context.Context, query string, limit, offset int) ([]*Album, *SearchPage, error) {
+	response, err := c.searchPage(ctx, "album", query, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal album data: %w", err)
+		return nil, nil, err
 	}
 	
+	// Parse albums
 	var albums []*Album
-	if err := json.Unmarshal(dataBytes, &albums); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal albums: %w", err)
+	if err := json.Unmarshal(response.data, &albums); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal albums: %w", err)
 	}
 	
-	// Cache result
-	responseCache.set(cacheKey, albums)
+	// Surface the advisory code in the flag the UI badges
+	for _, album := range albums {
+		if album != nil {
+			album.ExplicitLyrics = album.IsExplicit()
+		}
+	}
 	
-	return albums, nil
+	return albums, response.page, nil
 }
 
-// SearchArtists searches for artists on Deezer
-func (c *DeezerClient) SearchArtists(ctx context.Context, query string, limit int) ([]*Artist, error) {
-	if query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
-	}
-	
-	if limit <= 0 {
-		limit = 25
+// FilterExplicitTracks returns the tracks that are not flagged explicit.
+// A new slice is returned so cached search results are left untouched.
+func FilterExplicitTracks(tracks []*Track) []*Track {
+	filtered := make([]*Track, 0, len(tracks))
+	for _, track := range tracks {
+		if track != nil && !track.IsExplicit() {
+			filtered = append(filtered, track)
+		}
 	}
-	
-	// Check cache
-	cacheKey := fmt.Sprintf("search_artists_%s_%d", query, limit)
-	if cached, ok := responseCache.get(cacheKey); ok {
-		return cached.([]*Artist), nil
+	return filtered
+}
+
+// FilterExplicitAlbums returns the albums that are not flagged explicit
+func FilterExplicitAlbums(albums []*Album) []*Album {
+	filtered := make([]*Album, 0, len(albums))
+	for _, album := range albums {
+		if album != nil && !album.IsExplicit() {
+			filtered = append(filtered, album)
+		}
 	}
-	
-	params := url.Values{}
-	params.Set("q", query)
-	params.Set("limit", strconv.Itoa(limit))
-	
-	result, err := c.doPublicAPIRequest(ctx, "/search/artist", params)
+	return filtered
+}
+
+// SearchArtists searches for artists on Deezer
+func (c *DeezerClient) SearchArtists(ctx context.Context, query string, limit int) ([]*Artist, error) {
+	artists, _, err := c.SearchArtistsPage(ctx, query, limit, 0)
+	return artists, err
+}
+
+// SearchArtistsPage searches for artists starting at offset, returning where the page sits in
+// the full result list
+func (c *DeezerClient) SearchArtistsPage(ctx context.Context, query string, limit, offset int) ([]*Artist, *SearchPage, error) {
+	response, err := c.searchPage(ctx, "artist", query, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("search artists failed: %w", err)
+		return nil, nil, err
 	}
 	
 	// Parse artists
-	dataBytes, err := json.Marshal(result["data"])
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal artist data: %w", err)
-	}
-	
 	var artists []*Artist
-	if err := json.Unmarshal(dataBytes, &artists); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal artists: %w", err)
+	if err := json.Unmarshal(response.data, &artists); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal artists: %w", err)
 	}
 	
-	// Cache result
-	responseCache.set(cacheKey, artists)
-	
-	return artists, nil
+	return artists, response.page, nil
 }
 
 // SearchPlaylists searches for playlists on Deezer
 func (c *DeezerClient) SearchPlaylists(ctx context.Context, query string, limit int) ([]*Playlist, error) {
-	if query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
-	}
-	
-	if limit <= 0 {
-		limit = 25
-	}
-	
-	// Check cache
-	cacheKey := fmt.Sprintf("search_playlists_%s_%d", query, limit)
-	if cached, ok := responseCache.get(cacheKey); ok {
-		return cached.([]*Playlist), nil
-	}
-	
-	params := url.Values{}
-	params.Set("q", query)
-	params.Set("limit", strconv.Itoa(limit))
-	
-	result, err := c.doPublicAPIRequest(ctx, "/search/playlist", params)
+	playlists, _, err := c.SearchPlaylistsPage(ctx, query, limit, 0)
+	return playlists, err
+}
+
+// SearchPlaylistsPage searches for playlists starting at offset, returning where the page sits
+// in the full result list
+func (c *DeezerClient) SearchPlaylistsPage(ctx context.Context, query string, limit, offset int) ([]*Playlist, *SearchPage, error) {
+	response, err := c.searchPage(ctx, "playlist", query, limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("search playlists failed: %w", err)
+		return nil, nil, err
 	}
 	
 	// Parse playlists
-	dataBytes, err := json.Marshal(result["data"])
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal playlist data: %w", err)
-	}
-	
 	var playlists []*Playlist
-	if err := json.Unmarshal(dataBytes, &playlists); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal playlists: %w", err)
+	if err := json.Unmarshal(response.data, &playlists); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal playlists: %w", err)
 	}
 	
-	// Cache result
-	responseCache.set(cacheKey, playlists)
-	
-	return playlists, nil
+	return playlists, response.page, nil
+}
+
+// RefreshAlbum retrieves album details like GetAlbum, bypassing any cached copy
+func (c *DeezerClient) RefreshAlbum(ctx context.Context, albumID string) (*Album, error) {
+	responseCache.delete(fmt.Sprintf("album_%s", albumID))
+	return c.GetAlbum(ctx, albumID)
 }
 
 // GetAlbum retrieves full album details including tracks
@@ -316,6 +401,45 @@ func (c *DeezerClient) GetAlbum(ctx context.Context, albumID string) (*Album, er
 	return &album, nil
 }
 
+// GetAlbumOriginalReleaseDate retrieves the album's original release date (YYYY-MM-DD) from the
+// private API. The public API only exposes the date of this particular release, which for
+// reissues and remasters is years after the original. Returns "" if Deezer doesn't know it.
+func (c *DeezerClient) GetAlbumOriginalReleaseDate(ctx context.Context, albumID string) (string, error) {
+	if albumID == "" {
+		return "", fmt.Errorf("album ID cannot be empty")
+	}
+	
+	// Check cache
+	cacheKey := fmt.Sprintf("album_original_date_%s", albumID)
+	if cached, ok := responseCache.get(cacheKey); ok {
+		return cached.(string), nil
+	}
+	
+	params := map[string]interface{}{
+		"alb_id": albumID,
+	}
+	
+	result, err := c.doPrivateAPIRequest(ctx, "album.getData", params)
+	if err != nil {
+		return "", fmt.Errorf("get album data failed: %w", err)
+	}
+	
+	results, ok := result["results"].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("invalid response format")
+	}
+	
+	originalDate, _ := results["ORIGINAL_RELEASE_DATE"].(string)
+	if strings.HasPrefix(originalDate, "0000") {
+		originalDate = "" // Deezer's placeholder for an unknown date
+	}
+	
+	// Cache result (even if empty, it won't change)
+	responseCache.set(cacheKey, originalDate)
+	
+	return originalDate, nil
+}
+
 // GetAlbumTracks fetches all tracks for an album using pagination
 func (c *DeezerClient) GetAlbumTracks(ctx context.Context, albumID string, expectedCount int) ([]*Track, error) {
 	var allTracks []*Track
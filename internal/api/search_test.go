@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSearchPageInfo(t *testing.T) {
+	page := searchPageInfo(map[string]interface{}{
+		"total": float64(812),
+		"next":  "https://api.deezer.com/search/track?q=daft%20punk&limit=25&index=50",
+	}, 25)
+	if page.Offset != 25 || page.Total != 812 || !page.HasMore || page.NextOffset != 50 {
+		t.Errorf("Unexpected page %+v", page)
+	}
+
+	// The last page has no next link
+	page = searchPageInfo(map[string]interface{}{"total": float64(812)}, 800)
+	if page.HasMore || page.NextOffset != 0 {
+		t.Errorf("Expected the last page to have no more results, got %+v", page)
+	}
+
+	// A next link that doesn't move forward is ignored
+	page = searchPageInfo(map[string]interface{}{"total": float64(30), "next": "https://api.deezer.com/search/track?q=x&index=0"}, 0)
+	if page.HasMore {
+		t.Errorf("Expected a non-advancing next link to be ignored, got %+v", page)
+	}
+}
+
+func TestSearchTracksPageCached(t *testing.T) {
+	client := NewDeezerClient(30 * time.Second)
+	page := &SearchPage{Offset: 25, Total: 60, HasMore: true, NextOffset: 50}
+	responseCache.set("search_tracks_paged test_25_25", &searchResponse{
+		data: []byte(`[{"id": 3135556, "title": "Harder, Better, Faster, Stronger"}]`),
+		page: page,
+	})
+	defer responseCache.delete("search_tracks_paged test_25_25")
+
+	tracks, got, err := client.SearchTracksPage(context.Background(), "paged test", 25, 25)
+	if err != nil {
+		t.Fatalf("SearchTracksPage failed: %v", err)
+	}
+	if len(tracks) != 1 || tracks[0].ID.String() != "3135556" {
+		t.Errorf("Unexpected tracks %+v", tracks)
+	}
+	if got.Total != 60 || got.NextOffset != 50 {
+		t.Errorf("Unexpected page %+v", got)
+	}
+
+	if _, _, err := client.SearchTracksPage(context.Background(), "", 25, 0); err == nil {
+		t.Error("Expected an empty query to fail")
+	}
+}
+
+func TestFindTrack(t *testing.T) {
+	client := NewDeezerClient(30 * time.Second)
+	responseCache.set("search_tracks_Daft Punk One More Time_10_0", &searchResponse{
+		data: []byte(`[
+			{"id": 1, "title": "One More Time (Live)", "duration": 380, "artist": {"id": 27, "name": "Daft Punk"}},
+			{"id": 3135553, "title": "One More Time", "duration": 320, "artist": {"id": 27, "name": "Daft Punk"}},
+			{"id": 2, "title": "One More Time", "duration": 200, "artist": {"id": 5, "name": "Someone Else"}}
+		]`),
+		page: &SearchPage{},
+	})
+	defer responseCache.delete("search_tracks_Daft Punk One More Time_10_0")
+
+	track, confidence, err := client.FindTrack(context.Background(), "One More Time", "Daft Punk", 0)
+	if err != nil {
+		t.Fatalf("FindTrack failed: %v", err)
+	}
+	if track == nil || track.ID.String() != "3135553" || confidence != 1 {
+		t.Errorf("Expected the exact title and artist to win with full confidence, got %v (%.2f)", track, confidence)
+	}
+
+	// A known duration counts against a result of a different length
+	live := &Track{Title: "One More Time", Duration: 380, Artist: &Artist{Name: "Daft Punk"}}
+	if score := trackMatchScore("One More Time", "Daft Punk", 320, live); score >= 1 || score < 0.9 {
+		t.Errorf("Expected a small penalty for a 60 second difference, got %.2f", score)
+	}
+}
@@ -0,0 +1,379 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// userLibraryPageSize is how many items are requested per page of a user's library
+const userLibraryPageSize = 100
+
+var (
+	// ErrNotLoggedIn is returned by the user library calls when there is no ARL session
+	ErrNotLoggedIn = errors.New("not logged in to Deezer")
+
+	// ErrLibraryPermission is returned when Deezer refuses the session access to the library,
+	// typically because the ARL expired or belongs to a restricted account
+	ErrLibraryPermission = errors.New("the ARL does not give access to this user's library")
+)
+
+// GetUserPlaylists retrieves the logged-in user's playlists, including private and
+// collaborative ones, which the public API only shows to OAuth apps
+func (c *DeezerClient) GetUserPlaylists(ctx context.Context) ([]*Playlist, error) {
+	items, err := c.getProfileTab(ctx, "playlists", "PLAYLIST_ID")
+	if err != nil {
+		return nil, fmt.Errorf("get user playlists failed: %w", err)
+	}
+
+	playlists := make([]*Playlist, 0, len(items))
+	for _, item := range items {
+		playlists = append(playlists, playlistFromGW(item))
+	}
+	return playlists, nil
+}
+
+// GetUserFavoriteAlbums retrieves the albums the logged-in user added to their favorites
+func (c *DeezerClient) GetUserFavoriteAlbums(ctx context.Context) ([]*Album, error) {
+	items, err := c.getProfileTab(ctx, "albums", "ALB_ID")
+	if err != nil {
+		return nil, fmt.Errorf("get favorite albums failed: %w", err)
+	}
+
+	albums := make([]*Album, 0, len(items))
+	for _, item := range items {
+		albums = append(albums, albumFromGW(item))
+	}
+	return albums, nil
+}
+
+// GetUserFavoriteTracks retrieves the logged-in user's favorite ("loved") tracks, most
+// recently added first
+func (c *DeezerClient) GetUserFavoriteTracks(ctx context.Context) ([]*Track, error) {
+	if _, err := c.sessionUserID(); err != nil {
+		return nil, err
+	}
+
+	// The favorites list only holds IDs; the track details are looked up page by page
+	favorites, err := collectPages(func(start, nb int) ([]map[string]interface{}, int, error) {
+		result, err := c.doUserLibraryRequest(ctx, "song.getFavoriteIds", map[string]interface{}{
+			"nb":       nb,
+			"start":    start,
+			"checksum": nil,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		data, total := gwListing(result["results"])
+		return data, total, nil
+	}, "SNG_ID")
+	if err != nil {
+		return nil, fmt.Errorf("get favorite tracks failed: %w", err)
+	}
+
+	tracks := make([]*Track, 0, len(favorites))
+	for start := 0; start < len(favorites); start += userLibraryPageSize {
+		end := start + userLibraryPageSize
+		if end > len(favorites) {
+			end = len(favorites)
+		}
+
+		ids := make([]string, 0, end-start)
+		for _, favorite := range favorites[start:end] {
+			ids = append(ids, gwString(favorite["SNG_ID"]))
+		}
+
+		result, err := c.doUserLibraryRequest(ctx, "song.getListData", map[string]interface{}{
+			"sng_ids": ids,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get favorite tracks failed: %w", err)
+		}
+
+		data, _ := gwListing(result["results"])
+		for _, item := range data {
+			tracks = append(tracks, trackFromGW(item))
+		}
+	}
+
+	return tracks, nil
+}
+
+// sessionUserID returns the ID of the user the ARL belongs to
+func (c *DeezerClient) sessionUserID() (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.authenticated || c.userID == "" || c.userID == "0" {
+		return "", ErrNotLoggedIn
+	}
+	return c.userID, nil
+}
+
+// getProfileTab fetches every item of one tab of the logged-in user's profile page
+func (c *DeezerClient) getProfileTab(ctx context.Context, tab, idKey string) ([]map[string]interface{}, error) {
+	userID, err := c.sessionUserID()
+	if err != nil {
+		return nil, err
+	}
+
+	return collectPages(func(start, nb int) ([]map[string]interface{}, int, error) {
+		result, err := c.doUserLibraryRequest(ctx, "deezer.pageProfile", map[string]interface{}{
+			"USER_ID": userID,
+			"tab":     tab,
+			"nb":      nb,
+			"start":   start,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+
+		results, _ := result["results"].(map[string]interface{})
+		tabs, _ := results["TAB"].(map[string]interface{})
+		data, total := gwListing(tabs[tab])
+		return data, total, nil
+	}, idKey)
+}
+
+// doUserLibraryRequest performs a private API request and checks the response for the
+// errors the gateway reports by name, which doPrivateAPIRequest doesn't recognise
+func (c *DeezerClient) doUserLibraryRequest(ctx context.Context, method string, params map[string]interface{}) (map[string]interface{}, error) {
+	result, err := c.doPrivateAPIRequest(ctx, method, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := libraryError(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// libraryError returns the error in a gateway response, if any. Deezer sends an empty list
+// on success and a map such as {"PERMISSION_DENIED": "..."} on failure.
+func libraryError(result map[string]interface{}) error {
+	errData, ok := result["error"].(map[string]interface{})
+	if !ok || len(errData) == 0 {
+		return nil
+	}
+
+	for name := range errData {
+		if strings.Contains(name, "PERMISSION") || strings.Contains(name, "TOKEN") || strings.Contains(name, "AUTH") {
+			return fmt.Errorf("%w: %v", ErrLibraryPermission, errData)
+		}
+	}
+	return fmt.Errorf("API error: %v", errData)
+}
+
+// collectPages fetches a paginated gateway listing until a page comes back short or
+// the total is reached. Items are de-duplicated by idKey, and a page that adds nothing
+// new also ends the listing so a method that ignores start can't loop forever.
+func collectPages(fetch func(start, nb int) ([]map[string]interface{}, int, error), idKey string) ([]map[string]interface{}, error) {
+	var items []map[string]interface{}
+	seen := make(map[string]bool)
+
+	for start := 0; ; start += userLibraryPageSize {
+		page, total, err := fetch(start, userLibraryPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		added := 0
+		for _, item := range page {
+			id := gwString(item[idKey])
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			items = append(items, item)
+			added++
+		}
+
+		if added == 0 || len(page) < userLibraryPageSize || (total > 0 && len(items) >= total) {
+			break
+		}
+	}
+
+	return items, nil
+}
+
+// gwListing extracts the items and total count from a gateway listing ({"data": [...], "total": n})
+func gwListing(node interface{}) ([]map[string]interface{}, int) {
+	listing, ok := node.(map[string]interface{})
+	if !ok {
+		return nil, 0
+	}
+
+	raw, _ := listing["data"].([]interface{})
+	data := make([]map[string]interface{}, 0, len(raw))
+	for _, entry := range raw {
+		if item, ok := entry.(map[string]interface{}); ok {
+			data = append(data, item)
+		}
+	}
+	return data, gwInt(listing["total"])
+}
+
+// gwString reads a gateway field that may be sent as a string or a number
+func gwString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
+
+// gwInt reads a gateway number that may be sent as a string
+func gwInt(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	return 0
+}
+
+// gwFloat reads a gateway decimal that may be sent as a string
+func gwFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return 0
+}
+
+// gwImageURL builds a CDN image URL from the picture hash the gateway returns
+func gwImageURL(kind, md5 string, size int) string {
+	return fmt.Sprintf("https://e-cdns-images.dzcdn.net/images/%s/%s/%dx%d-000000-80-0-0.jpg", kind, md5, size, size)
+}
+
+// gwExplicitStatus reads EXPLICIT_LYRICS_STATUS from a gateway explicit content object
+func gwExplicitStatus(value interface{}) int {
+	content, _ := value.(map[string]interface{})
+	return gwInt(content["EXPLICIT_LYRICS_STATUS"])
+}
+
+// playlistFromGW converts a gateway playlist into the public API model
+func playlistFromGW(item map[string]interface{}) *Playlist {
+	id := gwString(item["PLAYLIST_ID"])
+	status := gwInt(item["STATUS"]) // 0 public, 1 private, 2 collaborative
+
+	playlist := &Playlist{
+		ID:            FlexibleID(id),
+		Title:         gwString(item["TITLE"]),
+		TrackCount:    gwInt(item["NB_SONG"]),
+		Checksum:      gwString(item["CHECKSUM"]),
+		Public:        status != 1,
+		Collaborative: status == 2,
+		Link:          "https://www.deezer.com/playlist/" + id,
+		Type:          "playlist",
+	}
+
+	if md5 := gwString(item["PLAYLIST_PICTURE"]); md5 != "" {
+		playlist.PictureSmall = gwImageURL("playlist", md5, 56)
+		playlist.PictureMedium = gwImageURL("playlist", md5, 250)
+		playlist.PictureBig = gwImageURL("playlist", md5, 500)
+		playlist.PictureXL = gwImageURL("playlist", md5, 1000)
+	}
+
+	if ownerID := gwString(item["PARENT_USER_ID"]); ownerID != "" {
+		playlist.Creator = &User{
+			ID:   FlexibleID(ownerID),
+			Name: gwString(item["PARENT_USERNAME"]),
+			Type: "user",
+		}
+	}
+
+	return playlist
+}
+
+// albumFromGW converts a gateway album into the public API model
+func albumFromGW(item map[string]interface{}) *Album {
+	id := gwString(item["ALB_ID"])
+
+	releaseDate := gwString(item["PHYSICAL_RELEASE_DATE"])
+	if releaseDate == "" {
+		releaseDate = gwString(item["DIGITAL_RELEASE_DATE"])
+	}
+
+	album := &Album{
+		ID:              FlexibleID(id),
+		Title:           gwString(item["ALB_TITLE"]),
+		TrackCount:      gwInt(item["NUMBER_TRACK"]),
+		ReleaseDate:     releaseDate,
+		ExplicitContent: gwExplicitStatus(item["EXPLICIT_ALBUM_CONTENT"]),
+		Available:       true,
+		Link:            "https://www.deezer.com/album/" + id,
+		Type:            "album",
+		Artist: &Artist{
+			ID:   FlexibleID(gwString(item["ART_ID"])),
+			Name: gwString(item["ART_NAME"]),
+			Type: "artist",
+		},
+	}
+	album.ExplicitLyrics = isExplicitAdvisory(album.ExplicitContent)
+	setGWCover(album, gwString(item["ALB_PICTURE"]))
+
+	return album
+}
+
+// trackFromGW converts a gateway track into the public API model
+func trackFromGW(item map[string]interface{}) *Track {
+	id := gwString(item["SNG_ID"])
+	title := gwString(item["SNG_TITLE"])
+	version := gwString(item["VERSION"])
+
+	track := &Track{
+		ID:              FlexibleID(id),
+		Title:           strings.TrimSpace(title + " " + version),
+		TitleShort:      title,
+		TitleVersion:    version,
+		ISRC:            gwString(item["ISRC"]),
+		Duration:        gwInt(item["DURATION"]),
+		TrackNumber:     gwInt(item["TRACK_NUMBER"]),
+		DiscNumber:      gwInt(item["DISK_NUMBER"]),
+		ExplicitContent: gwExplicitStatus(item["EXPLICIT_TRACK_CONTENT"]),
+		MD5Image:        gwString(item["ALB_PICTURE"]),
+		Gain:            gwFloat(item["GAIN"]),
+		Available:       true,
+		Link:            "https://www.deezer.com/track/" + id,
+		Type:            "track",
+		Artist: &Artist{
+			ID:   FlexibleID(gwString(item["ART_ID"])),
+			Name: gwString(item["ART_NAME"]),
+			Type: "artist",
+		},
+		Album: &Album{
+			ID:    FlexibleID(gwString(item["ALB_ID"])),
+			Title: gwString(item["ALB_TITLE"]),
+			Type:  "album",
+		},
+	}
+	track.ExplicitLyrics = isExplicitAdvisory(track.ExplicitContent)
+	setGWCover(track.Album, track.MD5Image)
+
+	return track
+}
+
+// setGWCover fills an album's cover URLs from its picture hash
+func setGWCover(album *Album, md5 string) {
+	if md5 == "" {
+		return
+	}
+	album.MD5Image = md5
+	album.CoverSmall = gwImageURL("cover", md5, 56)
+	album.CoverMedium = gwImageURL("cover", md5, 250)
+	album.CoverBig = gwImageURL("cover", md5, 500)
+	album.CoverXL = gwImageURL("cover", md5, 1000)
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestUserLibraryNotLoggedIn(t *testing.T) {
+	client := NewDeezerClient(30 * time.Second)
+	ctx := context.Background()
+
+	if _, err := client.GetUserPlaylists(ctx); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("Expected ErrNotLoggedIn for playlists, got %v", err)
+	}
+	if _, err := client.GetUserFavoriteAlbums(ctx); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("Expected ErrNotLoggedIn for albums, got %v", err)
+	}
+	if _, err := client.GetUserFavoriteTracks(ctx); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("Expected ErrNotLoggedIn for tracks, got %v", err)
+	}
+}
+
+// fakeListing returns a page fetcher over n items with IDs 1..n
+func fakeListing(n int, honourStart bool, calls *int) func(start, nb int) ([]map[string]interface{}, int, error) {
+	return func(start, nb int) ([]map[string]interface{}, int, error) {
+		*calls++
+		if !honourStart {
+			start = 0
+		}
+		var page []map[string]interface{}
+		for i := start; i < start+nb && i < n; i++ {
+			page = append(page, map[string]interface{}{"SNG_ID": fmt.Sprintf("%d", i+1)})
+		}
+		return page, n, nil
+	}
+}
+
+func TestCollectPages(t *testing.T) {
+	calls := 0
+	items, err := collectPages(fakeListing(250, true, &calls), "SNG_ID")
+	if err != nil {
+		t.Fatalf("collectPages failed: %v", err)
+	}
+	if len(items) != 250 || calls != 3 {
+		t.Errorf("Expected 250 items in 3 pages, got %d in %d", len(items), calls)
+	}
+
+	// An exact multiple of the page size stops at the total instead of fetching an empty page
+	calls = 0
+	items, _ = collectPages(fakeListing(200, true, &calls), "SNG_ID")
+	if len(items) != 200 || calls != 2 {
+		t.Errorf("Expected 200 items in 2 pages, got %d in %d", len(items), calls)
+	}
+
+	// A method that ignores start keeps returning the first page
+	calls = 0
+	items, _ = collectPages(fakeListing(250, false, &calls), "SNG_ID")
+	if len(items) != 100 || calls != 2 {
+		t.Errorf("Expected 100 unique items in 2 pages, got %d in %d", len(items), calls)
+	}
+
+	_, err = collectPages(func(start, nb int) ([]map[string]interface{}, int, error) {
+		return nil, 0, ErrLibraryPermission
+	}, "SNG_ID")
+	if !errors.Is(err, ErrLibraryPermission) {
+		t.Errorf("Expected the fetch error to be returned, got %v", err)
+	}
+}
+
+func TestLibraryError(t *testing.T) {
+	if err := libraryError(map[string]interface{}{"error": []interface{}{}}); err != nil {
+		t.Errorf("Expected an empty error list to be success, got %v", err)
+	}
+
+	err := libraryError(map[string]interface{}{"error": map[string]interface{}{"PERMISSION_DENIED": "denied"}})
+	if !errors.Is(err, ErrLibraryPermission) {
+		t.Errorf("Expected ErrLibraryPermission, got %v", err)
+	}
+
+	err = libraryError(map[string]interface{}{"error": map[string]interface{}{"DATA_ERROR": "missing"}})
+	if err == nil || errors.Is(err, ErrLibraryPermission) {
+		t.Errorf("Expected a plain API error, got %v", err)
+	}
+}
+
+func TestFromGW(t *testing.T) {
+	playlist := playlistFromGW(map[string]interface{}{
+		"PLAYLIST_ID":      "908622995",
+		"TITLE":            "Road Trip",
+		"NB_SONG":          float64(42),
+		"STATUS":           float64(1),
+		"PLAYLIST_PICTURE": "abc",
+		"PARENT_USER_ID":   "5",
+		"PARENT_USERNAME":  "me",
+	})
+	if playlist.ID != "908622995" || playlist.TrackCount != 42 || playlist.Public || playlist.Creator.Name != "me" {
+		t.Errorf("Unexpected playlist %+v", playlist)
+	}
+	if playlist.PictureXL != "https://e-cdns-images.dzcdn.net/images/playlist/abc/1000x1000-000000-80-0-0.jpg" {
+		t.Errorf("Unexpected playlist picture %s", playlist.PictureXL)
+	}
+
+	album := albumFromGW(map[string]interface{}{
+		"ALB_ID":                 float64(302127),
+		"ALB_TITLE":              "Discovery",
+		"ART_ID":                 "27",
+		"ART_NAME":               "Daft Punk",
+		"ALB_PICTURE":            "2e018122cb56986277102d2041a592c8",
+		"NUMBER_TRACK":           "14",
+		"DIGITAL_RELEASE_DATE":   "2001-03-07",
+		"EXPLICIT_ALBUM_CONTENT": map[string]interface{}{"EXPLICIT_LYRICS_STATUS": float64(1)},
+	})
+	if album.ID != "302127" || album.TrackCount != 14 || album.ReleaseDate != "2001-03-07" || !album.IsExplicit() {
+		t.Errorf("Unexpected album %+v", album)
+	}
+	if album.Artist.Name != "Daft Punk" || album.CoverXL == "" {
+		t.Errorf("Unexpected album artist or cover %+v", album)
+	}
+
+	track := trackFromGW(map[string]interface{}{
+		"SNG_ID":       "3135556",
+		"SNG_TITLE":    "Harder, Better, Faster, Stronger",
+		"VERSION":      "(Live)",
+		"DURATION":     "224",
+		"TRACK_NUMBER": "4",
+		"DISK_NUMBER":  "1",
+		"ART_NAME":     "Daft Punk",
+		"ALB_ID":       "302127",
+		"ALB_TITLE":    "Discovery",
+		"ALB_PICTURE":  "2e018122cb56986277102d2041a592c8",
+		"GAIN":         "-9.5",
+	})
+	if track.Title != "Harder, Better, Faster, Stronger (Live)" || track.TitleShort != "Harder, Better, Faster, Stronger" {
+		t.Errorf("Unexpected track title %q", track.Title)
+	}
+	if track.Duration != 224 || track.Gain != -9.5 || track.GetTrackNumber() != 4 || track.Album.ID != "302127" || track.Album.CoverXL == "" {
+		t.Errorf("Unexpected track %+v", track)
+	}
+}
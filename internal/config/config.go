@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -27,31 +28,79 @@ type DeezerConfig struct {
 // DownloadConfig contains download-related settings
 type DownloadConfig struct {
 	OutputDir                string            `json:"output_dir" mapstructure:"output_dir"`
+	StagingDir               string            `json:"staging_dir" mapstructure:"staging_dir"` // If set, tracks are downloaded and tagged here, then moved into OutputDir
 	Quality                  string            `json:"quality" mapstructure:"quality"`
 	ConcurrentDownloads      int               `json:"concurrent_downloads" mapstructure:"concurrent_downloads"`
 	EmbedArtwork             bool              `json:"embed_artwork" mapstructure:"embed_artwork"`
+	EmbedArtistImage         bool              `json:"embed_artist_image" mapstructure:"embed_artist_image"` // Also embed the artist picture (as an "artist" picture type) when embedding artwork
 	ArtworkSize              int               `json:"artwork_size" mapstructure:"artwork_size"`
-	SaveAlbumCover           bool              `json:"save_album_cover" mapstructure:"save_album_cover"`
+	SaveAlbumCover           bool              `json:"save_album_cover" mapstructure:"save_album_cover"` // Save cover.jpg next to the tracks; independent of EmbedArtwork
 	AlbumCoverSize           int               `json:"album_cover_size" mapstructure:"album_cover_size"`
 	AlbumCoverFilename       string            `json:"album_cover_filename" mapstructure:"album_cover_filename"`
-	SaveArtistImage          bool              `json:"save_artist_image" mapstructure:"save_artist_image"`
+	SaveArtistImage          bool              `json:"save_artist_image" mapstructure:"save_artist_image"` // Save the artist's folder.jpg to the artist folder; independent of EmbedArtwork
 	ArtistImageSize          int               `json:"artist_image_size" mapstructure:"artist_image_size"`
 	ArtistImageFilename      string            `json:"artist_image_filename" mapstructure:"artist_image_filename"`
 	SingleTrackTemplate      string            `json:"single_track_template" mapstructure:"single_track_template"`
 	AlbumTrackTemplate       string            `json:"album_track_template" mapstructure:"album_track_template"`
+	CustomTrackFilenames     bool              `json:"custom_track_filenames" mapstructure:"custom_track_filenames"` // Name album and single tracks with album_track_template/single_track_template instead of "01 - Artist - Title"
 	PlaylistTrackTemplate    string            `json:"playlist_track_template" mapstructure:"playlist_track_template"`
 	CreatePlaylistFolder     bool              `json:"create_playlist_folder" mapstructure:"create_playlist_folder"`
 	CreateArtistFolder       bool              `json:"create_artist_folder" mapstructure:"create_artist_folder"`
 	CreateAlbumFolder        bool              `json:"create_album_folder" mapstructure:"create_album_folder"`
 	CreateCDFolder           bool              `json:"create_cd_folder" mapstructure:"create_cd_folder"`
+	AlwaysIncludeDisc        bool              `json:"always_include_disc" mapstructure:"always_include_disc"` // Prefix album track filenames with "disc-track" (1-01) even on single-disc albums
 	PlaylistFolderStructure  bool              `json:"playlist_folder_structure" mapstructure:"playlist_folder_structure"`
 	SinglesFolderStructure   bool              `json:"singles_folder_structure" mapstructure:"singles_folder_structure"`
+	GroupArtistSingles       bool              `json:"group_artist_singles" mapstructure:"group_artist_singles"` // In discography downloads, collect the artist's singles in Artist/Singles, prefixed by release year
 	PlaylistFolderTemplate   string            `json:"playlist_folder_template" mapstructure:"playlist_folder_template"`
+	PlaylistFlat             bool              `json:"playlist_flat" mapstructure:"playlist_flat"` // Put playlist folders in the output root instead of under Various Artists
 	ArtistFolderTemplate     string            `json:"artist_folder_template" mapstructure:"artist_folder_template"`
 	AlbumFolderTemplate      string            `json:"album_folder_template" mapstructure:"album_folder_template"`
 	CDFolderTemplate         string            `json:"cd_folder_template" mapstructure:"cd_folder_template"`
 	FilenameTemplate         string            `json:"filename_template" mapstructure:"filename_template"`
 	FolderStructure          map[string]string `json:"folder_structure" mapstructure:"folder_structure"`
+	VariousArtistsName       string            `json:"various_artists_name" mapstructure:"various_artists_name"`
+	CompilationKeywords      []string          `json:"compilation_keywords" mapstructure:"compilation_keywords"`
+	CompilationOverrides     map[string]bool   `json:"compilation_overrides" mapstructure:"compilation_overrides"` // album ID -> force compilation on/off
+	SingleArtistAlbums       bool              `json:"single_artist_albums" mapstructure:"single_artist_albums"` // File albums whose tracks all share one artist under that artist, even if Deezer credits the label or Various Artists
+	MetadataConcurrency      int               `json:"metadata_concurrency" mapstructure:"metadata_concurrency"` // FLAC rewrites are always serialized
+	AutoClearCompleted       bool              `json:"auto_clear_completed" mapstructure:"auto_clear_completed"`
+	ImageConcurrency         int               `json:"image_concurrency" mapstructure:"image_concurrency"` // Artwork/artist image downloads, separate from track workers
+	ConcurrentAlbums         int               `json:"concurrent_albums" mapstructure:"concurrent_albums"` // Album/playlist jobs expanding into tracks at once
+	PreserveExistingTags     bool              `json:"preserve_existing_tags" mapstructure:"preserve_existing_tags"` // When a track's file already exists, only add missing tags
+	MaxFilenameBytes         int               `json:"max_filename_bytes" mapstructure:"max_filename_bytes"` // Per folder/file name, in UTF-8 bytes
+	TagProfile               string            `json:"tag_profile" mapstructure:"tag_profile"` // full, basic (title/artist/album) or none
+	MirrorPlaylist           bool              `json:"mirror_playlist" mapstructure:"mirror_playlist"` // SyncPlaylist deletes files of tracks dropped from the playlist
+	VerifyAlbumTrackCount    bool              `json:"verify_album_track_count" mapstructure:"verify_album_track_count"` // Re-check Deezer's tracklist before completing an album
+	OnCollision              string            `json:"on_collision" mapstructure:"on_collision"` // When a different track already has the filename: overwrite, number or skip
+	AlbumTrackOrder          string            `json:"album_track_order" mapstructure:"album_track_order"` // Order album tracks are queued in: track (disc then track number), reverse or as_is
+	MultiDiscDetection       string            `json:"multi_disc_detection" mapstructure:"multi_disc_detection"` // When album jobs fetch sample tracks to find disc numbers: auto, api (always) or off (trust nb_disk)
+	ID3Version               string            `json:"id3_version" mapstructure:"id3_version"` // ID3 version for MP3 tags: 2.3 (read by older players) or 2.4
+	ExistingFileArtwork      bool              `json:"existing_file_artwork" mapstructure:"existing_file_artwork"` // Still fetch missing cover/artist images when the track's file already exists
+	PrecreateFolders         bool              `json:"precreate_folders" mapstructure:"precreate_folders"` // Create all album/disc folders when an album is expanded instead of per track
+	MetadataRetries          int               `json:"metadata_retries" mapstructure:"metadata_retries"` // Extra tagging attempts before a track is flagged untagged
+	StrictQuality            bool              `json:"strict_quality" mapstructure:"strict_quality"` // Fail and retry once when an MP3's real bitrate is below the requested quality
+	WriteGainTag             bool              `json:"write_gain_tag" mapstructure:"write_gain_tag"` // Write Deezer's track gain as REPLAYGAIN_TRACK_GAIN (full tag profile only)
+	WriteCompilationTag      bool              `json:"write_compilation_tag" mapstructure:"write_compilation_tag"` // Flag compilation/soundtrack tracks with TCMP / COMPILATION
+	AlbumCoverUpfront        bool              `json:"album_cover_upfront" mapstructure:"album_cover_upfront"` // Save cover.jpg when an album is expanded instead of with its first track
+	SaveBooklet              bool              `json:"save_booklet" mapstructure:"save_booklet"` // Save every cover size to the album folder; Deezer has no booklets to offer
+	Schedule                 ScheduleConfig    `json:"schedule" mapstructure:"schedule"` // Only start queued downloads inside a daily time window
+	MinFreeSpaceMB           int               `json:"min_free_space_mb" mapstructure:"min_free_space_mb"` // Hold back pending items while the output volume has less free space; 0 disables
+	PendingMultiplier        int               `json:"pending_multiplier" mapstructure:"pending_multiplier"` // Pending items considered per dispatch, as a multiple of concurrent_downloads
+	DispatchInterval         int               `json:"dispatch_interval" mapstructure:"dispatch_interval"` // Seconds between queue checks; finished jobs also trigger one straight away
+	SlowStartSeconds         int               `json:"slow_start_seconds" mapstructure:"slow_start_seconds"` // Ramp concurrent downloads from 1 up to concurrent_downloads over this long when the queue starts; 0 disables
+	RateLimitBreaker         int               `json:"rate_limit_breaker" mapstructure:"rate_limit_breaker"` // Consecutive rate-limited track failures in an album/playlist before its remaining tracks wait out rate_limit_cooldown; 0 disables
+	RateLimitCooldown        int               `json:"rate_limit_cooldown" mapstructure:"rate_limit_cooldown"` // Seconds an album/playlist's tracks wait once rate_limit_breaker trips
+	SearchFallback           bool              `json:"search_fallback" mapstructure:"search_fallback"` // When a track ID no longer exists on Deezer, search by title and artist for a replacement
+	DeduplicateMode          string            `json:"deduplicate_mode" mapstructure:"deduplicate_mode"` // When a track's ISRC was already downloaded elsewhere: off, skip (don't download it again) or hardlink (link to the existing file)
+}
+
+// ScheduleConfig restricts queue dispatch to a daily window, e.g. off-peak hours on a metered connection
+type ScheduleConfig struct {
+	Enabled  bool   `json:"enabled" mapstructure:"enabled"`
+	Start    string `json:"start" mapstructure:"start"`       // HH:MM
+	End      string `json:"end" mapstructure:"end"`           // HH:MM; earlier than Start means the window spans midnight
+	Timezone string `json:"timezone" mapstructure:"timezone"` // IANA name, empty for the system timezone
 }
 
 // SpotifyConfig contains Spotify API settings
@@ -72,24 +121,33 @@ type LyricsConfig struct {
 	EmbedInFile      bool   `json:"embed_in_file" mapstructure:"embed_in_file"`
 	SaveSeparateFile bool   `json:"save_separate_file" mapstructure:"save_separate_file"`
 	Language         string `json:"language" mapstructure:"language"`
+	FetchRetries     int    `json:"fetch_retries" mapstructure:"fetch_retries"`
+	SyncedFormat     string `json:"synced_format" mapstructure:"synced_format"` // Synced lyrics sidecar: lrc, enhanced_lrc or srt
 }
 
 // NetworkConfig contains network-related settings
 type NetworkConfig struct {
-	ProxyURL         string `json:"proxy_url" mapstructure:"proxy_url"`
-	Timeout          int    `json:"timeout" mapstructure:"timeout"`
-	MaxRetries       int    `json:"max_retries" mapstructure:"max_retries"`
-	BandwidthLimit   int    `json:"bandwidth_limit" mapstructure:"bandwidth_limit"`
-	ConnectionsPerDL int    `json:"connections_per_dl" mapstructure:"connections_per_dl"`
+	ProxyURL          string `json:"proxy_url" mapstructure:"proxy_url"`
+	Timeout           int    `json:"timeout" mapstructure:"timeout"` // Deprecated: seeds DownloadTimeout for older settings files
+	APITimeout        int    `json:"api_timeout" mapstructure:"api_timeout"`                   // Seconds per Deezer API request
+	DownloadTimeout   int    `json:"download_timeout" mapstructure:"download_timeout"`         // Seconds per audio download, body included
+	ImageTimeout      int    `json:"image_timeout" mapstructure:"image_timeout"`               // Seconds per artwork or artist image
+	ImageConnsPerHost int    `json:"image_conns_per_host" mapstructure:"image_conns_per_host"` // Connections to the image CDN at once; more can trigger 403s
+	MaxRetries        int    `json:"max_retries" mapstructure:"max_retries"`
+	BandwidthLimit    int    `json:"bandwidth_limit" mapstructure:"bandwidth_limit"`           // Bytes/sec across all downloads; 0 is unlimited
+	PerDownloadLimit  int    `json:"per_download_limit" mapstructure:"per_download_limit"`     // Bytes/sec for each download on its own, all its connections included; 0 is unlimited
+	ConnectionsPerDL  int    `json:"connections_per_dl" mapstructure:"connections_per_dl"`
 }
 
 // SystemConfig contains system integration settings
 type SystemConfig struct {
-	RunOnStartup   bool   `json:"run_on_startup" mapstructure:"run_on_startup"`
-	MinimizeToTray bool   `json:"minimize_to_tray" mapstructure:"minimize_to_tray"`
-	StartMinimized bool   `json:"start_minimized" mapstructure:"start_minimized"`
-	Theme          string `json:"theme" mapstructure:"theme"` // "dark" or "light"
-	Language       string `json:"language" mapstructure:"language"`
+	RunOnStartup         bool   `json:"run_on_startup" mapstructure:"run_on_startup"`
+	MinimizeToTray       bool   `json:"minimize_to_tray" mapstructure:"minimize_to_tray"`
+	StartMinimized       bool   `json:"start_minimized" mapstructure:"start_minimized"`
+	Theme                string `json:"theme" mapstructure:"theme"`                                     // "dark" or "light"
+	Language             string `json:"language" mapstructure:"language"`
+	WatchConfig          bool   `json:"watch_config" mapstructure:"watch_config"`                       // Reload settings.json when it's edited outside the app
+	KeepEncryptedOnError bool   `json:"keep_encrypted_on_error" mapstructure:"keep_encrypted_on_error"` // Keep the encrypted download when decryption fails, for bug reports
 }
 
 // LoggingConfig contains logging settings
@@ -147,6 +205,11 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// Settings written before download_timeout existed used timeout for downloads
+	if v.InConfig("network.timeout") && !v.InConfig("network.download_timeout") {
+		cfg.Network.DownloadTimeout = cfg.Network.Timeout
+	}
+
 	// No encryption/decryption needed - ARL is stored in plain text
 	// The settings file is already in the user's AppData folder with appropriate permissions
 
@@ -158,40 +221,216 @@ func Load(configPath string) (*Config, error) {
 	return &cfg, nil
 }
 
+// defaultCompilationKeywords returns the album title words that mark a compilation by default
+func defaultCompilationKeywords() []string {
+	return []string{"soundtrack", "original score", "original motion picture"}
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	// Download validation
-	if c.Download.ConcurrentDownloads < 1 {
-		return fmt.Errorf("concurrent downloads must be at least 1")
-	}
-
-	if c.Download.ConcurrentDownloads > 32 {
-		return fmt.Errorf("concurrent downloads cannot exceed 32")
+	if err := checkRange("download.concurrent_downloads", c.Download.ConcurrentDownloads, "concurrent downloads"); err != nil {
+		return err
 	}
 
-	if c.Download.Quality != "MP3_320" && c.Download.Quality != "FLAC" {
-		return fmt.Errorf("invalid quality: %s (must be MP3_320 or FLAC)", c.Download.Quality)
+	if err := checkEnum("download.quality", c.Download.Quality, "quality"); err != nil {
+		return err
 	}
 
 	if c.Download.OutputDir == "" {
 		return fmt.Errorf("output directory cannot be empty")
 	}
 
-	if c.Download.ArtworkSize < 100 || c.Download.ArtworkSize > 5000 {
-		return fmt.Errorf("artwork size must be between 100 and 5000 pixels")
+	if c.Download.StagingDir != "" && filepath.Clean(c.Download.StagingDir) == filepath.Clean(c.Download.OutputDir) {
+		return fmt.Errorf("staging directory must be different from the output directory")
+	}
+
+	if err := checkRange("download.artwork_size", c.Download.ArtworkSize, "artwork size"); err != nil {
+		return err
+	}
+
+	if c.Download.MetadataConcurrency < 1 {
+		c.Download.MetadataConcurrency = 2
+	}
+
+	if err := checkRange("download.metadata_concurrency", c.Download.MetadataConcurrency, "metadata concurrency"); err != nil {
+		return err
+	}
+
+	if c.Download.ImageConcurrency < 1 {
+		c.Download.ImageConcurrency = 2
+	}
+
+	if err := checkRange("download.image_concurrency", c.Download.ImageConcurrency, "image concurrency"); err != nil {
+		return err
+	}
+
+	if c.Download.ConcurrentAlbums < 1 {
+		c.Download.ConcurrentAlbums = 2
+	}
+
+	if err := checkRange("download.concurrent_albums", c.Download.ConcurrentAlbums, "concurrent albums"); err != nil {
+		return err
+	}
+
+	if c.Download.MaxFilenameBytes < 1 {
+		c.Download.MaxFilenameBytes = 255
+	}
+
+	if err := checkRange("download.max_filename_bytes", c.Download.MaxFilenameBytes, "max filename bytes"); err != nil {
+		return err
+	}
+
+	if c.Download.TagProfile == "" {
+		c.Download.TagProfile = "full"
+	}
+
+	if err := checkEnum("download.tag_profile", c.Download.TagProfile, "tag profile"); err != nil {
+		return err
+	}
+
+	if c.Download.OnCollision == "" {
+		c.Download.OnCollision = "number"
+	}
+
+	if err := checkEnum("download.on_collision", c.Download.OnCollision, "collision mode"); err != nil {
+		return err
+	}
+
+	if c.Download.AlbumTrackOrder == "" {
+		c.Download.AlbumTrackOrder = "track"
+	}
+
+	if err := checkEnum("download.album_track_order", c.Download.AlbumTrackOrder, "album track order"); err != nil {
+		return err
+	}
+
+	if c.Download.MultiDiscDetection == "" {
+		c.Download.MultiDiscDetection = "auto"
+	}
+
+	if err := checkEnum("download.multi_disc_detection", c.Download.MultiDiscDetection, "multi-disc detection"); err != nil {
+		return err
+	}
+
+	if c.Download.DeduplicateMode == "" {
+		c.Download.DeduplicateMode = "off"
+	}
+
+	if err := checkEnum("download.deduplicate_mode", c.Download.DeduplicateMode, "deduplicate mode"); err != nil {
+		return err
+	}
+
+	// nil means unset; an empty list turns keyword detection off
+	if c.Download.CompilationKeywords == nil {
+		c.Download.CompilationKeywords = defaultCompilationKeywords()
+	}
+
+	if c.Download.ID3Version == "" {
+		c.Download.ID3Version = "2.3"
+	}
+
+	if err := checkEnum("download.id3_version", c.Download.ID3Version, "ID3 version"); err != nil {
+		return err
+	}
+
+	if err := checkRange("download.metadata_retries", c.Download.MetadataRetries, "metadata retries"); err != nil {
+		return err
+	}
+
+	if c.Download.VariousArtistsName == "" {
+		c.Download.VariousArtistsName = "Various Artists"
+	}
+
+	if err := checkRange("download.min_free_space_mb", c.Download.MinFreeSpaceMB, "minimum free space"); err != nil {
+		return err
+	}
+
+	// Zero means unset, as for the network timeouts below
+	if c.Download.PendingMultiplier == 0 {
+		c.Download.PendingMultiplier = 2
+	}
+	if c.Download.DispatchInterval == 0 {
+		c.Download.DispatchInterval = 5
+	}
+
+	if err := checkRange("download.pending_multiplier", c.Download.PendingMultiplier, "pending multiplier"); err != nil {
+		return err
+	}
+
+	if err := checkRange("download.dispatch_interval", c.Download.DispatchInterval, "dispatch interval"); err != nil {
+		return err
+	}
+
+	if err := checkRange("download.slow_start_seconds", c.Download.SlowStartSeconds, "slow start"); err != nil {
+		return err
+	}
+
+	if err := checkRange("download.rate_limit_breaker", c.Download.RateLimitBreaker, "rate limit breaker"); err != nil {
+		return err
+	}
+
+	if c.Download.RateLimitCooldown == 0 {
+		c.Download.RateLimitCooldown = 120
+	}
+
+	if err := checkRange("download.rate_limit_cooldown", c.Download.RateLimitCooldown, "rate limit cooldown"); err != nil {
+		return err
+	}
+
+	if err := c.Download.Schedule.validate(); err != nil {
+		return err
 	}
 
 	// Network validation
-	if c.Network.Timeout < 1 {
-		return fmt.Errorf("network timeout must be at least 1 second")
+	if err := checkRange("network.timeout", c.Network.Timeout, "network timeout"); err != nil {
+		return err
+	}
+
+	// Zero means unset (a config built in code, or migrated settings); negative values are rejected
+	if c.Network.APITimeout == 0 {
+		c.Network.APITimeout = 30
+	}
+	if c.Network.DownloadTimeout == 0 {
+		c.Network.DownloadTimeout = c.Network.Timeout
+	}
+	if c.Network.ImageTimeout == 0 {
+		c.Network.ImageTimeout = 30
+	}
+	if c.Network.ImageConnsPerHost == 0 {
+		c.Network.ImageConnsPerHost = 4
 	}
 
-	if c.Network.MaxRetries < 0 {
-		return fmt.Errorf("max retries cannot be negative")
+	if err := checkRange("network.api_timeout", c.Network.APITimeout, "API timeout"); err != nil {
+		return err
 	}
 
-	if c.Network.ConnectionsPerDL < 1 {
-		return fmt.Errorf("connections per download must be at least 1")
+	if err := checkRange("network.download_timeout", c.Network.DownloadTimeout, "download timeout"); err != nil {
+		return err
+	}
+
+	if err := checkRange("network.image_timeout", c.Network.ImageTimeout, "image timeout"); err != nil {
+		return err
+	}
+
+	if err := checkRange("network.image_conns_per_host", c.Network.ImageConnsPerHost, "image connections per host"); err != nil {
+		return err
+	}
+
+	if err := checkRange("network.max_retries", c.Network.MaxRetries, "max retries"); err != nil {
+		return err
+	}
+
+	if err := checkRange("network.connections_per_dl", c.Network.ConnectionsPerDL, "connections per download"); err != nil {
+		return err
+	}
+
+	if err := checkRange("network.bandwidth_limit", c.Network.BandwidthLimit, "bandwidth limit"); err != nil {
+		return err
+	}
+
+	if err := checkRange("network.per_download_limit", c.Network.PerDownloadLimit, "per-download limit"); err != nil {
+		return err
 	}
 
 	// Lyrics validation
@@ -199,9 +438,21 @@ func (c *Config) Validate() error {
 		c.Lyrics.Language = "en"
 	}
 
+	if err := checkRange("lyrics.fetch_retries", c.Lyrics.FetchRetries, "lyrics fetch retries"); err != nil {
+		return err
+	}
+
+	if c.Lyrics.SyncedFormat == "" {
+		c.Lyrics.SyncedFormat = "lrc"
+	}
+
+	if err := checkEnum("lyrics.synced_format", c.Lyrics.SyncedFormat, "synced lyrics format"); err != nil {
+		return err
+	}
+
 	// System validation
-	if c.System.Theme != "dark" && c.System.Theme != "light" {
-		return fmt.Errorf("invalid theme: %s (must be dark or light)", c.System.Theme)
+	if err := checkEnum("system.theme", c.System.Theme, "theme"); err != nil {
+		return err
 	}
 
 	if c.System.Language == "" {
@@ -209,40 +460,51 @@ func (c *Config) Validate() error {
 	}
 
 	// Logging validation
-	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
-	if !validLevels[c.Logging.Level] {
-		return fmt.Errorf("invalid log level: %s (must be debug, info, warn, or error)", c.Logging.Level)
+	if err := checkEnum("logging.level", c.Logging.Level, "log level"); err != nil {
+		return err
 	}
 
-	validFormats := map[string]bool{"json": true, "console": true}
-	if !validFormats[c.Logging.Format] {
-		return fmt.Errorf("invalid log format: %s (must be json or console)", c.Logging.Format)
+	if err := checkEnum("logging.format", c.Logging.Format, "log format"); err != nil {
+		return err
 	}
 
-	validOutputs := map[string]bool{"file": true, "console": true, "both": true}
-	if !validOutputs[c.Logging.Output] {
-		return fmt.Errorf("invalid log output: %s (must be file, console, or both)", c.Logging.Output)
+	if err := checkEnum("logging.output", c.Logging.Output, "log output"); err != nil {
+		return err
 	}
 
-	if c.Logging.MaxSizeMB < 1 {
-		return fmt.Errorf("log max size must be at least 1 MB")
+	if err := checkRange("logging.max_size_mb", c.Logging.MaxSizeMB, "log max size"); err != nil {
+		return err
 	}
 
-	if c.Logging.MaxBackups < 0 {
-		return fmt.Errorf("log max backups cannot be negative")
+	if err := checkRange("logging.max_backups", c.Logging.MaxBackups, "log max backups"); err != nil {
+		return err
 	}
 
-	if c.Logging.MaxAgeDays < 0 {
-		return fmt.Errorf("log max age cannot be negative")
+	if err := checkRange("logging.max_age_days", c.Logging.MaxAgeDays, "log max age"); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-// Save saves the configuration to file
+// ValidateForAccount checks download.quality against the best quality the logged-in account
+// can download (see api.DeezerClient.MaxQuality). Free accounts can't download FLAC and
+// would otherwise get MP3 files. An empty maxQuality means it isn't known yet and passes.
+func (c *Config) ValidateForAccount(maxQuality string) error {
+	if c.Download.Quality == "FLAC" && maxQuality != "" && maxQuality != "FLAC" {
+		return fmt.Errorf("FLAC requires a Deezer HiFi account; this account is limited to %s", maxQuality)
+	}
+	return nil
+}
+
+// Save saves the configuration to file. The file is written under a temporary name and
+// renamed into place, so a crash mid-write never leaves a truncated config.
 func (c *Config) Save(path string) error {
+	ext := filepath.Ext(path)
+	tmpPath := strings.TrimSuffix(path, ext) + ".tmp" + ext
+
 	v := viper.New()
-	v.SetConfigFile(path)
+	v.SetConfigFile(tmpPath)
 	v.SetConfigType("json")
 
 	// Set all values directly without encryption
@@ -254,16 +516,28 @@ func (c *Config) Save(path string) error {
 	v.Set("system", c.System)
 	v.Set("logging", c.Logging)
 
-	return v.WriteConfig()
+	if err := v.WriteConfig(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
 }
 
 // setDefaults sets default configuration values
 func setDefaults(v *viper.Viper) {
 	// Download defaults
 	v.SetDefault("download.output_dir", getDefaultDownloadDir())
+	v.SetDefault("download.staging_dir", "")
 	v.SetDefault("download.quality", "MP3_320")
 	v.SetDefault("download.concurrent_downloads", 8)
 	v.SetDefault("download.embed_artwork", true)
+	v.SetDefault("download.save_album_cover", true)
+	v.SetDefault("download.save_artist_image", false)
+	v.SetDefault("download.embed_artist_image", false)
 	v.SetDefault("download.artwork_size", 1200)
 	v.SetDefault("download.filename_template", "{artist} - {title}")
 	v.SetDefault("download.folder_structure", map[string]string{
@@ -271,18 +545,67 @@ func setDefaults(v *viper.Viper) {
 		"album":    "{artist}/{album}",
 		"playlist": "Playlists/{playlist}",
 	})
+	v.SetDefault("download.various_artists_name", "Various Artists")
+	v.SetDefault("download.metadata_concurrency", 2)
+	v.SetDefault("download.auto_clear_completed", false)
+	v.SetDefault("download.image_concurrency", 2)
+	v.SetDefault("download.concurrent_albums", 2)
+	v.SetDefault("download.preserve_existing_tags", false)
+	v.SetDefault("download.max_filename_bytes", 255)
+	v.SetDefault("download.tag_profile", "full")
+	v.SetDefault("download.mirror_playlist", false)
+	v.SetDefault("download.verify_album_track_count", false)
+	v.SetDefault("download.on_collision", "number")
+	v.SetDefault("download.album_track_order", "track")
+	v.SetDefault("download.multi_disc_detection", "auto")
+	v.SetDefault("download.id3_version", "2.3")
+	v.SetDefault("download.existing_file_artwork", true)
+	v.SetDefault("download.precreate_folders", false)
+	v.SetDefault("download.metadata_retries", 2)
+	v.SetDefault("download.pending_multiplier", 2)
+	v.SetDefault("download.dispatch_interval", 5)
+	v.SetDefault("download.slow_start_seconds", 0)
+	v.SetDefault("download.rate_limit_breaker", 0)
+	v.SetDefault("download.rate_limit_cooldown", 120)
+	v.SetDefault("download.search_fallback", false)
+	v.SetDefault("download.deduplicate_mode", "off")
+	v.SetDefault("download.group_artist_singles", false)
+	v.SetDefault("download.always_include_disc", false)
+	v.SetDefault("download.custom_track_filenames", false)
+	v.SetDefault("download.strict_quality", false)
+	v.SetDefault("download.write_gain_tag", false)
+	v.SetDefault("download.write_compilation_tag", true)
+	v.SetDefault("download.album_cover_upfront", true)
+	v.SetDefault("download.save_booklet", false)
+	v.SetDefault("download.playlist_flat", false)
+	v.SetDefault("download.min_free_space_mb", 500)
+	v.SetDefault("download.schedule.enabled", false)
+	v.SetDefault("download.schedule.start", "01:00")
+	v.SetDefault("download.schedule.end", "07:00")
+	v.SetDefault("download.schedule.timezone", "")
+	v.SetDefault("download.single_artist_albums", false)
+	v.SetDefault("download.compilation_keywords", defaultCompilationKeywords())
 
 	// Lyrics defaults
 	v.SetDefault("lyrics.enabled", true)
 	v.SetDefault("lyrics.embed_in_file", true)
+	v.SetDefault("lyrics.embed_synced", false)   // Write SYLT frames into MP3s
+	v.SetDefault("lyrics.embed_unsynced", false) // Write USLT frames into MP3s
 	v.SetDefault("lyrics.save_synced_file", true)  // Save .lrc files
 	v.SetDefault("lyrics.save_separate_file", false)
 	v.SetDefault("lyrics.language", "en")
+	v.SetDefault("lyrics.fetch_retries", 2) // Extra attempts for transient lyrics fetch failures
+	v.SetDefault("lyrics.synced_format", "lrc")
 
 	// Network defaults
 	v.SetDefault("network.timeout", 30)
+	v.SetDefault("network.api_timeout", 30)
+	v.SetDefault("network.download_timeout", 300) // Large FLACs on slow links take minutes
+	v.SetDefault("network.image_timeout", 30)
+	v.SetDefault("network.image_conns_per_host", 4)
 	v.SetDefault("network.max_retries", 3)
 	v.SetDefault("network.bandwidth_limit", 0)
+	v.SetDefault("network.per_download_limit", 0)
 	v.SetDefault("network.connections_per_dl", 1)
 
 	// System defaults
@@ -291,6 +614,8 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("system.start_minimized", false)
 	v.SetDefault("system.theme", "dark")
 	v.SetDefault("system.language", "en")
+	v.SetDefault("system.watch_config", false)
+	v.SetDefault("system.keep_encrypted_on_error", false)
 
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
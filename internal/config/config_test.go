@@ -1,6 +1,7 @@
 package config
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 )
@@ -118,6 +119,66 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid synced lyrics format",
+			config: Config{
+				Download: DownloadConfig{
+					Quality:             "MP3_320",
+					ConcurrentDownloads: 8,
+					OutputDir:           "/tmp/downloads",
+					ArtworkSize:         1200,
+				},
+				Network: NetworkConfig{
+					Timeout:          30,
+					ConnectionsPerDL: 1,
+				},
+				Lyrics: LyricsConfig{
+					SyncedFormat: "vtt",
+				},
+				System: SystemConfig{
+					Theme:    "dark",
+					Language: "en",
+				},
+				Logging: LoggingConfig{
+					Level:      "info",
+					Format:     "json",
+					Output:     "console",
+					MaxSizeMB:  10,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid schedule time",
+			config: Config{
+				Download: DownloadConfig{
+					Quality:             "MP3_320",
+					ConcurrentDownloads: 8,
+					OutputDir:           "/tmp/downloads",
+					ArtworkSize:         1200,
+					Schedule: ScheduleConfig{
+						Enabled: true,
+						Start:   "25:00",
+						End:     "07:00",
+					},
+				},
+				Network: NetworkConfig{
+					Timeout:          30,
+					ConnectionsPerDL: 1,
+				},
+				System: SystemConfig{
+					Theme:    "dark",
+					Language: "en",
+				},
+				Logging: LoggingConfig{
+					Level:      "info",
+					Format:     "json",
+					Output:     "console",
+					MaxSizeMB:  10,
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -185,6 +246,122 @@ func TestLoadConfig(t *testing.T) {
 	if cfg.System.Language != "en" {
 		t.Errorf("Expected language en, got %s", cfg.System.Language)
 	}
+
+	if cfg.Download.VariousArtistsName != "Various Artists" {
+		t.Errorf("Expected various artists name to default to Various Artists, got %s", cfg.Download.VariousArtistsName)
+	}
+}
+
+func TestNetworkTimeouts(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "settings.json")
+
+	if err := os.WriteFile(configPath, []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Network.APITimeout != 30 || cfg.Network.DownloadTimeout != 300 || cfg.Network.ImageTimeout != 30 {
+		t.Errorf("Expected default timeouts 30/300/30, got %d/%d/%d", cfg.Network.APITimeout, cfg.Network.DownloadTimeout, cfg.Network.ImageTimeout)
+	}
+
+	// An older settings file only has the single timeout, which applied to downloads
+	if err := os.WriteFile(configPath, []byte(`{"network": {"timeout": 90}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Network.DownloadTimeout != 90 {
+		t.Errorf("Expected download timeout to carry over from timeout, got %d", cfg.Network.DownloadTimeout)
+	}
+	if cfg.Network.APITimeout != 30 {
+		t.Errorf("Expected API timeout to keep its default, got %d", cfg.Network.APITimeout)
+	}
+
+	cfg.Network.ImageTimeout = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a negative image timeout to fail validation")
+	}
+}
+
+func TestDispatchSettings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(configPath, []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Download.PendingMultiplier != 2 || cfg.Download.DispatchInterval != 5 {
+		t.Errorf("Expected defaults 2/5, got %d/%d", cfg.Download.PendingMultiplier, cfg.Download.DispatchInterval)
+	}
+
+	cfg.Download.DispatchInterval = 61
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a dispatch interval over a minute to fail validation")
+	}
+	cfg.Download.DispatchInterval = 1
+	cfg.Download.PendingMultiplier = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a negative pending multiplier to fail validation")
+	}
+}
+
+func TestValidateCompilationKeywords(t *testing.T) {
+	cfg := &Config{}
+	cfg.Download.Quality = "MP3_320"
+	cfg.Download.ConcurrentDownloads = 8
+	cfg.Download.OutputDir = "/tmp/downloads"
+	cfg.Download.ArtworkSize = 1200
+	cfg.Network.Timeout = 30
+	cfg.Network.ConnectionsPerDL = 1
+	cfg.System.Theme = "dark"
+	cfg.System.Language = "en"
+	cfg.Logging.Level = "info"
+	cfg.Logging.Format = "json"
+	cfg.Logging.Output = "console"
+	cfg.Logging.MaxSizeMB = 10
+
+	// A config built without Load still gets the default keywords
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(cfg.Download.CompilationKeywords) != 3 || cfg.Download.CompilationKeywords[0] != "soundtrack" {
+		t.Errorf("Expected the default compilation keywords, got %v", cfg.Download.CompilationKeywords)
+	}
+
+	// An empty list turns keyword detection off and is kept
+	cfg.Download.CompilationKeywords = []string{}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(cfg.Download.CompilationKeywords) != 0 {
+		t.Errorf("Expected an empty keyword list to be kept, got %v", cfg.Download.CompilationKeywords)
+	}
+}
+
+func TestValidateForAccount(t *testing.T) {
+	cfg := &Config{}
+	cfg.Download.Quality = "FLAC"
+	if err := cfg.ValidateForAccount("MP3_320"); err == nil {
+		t.Error("Expected FLAC to be rejected on a non-HiFi account")
+	}
+	if err := cfg.ValidateForAccount("FLAC"); err != nil {
+		t.Errorf("Expected FLAC to be allowed on a HiFi account, got %v", err)
+	}
+	if err := cfg.ValidateForAccount(""); err != nil {
+		t.Errorf("Expected an unknown account quality to pass, got %v", err)
+	}
+
+	cfg.Download.Quality = "MP3_320"
+	if err := cfg.ValidateForAccount("MP3_128"); err != nil {
+		t.Errorf("Expected MP3 to be left alone, got %v", err)
+	}
 }
 
 func TestSaveConfig(t *testing.T) {
@@ -242,3 +419,170 @@ func TestSaveConfig(t *testing.T) {
 		t.Error("Expected RunOnStartup to be true")
 	}
 }
+
+func TestSchema(t *testing.T) {
+	fields := make(map[string]FieldSchema)
+	for _, f := range Schema() {
+		fields[f.Key] = f
+	}
+
+	// Every validated field must be published
+	for key := range fieldLimits {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("Schema() missing constrained field %s", key)
+		}
+	}
+
+	concurrent, ok := fields["download.concurrent_downloads"]
+	if !ok {
+		t.Fatal("Schema() missing download.concurrent_downloads")
+	}
+	if concurrent.Type != "int" {
+		t.Errorf("Expected type int, got %s", concurrent.Type)
+	}
+	if concurrent.Min == nil || *concurrent.Min != 1 || concurrent.Max == nil || *concurrent.Max != 32 {
+		t.Errorf("Expected range 1-32, got %v-%v", concurrent.Min, concurrent.Max)
+	}
+	if concurrent.Default != 8 {
+		t.Errorf("Expected default 8, got %v", concurrent.Default)
+	}
+
+	quality := fields["download.quality"]
+	if len(quality.Enum) != 2 || quality.Enum[0] != "MP3_320" || quality.Enum[1] != "FLAC" {
+		t.Errorf("Expected quality enum [MP3_320 FLAC], got %v", quality.Enum)
+	}
+
+	if fields["system.run_on_startup"].Type != "bool" {
+		t.Errorf("Expected system.run_on_startup to be bool, got %s", fields["system.run_on_startup"].Type)
+	}
+
+	// Nested sections are flattened into dotted keys
+	if start := fields["download.schedule.start"]; start.Type != "string" || start.Default != "01:00" {
+		t.Errorf("Expected download.schedule.start string defaulting to 01:00, got %+v", start)
+	}
+	if _, ok := fields["download.schedule"]; ok {
+		t.Error("Expected download.schedule to be published as its leaf fields")
+	}
+}
+
+func TestCheckRangeMessages(t *testing.T) {
+	tests := []struct {
+		key   string
+		value int
+		want  string
+	}{
+		{"download.concurrent_downloads", 0, "concurrent downloads must be at least 1"},
+		{"download.concurrent_downloads", 33, "concurrent downloads cannot exceed 32"},
+		{"download.artwork_size", 50, "artwork size must be between 100 and 5000"},
+		{"network.max_retries", -1, "max retries cannot be negative"},
+	}
+
+	names := map[string]string{
+		"download.concurrent_downloads": "concurrent downloads",
+		"download.artwork_size":         "artwork size",
+		"network.max_retries":           "max retries",
+	}
+
+	for _, tt := range tests {
+		err := checkRange(tt.key, tt.value, names[tt.key])
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("checkRange(%s, %d) = %v, want %q", tt.key, tt.value, err, tt.want)
+		}
+	}
+
+	if err := checkRange("download.artwork_size", 1200, "artwork size"); err != nil {
+		t.Errorf("checkRange() unexpected error = %v", err)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	d := &DownloadConfig{CDFolderTemplate: "Disc {disc_number}"}
+	templates := d.Templates()
+
+	if templates.PlaylistTrack != DefaultPlaylistTrackTemplate {
+		t.Errorf("Expected default playlist track template, got %q", templates.PlaylistTrack)
+	}
+	if templates.PlaylistFolder != DefaultPlaylistFolderTemplate {
+		t.Errorf("Expected default playlist folder template, got %q", templates.PlaylistFolder)
+	}
+	if templates.CDFolder != "Disc {disc_number}" {
+		t.Errorf("Expected configured CD folder template, got %q", templates.CDFolder)
+	}
+
+	// Track filename templates need custom_track_filenames
+	d.AlbumTrackTemplate = "{track_number:02d} - {album_artist} - {title}"
+	if templates := d.Templates(); templates.AlbumTrack != DefaultAlbumTrackTemplate {
+		t.Errorf("Expected default album track template, got %q", templates.AlbumTrack)
+	}
+	d.CustomTrackFilenames = true
+	if templates := d.Templates(); templates.AlbumTrack != d.AlbumTrackTemplate {
+		t.Errorf("Expected configured album track template, got %q", templates.AlbumTrack)
+	}
+}
+
+func TestGetAndWithSetting(t *testing.T) {
+	cfg := &Config{
+		Download: DownloadConfig{
+			Quality:             "MP3_320",
+			ConcurrentDownloads: 8,
+			OutputDir:           t.TempDir(),
+			ArtworkSize:         1200,
+		},
+		Network: NetworkConfig{
+			Timeout:          30,
+			MaxRetries:       3,
+			ConnectionsPerDL: 1,
+		},
+		System: SystemConfig{
+			Theme:    "dark",
+			Language: "en",
+		},
+		Logging: LoggingConfig{
+			Level:      "info",
+			Format:     "json",
+			Output:     "console",
+			MaxSizeMB:  10,
+			MaxBackups: 3,
+			MaxAgeDays: 7,
+		},
+	}
+
+	if value, err := cfg.Get("download.quality"); err != nil || value != "MP3_320" {
+		t.Errorf("Expected MP3_320, got %v (%v)", value, err)
+	}
+
+	updated, err := cfg.WithSetting("download.quality", []byte(`"FLAC"`))
+	if err != nil {
+		t.Fatalf("WithSetting failed: %v", err)
+	}
+	if updated.Download.Quality != "FLAC" || updated.Download.ConcurrentDownloads != 8 {
+		t.Errorf("Expected only quality to change, got %+v", updated.Download)
+	}
+	if cfg.Download.Quality != "MP3_320" {
+		t.Error("WithSetting must not modify the original config")
+	}
+
+	// Validation applies to single-key updates too
+	if _, err := cfg.WithSetting("download.concurrent_downloads", []byte(`99`)); err == nil {
+		t.Error("Expected out-of-range value to be rejected")
+	}
+	if _, err := cfg.WithSetting("download.quality", []byte(`320`)); err == nil {
+		t.Error("Expected wrongly typed value to be rejected")
+	}
+	scheduled, err := cfg.WithSetting("download.schedule.enabled", []byte(`true`))
+	if err != nil || !scheduled.Download.Schedule.Enabled {
+		t.Errorf("Expected nested setting to be updated, got %v", err)
+	}
+	if _, err := cfg.WithSetting("download.schedule.timezone", []byte(`"Nowhere/Special"`)); err != nil {
+		t.Errorf("Expected timezone to be ignored while the schedule is disabled, got %v", err)
+	}
+	if _, err := scheduled.WithSetting("download.schedule.timezone", []byte(`"Nowhere/Special"`)); err == nil {
+		t.Error("Expected unknown timezone to be rejected when the schedule is enabled")
+	}
+
+	for _, key := range []string{"download.nope", "download", "download.quality.extra"} {
+		if _, err := cfg.Get(key); err == nil {
+			t.Errorf("Expected error for key %q", key)
+		}
+	}
+}
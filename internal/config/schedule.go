@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // Windows has no system zoneinfo for LoadLocation
+)
+
+// Window parses the schedule into minutes after midnight for its start and end and the
+// location the times are in. An empty Timezone uses the system timezone.
+func (s ScheduleConfig) Window() (start, end int, loc *time.Location, err error) {
+	if start, err = parseClock(s.Start); err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid schedule start: %w", err)
+	}
+	if end, err = parseClock(s.End); err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid schedule end: %w", err)
+	}
+
+	loc = time.Local
+	if s.Timezone != "" {
+		if loc, err = time.LoadLocation(s.Timezone); err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid schedule timezone: %w", err)
+		}
+	}
+	return start, end, loc, nil
+}
+
+// validate fills in the default window and checks it only when the schedule is enabled,
+// so stale values don't block saving other settings
+func (s *ScheduleConfig) validate() error {
+	if s.Start == "" {
+		s.Start = "01:00"
+	}
+	if s.End == "" {
+		s.End = "07:00"
+	}
+	if !s.Enabled {
+		return nil
+	}
+	_, _, _, err := s.Window()
+	return err
+}
+
+// parseClock parses an HH:MM time of day into minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// FieldSchema describes a single configuration field so the frontend can render it
+type FieldSchema struct {
+	Key     string      `json:"key"`  // Dotted path, e.g. "download.quality"
+	Type    string      `json:"type"` // string, int, bool, string_list, string_map, bool_map
+	Default interface{} `json:"default"`
+	Min     *int        `json:"min,omitempty"`
+	Max     *int        `json:"max,omitempty"`
+	Enum    []string    `json:"enum,omitempty"`
+}
+
+// fieldLimit holds the constraints Validate enforces for a field
+type fieldLimit struct {
+	Min  *int
+	Max  *int
+	Enum []string
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// fieldLimits is the single source of truth for field constraints.
+// Validate checks against it and Schema publishes it.
+var fieldLimits = map[string]fieldLimit{
+	"download.concurrent_downloads": {Min: intPtr(1), Max: intPtr(32)},
+	"download.quality":              {Enum: []string{"MP3_320", "FLAC"}},
+	"download.artwork_size":         {Min: intPtr(100), Max: intPtr(5000)},
+	"download.metadata_concurrency": {Min: intPtr(1), Max: intPtr(32)},
+	"download.image_concurrency":    {Min: intPtr(1), Max: intPtr(16)},
+	"download.concurrent_albums":    {Min: intPtr(1), Max: intPtr(16)},
+	"download.max_filename_bytes":   {Min: intPtr(32), Max: intPtr(255)},
+	"download.tag_profile":          {Enum: []string{"full", "basic", "none"}},
+	"download.on_collision":         {Enum: []string{"overwrite", "number", "skip"}},
+	"download.album_track_order":    {Enum: []string{"track", "reverse", "as_is"}},
+	"download.multi_disc_detection": {Enum: []string{"auto", "api", "off"}},
+	"download.deduplicate_mode":     {Enum: []string{"off", "skip", "hardlink"}},
+	"download.id3_version":          {Enum: []string{"2.3", "2.4"}},
+	"download.metadata_retries":     {Min: intPtr(0), Max: intPtr(10)},
+	"download.min_free_space_mb":    {Min: intPtr(0)},
+	"download.pending_multiplier":   {Min: intPtr(1), Max: intPtr(20)},
+	"download.dispatch_interval":    {Min: intPtr(1), Max: intPtr(60)},
+	"download.slow_start_seconds":   {Min: intPtr(0), Max: intPtr(600)},
+	"download.rate_limit_breaker":   {Min: intPtr(0), Max: intPtr(100)},
+	"download.rate_limit_cooldown":  {Min: intPtr(1), Max: intPtr(3600)},
+	"network.timeout":               {Min: intPtr(1)},
+	"network.api_timeout":           {Min: intPtr(1)},
+	"network.download_timeout":      {Min: intPtr(1)},
+	"network.image_timeout":         {Min: intPtr(1)},
+	"network.image_conns_per_host":  {Min: intPtr(1), Max: intPtr(16)},
+	"network.max_retries":           {Min: intPtr(0)},
+	"network.connections_per_dl":    {Min: intPtr(1), Max: intPtr(16)},
+	"network.bandwidth_limit":       {Min: intPtr(0)},
+	"network.per_download_limit":    {Min: intPtr(0)},
+	"lyrics.fetch_retries":          {Min: intPtr(0)},
+	"lyrics.synced_format":          {Enum: []string{"lrc", "enhanced_lrc", "srt"}},
+	"system.theme":                  {Enum: []string{"dark", "light"}},
+	"logging.level":                 {Enum: []string{"debug", "info", "warn", "error"}},
+	"logging.format":                {Enum: []string{"json", "console"}},
+	"logging.output":                {Enum: []string{"file", "console", "both"}},
+	"logging.max_size_mb":           {Min: intPtr(1)},
+	"logging.max_backups":           {Min: intPtr(0)},
+	"logging.max_age_days":          {Min: intPtr(0)},
+}
+
+// checkRange validates value against the min/max registered for key
+func checkRange(key string, value int, name string) error {
+	limit := fieldLimits[key]
+	tooLow := limit.Min != nil && value < *limit.Min
+	tooHigh := limit.Max != nil && value > *limit.Max
+
+	switch {
+	case (tooLow || tooHigh) && limit.Min != nil && limit.Max != nil && *limit.Min > 1:
+		return fmt.Errorf("%s must be between %d and %d", name, *limit.Min, *limit.Max)
+	case tooLow && *limit.Min == 0:
+		return fmt.Errorf("%s cannot be negative", name)
+	case tooLow:
+		return fmt.Errorf("%s must be at least %d", name, *limit.Min)
+	case tooHigh:
+		return fmt.Errorf("%s cannot exceed %d", name, *limit.Max)
+	}
+	return nil
+}
+
+// checkEnum validates value against the allowed values registered for key
+func checkEnum(key string, value string, name string) error {
+	allowed := fieldLimits[key].Enum
+	for _, v := range allowed {
+		if v == value {
+			return nil
+		}
+	}
+
+	choices := strings.Join(allowed, " or ")
+	if len(allowed) > 2 {
+		choices = strings.Join(allowed[:len(allowed)-1], ", ") + ", or " + allowed[len(allowed)-1]
+	}
+	return fmt.Errorf("invalid %s: %s (must be %s)", name, value, choices)
+}
+
+// Schema describes every configuration field: its key path, type, default and constraints.
+// Fields are discovered from the Config struct tags, so new options appear automatically.
+func Schema() []FieldSchema {
+	v := viper.New()
+	setDefaults(v)
+
+	var fields []FieldSchema
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		section := configType.Field(i)
+		sectionKey := jsonName(section)
+		if sectionKey == "" || section.Type.Kind() != reflect.Struct {
+			continue
+		}
+
+		fields = appendFieldSchemas(fields, v, sectionKey, section.Type)
+	}
+
+	return fields
+}
+
+// appendFieldSchemas adds the fields of a config struct under prefix, descending into
+// nested structs (e.g. download.schedule.start) so every leaf field is published
+func appendFieldSchemas(fields []FieldSchema, v *viper.Viper, prefix string, structType reflect.Type) []FieldSchema {
+	for j := 0; j < structType.NumField(); j++ {
+		field := structType.Field(j)
+		name := jsonName(field)
+		if name == "" {
+			continue
+		}
+
+		key := prefix + "." + name
+		if field.Type.Kind() == reflect.Struct {
+			fields = appendFieldSchemas(fields, v, key, field.Type)
+			continue
+		}
+
+		schema := FieldSchema{
+			Key:     key,
+			Type:    schemaType(field.Type),
+			Default: v.Get(key),
+		}
+		if limit, ok := fieldLimits[key]; ok {
+			schema.Min = limit.Min
+			schema.Max = limit.Max
+			schema.Enum = limit.Enum
+		}
+		fields = append(fields, schema)
+	}
+	return fields
+}
+
+// jsonName returns the JSON key of a struct field, or "" if it isn't serialized
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// schemaType maps a Go field type to the type name used in the schema
+func schemaType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int64:
+		return "int"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return "string_list"
+		}
+	case reflect.Map:
+		switch t.Elem().Kind() {
+		case reflect.String:
+			return "string_map"
+		case reflect.Bool:
+			return "bool_map"
+		}
+	}
+	return t.Kind().String()
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Get returns the value of a single dotted key such as "download.quality"
+func (c *Config) Get(keyPath string) (interface{}, error) {
+	field, err := c.lookup(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	return field.Interface(), nil
+}
+
+// WithSetting returns a copy of the config with one dotted key set from its JSON value.
+// The copy is validated, so defaults are filled in and invalid values are rejected;
+// the receiver is never modified.
+func (c *Config) WithSetting(keyPath string, valueJSON []byte) (*Config, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy config: %w", err)
+	}
+	updated := &Config{}
+	if err := json.Unmarshal(data, updated); err != nil {
+		return nil, fmt.Errorf("failed to copy config: %w", err)
+	}
+
+	field, err := updated.lookup(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	value := reflect.New(field.Type())
+	if err := json.Unmarshal(valueJSON, value.Interface()); err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", keyPath, err)
+	}
+	field.Set(value.Elem())
+
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// lookup resolves a "section.field" key path (or "section.group.field" for nested
+// settings like download.schedule.start), using the same JSON names as Schema
+func (c *Config) lookup(keyPath string) (reflect.Value, error) {
+	parts := strings.Split(keyPath, ".")
+	if len(parts) < 2 {
+		return reflect.Value{}, fmt.Errorf("invalid setting key: %s (expected section.field)", keyPath)
+	}
+
+	current := reflect.ValueOf(c).Elem()
+	for _, part := range parts {
+		next, ok := fieldByJSONName(current, part)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("unknown setting: %s", keyPath)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// fieldByJSONName finds the struct field serialized under name
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if jsonName(v.Type().Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
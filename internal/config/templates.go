@@ -0,0 +1,52 @@
+package config
+
+// Templates applied when the corresponding setting is left blank
+const (
+	DefaultPlaylistFolderTemplate = "{playlist}"
+	DefaultPlaylistTrackTemplate  = "{playlist_position:02d} - {artist} - {title}"
+	DefaultCDFolderTemplate       = "CD {disc_number}"
+	DefaultArtistFolderTemplate   = "{album_artist}"
+	DefaultAlbumFolderTemplate    = "{album}"
+	DefaultAlbumTrackTemplate     = "{track_number:02d} - {artist} - {title}"
+	DefaultSingleTrackTemplate    = "{artist} - {title}"
+)
+
+// EffectiveTemplates holds the folder and file templates actually used when building output paths.
+// Besides their own placeholders, all templates accept {year} and {date} (the album release date).
+// A "/" in the artist or album folder template starts a nested folder.
+type EffectiveTemplates struct {
+	PlaylistFolder string `json:"playlist_folder_template"`
+	PlaylistTrack  string `json:"playlist_track_template"`
+	CDFolder       string `json:"cd_folder_template"`
+	ArtistFolder   string `json:"artist_folder_template"`
+	AlbumFolder    string `json:"album_folder_template"`
+	AlbumTrack     string `json:"album_track_template"`
+	SingleTrack    string `json:"single_track_template"`
+}
+
+// Templates returns the templates in effect, substituting defaults for blank settings. The
+// album and single track templates only apply with download.custom_track_filenames, so files
+// keep the names they have always had unless the user opts in.
+func (d *DownloadConfig) Templates() EffectiveTemplates {
+	albumTrack, singleTrack := DefaultAlbumTrackTemplate, DefaultSingleTrackTemplate
+	if d.CustomTrackFilenames {
+		albumTrack = templateOrDefault(d.AlbumTrackTemplate, DefaultAlbumTrackTemplate)
+		singleTrack = templateOrDefault(d.SingleTrackTemplate, DefaultSingleTrackTemplate)
+	}
+	return EffectiveTemplates{
+		PlaylistFolder: templateOrDefault(d.PlaylistFolderTemplate, DefaultPlaylistFolderTemplate),
+		PlaylistTrack:  templateOrDefault(d.PlaylistTrackTemplate, DefaultPlaylistTrackTemplate),
+		CDFolder:       templateOrDefault(d.CDFolderTemplate, DefaultCDFolderTemplate),
+		ArtistFolder:   templateOrDefault(d.ArtistFolderTemplate, DefaultArtistFolderTemplate),
+		AlbumFolder:    templateOrDefault(d.AlbumFolderTemplate, DefaultAlbumFolderTemplate),
+		AlbumTrack:     albumTrack,
+		SingleTrack:    singleTrack,
+	}
+}
+
+func templateOrDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay lets an editor finish saving (several writes, or write-then-rename) before reloading
+const reloadDelay = 300 * time.Millisecond
+
+// Watcher reloads the config file when it is changed on disk
+type Watcher struct {
+	path     string
+	fsw      *fsnotify.Watcher
+	onChange func(*Config)
+	onError  func(error)
+	mu       sync.Mutex
+	timer    *time.Timer
+	closed   bool
+}
+
+// Watch reloads configPath whenever it changes and passes the validated config to onChange.
+// A file that fails to load or validate is reported to onError (which may be nil) and
+// ignored, so a half-finished edit never replaces a working config.
+func Watch(configPath string, onChange func(*Config), onError func(error)) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config watcher: %w", err)
+	}
+
+	// Watch the directory rather than the file: editors (and Save) replace the file by
+	// renaming a new one over it, which drops a watch on the file itself
+	if err := fsw.Add(filepath.Dir(configPath)); err != nil {
+		fsw.Close()
+		return nil, fmt.Errorf("failed to watch config directory: %w", err)
+	}
+
+	w := &Watcher{
+		path:     filepath.Clean(configPath),
+		fsw:      fsw,
+		onChange: onChange,
+		onError:  onError,
+	}
+	go w.run()
+	return w, nil
+}
+
+// Close stops watching. A reload that is already running still completes.
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.mu.Unlock()
+
+	return w.fsw.Close()
+}
+
+func (w *Watcher) run() {
+	for {
+		select {
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != w.path || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			w.scheduleReload()
+
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			w.report(err)
+		}
+	}
+}
+
+// scheduleReload (re)starts the reload timer so a burst of events reloads once
+func (w *Watcher) scheduleReload() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(reloadDelay, w.reload)
+}
+
+func (w *Watcher) reload() {
+	w.mu.Lock()
+	closed := w.closed
+	w.mu.Unlock()
+	if closed {
+		return
+	}
+
+	cfg, err := Load(w.path)
+	if err != nil {
+		w.report(err)
+		return
+	}
+	w.onChange(cfg)
+}
+
+func (w *Watcher) report(err error) {
+	if w.onError != nil {
+		w.onError(err)
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	changes := make(chan *Config, 4)
+	errs := make(chan error, 4)
+	watcher, err := Watch(path, func(c *Config) { changes <- c }, func(err error) { errs <- err })
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer watcher.Close()
+
+	cfg.Download.ConcurrentDownloads = 3
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	select {
+	case reloaded := <-changes:
+		if reloaded.Download.ConcurrentDownloads != 3 {
+			t.Errorf("Expected reloaded concurrency 3, got %d", reloaded.Download.ConcurrentDownloads)
+		}
+	case err := <-errs:
+		t.Fatalf("Unexpected reload error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for reload")
+	}
+
+	// An invalid edit is reported and not applied
+	if err := os.WriteFile(path, []byte(`{"download": {"concurrent_downloads": 999}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-errs:
+	case reloaded := <-changes:
+		t.Fatalf("Expected invalid config to be rejected, got concurrency %d", reloaded.Download.ConcurrentDownloads)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for reload error")
+	}
+
+	// Nothing is reloaded after Close
+	watcher.Close()
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	select {
+	case <-changes:
+		t.Error("Expected no reload after Close")
+	case <-time.After(2 * reloadDelay):
+	}
+}
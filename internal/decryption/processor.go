@@ -5,15 +5,20 @@ import (
 	"crypto/cipher"
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/deemusic/deemusic-go/internal/network"
 	"golang.org/x/crypto/blowfish"
+	"golang.org/x/time/rate"
 )
 
 // StreamingProcessor handles memory-efficient streaming operations for downloading
@@ -26,6 +31,12 @@ type StreamingProcessor struct {
 	bfSecret           string // "g4el58wc0zvf9na1" - hardcoded Deezer secret
 	iv                 []byte // Fixed IV for Blowfish CBC
 	chunkSize          int    // Legacy chunk size for non-decryption operations
+
+	limitMu          sync.Mutex
+	globalLimiter    *rate.Limiter // network.bandwidth_limit, shared by every download; nil when unlimited
+	perDownloadLimit int           // network.per_download_limit in bytes/sec, for each download on its own; 0 is unlimited
+
+	dirs *network.DirCache // Folders already created, shared with the download manager; nil runs MkdirAll per file
 }
 
 // NewStreamingProcessor creates a new StreamingProcessor with fixed Deezer decryption parameters.
@@ -48,6 +59,65 @@ func NewStreamingProcessor(chunkSize int) *StreamingProcessor {
 	}
 }
 
+// SetBandwidthLimits sets the total and per-download caps, in bytes/sec (0 is unlimited).
+// Downloads already running keep the per-download cap they started with.
+func (sp *StreamingProcessor) SetBandwidthLimits(global, perDownload int) {
+	sp.limitMu.Lock()
+	defer sp.limitMu.Unlock()
+
+	switch {
+	case global <= 0:
+		sp.globalLimiter = nil
+	case sp.globalLimiter == nil:
+		sp.globalLimiter = network.NewBandwidthLimiter(global)
+	default:
+		// Running downloads share the limiter, so adjust it rather than replace it
+		sp.globalLimiter.SetLimit(rate.Limit(global))
+		sp.globalLimiter.SetBurst(global)
+	}
+	sp.perDownloadLimit = perDownload
+}
+
+// SetDirCache makes downloads create their folders through dirs, so MkdirAll runs once per
+// folder rather than once per file
+func (sp *StreamingProcessor) SetDirCache(dirs *network.DirCache) {
+	sp.dirs = dirs
+}
+
+// downloadLimiters returns the limiters one download waits on: the shared global one and
+// a fresh per-download one
+func (sp *StreamingProcessor) downloadLimiters() []*rate.Limiter {
+	sp.limitMu.Lock()
+	defer sp.limitMu.Unlock()
+	return []*rate.Limiter{sp.globalLimiter, network.NewBandwidthLimiter(sp.perDownloadLimit)}
+}
+
+// ErrInvalidSongID is returned when a download's song ID isn't a Deezer song ID, so the key
+// derived from it would decrypt to noise
+var ErrInvalidSongID = errors.New("invalid song ID")
+
+// ValidateSongID checks a song ID is a decimal Deezer ID. User-uploaded tracks have
+// negative IDs; the key is derived from the ID as written, minus sign included.
+func ValidateSongID(songID string) error {
+	id, err := strconv.ParseInt(songID, 10, 64)
+	if err != nil || id == 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidSongID, songID)
+	}
+	// Reject forms that parse to the same number but hash differently, e.g. "+123" or "0123"
+	if strconv.FormatInt(id, 10) != songID {
+		return fmt.Errorf("%w: %q is not in canonical form", ErrInvalidSongID, songID)
+	}
+	return nil
+}
+
+// trackKey validates a download's song ID and generates its decryption key
+func (sp *StreamingProcessor) trackKey(songID string) ([]byte, error) {
+	if err := ValidateSongID(songID); err != nil {
+		return nil, err
+	}
+	return sp.GenerateDecryptionKey(songID)
+}
+
 // GenerateDecryptionKey generates a decryption key for a given song ID.
 // This implements the exact algorithm from the Python version:
 // 1. MD5 hash of song ID
@@ -88,12 +158,27 @@ func (sp *StreamingProcessor) GenerateDecryptionKey(songID string) ([]byte, erro
 // of each 6144-byte segment, and writing the remaining 4096 bytes as-is.
 // CRITICAL: A new cipher must be created for each encrypted chunk to prevent state corruption.
 func (sp *StreamingProcessor) DecryptFile(encryptedPath, decryptedPath string, key []byte) error {
+	return sp.decryptFile(encryptedPath, decryptedPath, key, nil)
+}
+
+// decryptFile is DecryptFile reporting progress after each batch of segments, so a long
+// decryption (large FLACs) moves the progress bar instead of jumping to done at the end
+func (sp *StreamingProcessor) decryptFile(encryptedPath, decryptedPath string, key []byte, progressCallback ProgressCallback) error {
 	// Open encrypted file for reading with buffered I/O
 	encFile, err := os.Open(encryptedPath)
 	if err != nil {
 		return fmt.Errorf("failed to open encrypted file: %w", err)
 	}
 	defer encFile.Close()
+
+	var totalBytes int64
+	if progressCallback != nil {
+		fileInfo, err := encFile.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat encrypted file: %w", err)
+		}
+		totalBytes = fileInfo.Size()
+	}
 	
 	// Use buffered reader for better read performance (256KB buffer)
 	bufferedReader := bufio.NewReaderSize(encFile, 256*1024)
@@ -117,11 +202,13 @@ func (sp *StreamingProcessor) DecryptFile(encryptedPath, decryptedPath string, k
 	// Pre-allocate buffers
 	readBuffer := make([]byte, batchSize)
 	pendingData := make([]byte, 0, sp.segmentSize)
+	var bytesRead int64
 
 	for {
 		// Read a batch of data
 		n, readErr := bufferedReader.Read(readBuffer)
 		if n > 0 {
+			bytesRead += int64(n)
 			// Combine with any pending data from previous read
 			data := append(pendingData, readBuffer[:n]...)
 			pendingData = pendingData[:0] // Reset pending
@@ -139,6 +226,10 @@ func (sp *StreamingProcessor) DecryptFile(encryptedPath, decryptedPath string, k
 			if len(data) > 0 {
 				pendingData = append(pendingData, data...)
 			}
+
+			if progressCallback != nil {
+				progressCallback(bytesRead-int64(len(pendingData)), totalBytes)
+			}
 		}
 
 		// Check for EOF
@@ -148,6 +239,9 @@ func (sp *StreamingProcessor) DecryptFile(encryptedPath, decryptedPath string, k
 				if err := sp.processSegment(pendingData, bufferedWriter, key); err != nil {
 					return err
 				}
+				if progressCallback != nil {
+					progressCallback(bytesRead, totalBytes)
+				}
 			}
 			break
 		}
@@ -229,27 +323,15 @@ func (sp *StreamingProcessor) processSegment(segment []byte, writer io.Writer, k
 type ProgressCallback func(bytesProcessed, totalBytes int64)
 
 // StreamDecrypt decrypts a file using the CBC stripe algorithm with integrity validation.
-// It reads from an encrypted file and writes the decrypted output to the specified path.
+// It reads from an encrypted file and writes the decrypted output to the specified path,
+// reporting bytes decrypted so far as it goes.
 func (sp *StreamingProcessor) StreamDecrypt(encryptedPath, outputPath string, key []byte, progressCallback ProgressCallback) error {
-	// Get file size for progress reporting
-	fileInfo, err := os.Stat(encryptedPath)
-	if err != nil {
-		return fmt.Errorf("failed to stat encrypted file: %w", err)
-	}
-	totalBytes := fileInfo.Size()
-
-	// Decrypt the file
-	if err := sp.DecryptFile(encryptedPath, outputPath, key); err != nil {
+	if err := sp.decryptFile(encryptedPath, outputPath, key, progressCallback); err != nil {
 		// Clean up partial output file on error
 		os.Remove(outputPath)
 		return err
 	}
 
-	// Report progress completion if callback provided
-	if progressCallback != nil {
-		progressCallback(totalBytes, totalBytes)
-	}
-
 	return nil
 }
 
@@ -260,6 +342,7 @@ type DownloadResult struct {
 	FileSize      int64
 	DownloadTime  float64 // seconds
 	DecryptTime   float64 // seconds
+	EncryptedPath string  // Where the encrypted download was kept after a failed decryption, if it was
 }
 
 // StreamDownload downloads a file with streaming and integrated progress reporting.
@@ -295,13 +378,17 @@ func (sp *StreamingProcessor) StreamDownload(url, outputPath string, progressCal
 	var bytesDownloaded int64
 
 	// Ensure output directory exists
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+	if err := sp.dirs.Ensure(filepath.Dir(outputPath)); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	// Create output file
 	outFile, err := os.Create(outputPath)
 	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			// The folder was removed since it was created; make it again next time
+			sp.dirs.Forget(filepath.Dir(outputPath))
+		}
 		return fmt.Errorf("failed to create output file: %w", err)
 	}
 	defer outFile.Close()
@@ -312,6 +399,7 @@ func (sp *StreamingProcessor) StreamDownload(url, outputPath string, progressCal
 
 	// Download with progress reporting using larger buffer
 	buffer := make([]byte, sp.chunkSize)
+	limiters := sp.downloadLimiters()
 	for {
 		n, err := resp.Body.Read(buffer)
 		if n > 0 {
@@ -324,6 +412,12 @@ func (sp *StreamingProcessor) StreamDownload(url, outputPath string, progressCal
 			if progressCallback != nil {
 				progressCallback(bytesDownloaded, totalSize)
 			}
+
+			// Hold the next read back while over the total or per-download cap
+			if waitErr := network.WaitBandwidth(req.Context(), n, limiters...); waitErr != nil {
+				os.Remove(outputPath)
+				return fmt.Errorf("bandwidth limit wait failed: %w", waitErr)
+			}
 		}
 
 		if err == io.EOF {
@@ -345,15 +439,47 @@ func (sp *StreamingProcessor) StreamDownload(url, outputPath string, progressCal
 	return nil
 }
 
+// SegmentedDownload downloads a file over the given number of concurrent Range requests.
+// Range boundaries are aligned to the 6144-byte decryption segment so every range starts
+// on a segment. Falls back to StreamDownload when connections <= 1 or the server can't
+// serve ranges.
+func (sp *StreamingProcessor) SegmentedDownload(url, outputPath string, connections int, progressCallback ProgressCallback, headers map[string]string, timeout int) error {
+	if connections <= 1 {
+		return sp.StreamDownload(url, outputPath, progressCallback, headers, timeout)
+	}
+
+	_, err := network.SegmentedDownload(&network.SegmentedDownloadConfig{
+		URL:              url,
+		OutputPath:       outputPath,
+		Connections:      connections,
+		Alignment:        int64(sp.segmentSize),
+		Headers:          headers,
+		Timeout:          time.Duration(timeout) * time.Second,
+		ProgressCallback: progressCallback,
+		Limiters:         sp.downloadLimiters(),
+		Dirs:             sp.dirs,
+	})
+	if errors.Is(err, network.ErrRangesNotSupported) {
+		return sp.StreamDownload(url, outputPath, progressCallback, headers, timeout)
+	}
+	return err
+}
+
+// ErrCorruptAudio is returned when a decrypted file doesn't start like an MP3 or FLAC file
+var ErrCorruptAudio = errors.New("decrypted file is not valid audio")
+
 // DownloadAndDecrypt downloads and decrypts a file in a single streaming operation.
 // This is the main method that combines download and decryption with progress reporting.
-func (sp *StreamingProcessor) DownloadAndDecrypt(url, songID, outputPath string, progressCallback ProgressCallback, headers map[string]string, timeout int) (*DownloadResult, error) {
+// With connections > 1 the encrypted file is fetched over that many concurrent ranges.
+// When keepEncryptedDir is set and decryption or the integrity check fails, the encrypted
+// download is moved there as <songID>.enc instead of being deleted.
+func (sp *StreamingProcessor) DownloadAndDecrypt(url, songID, outputPath string, progressCallback ProgressCallback, headers map[string]string, timeout int, connections int, keepEncryptedDir string) (*DownloadResult, error) {
 	result := &DownloadResult{
 		Success: false,
 	}
 
 	// Generate decryption key
-	key, err := sp.GenerateDecryptionKey(songID)
+	key, err := sp.trackKey(songID)
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed to generate decryption key: %v", err)
 		return result, fmt.Errorf("failed to generate decryption key: %w", err)
@@ -378,7 +504,7 @@ func (sp *StreamingProcessor) DownloadAndDecrypt(url, songID, outputPath string,
 		}
 	}
 
-	if err := sp.StreamDownload(url, tempPath, downloadCallback, headers, timeout); err != nil {
+	if err := sp.SegmentedDownload(url, tempPath, connections, downloadCallback, headers, timeout); err != nil {
 		result.ErrorMessage = fmt.Sprintf("download failed: %v", err)
 		return result, fmt.Errorf("download failed: %w", err)
 	}
@@ -395,10 +521,17 @@ func (sp *StreamingProcessor) DownloadAndDecrypt(url, songID, outputPath string,
 
 	if err := sp.StreamDecrypt(tempPath, outputPath, key, decryptCallback); err != nil {
 		result.ErrorMessage = fmt.Sprintf("decryption failed: %v", err)
-		return result, fmt.Errorf("decryption failed: %w", err)
+		return result, keepEncrypted(result, tempPath, songID, keepEncryptedDir, fmt.Errorf("decryption failed: %w", err))
 	}
 	result.DecryptTime = time.Since(decryptStart).Seconds()
 
+	// A wrong key or a mangled download still "decrypts", so check the result looks like audio
+	if err := verifyAudioHeader(outputPath); err != nil {
+		os.Remove(outputPath)
+		result.ErrorMessage = fmt.Sprintf("integrity check failed: %v", err)
+		return result, keepEncrypted(result, tempPath, songID, keepEncryptedDir, fmt.Errorf("integrity check failed: %w", err))
+	}
+
 	// Get final file size
 	if fileInfo, err := os.Stat(outputPath); err == nil {
 		result.FileSize = fileInfo.Size()
@@ -408,6 +541,46 @@ func (sp *StreamingProcessor) DownloadAndDecrypt(url, songID, outputPath string,
 	return result, nil
 }
 
+// verifyAudioHeader checks that a decrypted file starts with an ID3 tag, an MPEG frame
+// sync or the FLAC marker
+func verifyAudioHeader(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open decrypted file: %w", err)
+	}
+	defer file.Close()
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return fmt.Errorf("%w: too short", ErrCorruptAudio)
+	}
+	switch {
+	case string(header) == "fLaC", string(header[:3]) == "ID3":
+		return nil
+	case header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		return nil
+	}
+	return fmt.Errorf("%w: unexpected header % x", ErrCorruptAudio, header)
+}
+
+// keepEncrypted moves the encrypted download for songID into dir so it survives the
+// temp file cleanup, noting the location in the result and the returned error. With an
+// empty dir, or if the move fails, cause is returned unchanged.
+func keepEncrypted(result *DownloadResult, encryptedPath, songID, dir string, cause error) error {
+	if dir == "" {
+		return cause
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return cause
+	}
+	keptPath := filepath.Join(dir, songID+".enc")
+	if err := os.Rename(encryptedPath, keptPath); err != nil {
+		return cause
+	}
+	result.EncryptedPath = keptPath
+	return fmt.Errorf("%w (encrypted file kept at %s)", cause, keptPath)
+}
+
 // DownloadAndDecryptResumable downloads and decrypts a file with resume capability.
 // It supports resuming interrupted downloads using HTTP Range requests.
 func (sp *StreamingProcessor) DownloadAndDecryptResumable(
@@ -422,7 +595,7 @@ func (sp *StreamingProcessor) DownloadAndDecryptResumable(
 	}
 
 	// Generate decryption key
-	key, err := sp.GenerateDecryptionKey(songID)
+	key, err := sp.trackKey(songID)
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("failed to generate decryption key: %v", err)
 		return result, fmt.Errorf("failed to generate decryption key: %w", err)
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -235,6 +236,47 @@ func TestDecryptFileWithMultipleSegments(t *testing.T) {
 	}
 }
 
+// TestStreamDecryptReportsProgress tests that decryption progress is reported as it goes
+func TestStreamDecryptReportsProgress(t *testing.T) {
+	sp := NewStreamingProcessor(8192)
+	tempDir := t.TempDir()
+
+	// 200 segments and a partial one, several read batches' worth
+	encryptedPath := filepath.Join(tempDir, "large_encrypted.bin")
+	data := make([]byte, 200*6144+100)
+	if err := os.WriteFile(encryptedPath, data, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	key, err := sp.GenerateDecryptionKey("3135556")
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	var reports []int64
+	err = sp.StreamDecrypt(encryptedPath, filepath.Join(tempDir, "large_decrypted.bin"), key, func(processed, total int64) {
+		if total != int64(len(data)) {
+			t.Errorf("Expected a total of %d, got %d", len(data), total)
+		}
+		reports = append(reports, processed)
+	})
+	if err != nil {
+		t.Fatalf("StreamDecrypt failed: %v", err)
+	}
+
+	if len(reports) < 3 {
+		t.Fatalf("Expected progress during decryption, got %d reports", len(reports))
+	}
+	for i := 1; i < len(reports); i++ {
+		if reports[i] <= reports[i-1] {
+			t.Errorf("Expected progress to increase, got %v", reports)
+			break
+		}
+	}
+	if reports[len(reports)-1] != int64(len(data)) {
+		t.Errorf("Expected the last report to cover the whole file, got %d", reports[len(reports)-1])
+	}
+}
+
 // TestDecryptFileWithPartialSegment tests decryption with partial final segment
 func TestDecryptFileWithPartialSegment(t *testing.T) {
 	sp := NewStreamingProcessor(8192)
@@ -276,6 +318,29 @@ func TestDecryptFileWithPartialSegment(t *testing.T) {
 	}
 }
 
+// TestSetBandwidthLimits verifies the global limiter is shared and each download gets its own
+func TestSetBandwidthLimits(t *testing.T) {
+	sp := NewStreamingProcessor(8192)
+	if limiters := sp.downloadLimiters(); limiters[0] != nil || limiters[1] != nil {
+		t.Fatalf("Expected no limits by default, got %v", limiters)
+	}
+
+	sp.SetBandwidthLimits(1000000, 50000)
+	first, second := sp.downloadLimiters(), sp.downloadLimiters()
+	if first[0] == nil || first[0] != second[0] {
+		t.Error("Expected every download to share the global limiter")
+	}
+	if first[1] == nil || first[1] == second[1] || first[1].Limit() != 50000 {
+		t.Error("Expected each download to get its own per-download limiter")
+	}
+
+	// Changing the global cap adjusts the limiter running downloads already hold
+	sp.SetBandwidthLimits(2000000, 0)
+	if limiters := sp.downloadLimiters(); limiters[0] != first[0] || first[0].Limit() != 2000000 || limiters[1] != nil {
+		t.Errorf("Expected the global limiter to be updated in place, got %v", limiters)
+	}
+}
+
 // TestStreamingProcessorParameters verifies the fixed decryption parameters
 func TestStreamingProcessorParameters(t *testing.T) {
 	sp := NewStreamingProcessor(8192)
@@ -346,3 +411,98 @@ func TestDecryptFileErrorHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyAudioHeader(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name  string
+		data  []byte
+		valid bool
+	}{
+		{"flac", []byte("fLaC\x00\x00\x00\x22"), true},
+		{"id3", []byte("ID3\x04\x00\x00"), true},
+		{"mpeg", []byte{0xFF, 0xFB, 0x90, 0x64}, true},
+		{"garbage", []byte{0x13, 0x37, 0xBE, 0xEF}, false},
+		{"short", []byte("fL"), false},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, tt.data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		err := verifyAudioHeader(path)
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected valid audio, got %v", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrCorruptAudio) {
+			t.Errorf("%s: expected ErrCorruptAudio, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestKeepEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	encryptedPath := filepath.Join(dir, "deemusic-encrypted-1.tmp")
+	if err := os.WriteFile(encryptedPath, []byte("encrypted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cause := errors.New("decryption failed")
+
+	result := &DownloadResult{}
+	if err := keepEncrypted(result, encryptedPath, "3135556", "", cause); err != cause || result.EncryptedPath != "" {
+		t.Errorf("Expected nothing kept without a folder, got %v (%q)", err, result.EncryptedPath)
+	}
+
+	keepDir := filepath.Join(dir, "kept")
+	err := keepEncrypted(result, encryptedPath, "3135556", keepDir, cause)
+	if !errors.Is(err, cause) {
+		t.Errorf("Expected the cause to be wrapped, got %v", err)
+	}
+	if result.EncryptedPath != filepath.Join(keepDir, "3135556.enc") {
+		t.Errorf("Unexpected kept path %q", result.EncryptedPath)
+	}
+	if data, err := os.ReadFile(result.EncryptedPath); err != nil || string(data) != "encrypted" {
+		t.Errorf("Expected the encrypted file to be moved, got %q (%v)", data, err)
+	}
+}
+
+// TestGenerateDecryptionKeyVectors locks the key derivation down with known keys
+func TestGenerateDecryptionKeyVectors(t *testing.T) {
+	sp := NewStreamingProcessor(8192)
+
+	vectors := map[string]string{
+		"3135556":    "6c6c666b39662c37652575603c643439",
+		"123456789":  "6d34656061377f31322a7336393f626b",
+		"1920371667": "366f653f30347634352c2c673c6a6067",
+		"-1234567":   "6734686b31317c61642c7a65693e6667", // User upload
+	}
+	for songID, want := range vectors {
+		key, err := sp.trackKey(songID)
+		if err != nil {
+			t.Errorf("trackKey(%q) failed: %v", songID, err)
+			continue
+		}
+		if hex.EncodeToString(key) != want {
+			t.Errorf("trackKey(%q) = %x, want %s", songID, key, want)
+		}
+	}
+}
+
+func TestValidateSongID(t *testing.T) {
+	for _, valid := range []string{"3135556", "-1234567", "9223372036854775807"} {
+		if err := ValidateSongID(valid); err != nil {
+			t.Errorf("ValidateSongID(%q) failed: %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"", "0", "abc", "123abc", " 123", "+123", "0123", "3.135556e+06", "99999999999999999999"} {
+		if err := ValidateSongID(invalid); !errors.Is(err, ErrInvalidSongID) {
+			t.Errorf("ValidateSongID(%q) = %v, want ErrInvalidSongID", invalid, err)
+		}
+	}
+
+	sp := NewStreamingProcessor(8192)
+	if _, err := sp.DownloadAndDecrypt("http://127.0.0.1:0/never", "3.135556e+06", filepath.Join(t.TempDir(), "out.mp3"), nil, nil, 1, 1, ""); !errors.Is(err, ErrInvalidSongID) {
+		t.Errorf("Expected DownloadAndDecrypt to reject the song ID before downloading, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+)
+
+// LowQualityReason prefixes the error message of tracks rejected by download.strict_quality
+const LowQualityReason = "LOW_QUALITY"
+
+// ErrLowQuality is returned when Deezer serves an MP3 whose frames are encoded at a lower
+// bitrate than the quality it reported (the "fake 320" that is really 128 kbps)
+var ErrLowQuality = errors.New(LowQualityReason + ": served bitrate is below the requested quality")
+
+const (
+	// mp3ScanBytes bounds how much of the file is read to measure the bitrate
+	mp3ScanBytes = 512 * 1024
+	// mp3ScanFrames is how many frames are averaged; enough to even out VBR files
+	mp3ScanFrames = 200
+)
+
+// qualityBitrates is the bitrate in kbps each MP3 quality should be encoded at
+var qualityBitrates = map[string]int{
+	api.QualityMP3320: 320,
+	api.QualityMP3128: 128,
+}
+
+// MPEG audio layer III bitrates in kbps by bitrate index (0 is free format, 15 is invalid)
+var (
+	mpeg1Layer3Bitrates = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mpeg2Layer3Bitrates = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+)
+
+// MPEG sample rates in Hz by version bits (MPEG 2.5, reserved, MPEG 2, MPEG 1) and index
+var mpegSampleRates = [4][3]int{
+	{11025, 12000, 8000},
+	{0, 0, 0},
+	{22050, 24000, 16000},
+	{44100, 48000, 32000},
+}
+
+// checkMP3Bitrate measures the bitrate of a decrypted MP3 and compares it with the quality
+// Deezer reported. It returns the measured kbps (0 if it couldn't be read) and a warning
+// when the file is more than 10% below what was requested.
+func checkMP3Bitrate(filePath, quality string) (int, string) {
+	bitrate, err := detectMP3Bitrate(filePath)
+	if err != nil {
+		return 0, ""
+	}
+
+	expected, ok := qualityBitrates[quality]
+	if !ok || bitrate*10 >= expected*9 {
+		return bitrate, ""
+	}
+	return bitrate, fmt.Sprintf("%s: file is %d kbps, requested %s", LowQualityReason, bitrate, quality)
+}
+
+// detectMP3Bitrate returns the average bitrate in kbps of the first frames of an MP3 file
+func detectMP3Bitrate(filePath string) (int, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, mp3ScanBytes))
+	if err != nil {
+		return 0, err
+	}
+	return mp3Bitrate(data)
+}
+
+// mp3Bitrate averages the bitrate of the MPEG layer III frames in data, skipping a leading ID3v2 tag
+func mp3Bitrate(data []byte) (int, error) {
+	pos := 0
+	if len(data) >= 10 && string(data[:3]) == "ID3" {
+		size := int(data[6]&0x7f)<<21 | int(data[7]&0x7f)<<14 | int(data[8]&0x7f)<<7 | int(data[9]&0x7f)
+		pos = 10 + size
+		if data[5]&0x10 != 0 {
+			pos += 10 // Footer
+		}
+	}
+
+	total, frames := 0, 0
+	for pos+4 <= len(data) && frames < mp3ScanFrames {
+		bitrate, length := mp3FrameHeader(data[pos:])
+		if length == 0 {
+			// Not a frame boundary: resync one byte at a time
+			pos++
+			continue
+		}
+		// Before the first frame is counted, require the next one to follow it
+		// so that stray 0xFF bytes in padding aren't mistaken for a header
+		if frames == 0 && pos+length+4 <= len(data) {
+			if _, next := mp3FrameHeader(data[pos+length:]); next == 0 {
+				pos++
+				continue
+			}
+		}
+		total += bitrate
+		frames++
+		pos += length
+	}
+
+	if frames == 0 {
+		return 0, fmt.Errorf("no MP3 frames found")
+	}
+	return total / frames, nil
+}
+
+// mp3FrameHeader parses an MPEG layer III frame header, returning its bitrate in kbps
+// and the frame length in bytes, or a zero length if it isn't a valid frame header
+func mp3FrameHeader(header []byte) (int, int) {
+	if len(header) < 4 || header[0] != 0xff || header[1]&0xe0 != 0xe0 {
+		return 0, 0
+	}
+
+	version := (header[1] >> 3) & 0x03
+	layer := (header[1] >> 1) & 0x03
+	bitrateIndex := header[2] >> 4
+	sampleRateIndex := (header[2] >> 2) & 0x03
+	padding := int((header[2] >> 1) & 0x01)
+	if version == 1 || layer != 1 || sampleRateIndex == 3 {
+		return 0, 0
+	}
+
+	sampleRate := mpegSampleRates[version][sampleRateIndex]
+	var bitrate, length int
+	if version == 3 {
+		bitrate = mpeg1Layer3Bitrates[bitrateIndex]
+		if bitrate == 0 {
+			return 0, 0
+		}
+		length = 144*bitrate*1000/sampleRate + padding
+	} else {
+		bitrate = mpeg2Layer3Bitrates[bitrateIndex]
+		if bitrate == 0 {
+			return 0, 0
+		}
+		length = 72*bitrate*1000/sampleRate + padding
+	}
+	return bitrate, length
+}
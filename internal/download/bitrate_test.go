@@ -0,0 +1,81 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+)
+
+// testMP3 builds an MPEG 1 layer III stream at 44.1 kHz: an ID3v2 tag, some junk,
+// then count frames at each bitrate index
+func testMP3(bitrateIndexes []byte, count int) []byte {
+	data := []byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 20}
+	data = append(data, make([]byte, 20)...)
+	data = append(data, 0xff, 0x00, 0xff) // Stray sync-like bytes before the first frame
+	for _, index := range bitrateIndexes {
+		_, length := mp3FrameHeader([]byte{0xff, 0xfb, index << 4, 0x44})
+		for i := 0; i < count; i++ {
+			frame := make([]byte, length)
+			copy(frame, []byte{0xff, 0xfb, index << 4, 0x44})
+			data = append(data, frame...)
+		}
+	}
+	return data
+}
+
+func TestMP3Bitrate(t *testing.T) {
+	tests := []struct {
+		name     string
+		indexes  []byte
+		expected int
+	}{
+		{"CBR 320", []byte{14}, 320},
+		{"CBR 128", []byte{9}, 128},
+		{"VBR", []byte{9, 14}, 224},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mp3Bitrate(testMP3(tt.indexes, 20))
+			if err != nil {
+				t.Fatalf("mp3Bitrate failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %d kbps, got %d", tt.expected, got)
+			}
+		})
+	}
+
+	if _, err := mp3Bitrate([]byte("fLaC not an mp3")); err == nil {
+		t.Error("Expected an error for data without MP3 frames")
+	}
+}
+
+func TestCheckMP3Bitrate(t *testing.T) {
+	dir := t.TempDir()
+	real := filepath.Join(dir, "real.mp3")
+	fake := filepath.Join(dir, "fake.mp3")
+	if err := os.WriteFile(real, testMP3([]byte{14}, 20), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fake, testMP3([]byte{9}, 20), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if bitrate, warning := checkMP3Bitrate(real, api.QualityMP3320); bitrate != 320 || warning != "" {
+		t.Errorf("Expected 320 kbps without warning, got %d %q", bitrate, warning)
+	}
+	bitrate, warning := checkMP3Bitrate(fake, api.QualityMP3320)
+	if bitrate != 128 || !strings.HasPrefix(warning, LowQualityReason) {
+		t.Errorf("Expected 128 kbps with a %s warning, got %d %q", LowQualityReason, bitrate, warning)
+	}
+	if _, warning := checkMP3Bitrate(fake, api.QualityMP3128); warning != "" {
+		t.Errorf("Expected no warning when 128 was requested, got %q", warning)
+	}
+	if bitrate, warning := checkMP3Bitrate(filepath.Join(dir, "missing.mp3"), api.QualityMP3320); bitrate != 0 || warning != "" {
+		t.Errorf("Expected an unreadable file to be skipped, got %d %q", bitrate, warning)
+	}
+}
@@ -0,0 +1,87 @@
+package download
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// rateLimitBreaker holds back an album or playlist's remaining tracks for a cooldown once
+// download.rate_limit_breaker of its tracks in a row failed because Deezer rate limited us,
+// so a throttled album slows down instead of burning through every track's retries
+type rateLimitBreaker struct {
+	mu        sync.Mutex
+	failures  map[string]int       // Consecutive rate-limited failures per parent
+	openUntil map[string]time.Time // Parents whose tracks are waiting out the cooldown
+}
+
+func newRateLimitBreaker() *rateLimitBreaker {
+	return &rateLimitBreaker{
+		failures:  make(map[string]int),
+		openUntil: make(map[string]time.Time),
+	}
+}
+
+// failed counts a track failure for parentID and reports whether it tripped the breaker.
+// Failures that aren't rate limits break the run.
+func (b *rateLimitBreaker) failed(parentID string, rateLimited bool, threshold int, cooldown time.Duration, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !rateLimited {
+		delete(b.failures, parentID)
+		return false
+	}
+	b.failures[parentID]++
+	if b.failures[parentID] < threshold {
+		return false
+	}
+	delete(b.failures, parentID)
+	b.openUntil[parentID] = now.Add(cooldown)
+	return true
+}
+
+// succeeded resets parentID's run of failures
+func (b *rateLimitBreaker) succeeded(parentID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.failures, parentID)
+}
+
+// open reports whether parentID's tracks are waiting out a cooldown
+func (b *rateLimitBreaker) open(parentID string, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	until, ok := b.openUntil[parentID]
+	if !ok {
+		return false
+	}
+	if !now.Before(until) {
+		delete(b.openUntil, parentID)
+		return false
+	}
+	return true
+}
+
+// recordBreakerResult feeds a finished track into its album/playlist's breaker
+func (m *Manager) recordBreakerResult(parentID string, err error, rateLimited bool) {
+	if parentID == "" || m.config.Download.RateLimitBreaker <= 0 {
+		return
+	}
+	if err == nil {
+		m.breaker.succeeded(parentID)
+		return
+	}
+
+	cooldown := time.Duration(m.config.Download.RateLimitCooldown) * time.Second
+	if m.breaker.failed(parentID, rateLimited, m.config.Download.RateLimitBreaker, cooldown, time.Now()) {
+		m.logWarn(parentID, "Tracks keep getting rate limited, holding the rest back", zap.Int("failures", m.config.Download.RateLimitBreaker), zap.Duration("cooldown", cooldown))
+	}
+}
+
+// coolingDown reports whether an album/playlist's tracks are held back by the breaker
+func (m *Manager) coolingDown(parentID string) bool {
+	return parentID != "" && m.breaker.open(parentID, time.Now())
+}
@@ -0,0 +1,89 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+	"github.com/deemusic/deemusic-go/internal/config"
+	"github.com/deemusic/deemusic-go/internal/store"
+)
+
+func TestRateLimitBreaker(t *testing.T) {
+	b := newRateLimitBreaker()
+	now := time.Now()
+	cooldown := time.Minute
+
+	if b.failed("album_1", true, 3, cooldown, now) || b.failed("album_1", true, 3, cooldown, now) {
+		t.Fatal("Expected the breaker to stay closed below the threshold")
+	}
+	// Another kind of failure, or a success, breaks the run
+	b.failed("album_1", false, 3, cooldown, now)
+	if b.failed("album_1", true, 3, cooldown, now) {
+		t.Fatal("Expected a non rate limit failure to reset the count")
+	}
+	b.succeeded("album_1")
+	b.failed("album_1", true, 3, cooldown, now)
+	b.failed("album_1", true, 3, cooldown, now)
+	if b.open("album_1", now) {
+		t.Fatal("Expected a success to reset the count")
+	}
+
+	if !b.failed("album_1", true, 3, cooldown, now) {
+		t.Fatal("Expected the third rate limit in a row to trip the breaker")
+	}
+	if !b.open("album_1", now.Add(30*time.Second)) {
+		t.Error("Expected the album to cool down")
+	}
+	if b.open("album_2", now) {
+		t.Error("Expected other albums to be unaffected")
+	}
+	if b.open("album_1", now.Add(cooldown)) {
+		t.Error("Expected the album to resume after the cooldown")
+	}
+}
+
+func TestRateLimitBreakerHoldsTracks(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &config.Config{}
+	cfg.Download.RateLimitBreaker = 2
+	cfg.Download.RateLimitCooldown = 60
+	queueStore := store.NewQueueStore(db)
+	mgr := NewManager(cfg, queueStore, nil, nil)
+
+	queueStore.Add(&store.QueueItem{ID: "album_3", Type: "album", Status: "downloading", TotalTracks: 3})
+	queueStore.Add(&store.QueueItem{ID: "track_3_3", Type: "track", Status: "pending", ParentID: "album_3"})
+
+	rateLimited := fmt.Errorf("failed to get download URL: %w", api.ErrRateLimited)
+	mgr.recordBreakerResult("album_3", rateLimited, true)
+	if mgr.coolingDown("album_3") {
+		t.Fatal("Expected one rate limit not to trip the breaker")
+	}
+	mgr.recordBreakerResult("album_3", rateLimited, true)
+	if !mgr.coolingDown("album_3") {
+		t.Fatal("Expected two rate limits in a row to trip the breaker")
+	}
+
+	// The album's next track is held back as pending instead of hitting Deezer
+	if err := mgr.downloadTrackJob(context.Background(), &Job{ID: "track_3_3", TrackID: "3"}); err != nil {
+		t.Fatalf("Expected the held track not to fail: %v", err)
+	}
+	if item, _ := queueStore.GetByID("track_3_3"); item.Status != "pending" || item.RetryCount != 0 {
+		t.Errorf("Expected the track held as pending without using a retry, got %+v", item)
+	}
+
+	cfg.Download.RateLimitBreaker = 0
+	mgr.recordBreakerResult("album_4", rateLimited, true)
+	mgr.recordBreakerResult("album_4", rateLimited, true)
+	if mgr.coolingDown("album_4") {
+		t.Error("Expected no breaker with rate_limit_breaker off")
+	}
+}
@@ -0,0 +1,180 @@
+package download
+
+import (
+	"sync"
+	"time"
+
+	"github.com/deemusic/deemusic-go/internal/store"
+	"go.uber.org/zap"
+)
+
+// CompletionSummary describes a finished batch of downloads, for a toast or similar
+// notification. Kind is "queue" when the whole queue went idle, or "album"/"playlist"
+// when a single parent finished.
+type CompletionSummary struct {
+	Kind            string    `json:"kind"`
+	ItemID          string    `json:"item_id,omitempty"`
+	Title           string    `json:"title,omitempty"`
+	Artist          string    `json:"artist,omitempty"`
+	Completed       int       `json:"completed"`        // Tracks downloaded
+	Failed          int       `json:"failed"`           // Tracks that failed for good
+	Albums          int       `json:"albums,omitempty"` // Albums and playlists finished, for the queue summary
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	DurationSeconds int       `json:"duration_seconds"`
+}
+
+// CompletionNotifier is implemented by notifiers that want a summary when the queue goes
+// idle or an album/playlist finishes, rather than per-item status
+type CompletionNotifier interface {
+	NotifyCompletion(summary *CompletionSummary)
+}
+
+// completionBatch counts what happened since the queue last started from idle
+type completionBatch struct {
+	mu        sync.Mutex
+	active    bool
+	startedAt time.Time
+	completed int
+	failed    int
+	albums    int
+	retries   int // Retries waiting out their backoff, which keep the queue busy
+}
+
+// begin starts a batch when a job is dispatched onto an idle queue, reporting whether it did
+func (b *completionBatch) begin() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.active {
+		return false
+	}
+	b.active = true
+	b.startedAt = time.Now()
+	b.completed, b.failed, b.albums = 0, 0, 0
+	return true
+}
+
+// record counts a track or parent reaching a final status
+func (b *completionBatch) record(itemType string, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case itemType == "album" || itemType == "playlist":
+		b.albums++
+	case success:
+		b.completed++
+	default:
+		b.failed++
+	}
+}
+
+// retryScheduled and retrySubmitted bracket a retry's backoff
+func (b *completionBatch) retryScheduled() {
+	b.mu.Lock()
+	b.retries++
+	b.mu.Unlock()
+}
+
+func (b *completionBatch) retrySubmitted() {
+	b.mu.Lock()
+	b.retries--
+	b.mu.Unlock()
+}
+
+// finish ends the batch and returns its summary, or nil when there is no batch or a retry
+// is still pending
+func (b *completionBatch) finish() *CompletionSummary {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.active || b.retries > 0 {
+		return nil
+	}
+	b.active = false
+	now := time.Now()
+	return &CompletionSummary{
+		Kind:            "queue",
+		Completed:       b.completed,
+		Failed:          b.failed,
+		Albums:          b.albums,
+		StartedAt:       b.startedAt,
+		FinishedAt:      now,
+		DurationSeconds: int(now.Sub(b.startedAt).Seconds()),
+	}
+}
+
+// recordTrackResult counts a track job that ended up completed and resets its album or
+// playlist's run of rate-limited failures
+func (m *Manager) recordTrackResult(jobID string) {
+	item, err := m.queueStore.GetByID(jobID)
+	if err != nil || item.Type != "track" || item.Status != "completed" {
+		return
+	}
+	m.completion.record(item.Type, true)
+	m.recordBreakerResult(item.ParentID, nil, false)
+}
+
+// notifyParentCompletion sends the summary of an album or playlist that just finished
+func (m *Manager) notifyParentCompletion(parent *store.QueueItem) {
+	m.completion.record(parent.Type, true)
+
+	notifier, ok := m.notifier.(CompletionNotifier)
+	if !ok {
+		return
+	}
+	finishedAt := time.Now()
+	if parent.CompletedAt != nil {
+		finishedAt = *parent.CompletedAt
+	}
+	notifier.NotifyCompletion(&CompletionSummary{
+		Kind:            parent.Type,
+		ItemID:          parent.ID,
+		Title:           parent.Title,
+		Artist:          parent.Artist,
+		Completed:       parent.CompletedTracks,
+		Failed:          parent.TotalTracks - parent.CompletedTracks,
+		StartedAt:       parent.CreatedAt,
+		FinishedAt:      finishedAt,
+		DurationSeconds: int(finishedAt.Sub(parent.CreatedAt).Seconds()),
+	})
+}
+
+// checkQueueIdle sends the queue summary once nothing is running, waiting in the pool's
+// buffer, waiting to retry or waiting to be dispatched, and no album or playlist is still
+// pending or downloading. Paused items don't keep the queue busy; like processPendingItems,
+// only the next pendingFetchLimit items are looked at.
+func (m *Manager) checkQueueIdle() {
+	if status := m.workerPool.Status(); status.ActiveJobs > 0 || status.QueuedJobs > 0 {
+		return
+	}
+	pending, err := m.queueStore.GetPending(m.pendingFetchLimit())
+	if err != nil {
+		return
+	}
+	for _, item := range pending {
+		if !m.isJobPaused(item.ID) && (item.ParentID == "" || !m.isJobPaused(item.ParentID)) {
+			return
+		}
+	}
+	// An album's tracks are submitted straight to the pool and only reach the queue table
+	// when they run, so between them only the album itself shows the queue is busy
+	for _, status := range []string{"pending", "downloading"} {
+		ids, err := m.queueStore.GetTopLevelIDsByStatus(status)
+		if err != nil {
+			return
+		}
+		for _, id := range ids {
+			if !m.isJobPaused(id) {
+				return
+			}
+		}
+	}
+
+	summary := m.completion.finish()
+	if summary == nil {
+		return
+	}
+	m.logInfo("", "Queue idle", zap.Int("completed", summary.Completed), zap.Int("failed", summary.Failed), zap.Int("duration_seconds", summary.DurationSeconds))
+	if notifier, ok := m.notifier.(CompletionNotifier); ok {
+		notifier.NotifyCompletion(summary)
+	}
+}
@@ -0,0 +1,143 @@
+package download
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+	"github.com/deemusic/deemusic-go/internal/store"
+)
+
+// completionRecorder is a Notifier that records completion summaries
+type completionRecorder struct {
+	nopNotifier
+	summaries []*CompletionSummary
+}
+
+func (r *completionRecorder) NotifyCompletion(summary *CompletionSummary) {
+	r.summaries = append(r.summaries, summary)
+}
+
+func TestCompletionBatch(t *testing.T) {
+	var batch completionBatch
+
+	if batch.finish() != nil {
+		t.Fatal("Expected no summary before anything was dispatched")
+	}
+
+	batch.begin()
+	batch.record("track", true)
+	batch.record("track", true)
+	batch.record("track", false)
+	batch.record("album", true)
+
+	// A retry waiting out its backoff keeps the queue busy
+	batch.retryScheduled()
+	if batch.finish() != nil {
+		t.Fatal("Expected no summary while a retry is pending")
+	}
+	batch.retrySubmitted()
+
+	summary := batch.finish()
+	if summary == nil || summary.Kind != "queue" || summary.Completed != 2 || summary.Failed != 1 || summary.Albums != 1 {
+		t.Fatalf("Unexpected summary %+v", summary)
+	}
+	if batch.finish() != nil {
+		t.Error("Expected the summary to be sent only once")
+	}
+
+	// The next batch starts from zero
+	batch.begin()
+	batch.record("track", true)
+	if summary = batch.finish(); summary == nil || summary.Completed != 1 || summary.Failed != 0 {
+		t.Errorf("Expected a fresh batch, got %+v", summary)
+	}
+}
+
+func TestCheckQueueIdle(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to init db: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &config.Config{}
+	cfg.Download.ConcurrentDownloads = 2
+	recorder := &completionRecorder{}
+	mgr := NewManager(cfg, store.NewQueueStore(db), nil, recorder)
+
+	mgr.completion.begin()
+	mgr.notifyParentCompletion(&store.QueueItem{ID: "album_1", Type: "album", Title: "Discovery", TotalTracks: 14, CompletedTracks: 13})
+
+	pending := &store.QueueItem{ID: "2", Type: "track", Status: "pending"}
+	if err := mgr.queueStore.Add(pending); err != nil {
+		t.Fatalf("Failed to add item: %v", err)
+	}
+	mgr.checkQueueIdle()
+	if len(recorder.summaries) != 1 {
+		t.Fatalf("Expected only the album summary while an item is pending, got %d", len(recorder.summaries))
+	}
+	if album := recorder.summaries[0]; album.Kind != "album" || album.Completed != 13 || album.Failed != 1 {
+		t.Errorf("Unexpected album summary %+v", album)
+	}
+
+	pending.Status = "completed"
+	mgr.queueStore.Update(pending)
+
+	// An album whose tracks are still in the pool's buffer keeps the queue busy
+	album := &store.QueueItem{ID: "album_2", Type: "album", Status: "downloading", TotalTracks: 10}
+	if err := mgr.queueStore.Add(album); err != nil {
+		t.Fatalf("Failed to add album: %v", err)
+	}
+	mgr.checkQueueIdle()
+	if len(recorder.summaries) != 1 {
+		t.Fatalf("Expected no queue summary while an album is downloading, got %+v", recorder.summaries)
+	}
+
+	if ok, err := mgr.queueStore.MarkCompleted(album.ID, time.Now()); err != nil || !ok {
+		t.Fatalf("MarkCompleted() = %v, %v", ok, err)
+	}
+	mgr.checkQueueIdle()
+	if len(recorder.summaries) != 2 || recorder.summaries[1].Kind != "queue" || recorder.summaries[1].Albums != 1 {
+		t.Fatalf("Expected the queue summary once idle, got %+v", recorder.summaries)
+	}
+}
+
+func TestUpdateParentProgressCompletesOnce(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to init db: %v", err)
+	}
+	defer db.Close()
+
+	recorder := &completionRecorder{}
+	mgr := NewManager(&config.Config{}, store.NewQueueStore(db), nil, recorder)
+
+	if err := mgr.queueStore.Add(&store.QueueItem{ID: "album_1", Type: "album", Status: "downloading", TotalTracks: 3}); err != nil {
+		t.Fatalf("Failed to add album: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		track := &store.QueueItem{ID: fmt.Sprintf("track_1_%d", i), Type: "track", Status: "completed", ParentID: "album_1"}
+		if err := mgr.queueStore.Add(track); err != nil {
+			t.Fatalf("Failed to add track: %v", err)
+		}
+	}
+
+	// The album's last tracks finish together
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mgr.updateParentProgress("album_1")
+		}()
+	}
+	wg.Wait()
+
+	if len(recorder.summaries) != 1 {
+		t.Errorf("Expected one album summary, got %d", len(recorder.summaries))
+	}
+}
@@ -0,0 +1,93 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+)
+
+// coverSizes are the square sizes saved by download.save_booklet. 1800 is the largest the
+// image CDN reliably serves for a cover hash.
+var coverSizes = []int{56, 250, 500, 1000, 1800}
+
+// coverImage is one size of an album cover
+type coverImage struct {
+	Size int
+	URL  string
+}
+
+// albumCoverSet returns every cover size available for the album. With the cover hash any size
+// can be requested; otherwise only the fixed-size URLs the album lists are known.
+func albumCoverSet(album *api.Album) []coverImage {
+	var covers []coverImage
+	if album.MD5Image != "" {
+		for _, size := range coverSizes {
+			covers = append(covers, coverImage{
+				Size: size,
+				URL:  fmt.Sprintf("https://e-cdns-images.dzcdn.net/images/cover/%s/%dx%d-000000-80-0-0.jpg", album.MD5Image, size, size),
+			})
+		}
+		return covers
+	}
+
+	for _, cover := range []coverImage{{56, album.CoverSmall}, {250, album.CoverMedium}, {500, album.CoverBig}, {1000, album.CoverXL}} {
+		if cover.URL != "" {
+			covers = append(covers, cover)
+		}
+	}
+	return covers
+}
+
+// queueAlbumCoverSet saves every cover size as cover_<size>.jpg in the album folder (above
+// any CD folders). Deezer doesn't expose digital booklets, so the cover set is the closest
+// thing to the full package download.save_booklet asks for.
+func (m *Manager) queueAlbumCoverSet(ctx context.Context, album *api.Album) {
+	if m.inSinglesFolder(&api.Track{Album: album}) {
+		return // The shared Singles folder gets no cover set
+	}
+	folders := m.albumFolders(album, album.Tracks.Data)
+	if len(folders) == 0 {
+		return
+	}
+	dir := folders[0]
+	if len(folders) > 1 {
+		dir = filepath.Dir(dir)
+	}
+
+	for _, cover := range albumCoverSet(album) {
+		coverURL := cover.URL
+		destPath := filepath.Join(dir, fmt.Sprintf("cover_%d.jpg", cover.Size))
+		m.queueImageDownload(ctx, destPath, fmt.Sprintf("%dpx album cover", cover.Size), func(ctx context.Context) error {
+			if err := m.ensureDir(dir); err != nil {
+				return fmt.Errorf("failed to create %s: %w", dir, err)
+			}
+			return m.downloadImageFile(ctx, coverURL, destPath)
+		})
+	}
+}
+
+// downloadImageFile fetches an image to destPath
+func (m *Manager) downloadImageFile(ctx context.Context, imageURL, destPath string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", imageURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create image request: %w", err)
+	}
+
+	resp, err := m.imageClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("image download failed with status: %d", resp.StatusCode)
+	}
+
+	if err := writeFileAtomic(destPath, resp.Body); err != nil {
+		return fmt.Errorf("failed to save image: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package download
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+)
+
+func TestAlbumCoverSet(t *testing.T) {
+	covers := albumCoverSet(&api.Album{MD5Image: "2e018122cb56986277102d2041a592c8"})
+	if len(covers) != len(coverSizes) {
+		t.Fatalf("Expected every size from the cover hash, got %d", len(covers))
+	}
+	if last := covers[len(covers)-1]; last.Size != 1800 || !strings.Contains(last.URL, "/2e018122cb56986277102d2041a592c8/1800x1800-") {
+		t.Errorf("Unexpected largest cover %+v", last)
+	}
+
+	// Without the hash only the listed sizes are available
+	covers = albumCoverSet(&api.Album{CoverMedium: "https://example.com/250.jpg", CoverXL: "https://example.com/1000.jpg"})
+	if len(covers) != 2 || covers[0].Size != 250 || covers[1].Size != 1000 {
+		t.Errorf("Expected the listed 250 and 1000 covers, got %+v", covers)
+	}
+
+	if covers := albumCoverSet(&api.Album{}); len(covers) != 0 {
+		t.Errorf("Expected no covers, got %+v", covers)
+	}
+}
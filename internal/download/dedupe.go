@@ -0,0 +1,96 @@
+package download
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+	"github.com/deemusic/deemusic-go/internal/store"
+	"go.uber.org/zap"
+)
+
+// duplicateOf returns a file already downloaded for track's recording (same ISRC) in the same
+// format as outputPath, or "" when download.deduplicate_mode is off or there is none on disk
+func (m *Manager) duplicateOf(track *api.Track, outputPath string) string {
+	mode := m.config.Download.DeduplicateMode
+	if mode == "" || mode == "off" || track.ISRC == "" || m.queueStore == nil {
+		return ""
+	}
+
+	paths, err := m.queueStore.GetHistoryPathsByISRC(track.ISRC)
+	if err != nil {
+		return ""
+	}
+	for _, path := range paths {
+		if path == outputPath || !strings.EqualFold(filepath.Ext(path), filepath.Ext(outputPath)) {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() && info.Size() > 0 {
+			return path
+		}
+	}
+	return ""
+}
+
+// linkDuplicate creates outputPath as a hard link to existing, or as a symlink when the two are
+// on different volumes and can't be hard-linked
+func linkDuplicate(existing, outputPath string) error {
+	if err := os.Link(existing, outputPath); err == nil {
+		return nil
+	}
+	if err := os.Symlink(existing, outputPath); err != nil {
+		return fmt.Errorf("failed to link %s to %s: %w", outputPath, existing, err)
+	}
+	return nil
+}
+
+// completeDuplicate finishes a track from an existing copy of the same recording instead of
+// downloading it again. With download.deduplicate_mode "skip" the item completes without a file
+// of its own; with "hardlink" outputPath is linked to the existing file, which already carries
+// its tags, so it isn't tagged again. Returns false when there is no copy to reuse.
+func (m *Manager) completeDuplicate(job *Job, item *store.QueueItem, track *api.Track, outputPath, quality string) (bool, error) {
+	existing := m.duplicateOf(track, outputPath)
+	if existing == "" {
+		return false, nil
+	}
+
+	var size int64
+	if m.config.Download.DeduplicateMode == "hardlink" {
+		if err := linkDuplicate(existing, outputPath); err != nil {
+			// Download it normally instead
+			m.logWarn(job.ID, "Failed to link duplicate", zap.String("existing", existing), zap.Error(err))
+			return false, nil
+		}
+		if info, err := os.Stat(outputPath); err == nil {
+			size = info.Size()
+		}
+		item.OutputPath = outputPath
+	}
+
+	item.Status = "completed"
+	item.Progress = 100
+	now := time.Now()
+	item.CompletedAt = &now
+	if err := m.queueStore.Update(item); err != nil {
+		return true, fmt.Errorf("failed to update queue item: %w", err)
+	}
+	m.logInfo(job.ID, "Track already downloaded, reused existing file", zap.String("mode", m.config.Download.DeduplicateMode), zap.String("existing", existing))
+
+	if item.ParentID != "" {
+		m.updateParentProgress(item.ParentID)
+	}
+
+	if item.OutputPath != "" {
+		if err := m.queueStore.AddToHistory(job.TrackID, track.Title, track.Artist.Name, track.Album.Title, outputPath, quality, size); err == nil {
+			m.queueStore.SetHistoryISRC(outputPath, track.ISRC)
+		}
+	}
+
+	if m.notifier != nil {
+		m.notifier.NotifyCompleted(job.ID)
+	}
+	return true, nil
+}
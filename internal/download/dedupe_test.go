@@ -0,0 +1,78 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+	"github.com/deemusic/deemusic-go/internal/config"
+	"github.com/deemusic/deemusic-go/internal/store"
+)
+
+func TestCompleteDuplicate(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = outputDir
+	queueStore := store.NewQueueStore(db)
+	mgr := NewManager(cfg, queueStore, nil, nil)
+
+	existing := filepath.Join(outputDir, "Artist", "Album", "01 - Song.mp3")
+	os.MkdirAll(filepath.Dir(existing), 0755)
+	if err := os.WriteFile(existing, []byte("audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	queueStore.AddToHistory("1", "Song", "Artist", "Album", existing, "MP3_320", 5)
+	queueStore.SetHistoryISRC(existing, "USRC17607839")
+
+	track := &api.Track{ID: "1", Title: "Song", ISRC: "USRC17607839", Artist: &api.Artist{Name: "Artist"}, Album: &api.Album{Title: "Album"}}
+	linked := filepath.Join(outputDir, "Various Artists", "Playlist", "Song.mp3")
+	os.MkdirAll(filepath.Dir(linked), 0755)
+
+	newItem := func(id string) (*Job, *store.QueueItem) {
+		item := &store.QueueItem{ID: id, Type: "track", Status: "downloading"}
+		queueStore.Add(item)
+		return &Job{ID: id, TrackID: "1"}, item
+	}
+
+	// Off by default
+	job, item := newItem("track_1")
+	if done, _ := mgr.completeDuplicate(job, item, track, linked, "MP3_320"); done {
+		t.Fatal("Expected no deduplication with deduplicate_mode off")
+	}
+
+	cfg.Download.DeduplicateMode = "skip"
+	if done, _ := mgr.completeDuplicate(job, item, track, filepath.Join(outputDir, "Song.flac"), "FLAC"); done {
+		t.Error("Expected an MP3 not to stand in for a FLAC download")
+	}
+	if done, err := mgr.completeDuplicate(job, item, track, linked, "MP3_320"); !done || err != nil {
+		t.Fatalf("Expected the track to be skipped, got %v (%v)", done, err)
+	}
+	if got, _ := queueStore.GetByID("track_1"); got.Status != "completed" || got.OutputPath != "" {
+		t.Errorf("Expected a completed item without a file of its own, got %+v", got)
+	}
+	if _, err := os.Lstat(linked); !os.IsNotExist(err) {
+		t.Error("Expected skip not to create a file")
+	}
+
+	cfg.Download.DeduplicateMode = "hardlink"
+	job, item = newItem("track_2")
+	if done, err := mgr.completeDuplicate(job, item, track, linked, "MP3_320"); !done || err != nil {
+		t.Fatalf("Expected the track to be linked, got %v (%v)", done, err)
+	}
+	if data, err := os.ReadFile(linked); err != nil || string(data) != "audio" {
+		t.Errorf("Expected the link to read the existing file, got %q (%v)", data, err)
+	}
+	if got, _ := queueStore.GetByID("track_2"); got.Status != "completed" || got.OutputPath != linked {
+		t.Errorf("Expected the item completed at the linked path, got %+v", got)
+	}
+	if paths, _ := queueStore.GetHistoryPathsByISRC("USRC17607839"); len(paths) != 2 {
+		t.Errorf("Expected the linked copy in the history too, got %v", paths)
+	}
+}
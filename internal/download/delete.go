@@ -0,0 +1,185 @@
+package download
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// DeleteResult reports what DeleteWithFiles removed from disk
+type DeleteResult struct {
+	ItemID         string   `json:"item_id"`
+	FilesRemoved   int      `json:"files_removed"`   // Audio files and lyrics sidecars
+	FoldersRemoved int      `json:"folders_removed"` // Album/artist folders left empty
+	BytesFreed     int64    `json:"bytes_freed"`
+	Skipped        []string `json:"skipped,omitempty"` // Files left alone because they aren't inside download.output_dir
+}
+
+// leftoverArtworkPattern matches the artwork DeeMusic saves next to the audio: cover.jpg,
+// the save_booklet cover_<size>.jpg set and the artist folder.jpg. A folder holding only
+// these counts as empty once its tracks are deleted.
+var leftoverArtworkPattern = regexp.MustCompile(`^(cover(_\d+)?|folder)\.jpg$`)
+
+// DeleteWithFiles deletes a completed track, album or playlist from disk - audio files, lyrics
+// sidecars and the album/artist folders left empty - then removes its queue and history rows.
+// Only files inside download.output_dir are touched, and symlinks are never followed out of it.
+func (m *Manager) DeleteWithFiles(itemID string) (*DeleteResult, error) {
+	item, err := m.queueStore.GetByID(itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue item: %w", err)
+	}
+	if item.Status != "completed" {
+		return nil, fmt.Errorf("%s is %s, only completed downloads can be deleted with their files", itemID, item.Status)
+	}
+
+	root, err := m.deleteRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	if item.Type == "track" {
+		paths = append(paths, item.OutputPath)
+	} else {
+		children, err := m.queueStore.GetChildren(itemID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tracks: %w", err)
+		}
+		for _, child := range children {
+			paths = append(paths, child.OutputPath)
+		}
+	}
+
+	result := &DeleteResult{ItemID: itemID}
+	dirs := make(map[string]string) // resolved folder -> folder as the download path names it
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		dir, ok := insideRoot(root, path)
+		if !ok {
+			result.Skipped = append(result.Skipped, path)
+			continue
+		}
+		base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		names := []string{filepath.Base(path)}
+		for _, ext := range lyricsSidecarExts {
+			names = append(names, base+ext)
+		}
+		for _, name := range names {
+			if size, removed := removeFile(filepath.Join(dir, name)); removed {
+				result.FilesRemoved++
+				result.BytesFreed += size
+			}
+		}
+		dirs[dir] = filepath.Dir(path)
+
+		if err := m.queueStore.DeleteHistoryByPath(path); err != nil {
+			m.logWarn(itemID, "Failed to delete history entry", zap.String("path", path), zap.Error(err))
+		}
+	}
+
+	for dir, folder := range dirs {
+		removed := removeEmptyFolders(root, dir)
+		result.FoldersRemoved += removed
+		if removed > 0 {
+			// Later downloads into these folders must create them again
+			for i := 1; i < removed; i++ {
+				dir, folder = filepath.Dir(dir), filepath.Dir(folder)
+			}
+			m.knownDirs.Forget(dir)
+			m.knownDirs.Forget(folder)
+		}
+	}
+
+	if err := m.RemoveItem(itemID); err != nil {
+		return result, err
+	}
+
+	m.logInfo(itemID, "Deleted download with its files", zap.Int("files", result.FilesRemoved), zap.Int("folders", result.FoldersRemoved), zap.Int64("bytes", result.BytesFreed), zap.Int("skipped", len(result.Skipped)))
+	return result, nil
+}
+
+// deleteRoot returns the output folder with symlinks resolved, the boundary for DeleteWithFiles
+func (m *Manager) deleteRoot() (string, error) {
+	if m.config.Download.OutputDir == "" {
+		return "", fmt.Errorf("download.output_dir is not set")
+	}
+	abs, err := filepath.Abs(m.config.Download.OutputDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve output folder: %w", err)
+	}
+	root, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve output folder: %w", err)
+	}
+	return root, nil
+}
+
+// insideRoot resolves the folder path is in, following symlinks, and reports whether it
+// lies inside root (root itself excluded). The returned folder is the resolved one, so
+// files are removed where they really are rather than through a link.
+func insideRoot(root, path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Dir(abs))
+	if err != nil {
+		return "", false
+	}
+	if dir != root && !withinDir(root, dir) {
+		return "", false
+	}
+	return dir, true
+}
+
+// withinDir reports whether path is below dir
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// removeFile deletes a regular file or a symlink (never its target), returning the bytes freed
+func removeFile(path string) (int64, bool) {
+	info, err := os.Lstat(path)
+	if err != nil || info.IsDir() {
+		return 0, false
+	}
+	if err := os.Remove(path); err != nil {
+		return 0, false
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return 0, true
+	}
+	return info.Size(), true
+}
+
+// removeEmptyFolders removes dir and its parents up to (not including) root while they hold
+// nothing but leftover artwork, returning how many folders were removed
+func removeEmptyFolders(root, dir string) int {
+	removed := 0
+	for ; withinDir(root, dir); dir = filepath.Dir(dir) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			break
+		}
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || !leftoverArtworkPattern.MatchString(entry.Name()) {
+				return removed
+			}
+		}
+		for _, entry := range entries {
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+		if os.Remove(dir) != nil {
+			break
+		}
+		removed++
+	}
+	return removed
+}
@@ -0,0 +1,87 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+	"github.com/deemusic/deemusic-go/internal/store"
+)
+
+func TestDeleteWithFiles(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	outputDir := t.TempDir()
+	outsideDir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = outputDir
+	queueStore := store.NewQueueStore(db)
+	mgr := NewManager(cfg, queueStore, nil, nil)
+
+	write := func(path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	albumDir := filepath.Join(outputDir, "Artist", "Album")
+	track := filepath.Join(albumDir, "01 - Song.mp3")
+	write(track)
+	mgr.ensureDir(albumDir)
+	write(filepath.Join(albumDir, "01 - Song.lrc"))
+	write(filepath.Join(albumDir, "cover.jpg"))
+	write(filepath.Join(outputDir, "Artist", "folder.jpg"))
+
+	// A folder linking out of the output folder must not be deleted through
+	outsideTrack := filepath.Join(outsideDir, "02 - Song.mp3")
+	write(outsideTrack)
+	if err := os.Symlink(outsideDir, filepath.Join(outputDir, "Linked")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	queueStore.Add(&store.QueueItem{ID: "album_7", Type: "album", Status: "completed", TotalTracks: 3, CompletedTracks: 3})
+	queueStore.Add(&store.QueueItem{ID: "track_7_1", Type: "track", Status: "completed", ParentID: "album_7", OutputPath: track})
+	queueStore.Add(&store.QueueItem{ID: "track_7_2", Type: "track", Status: "completed", ParentID: "album_7", OutputPath: filepath.Join(outputDir, "Linked", "02 - Song.mp3")})
+	queueStore.Add(&store.QueueItem{ID: "track_7_3", Type: "track", Status: "completed", ParentID: "album_7", OutputPath: filepath.Join(outputDir, "..", "03 - Song.mp3")})
+	queueStore.AddToHistory("1", "Song", "Artist", "Album", track, "MP3_320", 4)
+
+	queueStore.Add(&store.QueueItem{ID: "track_8", Type: "track", Status: "downloading"})
+	if _, err := mgr.DeleteWithFiles("track_8"); err == nil {
+		t.Error("Expected a download that isn't completed to be refused")
+	}
+
+	result, err := mgr.DeleteWithFiles("album_7")
+	if err != nil {
+		t.Fatalf("DeleteWithFiles failed: %v", err)
+	}
+	if result.FilesRemoved != 2 || result.BytesFreed != 8 || result.FoldersRemoved != 2 || len(result.Skipped) != 2 {
+		t.Errorf("Expected the track and its lyrics removed with both folders and 2 skipped, got %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "Artist")); !os.IsNotExist(err) {
+		t.Error("Expected the artist folder holding only artwork to be removed")
+	}
+	if _, err := os.Stat(outputDir); err != nil {
+		t.Error("Expected the output folder itself to be kept")
+	}
+	if mgr.knownDirs.Known(albumDir) || mgr.knownDirs.Known(filepath.Join(outputDir, "Artist")) {
+		t.Error("Expected the removed folders to be forgotten so later downloads create them again")
+	}
+	if _, err := os.Stat(outsideTrack); err != nil {
+		t.Error("Expected the file outside the output folder to be kept")
+	}
+	if item, _ := queueStore.GetByID("track_7_1"); item != nil {
+		t.Error("Expected the album's tracks to be removed from the queue")
+	}
+	if trackID, _ := queueStore.GetHistoryTrackIDByPath(track); trackID != "" {
+		t.Error("Expected the history entry to be removed")
+	}
+}
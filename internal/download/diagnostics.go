@@ -0,0 +1,110 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// diagnosticsTrackID is a long-standing catalogue track used to check download URLs
+const diagnosticsTrackID = "3135556"
+
+// DiagnosticCheck is the outcome of one self-check
+type DiagnosticCheck struct {
+	Name       string `json:"name"` // arl, api, download_url, output_dir or disk_space
+	Passed     bool   `json:"passed"`
+	Message    string `json:"message"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// DiagnosticsReport collects the self-checks for a "nothing downloads" support report
+type DiagnosticsReport struct {
+	Passed bool               `json:"passed"` // Every check passed
+	Checks []*DiagnosticCheck `json:"checks"`
+}
+
+// RunDiagnostics checks, in order, the ARL session, Deezer API reachability, fetching a
+// download URL for a sample track, writing to the output folder and free disk space. The
+// download URL check is skipped (and fails) when there's no valid session.
+func (m *Manager) RunDiagnostics(ctx context.Context) *DiagnosticsReport {
+	report := &DiagnosticsReport{Passed: true}
+	run := func(name string, check func() (string, error)) bool {
+		start := time.Now()
+		message, err := check()
+		result := &DiagnosticCheck{Name: name, Passed: err == nil, Message: message, DurationMS: time.Since(start).Milliseconds()}
+		if err != nil {
+			result.Message = err.Error()
+			report.Passed = false
+		}
+		report.Checks = append(report.Checks, result)
+		return result.Passed
+	}
+
+	loggedIn := run("arl", func() (string, error) {
+		if m.config.Deezer.ARL == "" {
+			return "", fmt.Errorf("no ARL configured")
+		}
+		if m.deezerAPI == nil {
+			return "", fmt.Errorf("Deezer client not initialized")
+		}
+		// Re-authenticating proves the ARL still works rather than trusting the startup login
+		if err := m.deezerAPI.RefreshToken(ctx); err != nil {
+			return "", fmt.Errorf("ARL rejected: %w", err)
+		}
+		return fmt.Sprintf("logged in, account max quality %s", m.deezerAPI.MaxQuality()), nil
+	})
+
+	run("api", func() (string, error) {
+		if m.deezerAPI == nil {
+			return "", fmt.Errorf("Deezer client not initialized")
+		}
+		if err := m.deezerAPI.Ping(ctx); err != nil {
+			return "", fmt.Errorf("Deezer API unreachable: %w", err)
+		}
+		return "Deezer API reachable", nil
+	})
+
+	run("download_url", func() (string, error) {
+		if !loggedIn {
+			return "", fmt.Errorf("skipped, needs a valid ARL")
+		}
+		url, err := m.deezerAPI.GetTrackDownloadURL(ctx, diagnosticsTrackID, m.config.Download.Quality)
+		if err != nil {
+			return "", fmt.Errorf("failed to get a download URL: %w", err)
+		}
+		return fmt.Sprintf("got a %s download URL for track %s", url.Quality, diagnosticsTrackID), nil
+	})
+
+	run("output_dir", func() (string, error) {
+		dir := m.config.Download.OutputDir
+		if dir == "" {
+			return "", fmt.Errorf("no output folder configured")
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("can't create %s: %w", dir, err)
+		}
+		probe, err := os.CreateTemp(dir, ".deemusic-write-test-*")
+		if err != nil {
+			return "", fmt.Errorf("can't write to %s: %w", dir, err)
+		}
+		probe.Close()
+		os.Remove(probe.Name())
+		return fmt.Sprintf("%s is writable", dir), nil
+	})
+
+	run("disk_space", func() (string, error) {
+		// Report free space even with download.min_free_space_mb disabled
+		minFreeMB := m.config.Download.MinFreeSpaceMB
+		status := diskSpaceStatus(max(minFreeMB, 1), m.outputDirs())
+		if status.FreeMB < 0 {
+			return "", fmt.Errorf("couldn't read free space for %s", m.config.Download.OutputDir)
+		}
+		if status.Paused && minFreeMB > 0 {
+			return "", fmt.Errorf("%s on %s", status.Message, status.Path)
+		}
+		return fmt.Sprintf("%d MB free on %s", status.FreeMB, status.Path), nil
+	})
+
+	return report
+}
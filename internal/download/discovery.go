@@ -0,0 +1,48 @@
+package download
+
+import (
+	"fmt"
+	"time"
+)
+
+// discoveryInterval keeps a large playlist from sending a notification per track
+const discoveryInterval = 250 * time.Millisecond
+
+// DiscoveryNotifier is implemented by notifiers that report progress while an album's or
+// playlist's tracks are looked up, before any of them shows up in the queue
+type DiscoveryNotifier interface {
+	NotifyDiscovery(itemID string, resolved, total int)
+}
+
+// DiscoveryMessage describes discovery progress, e.g. "resolving 120/300 tracks"
+func DiscoveryMessage(resolved, total int) string {
+	return fmt.Sprintf("resolving %d/%d tracks", resolved, total)
+}
+
+// discoveryReporter sends throttled discovery notifications for one album or playlist
+type discoveryReporter struct {
+	notifier DiscoveryNotifier
+	itemID   string
+	total    int
+	last     time.Time
+}
+
+// newDiscoveryReporter returns a reporter for itemID; it does nothing when the notifier
+// doesn't support discovery notifications
+func (m *Manager) newDiscoveryReporter(itemID string, total int) *discoveryReporter {
+	notifier, _ := m.notifier.(DiscoveryNotifier)
+	return &discoveryReporter{notifier: notifier, itemID: itemID, total: total}
+}
+
+// report notifies that resolved of total tracks have been looked up. The first and last
+// counts are always sent; the ones between at most every discoveryInterval.
+func (r *discoveryReporter) report(resolved int) {
+	if r.notifier == nil || r.total == 0 {
+		return
+	}
+	if resolved > 0 && resolved < r.total && time.Since(r.last) < discoveryInterval {
+		return
+	}
+	r.last = time.Now()
+	r.notifier.NotifyDiscovery(r.itemID, resolved, r.total)
+}
@@ -0,0 +1,41 @@
+package download
+
+import (
+	"testing"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+)
+
+// discoveryRecorder is a Notifier that records discovery notifications
+type discoveryRecorder struct {
+	nopNotifier
+	resolved []int
+}
+
+func (r *discoveryRecorder) NotifyDiscovery(itemID string, resolved, total int) {
+	r.resolved = append(r.resolved, resolved)
+}
+
+func TestDiscoveryReporter(t *testing.T) {
+	recorder := &discoveryRecorder{}
+	mgr := NewManager(&config.Config{}, nil, nil, recorder)
+
+	discovery := mgr.newDiscoveryReporter("playlist_1", 300)
+	for i := 0; i < 300; i++ {
+		discovery.report(i)
+	}
+	discovery.report(300)
+
+	// A fast loop only sends the first and last counts
+	if len(recorder.resolved) != 2 || recorder.resolved[0] != 0 || recorder.resolved[1] != 300 {
+		t.Errorf("Expected notifications for 0 and 300, got %v", recorder.resolved)
+	}
+
+	if got := DiscoveryMessage(120, 300); got != "resolving 120/300 tracks" {
+		t.Errorf("Unexpected message %q", got)
+	}
+
+	// Notifiers without discovery support are skipped
+	plain := NewManager(&config.Config{}, nil, nil, nil)
+	plain.newDiscoveryReporter("playlist_2", 10).report(0)
+}
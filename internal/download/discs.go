@@ -0,0 +1,100 @@
+package download
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+	"github.com/deemusic/deemusic-go/internal/store"
+)
+
+// DiscProgress is the download progress of one disc of an album
+type DiscProgress struct {
+	Disc      int `json:"disc"`
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// albumDiscLayout is stored in an album item's metadata when the album is expanded,
+// since track children are only added to the queue once they start downloading
+type albumDiscLayout struct {
+	TrackDiscs map[string]int `json:"track_discs"` // track ID -> disc number
+}
+
+// newAlbumDiscLayout records the disc of every track, treating a missing disc number as disc 1
+func newAlbumDiscLayout(tracks []*api.Track) albumDiscLayout {
+	layout := albumDiscLayout{TrackDiscs: make(map[string]int, len(tracks))}
+	for _, track := range tracks {
+		disc := track.DiscNumber
+		if disc == 0 {
+			disc = 1
+		}
+		layout.TrackDiscs[track.ID.String()] = disc
+	}
+	return layout
+}
+
+// GetDiscProgress returns an album's progress grouped by disc, in disc order.
+// The result is empty until the album has been expanded into tracks.
+func (m *Manager) GetDiscProgress(albumItemID string) ([]*DiscProgress, error) {
+	item, err := m.queueStore.GetByID(albumItemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Type != "album" {
+		return nil, fmt.Errorf("%s is not an album", albumItemID)
+	}
+
+	var layout albumDiscLayout
+	if item.MetadataJSON != "" {
+		if err := item.GetMetadata(&layout); err != nil {
+			return nil, fmt.Errorf("failed to read disc layout: %w", err)
+		}
+	}
+	if len(layout.TrackDiscs) == 0 {
+		return []*DiscProgress{}, nil
+	}
+
+	children, err := m.queueStore.GetChildren(albumItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	return discProgress(strings.TrimPrefix(albumItemID, "album_"), layout, children), nil
+}
+
+// discProgress tallies the album's child items against the disc layout
+func discProgress(albumID string, layout albumDiscLayout, children []*store.QueueItem) []*DiscProgress {
+	byDisc := make(map[int]*DiscProgress)
+	for _, disc := range layout.TrackDiscs {
+		if byDisc[disc] == nil {
+			byDisc[disc] = &DiscProgress{Disc: disc}
+		}
+		byDisc[disc].Total++
+	}
+
+	childPrefix := fmt.Sprintf("track_%s_", albumID)
+	for _, child := range children {
+		disc, ok := layout.TrackDiscs[strings.TrimPrefix(child.ID, childPrefix)]
+		if !ok {
+			continue
+		}
+		switch child.Status {
+		case "completed":
+			byDisc[disc].Completed++
+		case "failed":
+			byDisc[disc].Failed++
+		}
+	}
+
+	progress := make([]*DiscProgress, 0, len(byDisc))
+	for _, disc := range byDisc {
+		progress = append(progress, disc)
+	}
+	sort.Slice(progress, func(i, j int) bool {
+		return progress[i].Disc < progress[j].Disc
+	})
+	return progress
+}
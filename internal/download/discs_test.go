@@ -0,0 +1,74 @@
+package download
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+	"github.com/deemusic/deemusic-go/internal/config"
+	"github.com/deemusic/deemusic-go/internal/store"
+)
+
+func TestGetDiscProgress(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	queueStore := store.NewQueueStore(db)
+	mgr := NewManager(&config.Config{}, queueStore, nil, nil)
+
+	album := &store.QueueItem{ID: "album_9", Type: "album", Title: "Box Set", Status: "downloading", TotalTracks: 5}
+	if err := queueStore.Add(album); err != nil {
+		t.Fatalf("Failed to add album: %v", err)
+	}
+
+	// Not expanded yet: no layout stored
+	progress, err := mgr.GetDiscProgress("album_9")
+	if err != nil {
+		t.Fatalf("GetDiscProgress failed: %v", err)
+	}
+	if len(progress) != 0 {
+		t.Errorf("Expected no discs before expansion, got %d", len(progress))
+	}
+
+	album.SetMetadata(newAlbumDiscLayout([]*api.Track{
+		{ID: "1", DiscNumber: 1},
+		{ID: "2", DiscNumber: 1},
+		{ID: "3", DiscNumber: 2},
+		{ID: "4", DiscNumber: 2},
+		{ID: "5", DiscNumber: 2},
+	}))
+	if err := queueStore.Update(album); err != nil {
+		t.Fatalf("Failed to update album: %v", err)
+	}
+
+	for id, status := range map[string]string{"1": "completed", "2": "completed", "3": "completed", "4": "failed", "5": "downloading"} {
+		child := &store.QueueItem{ID: "track_9_" + id, Type: "track", Status: status, ParentID: "album_9"}
+		if err := queueStore.Add(child); err != nil {
+			t.Fatalf("Failed to add track: %v", err)
+		}
+	}
+
+	progress, err = mgr.GetDiscProgress("album_9")
+	if err != nil {
+		t.Fatalf("GetDiscProgress failed: %v", err)
+	}
+	expected := []DiscProgress{
+		{Disc: 1, Total: 2, Completed: 2},
+		{Disc: 2, Total: 3, Completed: 1, Failed: 1},
+	}
+	if len(progress) != len(expected) {
+		t.Fatalf("Expected %d discs, got %d", len(expected), len(progress))
+	}
+	for i, want := range expected {
+		if *progress[i] != want {
+			t.Errorf("Disc %d: expected %+v, got %+v", i+1, want, *progress[i])
+		}
+	}
+
+	if _, err := mgr.GetDiscProgress("track_9_1"); err == nil {
+		t.Error("Expected an error for a non-album item")
+	}
+}
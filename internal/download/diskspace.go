@@ -0,0 +1,103 @@
+package download
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+)
+
+// DiskSpaceStatus reports whether download.min_free_space_mb is holding back the queue
+type DiskSpaceStatus struct {
+	Enabled   bool   `json:"enabled"`
+	Paused    bool   `json:"paused"`
+	Path      string `json:"path,omitempty"` // Folder whose volume is lowest on space
+	FreeMB    int64  `json:"free_mb"`
+	MinFreeMB int    `json:"min_free_mb"`
+	Message   string `json:"message,omitempty"` // e.g. "low disk space: 120 MB free, 500 MB required"
+}
+
+// DiskSpaceNotifier is implemented by notifiers that tell the UI when low disk space
+// pauses or resumes dispatch
+type DiskSpaceNotifier interface {
+	NotifyDiskSpace(status *DiskSpaceStatus)
+}
+
+// GetDiskSpaceStatus returns the free space on the output (and staging) volume against
+// download.min_free_space_mb
+func (m *Manager) GetDiskSpaceStatus() *DiskSpaceStatus {
+	return diskSpaceStatus(m.config.Download.MinFreeSpaceMB, m.outputDirs())
+}
+
+// outputDirs returns every folder a download is written to
+func (m *Manager) outputDirs() []string {
+	dirs := []string{m.config.Download.OutputDir}
+	if m.config.Download.StagingDir != "" {
+		dirs = append(dirs, m.config.Download.StagingDir)
+	}
+	return dirs
+}
+
+// diskSpaceStatus checks every folder a download is written to and reports the one with
+// the least free space. A volume whose free space can't be read never pauses the queue.
+func diskSpaceStatus(minFreeMB int, dirs []string) *DiskSpaceStatus {
+	status := &DiskSpaceStatus{Enabled: minFreeMB > 0, MinFreeMB: minFreeMB, FreeMB: -1}
+	if !status.Enabled {
+		return status
+	}
+
+	for _, dir := range dirs {
+		free, err := freeDiskSpace(existingAncestor(dir))
+		if err != nil {
+			continue
+		}
+		freeMB := int64(free / (1024 * 1024))
+		if status.FreeMB < 0 || freeMB < status.FreeMB {
+			status.FreeMB = freeMB
+			status.Path = dir
+		}
+	}
+
+	if status.FreeMB >= 0 && status.FreeMB < int64(minFreeMB) {
+		status.Paused = true
+		status.Message = fmt.Sprintf("low disk space: %d MB free, %d MB required", status.FreeMB, minFreeMB)
+	}
+	return status
+}
+
+// existingAncestor returns dir or its nearest parent that exists, since the output folder
+// may not have been created yet
+func existingAncestor(dir string) string {
+	dir = filepath.Clean(dir)
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// diskSpaceAllowed checks free space before processQueue dispatches pending items,
+// notifying the UI when it runs low or is freed again. Jobs already running are left to finish.
+func (m *Manager) diskSpaceAllowed() bool {
+	status := m.GetDiskSpaceStatus()
+
+	if status.Paused != m.diskSpacePaused {
+		m.diskSpacePaused = status.Paused
+		if status.Paused {
+			m.logWarn("", "Pausing queue, low disk space", zap.String("path", status.Path), zap.Int64("free_mb", status.FreeMB), zap.Int("min_free_mb", status.MinFreeMB))
+		} else {
+			m.logInfo("", "Disk space freed, resuming queue")
+		}
+		if notifier, ok := m.notifier.(DiskSpaceNotifier); ok {
+			notifier.NotifyDiskSpace(status)
+		}
+	}
+
+	return !status.Paused
+}
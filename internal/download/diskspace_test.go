@@ -0,0 +1,65 @@
+package download
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+)
+
+// diskSpaceRecorder is a Notifier that records disk space notifications
+type diskSpaceRecorder struct {
+	nopNotifier
+	paused []bool
+}
+
+func (r *diskSpaceRecorder) NotifyDiskSpace(status *DiskSpaceStatus) {
+	r.paused = append(r.paused, status.Paused)
+}
+
+func TestDiskSpaceStatus(t *testing.T) {
+	dir := t.TempDir()
+
+	if status := diskSpaceStatus(0, []string{dir}); status.Enabled || status.Paused {
+		t.Errorf("Expected a zero threshold to disable the check, got %+v", status)
+	}
+
+	// The output folder doesn't have to exist yet
+	status := diskSpaceStatus(1, []string{filepath.Join(dir, "not", "created")})
+	if status.FreeMB < 1 || status.Paused {
+		t.Errorf("Expected free space to be read from the nearest existing parent, got %+v", status)
+	}
+
+	// No real volume has an exabyte free
+	status = diskSpaceStatus(1<<40, []string{dir})
+	if !status.Paused || status.Path != dir || status.Message == "" {
+		t.Errorf("Expected an unreachable threshold to pause, got %+v", status)
+	}
+}
+
+func TestDiskSpaceAllowed(t *testing.T) {
+	recorder := &diskSpaceRecorder{}
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = t.TempDir()
+	mgr := NewManager(cfg, nil, nil, recorder)
+
+	if !mgr.diskSpaceAllowed() {
+		t.Fatal("Expected dispatch to be allowed with the check disabled")
+	}
+
+	cfg.Download.MinFreeSpaceMB = 1 << 40
+	if mgr.diskSpaceAllowed() || mgr.diskSpaceAllowed() {
+		t.Error("Expected dispatch to be held back while space is low")
+	}
+
+	// Freeing space (here, lowering the threshold) resumes without any other action
+	cfg.Download.MinFreeSpaceMB = 1
+	if !mgr.diskSpaceAllowed() {
+		t.Error("Expected dispatch to resume once space is available")
+	}
+
+	// Only the changes are notified
+	if len(recorder.paused) != 2 || !recorder.paused[0] || recorder.paused[1] {
+		t.Errorf("Expected paused then resumed notifications, got %v", recorder.paused)
+	}
+}
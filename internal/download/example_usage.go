@@ -49,7 +49,7 @@ func ExampleBasicSetup() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer manager.Stop()
+	defer manager.Stop(context.Background())
 
 	fmt.Println("Download manager started successfully")
 }
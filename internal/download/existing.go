@@ -0,0 +1,50 @@
+package download
+
+import (
+	"errors"
+	"os"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+)
+
+// ErrAlreadyDownloaded is returned by DownloadTrack and DownloadAlbum when every file the
+// download would write is already on disk, so nothing is queued
+var ErrAlreadyDownloaded = errors.New("already downloaded")
+
+// albumOnDisk reports whether every track of the album already exists at the path the album
+// job would write it to. An album whose track list is incomplete is never treated as on disk.
+func (m *Manager) albumOnDisk(album *api.Album) bool {
+	if album.Tracks == nil || len(album.Tracks.Data) == 0 || len(album.Tracks.Data) < album.TrackCount {
+		return false
+	}
+	for _, path := range m.albumTrackPaths(album, albumTracks(album)) {
+		if !fileOnDisk(path) {
+			return false
+		}
+	}
+	return true
+}
+
+// trackOnDisk reports whether a single track already exists where a standalone track
+// download would put it
+func (m *Manager) trackOnDisk(track *api.Track) bool {
+	if track.Album == nil || track.Artist == nil {
+		return false
+	}
+	t := *track
+	t.IsMultiDiscAlbum = false
+	t.AlbumArtist = m.folderAlbumArtist(track.Album)
+	if t.AlbumArtist == "" {
+		t.AlbumArtist = track.Artist.Name
+	}
+	return fileOnDisk(m.resolveOutputPath(&t, m.config.Download.Quality))
+}
+
+// fileOnDisk matches the resume check in downloadTrackJob: a non-empty file counts as downloaded
+func fileOnDisk(path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Size() > 0
+}
@@ -0,0 +1,96 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+	"github.com/deemusic/deemusic-go/internal/config"
+)
+
+func TestAlbumOnDisk(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = t.TempDir()
+	cfg.Download.Quality = "MP3_320"
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	artist := &api.Artist{ID: "7", Name: "Existing Artist"}
+	album := &api.Album{ID: "existing-1", Title: "Already Here", Artist: artist, TrackCount: 2}
+	album.Tracks = &api.Tracks{Data: []*api.Track{
+		{ID: "1", Title: "First", TrackNumber: 1, Artist: artist},
+		{ID: "2", Title: "Second", TrackNumber: 2, Artist: artist},
+	}}
+
+	paths := mgr.albumTrackPaths(album, albumTracks(album))
+	if mgr.albumOnDisk(album) {
+		t.Fatal("Expected album with no files not to be on disk")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(paths[0]), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(paths[0], []byte("audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if mgr.albumOnDisk(album) {
+		t.Error("Expected album with a missing track not to be on disk")
+	}
+
+	// An empty file is an interrupted download, not a finished one
+	if err := os.WriteFile(paths[1], nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if mgr.albumOnDisk(album) {
+		t.Error("Expected an empty file not to count as downloaded")
+	}
+
+	if err := os.WriteFile(paths[1], []byte("audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !mgr.albumOnDisk(album) {
+		t.Error("Expected album with every track present to be on disk")
+	}
+
+	// Deezer listed fewer tracks than the album has, so the rest can't be checked
+	album.TrackCount = 3
+	if mgr.albumOnDisk(album) {
+		t.Error("Expected an incomplete track list not to be on disk")
+	}
+}
+
+func TestTrackOnDisk(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = t.TempDir()
+	cfg.Download.Quality = "FLAC"
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	artist := &api.Artist{ID: "8", Name: "Single Artist"}
+	track := &api.Track{
+		ID:          "9",
+		Title:       "Lone Song",
+		TrackNumber: 1,
+		Artist:      artist,
+		Album:       &api.Album{ID: "existing-2", Title: "Lone Album", Artist: artist},
+	}
+
+	if mgr.trackOnDisk(track) {
+		t.Fatal("Expected track not to be on disk yet")
+	}
+
+	t2 := *track
+	t2.AlbumArtist = artist.Name
+	path := mgr.resolveOutputPath(&t2, cfg.Download.Quality)
+	if filepath.Ext(path) != ".flac" {
+		t.Fatalf("Expected a .flac path, got %s", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !mgr.trackOnDisk(track) {
+		t.Error("Expected track to be on disk")
+	}
+}
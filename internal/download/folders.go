@@ -0,0 +1,149 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+)
+
+// FolderPlan lists the folders an album download writes to
+type FolderPlan struct {
+	AlbumID string   `json:"album_id"`
+	Folders []string `json:"folders"`
+	Missing int      `json:"missing"` // Folders that didn't exist yet
+	Created bool     `json:"created"`
+}
+
+// PrepareAlbumFolders works out the artist, album and disc folders an album download will use
+// and, when create is set, creates them up front instead of lazily per track. Disc folders
+// are only planned for tracks whose disc number Deezer lists on the album.
+func (m *Manager) PrepareAlbumFolders(ctx context.Context, albumID string, create bool) (*FolderPlan, error) {
+	album, err := m.deezerAPI.GetAlbum(ctx, albumID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get album details: %w", err)
+	}
+	if album.Tracks == nil {
+		return nil, fmt.Errorf("album %s has no tracks", albumID)
+	}
+
+	plan := &FolderPlan{AlbumID: albumID, Folders: m.albumFolders(album, albumTracks(album))}
+	for _, folder := range plan.Folders {
+		if _, err := os.Stat(folder); err != nil {
+			plan.Missing++
+		}
+	}
+
+	if create {
+		if err := m.createFolders(plan.Folders); err != nil {
+			return plan, err
+		}
+		plan.Created = true
+	}
+	return plan, nil
+}
+
+// albumTracks copies the album's tracks with the disc fields downloadAlbumJob sets, so
+// their paths resolve into the same CD folders
+func albumTracks(album *api.Album) []*api.Track {
+	isMultiDisc := album.DiscCount > 1
+	for _, track := range album.Tracks.Data {
+		if track.DiscNumber > 1 {
+			isMultiDisc = true
+		}
+	}
+	tracks := make([]*api.Track, len(album.Tracks.Data))
+	for i, track := range album.Tracks.Data {
+		t := *track
+		t.IsMultiDiscAlbum = isMultiDisc
+		if isMultiDisc && t.DiscNumber == 0 {
+			t.DiscNumber = 1
+		}
+		tracks[i] = &t
+	}
+	return tracks
+}
+
+// albumFolders returns the distinct folders the album's tracks resolve to, sorted
+func (m *Manager) albumFolders(album *api.Album, tracks []*api.Track) []string {
+	seen := make(map[string]bool)
+	var folders []string
+	for _, path := range m.albumTrackPaths(album, tracks) {
+		if path == "" {
+			continue
+		}
+		folder := filepath.Dir(path)
+		if !seen[folder] {
+			seen[folder] = true
+			folders = append(folders, folder)
+		}
+	}
+	sort.Strings(folders)
+	return folders
+}
+
+// albumTrackPaths resolves the output file of each track, in order. Tracks without an
+// artist can't be placed and get an empty path.
+func (m *Manager) albumTrackPaths(album *api.Album, tracks []*api.Track) []string {
+	albumArtist := m.folderAlbumArtist(album)
+
+	paths := make([]string, len(tracks))
+	for i, track := range tracks {
+		t := *track
+		t.Album = album
+		if t.Artist == nil {
+			t.Artist = album.Artist
+		}
+		if t.Artist == nil {
+			continue
+		}
+		t.AlbumArtist = albumArtist
+		paths[i] = m.resolveOutputPath(&t, m.config.Download.Quality)
+	}
+	return paths
+}
+
+// folderAlbumArtist picks the artist folder for an album the same way downloadTrackJob does:
+// the artist cached by the album job, then Various Artists for compilations, then the album artist
+func (m *Manager) folderAlbumArtist(album *api.Album) string {
+	if cached, ok := getCachedAlbumArtist(album.ID.String()); ok {
+		return cached
+	}
+	if m.isTrackAlbumCompilation(album) {
+		return m.variousArtistsName()
+	}
+	if album.Artist != nil {
+		return album.Artist.Name
+	}
+	return ""
+}
+
+// createFolders creates each folder, remembering them so later tracks skip MkdirAll
+func (m *Manager) createFolders(folders []string) error {
+	for _, folder := range folders {
+		if err := m.ensureDir(folder); err != nil {
+			return fmt.Errorf("failed to create %s: %w", folder, err)
+		}
+	}
+	return nil
+}
+
+// queueAlbumCovers queues cover.jpg for every folder the album's tracks go to (one per disc
+// with CD folders). Per-track artwork still fills in any cover that fails here.
+func (m *Manager) queueAlbumCovers(ctx context.Context, album *api.Album) {
+	if m.inSinglesFolder(&api.Track{Album: album}) {
+		return // The shared Singles folder gets no cover.jpg
+	}
+	for _, folder := range m.albumFolders(album, album.Tracks.Data) {
+		dir := folder
+		m.queueImageDownload(ctx, filepath.Join(dir, "cover.jpg"), "album artwork", func(ctx context.Context) error {
+			if err := m.ensureDir(dir); err != nil {
+				return fmt.Errorf("failed to create %s: %w", dir, err)
+			}
+			return m.downloadAlbumArtwork(ctx, album, dir)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+	"github.com/deemusic/deemusic-go/internal/config"
+)
+
+func TestAlbumFolders(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = t.TempDir()
+	cfg.Download.CreateCDFolder = true
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	artist := &api.Artist{ID: "5", Name: "Folder Artist"}
+	album := &api.Album{ID: "folders-1", Title: "Box Set", Artist: artist}
+	tracks := []*api.Track{
+		{ID: "1", Title: "One", TrackNumber: 1, DiscNumber: 1, IsMultiDiscAlbum: true, Artist: artist},
+		{ID: "2", Title: "Two", TrackNumber: 2, DiscNumber: 1, IsMultiDiscAlbum: true, Artist: artist},
+		{ID: "3", Title: "Three", TrackNumber: 1, DiscNumber: 2, IsMultiDiscAlbum: true},
+	}
+
+	folders := mgr.albumFolders(album, tracks)
+	albumDir := filepath.Join(cfg.Download.OutputDir, "Folder Artist", "Box Set")
+	expected := []string{filepath.Join(albumDir, "CD 1"), filepath.Join(albumDir, "CD 2")}
+	if len(folders) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, folders)
+	}
+	for i := range expected {
+		if folders[i] != expected[i] {
+			t.Errorf("Folder %d: expected %s, got %s", i, expected[i], folders[i])
+		}
+	}
+
+	// Planning alone doesn't touch the disk
+	if _, err := os.Stat(albumDir); !os.IsNotExist(err) {
+		t.Fatalf("Expected no folders before createFolders, got %v", err)
+	}
+
+	if err := mgr.createFolders(folders); err != nil {
+		t.Fatalf("createFolders failed: %v", err)
+	}
+	for _, folder := range folders {
+		if info, err := os.Stat(folder); err != nil || !info.IsDir() {
+			t.Errorf("Expected %s to be created: %v", folder, err)
+		}
+		if !mgr.knownDirs.Known(folder) {
+			t.Errorf("Expected %s to be remembered", folder)
+		}
+	}
+
+	// Tracks then resolve into the pre-created folders
+	track := *tracks[2]
+	track.Album = album
+	track.Artist = artist
+	track.AlbumArtist = artist.Name
+	if got := filepath.Dir(mgr.buildOutputPath(&track, "MP3_320")); got != expected[1] {
+		t.Errorf("Expected track in %s, got %s", expected[1], got)
+	}
+}
+
+func TestQueueAlbumCovers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("jpeg"))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = t.TempDir()
+	cfg.Download.CreateCDFolder = true
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	artist := &api.Artist{ID: "6", Name: "Cover Artist"}
+	album := &api.Album{ID: "covers-1", Title: "Double Album", Artist: artist, CoverXL: server.URL}
+	album.Tracks = &api.Tracks{Data: []*api.Track{
+		{ID: "1", Title: "One", TrackNumber: 1, DiscNumber: 1, IsMultiDiscAlbum: true},
+		{ID: "2", Title: "Two", TrackNumber: 1, DiscNumber: 2, IsMultiDiscAlbum: true},
+	}}
+
+	// No track has downloaded yet, so the folders don't exist
+	mgr.queueAlbumCovers(context.Background(), album)
+	mgr.backgroundWG.Wait()
+
+	albumDir := filepath.Join(cfg.Download.OutputDir, "Cover Artist", "Double Album")
+	for _, disc := range []string{"CD 1", "CD 2"} {
+		data, err := os.ReadFile(filepath.Join(albumDir, disc, "cover.jpg"))
+		if err != nil || string(data) != "jpeg" {
+			t.Errorf("Expected cover.jpg in %s, got %q (%v)", disc, data, err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package download
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+	"github.com/deemusic/deemusic-go/internal/monitoring"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// downloadLog writes the manager's log lines. With logging.format "json" each entry is a
+// structured record (timestamp, level, component, item_id, message, fields) written to
+// logging.output; otherwise it is a plain line in the debug log in the temp folder.
+// Entries below logging.level are dropped either way.
+type downloadLog struct {
+	mu         sync.Mutex
+	configured bool
+	settings   config.LoggingConfig // Settings the logger was built from, to rebuild on changes only
+	level      zapcore.Level
+	logger     *zap.Logger // nil unless logging.format is "json"
+}
+
+// configure applies the logging settings, rebuilding the JSON logger when they change.
+// If the logger can't be created the plain-text debug log is used instead.
+func (l *downloadLog) configure(settings config.LoggingConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.configured && l.settings == settings {
+		return
+	}
+	l.configured = true
+	l.settings = settings
+
+	if l.logger != nil {
+		l.logger.Sync()
+		l.logger = nil
+	}
+
+	l.level = zapcore.InfoLevel
+	if settings.Level != "" {
+		if level, err := zapcore.ParseLevel(settings.Level); err == nil {
+			l.level = level
+		}
+	}
+
+	if settings.Format != "json" {
+		return
+	}
+	logConfig := monitoring.LogConfig(settings)
+	logConfig.Level = l.level.String()
+	logger, err := monitoring.NewLogger(&logConfig)
+	if err != nil {
+		writeDebugLog(fmt.Sprintf("Failed to create JSON logger, using the debug log: %v", err))
+		return
+	}
+	// Report the manager's call site rather than the log helpers
+	l.logger = logger.WithOptions(zap.AddCallerSkip(2)).With(zap.String("component", "download"))
+}
+
+// write logs one entry; fields are nested under "fields" in JSON output
+func (l *downloadLog) write(level zapcore.Level, itemID, msg string, fields []zap.Field) {
+	l.mu.Lock()
+	logger, minLevel := l.logger, l.level
+	l.mu.Unlock()
+	if level < minLevel {
+		return
+	}
+
+	if logger != nil {
+		if entry := logger.Check(level, msg); entry != nil {
+			entry.Write(append([]zap.Field{zap.String("item_id", itemID), zap.Namespace("fields")}, fields...)...)
+		}
+		return
+	}
+
+	line := msg
+	if itemID != "" {
+		line += " item_id=" + itemID
+	}
+	writeDebugLog(line + formatFields(fields))
+}
+
+// sync flushes buffered JSON entries
+func (l *downloadLog) sync() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.logger != nil {
+		l.logger.Sync()
+	}
+}
+
+// formatFields renders fields as " key=value" pairs for the plain-text log
+func formatFields(fields []zap.Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	keys := make([]string, 0, len(enc.Fields))
+	for key := range enc.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%v", key, enc.Fields[key])
+	}
+	return b.String()
+}
+
+// writeDebugLog appends a line to the plain-text debug log
+func writeDebugLog(line string) {
+	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+		fmt.Fprintf(logFile, "[%s] %s\n", time.Now().Format("2006-01-02 15:04:05"), line)
+		logFile.Close()
+	}
+}
+
+func (m *Manager) logDebug(itemID, msg string, fields ...zap.Field) {
+	m.log.write(zapcore.DebugLevel, itemID, msg, fields)
+}
+
+func (m *Manager) logInfo(itemID, msg string, fields ...zap.Field) {
+	m.log.write(zapcore.InfoLevel, itemID, msg, fields)
+}
+
+func (m *Manager) logWarn(itemID, msg string, fields ...zap.Field) {
+	m.log.write(zapcore.WarnLevel, itemID, msg, fields)
+}
+
+func (m *Manager) logError(itemID, msg string, fields ...zap.Field) {
+	m.log.write(zapcore.ErrorLevel, itemID, msg, fields)
+}
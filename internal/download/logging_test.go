@@ -0,0 +1,81 @@
+package download
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+	"go.uber.org/zap"
+)
+
+func TestJSONLogging(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	cfg := &config.Config{}
+	cfg.Logging = config.LoggingConfig{Level: "info", Format: "json", Output: "file", FilePath: logPath, MaxSizeMB: 1}
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	mgr.logDebug("track_1_2", "Suppressed below logging.level")
+	mgr.logWarn("track_1_2", "Track failed, will retry", zap.Int("attempt", 1), zap.Error(errors.New("timeout")))
+	mgr.log.sync()
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected only the warning to be logged, got %d lines: %s", len(lines), data)
+	}
+
+	var entry struct {
+		Timestamp string                 `json:"timestamp"`
+		Level     string                 `json:"level"`
+		Component string                 `json:"component"`
+		ItemID    string                 `json:"item_id"`
+		Message   string                 `json:"msg"`
+		Caller    string                 `json:"caller"`
+		Fields    map[string]interface{} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Expected a JSON entry, got %s: %v", lines[0], err)
+	}
+	if entry.Timestamp == "" || entry.Level != "WARN" || entry.Component != "download" || entry.ItemID != "track_1_2" || entry.Message != "Track failed, will retry" {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+	if entry.Fields["attempt"] != float64(1) || entry.Fields["error"] != "timeout" {
+		t.Errorf("Expected fields to be nested, got %v", entry.Fields)
+	}
+	if !strings.HasPrefix(entry.Caller, "download/logging_test.go") {
+		t.Errorf("Expected the caller to be the log call site, got %s", entry.Caller)
+	}
+}
+
+func TestLoggingReconfigure(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Logging.Format = "console"
+	mgr := NewManager(cfg, nil, nil, nil)
+	if mgr.log.logger != nil {
+		t.Error("Expected console format to use the plain-text debug log")
+	}
+
+	updated := *cfg
+	updated.Logging = config.LoggingConfig{Level: "debug", Format: "json", Output: "file", FilePath: filepath.Join(t.TempDir(), "app.log"), MaxSizeMB: 1}
+	mgr.UpdateConfig(&updated)
+	if mgr.log.logger == nil || mgr.log.level != zap.DebugLevel {
+		t.Error("Expected switching to json to build the structured logger")
+	}
+}
+
+func TestFormatFields(t *testing.T) {
+	got := formatFields([]zap.Field{zap.String("quality", "FLAC"), zap.Int("attempt", 2)})
+	if got != " attempt=2 quality=FLAC" {
+		t.Errorf("Unexpected plain-text fields %q", got)
+	}
+	if formatFields(nil) != "" {
+		t.Error("Expected no fields to render as nothing")
+	}
+}
@@ -2,22 +2,30 @@ package download
 
 import (
 	"context"
+	"crypto/sha1"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/deemusic/deemusic-go/internal/api"
 	"github.com/deemusic/deemusic-go/internal/config"
 	"github.com/deemusic/deemusic-go/internal/decryption"
 	"github.com/deemusic/deemusic-go/internal/metadata"
+	"github.com/deemusic/deemusic-go/internal/network"
 	"github.com/deemusic/deemusic-go/internal/store"
+	"go.uber.org/zap"
 )
 
 // Manager coordinates all download operations
@@ -31,9 +39,31 @@ type Manager struct {
 	mu                  sync.RWMutex
 	pausedJobs          map[string]bool
 	started             bool
-	albumMu             sync.Mutex            // Serialize album job processing to avoid database contention
+	albumSem            chan struct{}         // Bounds album/playlist jobs expanding into track jobs
 	artistImageMu       sync.Mutex            // Protect artist image downloads from race conditions
 	artistImageInFlight map[string]bool       // Track which artist images are currently being downloaded
+	metadataSem         chan struct{}         // Bounds concurrent metadata applies
+	flacTagMu           sync.Mutex            // FLAC tagging rewrites the whole file - one at a time
+	imageSem            chan struct{}         // Bounds concurrent artwork/artist image downloads
+	imageHTTP           *http.Client          // Shared image client, so CDN connections are reused and capped per host
+	imageMu             sync.Mutex            // Protects imageQueued
+	imageQueued         map[string]bool       // Image destination paths with a queued or running download
+	backgroundWG        sync.WaitGroup        // Tagging/lyrics/image goroutines started by jobs; Stop waits for them
+	stopQueue           context.CancelFunc    // Stops processQueue from submitting more jobs
+	queueDone           sync.WaitGroup        // processQueue and sampleThroughput; Stop waits for them
+	stopResults         chan struct{}         // Closed by Stop to end processResults when results can't be drained
+	resultsDone         chan struct{}         // Closed when processResults has drained the results channel
+	throughput          *throughputTracker    // Per-concurrency throughput telemetry
+	slowStart           *slowStart            // Ramps concurrent track downloads up when the queue starts from idle
+	parentRates         *parentRates          // Recent track completion times per album/playlist, for their ETA
+	breaker             *rateLimitBreaker     // Holds back an album/playlist's tracks after repeated rate limits
+	knownDirs           *network.DirCache     // Output folders already created, so MkdirAll runs once per folder
+	parentLocks         parentLockSet         // Serializes updateParentProgress per album/playlist
+	schedulePaused      bool                  // Last schedule state seen by processQueue, to notify on changes only
+	diskSpacePaused     bool                  // Last low disk space state seen by processQueue, to notify on changes only
+	dispatchNow         chan struct{}         // Wakes processQueue when a job finishes instead of waiting for the next tick
+	completion          completionBatch       // Counts since the queue last started, for the idle summary
+	log                 downloadLog           // Structured or plain-text log, per logging.format and logging.level
 }
 
 // Notifier interface for progress notifications
@@ -44,6 +74,11 @@ type Notifier interface {
 	NotifyFailed(itemID string, err error)
 }
 
+// StatsProvider is implemented by notifiers that track live per-item speed and ETA
+type StatsProvider interface {
+	GetAllDownloadStats() []*DownloadStats
+}
+
 // NewManager creates a new download manager
 func NewManager(
 	cfg *config.Config,
@@ -52,18 +87,38 @@ func NewManager(
 	notifier Notifier,
 ) *Manager {
 	processor := decryption.NewStreamingProcessor(8192)
+	processor.SetBandwidthLimits(cfg.Network.BandwidthLimit, cfg.Network.PerDownloadLimit)
+	knownDirs := &network.DirCache{}
+	processor.SetDirCache(knownDirs)
 
 	mgr := &Manager{
 		config:              cfg,
 		queueStore:          queueStore,
 		deezerAPI:           deezerAPI,
 		processor:           processor,
+		knownDirs:           knownDirs,
 		notifier:            notifier,
 		pausedJobs:          make(map[string]bool),
 		artistImageInFlight: make(map[string]bool),
+		metadataSem:         make(chan struct{}, metadataConcurrency(cfg)),
+		imageSem:            make(chan struct{}, imageConcurrency(cfg)),
+		albumSem:            make(chan struct{}, albumConcurrency(cfg)),
+		imageHTTP:           newImageClient(cfg),
+		imageQueued:         make(map[string]bool),
+		throughput:          newThroughputTracker(),
+		slowStart:           newSlowStart(),
+		parentRates:         newParentRates(),
+		breaker:             newRateLimitBreaker(),
+		dispatchNow:         make(chan struct{}, 1),
 		started:             false,
 	}
 
+	mgr.log.configure(cfg.Logging)
+
+	// Restore paused state from the previous session
+	mgr.loadPausedJobs()
+	mgr.loadThroughput()
+
 	// Create worker pool with job handler
 	mgr.workerPool = NewWorkerPool(cfg.Download.ConcurrentDownloads, mgr.handleJob)
 
@@ -82,30 +137,7 @@ func (m *Manager) Start(ctx context.Context) error {
 	}
 
 	// Reset any downloads that were interrupted (status='downloading' from previous session)
-	fmt.Fprintf(os.Stderr, "[INFO] Resetting interrupted downloads...\n")
-	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		fmt.Fprintf(logFile, "[%s] Resetting interrupted downloads to pending status\n", time.Now().Format("2006-01-02 15:04:05"))
-		logFile.Close()
-	}
-	
-	// Get all items with status='downloading' and reset them to 'pending'
-	downloadingItems, err := m.queueStore.GetByStatus("downloading", 0, 1000)
-	if err == nil {
-		for _, item := range downloadingItems {
-			item.Status = "pending"
-			item.Progress = 0
-			if updateErr := m.queueStore.Update(item); updateErr != nil {
-				fmt.Fprintf(os.Stderr, "[WARN] Failed to reset item %s: %v\n", item.ID, updateErr)
-			} else {
-				fmt.Fprintf(os.Stderr, "[INFO] Reset interrupted download: %s\n", item.ID)
-				if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-					fmt.Fprintf(logFile, "[%s] Reset interrupted download: %s (%s)\n", time.Now().Format("2006-01-02 15:04:05"), item.ID, item.Title)
-					logFile.Close()
-				}
-			}
-		}
-		fmt.Fprintf(os.Stderr, "[INFO] Reset %d interrupted downloads\n", len(downloadingItems))
-	}
+	m.resetInterruptedDownloads()
 
 	// Start worker pool
 	fmt.Fprintf(os.Stderr, "[DEBUG] Starting worker pool...\n")
@@ -115,33 +147,157 @@ func (m *Manager) Start(ctx context.Context) error {
 	}
 	fmt.Fprintf(os.Stderr, "[DEBUG] Worker pool started\n")
 
-	// Start result processor
+	// Start result processor on this run's results channel; each Start gets a new one
 	fmt.Fprintf(os.Stderr, "[DEBUG] Starting result processor goroutine...\n")
-	go m.processResults()
+	results := m.workerPool.Results()
+	stopResults := make(chan struct{})
+	resultsDone := make(chan struct{})
+	m.stopResults = stopResults
+	m.resultsDone = resultsDone
+	go func() {
+		defer close(resultsDone)
+		m.processResults(results, stopResults)
+	}()
 
 	// Start queue processor
 	fmt.Fprintf(os.Stderr, "[DEBUG] Starting queue processor goroutine...\n")
-	go m.processQueue(ctx)
+	queueCtx, stopQueue := context.WithCancel(ctx)
+	m.stopQueue = stopQueue
+	m.queueDone.Add(2)
+	go func() {
+		defer m.queueDone.Done()
+		m.processQueue(queueCtx)
+	}()
+	go func() {
+		defer m.queueDone.Done()
+		m.sampleThroughput(queueCtx)
+	}()
 
 	m.started = true
 	fmt.Fprintf(os.Stderr, "[DEBUG] Manager.Start() completed successfully\n")
 	return nil
 }
 
-// Stop stops the download manager
-func (m *Manager) Stop() {
+// resetInterruptedDownloads resets tracks left downloading by a previous session and
+// re-enqueues albums/playlists that hadn't finished all their tracks. A parent's job only
+// dispatches its tracks, so re-running it is what recreates children that were never started;
+// already-completed tracks are skipped by downloadTrackJob.
+func (m *Manager) resetInterruptedDownloads() {
+	fmt.Fprintf(os.Stderr, "[INFO] Resetting interrupted downloads...\n")
+	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+		fmt.Fprintf(logFile, "[%s] Resetting interrupted downloads to pending status\n", time.Now().Format("2006-01-02 15:04:05"))
+		logFile.Close()
+	}
+
+	// Tracks that were mid-download restart from scratch
+	trackCount, err := m.queueStore.ResetDownloadingTracks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] Failed to reset interrupted tracks: %v\n", err)
+	}
+
+	// Re-enqueue incomplete albums/playlists so their jobs run again
+	parents, err := m.queueStore.GetIncompleteParents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] Failed to get incomplete albums/playlists: %v\n", err)
+		return
+	}
+
+	parentCount := 0
+	for _, item := range parents {
+		if item.Status == "pending" {
+			continue
+		}
+		// Parents that exhausted their retries stay failed until the user retries them
+		if item.Status == "failed" && item.RetryCount > m.config.Network.MaxRetries {
+			continue
+		}
+
+		item.Status = "pending"
+		item.ErrorMessage = ""
+		item.CompletedAt = nil
+		if item.TotalTracks > 0 {
+			item.Progress = (item.CompletedTracks * 100) / item.TotalTracks
+		}
+		if updateErr := m.queueStore.Update(item); updateErr != nil {
+			fmt.Fprintf(os.Stderr, "[WARN] Failed to reset item %s: %v\n", item.ID, updateErr)
+			continue
+		}
+
+		parentCount++
+		fmt.Fprintf(os.Stderr, "[INFO] Reset interrupted download: %s\n", item.ID)
+		if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+			fmt.Fprintf(logFile, "[%s] Re-enqueued interrupted %s %s (%s) at %d/%d tracks\n", time.Now().Format("2006-01-02 15:04:05"), item.Type, item.ID, item.Title, item.CompletedTracks, item.TotalTracks)
+			logFile.Close()
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "[INFO] Reset %d interrupted tracks and %d albums/playlists\n", trackCount, parentCount)
+}
+
+// Stop stops the download manager. No new jobs are started; running downloads, result
+// bookkeeping and background tagging get until ctx is done to finish so nothing is left
+// half-written and the database can be closed safely afterwards. Returns an error if
+// anything was still running when ctx expired. The manager can be started again.
+func (m *Manager) Stop(ctx context.Context) error {
 	m.mu.Lock()
 	if !m.started {
 		m.mu.Unlock()
-		return
+		return nil
 	}
+	stopQueue := m.stopQueue
+	stopResults := m.stopResults
+	resultsDone := m.resultsDone
 	m.mu.Unlock()
 
-	m.workerPool.Stop()
+	// Stop feeding the pool first so nothing new is submitted while it drains
+	if stopQueue != nil {
+		stopQueue()
+	}
+	queueDone := make(chan struct{})
+	go func() {
+		m.queueDone.Wait()
+		close(queueDone)
+	}()
+	select {
+	case <-queueDone:
+	case <-ctx.Done():
+	}
+
+	err := m.workerPool.Shutdown(ctx)
+
+	// Let processResults record the outcome of the last jobs. If the pool didn't drain, its
+	// results channel closes only once the stuck jobs return, so don't wait on it.
+	if err == nil && resultsDone != nil {
+		select {
+		case <-resultsDone:
+		case <-ctx.Done():
+			err = fmt.Errorf("timed out recording job results: %w", ctx.Err())
+		}
+	}
+	if stopResults != nil {
+		close(stopResults)
+	}
+
+	// Wait for tagging/lyrics/image goroutines started by finished jobs
+	backgroundDone := make(chan struct{})
+	go func() {
+		m.backgroundWG.Wait()
+		close(backgroundDone)
+	}()
+	select {
+	case <-backgroundDone:
+	case <-ctx.Done():
+		if err == nil {
+			err = fmt.Errorf("timed out waiting for background tagging: %w", ctx.Err())
+		}
+	}
 
 	m.mu.Lock()
 	m.started = false
 	m.mu.Unlock()
+
+	m.log.sync()
+	return err
 }
 
 // UpdateConfig updates the manager's configuration
@@ -152,12 +308,24 @@ func (m *Manager) UpdateConfig(newConfig *config.Config) {
 	
 	m.config = newConfig
 	
-	// Log the update
-	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		fmt.Fprintf(logFile, "[%s] Download manager config updated: quality=%s, concurrent=%d\n", 
-			time.Now().Format("2006-01-02 15:04:05"), newConfig.Download.Quality, newConfig.Download.ConcurrentDownloads)
-		logFile.Close()
+	// Resize the metadata semaphore; in-flight applies release into the old one
+	if cap(m.metadataSem) != metadataConcurrency(newConfig) {
+		m.metadataSem = make(chan struct{}, metadataConcurrency(newConfig))
+	}
+	if cap(m.imageSem) != imageConcurrency(newConfig) {
+		m.imageSem = make(chan struct{}, imageConcurrency(newConfig))
+	}
+	if cap(m.albumSem) != albumConcurrency(newConfig) {
+		m.albumSem = make(chan struct{}, albumConcurrency(newConfig))
+	}
+	if m.imageHTTP.Timeout != imageTimeout(newConfig) || m.imageHTTP.Transport.(*http.Transport).MaxConnsPerHost != imageConnsPerHost(newConfig) {
+		m.imageHTTP = newImageClient(newConfig)
 	}
+	
+	m.processor.SetBandwidthLimits(newConfig.Network.BandwidthLimit, newConfig.Network.PerDownloadLimit)
+	m.log.configure(newConfig.Logging)
+	m.logInfo("", "Download manager config updated",
+		zap.String("quality", newConfig.Download.Quality), zap.Int("concurrent", newConfig.Download.ConcurrentDownloads))
 }
 
 // handleJob processes a single download job
@@ -177,27 +345,18 @@ func (m *Manager) handleJob(ctx context.Context, job *Job) error {
 // downloadTrackJob downloads a single track
 func (m *Manager) downloadTrackJob(ctx context.Context, job *Job) error {
 	// Log to temp file
-	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		fmt.Fprintf(logFile, "[%s] downloadTrackJob started for track %s (ID: %s)\n", time.Now().Format("2006-01-02 15:04:05"), job.TrackID, job.ID)
-		logFile.Close()
-	}
+	m.logInfo(job.ID, "Track job started", zap.String("track_id", job.TrackID))
 
 	// Get or create queue item
 	item, err := m.queueStore.GetByID(job.ID)
 	if err == nil && item != nil {
 		// Check if track is already completed - skip if so
 		if item.Status == "completed" {
-			if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-				fmt.Fprintf(logFile, "[%s] SKIPPING track %s - already completed (but updating parent progress)\n", time.Now().Format("2006-01-02 15:04:05"), job.ID)
-				logFile.Close()
-			}
+			m.logDebug(job.ID, "Skipping track, already completed")
 			
 			// Still update parent progress in case this is a retry/resubmit scenario
 			if item.ParentID != "" {
-				if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-					fmt.Fprintf(logFile, "[%s] Track %s already completed, updating parent %s progress\n", time.Now().Format("2006-01-02 15:04:05"), item.ID, item.ParentID)
-					logFile.Close()
-				}
+				m.logDebug(item.ID, "Updating parent progress for completed track", zap.String("parent_id", item.ParentID))
 				m.updateParentProgress(item.ParentID)
 			}
 			
@@ -237,6 +396,39 @@ func (m *Manager) downloadTrackJob(ctx context.Context, job *Job) error {
 		return fmt.Errorf("job is paused")
 	}
 
+	// A job queued before its track or album was cancelled
+	if m.cancelledByUser(item) {
+		m.logInfo(job.ID, "Skipping track, cancelled")
+		return nil
+	}
+
+	// Hold the track back while its album/playlist is paused
+	if item.ParentID != "" && m.isJobPaused(item.ParentID) {
+		if item.Status != "pending" {
+			item.Status = "pending"
+			m.queueStore.Update(item)
+		}
+		m.logDebug(job.ID, "Track held, parent is paused", zap.String("parent_id", item.ParentID))
+		return nil
+	}
+
+	// Likewise while download.rate_limit_breaker has its album/playlist cooling down
+	if m.coolingDown(item.ParentID) {
+		if item.Status != "pending" {
+			item.Status = "pending"
+			m.queueStore.Update(item)
+		}
+		m.logDebug(job.ID, "Track held, parent is cooling down after rate limits", zap.String("parent_id", item.ParentID))
+		return nil
+	}
+
+	// Wait for the slow start ramp before hitting Deezer
+	release, err := m.acquireDownloadSlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	// Update status to downloading
 	item.Status = "downloading"
 	item.Progress = 0
@@ -244,10 +436,7 @@ func (m *Manager) downloadTrackJob(ctx context.Context, job *Job) error {
 		return fmt.Errorf("failed to update queue item: %w", err)
 	}
 
-	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		fmt.Fprintf(logFile, "[%s] Track status updated to downloading\n", time.Now().Format("2006-01-02 15:04:05"))
-		logFile.Close()
-	}
+	m.logDebug(job.ID, "Track status updated to downloading")
 
 	// Notify started
 	if m.notifier != nil {
@@ -256,9 +445,19 @@ func (m *Manager) downloadTrackJob(ctx context.Context, job *Job) error {
 
 	// Get track details
 	track, err := m.deezerAPI.GetTrack(ctx, job.TrackID)
+	if errors.Is(err, api.ErrNotFound) && m.config.Download.SearchFallback {
+		track, err = m.searchFallbackTrack(ctx, job, item)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to get track details: %w", err)
 	}
+	if track.Album == nil {
+		track.Album = standaloneAlbum(track)
+		if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+			fmt.Fprintf(logFile, "[%s] Track %s has no album, using its title as the album\n", time.Now().Format("2006-01-02 15:04:05"), job.TrackID)
+			logFile.Close()
+		}
+	}
 
 	// Update queue item with track metadata (if it was created without metadata)
 	if item.Title == "" {
@@ -270,10 +469,7 @@ func (m *Manager) downloadTrackJob(ctx context.Context, job *Job) error {
 
 	// Check if this track is part of an album or playlist download (has ParentID)
 	if item.ParentID != "" {
-		if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-			fmt.Fprintf(logFile, "[%s] Track has ParentID: %s\n", time.Now().Format("2006-01-02 15:04:05"), item.ParentID)
-			logFile.Close()
-		}
+		m.logDebug(job.ID, "Track has a parent", zap.String("parent_id", item.ParentID))
 		
 		// Get parent item to determine if it's an album or playlist
 		parentItem, err := m.queueStore.GetByID(item.ParentID)
@@ -366,6 +562,7 @@ func (m *Manager) downloadTrackJob(ctx context.Context, job *Job) error {
 				// This is part of an album download
 				// Check the cache to see if this album is multi-disc
 				albumID := track.Album.ID.String()
+				cacheAlbumDiscography(albumID, parentItem.Discography)
 		
 		// Check cache first
 		multiDiscCacheMu.RLock()
@@ -431,93 +628,70 @@ func (m *Manager) downloadTrackJob(ctx context.Context, job *Job) error {
 		track.IsMultiDiscAlbum = false
 		track.TotalDiscs = 0
 		
-		if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-			fmt.Fprintf(logFile, "[%s] Single track download, IsMultiDiscAlbum=false\n", time.Now().Format("2006-01-02 15:04:05"))
-			logFile.Close()
-		}
+		m.logDebug(job.ID, "Single track download")
 	}
 
-	// Get download URL
-	if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-		fmt.Fprintf(logFile, "[%s] Requesting download URL: trackID=%s, quality=%s\n", 
-			time.Now().Format("2006-01-02 15:04:05"), job.TrackID, m.config.Download.Quality)
-		logFile.Close()
-	}
+	// Get download URL, in the quality set for this item if SetItemQuality changed it
+	quality, targetDir := m.itemSettings(item)
+	m.logDebug(job.ID, "Requesting download URL", zap.String("track_id", job.TrackID), zap.String("quality", quality))
 	
-	downloadURLInfo, err := m.deezerAPI.GetTrackDownloadURL(ctx, job.TrackID, m.config.Download.Quality)
+	downloadURLInfo, err := m.deezerAPI.GetTrackDownloadURL(ctx, job.TrackID, quality)
 	if err != nil {
-		if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-			fmt.Fprintf(logFile, "[%s] ERROR getting download URL: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
-			logFile.Close()
-		}
+		m.logError(job.ID, "Failed to get download URL", zap.Error(err))
 		return fmt.Errorf("failed to get download URL: %w", err)
 	}
 
-	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		fmt.Fprintf(logFile, "[%s] Got download URL: quality=%s, format=%s, starting download...\n", 
-			time.Now().Format("2006-01-02 15:04:05"), downloadURLInfo.Quality, downloadURLInfo.Format)
-		logFile.Close()
+	m.logDebug(job.ID, "Got download URL, starting download", zap.String("quality", downloadURLInfo.Quality), zap.String("format", downloadURLInfo.Format))
+
+	if item.ParentID == "" {
+		m.cacheSingleTrackSharedArtist(ctx, job.ID, track)
 	}
+	m.resolveAlbumArtist(track, item.ParentID == "")
 
-	// Determine album artist for folder structure
-	// ALWAYS prefer album-level artist over track artist to keep all tracks in one folder
-	// This prevents splitting albums when individual tracks have different artists
-	track.AlbumArtist = track.Artist.Name // Default fallback
-	
-	// For playlist downloads, use "Various Artists"
+	// Album total for the TRCK "n/total" tag; single tracks only know it when GetTrack includes nb_tracks
 	if track.Playlist != nil {
-		track.AlbumArtist = "Various Artists"
+		track.TotalTracks = track.Playlist.TrackCount
 	} else if track.Album != nil {
-		// First, check if we have a cached album artist from the album download job
-		// This ensures ALL tracks in an album use the same artist folder
-		albumID := fmt.Sprintf("%v", track.Album.ID)
-		if cachedArtist, ok := getCachedAlbumArtist(albumID); ok {
-			track.AlbumArtist = cachedArtist
-			if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-				fmt.Fprintf(logFile, "[%s] Using cached album artist for album %s: %s\n", 
-					time.Now().Format("2006-01-02 15:04:05"), albumID, cachedArtist)
-				logFile.Close()
-			}
-		} else if track.Album.RecordType != "single" && track.Album.RecordType != "ep" &&
-		   (track.Album.RecordType == "compilation" || 
-		    strings.Contains(strings.ToLower(track.Album.Title), "soundtrack") ||
-		    strings.Contains(strings.ToLower(track.Album.Title), "original score") ||
-		    strings.Contains(strings.ToLower(track.Album.Title), "original motion picture")) {
-			// For compilations and soundtracks, use "Various Artists"
-			track.AlbumArtist = "Various Artists"
-			
-			if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-				fmt.Fprintf(logFile, "[%s] Compilation/Soundtrack detected for folder structure: Album='%s', RecordType='%s', using AlbumArtist=Various Artists\n", 
-					time.Now().Format("2006-01-02 15:04:05"), track.Album.Title, track.Album.RecordType)
-				logFile.Close()
+		track.TotalTracks = track.Album.TrackCount
+		if cachedCount, ok := getCachedAlbumTrackCount(fmt.Sprintf("%v", track.Album.ID)); ok {
+			track.TotalTracks = cachedCount
+		}
+		// The record type decides whether a single goes in the artist's Singles folder
+		if track.Album.RecordType == "" {
+			if recordType, ok := getCachedAlbumRecordType(track.Album.ID.String()); ok {
+				track.Album.RecordType = recordType
+			} else if m.config.Download.GroupArtistSingles && isDiscographyAlbum(track.Album.ID.String()) {
+				// Not cached when the album job ran in an earlier session; GetTrack's album lacks it
+				if album, err := m.deezerAPI.GetAlbum(ctx, track.Album.ID.String()); err == nil {
+					track.Album.RecordType = album.RecordType
+					cacheAlbumRecordType(track.Album.ID.String(), album.RecordType)
+				} else {
+					m.logWarn(job.ID, "Failed to look up the album's record type", zap.Error(err))
+				}
 			}
-		} else if track.Album.Artist != nil && track.Album.Artist.Name != "" {
-			// Use album-level artist from track's album object
-			track.AlbumArtist = track.Album.Artist.Name
 		}
 	}
 
 	// Build output path
-	outputPath := m.buildOutputPath(track, downloadURLInfo.Format)
+	outputPath := m.itemOutputPath(track, downloadURLInfo.Format, targetDir)
+
+	// Don't let a different track that sanitizes to the same name overwrite this one
+	outputPath, err = m.resolveCollision(outputPath, track)
+	if err != nil {
+		return err
+	}
 
 	// Check if file already exists (resume functionality)
 	if fileInfo, err := os.Stat(outputPath); err == nil {
 		// File exists - check if it's complete by comparing size
 		if fileInfo.Size() > 0 {
-			if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-				fmt.Fprintf(logFile, "[%s] File already exists (%d bytes), skipping download and applying metadata\n", 
-					time.Now().Format("2006-01-02 15:04:05"), fileInfo.Size())
-				logFile.Close()
-			}
+			m.logInfo(job.ID, "File already exists, skipping download and applying metadata", zap.Int64("size", fileInfo.Size()))
 			
 			// File exists, just apply metadata and mark as completed
 			// Apply metadata synchronously since we're not downloading
-			metadataErr := m.applyMetadataTags(ctx, outputPath, track)
+			metadataErr := m.applyMetadataTagsWithRetry(ctx, outputPath, track, true)
 			if metadataErr != nil {
-				if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-					fmt.Fprintf(logFile, "[%s] Failed to apply metadata tags: %v\n", time.Now().Format("2006-01-02 15:04:05"), metadataErr)
-					logFile.Close()
-				}
+				m.logWarn(job.ID, "Failed to apply metadata tags", zap.Error(metadataErr))
 				// Don't mark as completed if metadata failed
 				item.Status = "failed"
 				item.ErrorMessage = fmt.Sprintf("Metadata error: %v", metadataErr)
@@ -527,9 +701,14 @@ func (m *Manager) downloadTrackJob(ctx context.Context, job *Job) error {
 				return metadataErr
 			}
 			
+			// Backfill cover/artist image sidecars a previous run didn't save
+			if m.sidecarArtworkWanted() && m.config.Download.ExistingFileArtwork {
+				m.queueTrackArtwork(ctx, track, filepath.Dir(outputPath))
+			}
+			
 			// Download lyrics if enabled
-			if m.config.Lyrics.Enabled && m.config.Lyrics.SaveSyncedFile {
-				if err := m.downloadAndSaveLyrics(ctx, outputPath, track); err != nil {
+			if m.lyricsWanted() {
+				if err := m.downloadAndSaveLyrics(ctx, outputPath, track.ID.String()); err != nil {
 					if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
 						fmt.Fprintf(logFile, "[%s] Failed to download lyrics: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
 						logFile.Close()
@@ -548,10 +727,7 @@ func (m *Manager) downloadTrackJob(ctx context.Context, job *Job) error {
 				return fmt.Errorf("failed to update queue item: %w", err)
 			}
 			
-			if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-				fmt.Fprintf(logFile, "[%s] Track marked as completed: %s\n", time.Now().Format("2006-01-02 15:04:05"), item.ID)
-				logFile.Close()
-			}
+			m.logInfo(item.ID, "Track marked as completed")
 			
 			// Update parent progress
 			if item.ParentID != "" {
@@ -563,15 +739,17 @@ func (m *Manager) downloadTrackJob(ctx context.Context, job *Job) error {
 				m.notifier.NotifyCompleted(job.ID)
 			}
 			
-			if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-				fmt.Fprintf(logFile, "[%s] Track resumed and completed successfully\n", time.Now().Format("2006-01-02 15:04:05"))
-				logFile.Close()
-			}
+			m.logInfo(job.ID, "Track resumed and completed")
 			
 			return nil
 		}
 	}
 
+	// download.deduplicate_mode: the same recording may already be on disk from another album or playlist
+	if done, err := m.completeDuplicate(job, item, track, outputPath, quality); done || err != nil {
+		return err
+	}
+
 	// Progress callback
 	lastProgress := -1
 	lastUpdateTime := time.Now()
@@ -617,14 +795,37 @@ func (m *Manager) downloadTrackJob(ctx context.Context, job *Job) error {
 		"User-Agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
 	}
 
+	// With a staging folder the track is downloaded and tagged there and only moved into
+	// the library once complete, so media servers never index a half-written file
+	stagedPath := m.stagingPath(outputPath)
+
+	// The media is encrypted with the song ID its track token was issued for
+	keyID := downloadURLInfo.KeyID
+	if keyID == "" {
+		keyID = job.TrackID
+	} else if keyID != job.TrackID {
+		m.logInfo(job.ID, "Deezer served another version of the track", zap.String("song_id", keyID))
+	}
+
+	m.throughput.begin()
 	result, err := m.processor.DownloadAndDecrypt(
 		downloadURLInfo.URL,
-		job.TrackID,
-		outputPath,
+		keyID,
+		stagedPath,
 		progressCallback,
 		headers,
-		m.config.Network.Timeout,
+		m.config.Network.DownloadTimeout,
+		m.config.Network.ConnectionsPerDL,
+		m.keepEncryptedDir(),
 	)
+	if result != nil && result.EncryptedPath != "" {
+		m.logError(job.ID, "Track failed to decrypt, encrypted file kept", zap.String("encrypted_path", result.EncryptedPath))
+	}
+	if err == nil && result.Success {
+		m.throughput.end(result.FileSize)
+	} else {
+		m.throughput.end(0)
+	}
 
 	if err != nil {
 		return fmt.Errorf("download failed: %w", err)
@@ -634,147 +835,90 @@ func (m *Manager) downloadTrackJob(ctx context.Context, job *Job) error {
 		return fmt.Errorf("download failed: %s", result.ErrorMessage)
 	}
 
-	// Download artwork if enabled
-	if m.config.Download.EmbedArtwork {
-		trackDir := filepath.Dir(outputPath)
-		
-		if track.Playlist != nil {
-			// Playlist download - download playlist cover
-			if err := m.downloadPlaylistArtwork(ctx, track.Playlist, trackDir); err != nil {
-				// Log error but don't fail the download
-				if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-					fmt.Fprintf(logFile, "[%s] Failed to download playlist artwork: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
-					logFile.Close()
-				}
-			}
-			// No artist image for playlists
-		} else {
-			// Album download - download album artwork
-			if err := m.downloadAlbumArtwork(ctx, track.Album, trackDir); err != nil {
-				// Log error but don't fail the download
-				fmt.Printf("Failed to download album artwork: %v\n", err)
-			}
-			
-			// Download artist image (to artist folder) - but NOT for compilations/soundtracks
-			// Now with extensive logging to identify crash location
-			if track.AlbumArtist != "Various Artists" {
-				// trackDir is the directory containing the track file
-				// For multi-disc albums: Artist\Album\CD X\ -> go up 2 levels to Artist
-				// For single-disc albums: Artist\Album\ -> go up 1 level to Artist
-				var artistDir string
-				if track.IsMultiDiscAlbum {
-					// Multi-disc: trackDir is "Artist\Album\CD X", go up 2 levels
-					albumDir := filepath.Dir(trackDir)  // Up to Album folder
-					artistDir = filepath.Dir(albumDir)  // Up to Artist folder
-				} else {
-					// Single-disc: trackDir is "Artist\Album", go up 1 level
-					artistDir = filepath.Dir(trackDir)  // Up to Artist folder
-				}
-				
-				if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-					fmt.Fprintf(logFile, "[%s] [ARTIST_IMG] Track download complete, attempting artist image for: %s\n", time.Now().Format("2006-01-02 15:04:05"), track.AlbumArtist)
-					logFile.Close()
-				}
-				
-				// Get artist ID - prefer album artist, fallback to track artist
-				var artistID api.FlexibleID
-				var artistName string
-				var hasArtist bool
-				
-				if track.Album != nil && track.Album.Artist != nil {
-					artistID = track.Album.Artist.ID
-					artistName = track.AlbumArtist
-					hasArtist = true
-					if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-						fmt.Fprintf(logFile, "[%s] [ARTIST_IMG] Using album artist ID: %v\n", time.Now().Format("2006-01-02 15:04:05"), artistID)
-						logFile.Close()
-					}
-				} else if track.Artist != nil {
-					artistID = track.Artist.ID
-					artistName = track.AlbumArtist
-					hasArtist = true
-					if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-						fmt.Fprintf(logFile, "[%s] [ARTIST_IMG] Using track artist ID: %v\n", time.Now().Format("2006-01-02 15:04:05"), artistID)
-						logFile.Close()
-					}
-				} else {
-					if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-						fmt.Fprintf(logFile, "[%s] [ARTIST_IMG] ERROR: No artist ID available for %s\n", time.Now().Format("2006-01-02 15:04:05"), track.AlbumArtist)
-						logFile.Close()
-					}
-				}
-				
-				if hasArtist {
-					albumArtist := &api.Artist{
-						ID:   artistID,
-						Name: artistName,
-					}
-					
-					if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-						fmt.Fprintf(logFile, "[%s] [ARTIST_IMG] Calling downloadArtistImage for %s\n", time.Now().Format("2006-01-02 15:04:05"), artistName)
-						logFile.Close()
-					}
-					
-					if err := m.downloadArtistImage(ctx, albumArtist, artistDir); err != nil {
-						// Log error but don't fail the download
-						if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-							fmt.Fprintf(logFile, "[%s] [ARTIST_IMG] Failed to download artist image for %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), artistName, err)
-							logFile.Close()
-						}
-					}
-				}
+	// Deezer occasionally serves a lower bitrate than the MP3 quality it reports,
+	// so read the real bitrate from the decrypted frame headers
+	var bitrate int
+	var qualityWarning string
+	if downloadURLInfo.Format == "mp3" {
+		bitrate, qualityWarning = checkMP3Bitrate(stagedPath, downloadURLInfo.Quality)
+		if qualityWarning != "" {
+			m.logWarn(job.ID, qualityWarning)
+			if m.config.Download.StrictQuality {
+				// Drop the file so the retry downloads it again instead of treating it as existing
+				os.Remove(stagedPath)
+				return fmt.Errorf("%w (%d kbps)", ErrLowQuality, bitrate)
 			}
 		}
 	}
 
-	// Apply metadata tags with panic recovery (in background to not slow down queue)
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				fmt.Printf("Panic in metadata tagging: %v\n", r)
-				if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-					fmt.Fprintf(logFile, "[%s] PANIC in metadata tagging: %v\n", time.Now().Format("2006-01-02 15:04:05"), r)
-					logFile.Close()
-				}
-			}
-		}()
-		
-		// Small delay to ensure file is fully written and closed
-		time.Sleep(100 * time.Millisecond)
-		
-		if err := m.applyMetadataTags(ctx, outputPath, track); err != nil {
-			// Silently fail - metadata is not critical
-			if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-				fmt.Fprintf(logFile, "[%s] Failed to apply metadata tags: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
-				logFile.Close()
-			}
-		}
-	}()
+	// Save cover.jpg / artist image sidecars if enabled; embedding is decided in applyMetadataTags
+	if m.sidecarArtworkWanted() {
+		m.queueTrackArtwork(ctx, track, filepath.Dir(outputPath))
+	}
 
-	// Download and save lyrics with panic recovery (in background to not slow down queue)
-	if m.config.Lyrics.Enabled && m.config.Lyrics.SaveSyncedFile {
-		go func() {
+	// Apply metadata tags with panic recovery (in background to not slow down queue).
+	// With the "none" tag profile there is nothing to write, so the goroutine only runs for lyrics.
+	// A staged track is tagged in place instead: it has to be moved into the library, complete,
+	// before the item can be marked completed.
+	tagFile := m.tagProfile() != "none"
+	staged := stagedPath != outputPath
+	if tagFile || m.lyricsWanted() {
+		// The file is complete, so tagging outlives the job's context (pause/cancel/shutdown)
+		tagCtx := context.WithoutCancel(ctx)
+		finishFile := func() {
 			defer func() {
 				if r := recover(); r != nil {
-					fmt.Printf("Panic in lyrics download: %v\n", r)
+					fmt.Printf("Panic in metadata tagging: %v\n", r)
+					m.logError(job.ID, "Panic in metadata tagging", zap.Any("panic", r))
+				}
+			}()
+		
+			// Small delay to ensure file is fully written and closed
+			time.Sleep(100 * time.Millisecond)
+		
+			if tagFile {
+				// The download still counts as completed; the flag lets the user find untagged files
+				tagErr := m.applyMetadataTagsWithRetry(tagCtx, stagedPath, track, false)
+				if tagErr != nil {
+					m.logWarn(item.ID, "Failed to apply metadata tags, marking untagged", zap.Error(tagErr))
+				}
+				if err := m.queueStore.SetTagged(item.ID, tagErr == nil); err != nil {
 					if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-						fmt.Fprintf(logFile, "[%s] PANIC in lyrics download: %v\n", time.Now().Format("2006-01-02 15:04:05"), r)
+						fmt.Fprintf(logFile, "[%s] %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
 						logFile.Close()
 					}
 				}
-			}()
-			
-			// Small delay to ensure file is fully written
-			time.Sleep(100 * time.Millisecond)
-			
-			if err := m.downloadAndSaveLyrics(ctx, outputPath, track); err != nil {
-				// Silently fail - lyrics are not critical
-				if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-					fmt.Fprintf(logFile, "[%s] Failed to download lyrics: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
-					logFile.Close()
+			}
+		
+			// Lyrics run after tagging so embedded lyrics frames aren't written concurrently with the tags
+			if m.lyricsWanted() {
+				if err := m.downloadAndSaveLyrics(tagCtx, stagedPath, track.ID.String()); err != nil {
+					// Silently fail - lyrics are not critical
+					if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
+						fmt.Fprintf(logFile, "[%s] Failed to download lyrics: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
+						logFile.Close()
+					}
 				}
 			}
-		}()
+		}
+		if staged {
+			finishFile()
+		} else {
+			m.backgroundWG.Add(1)
+			go func() {
+				defer m.backgroundWG.Done()
+				finishFile()
+			}()
+		}
+	}
+	if staged {
+		// The item fails rather than completing without a file in the library
+		if err := m.moveStagedTrack(stagedPath, outputPath); err != nil {
+			return err
+		}
+		if item.ParentID == "" {
+			m.pruneStaging(stagedPath)
+		}
 	}
 
 	// Update queue item
@@ -790,25 +934,26 @@ func (m *Manager) downloadTrackJob(ctx context.Context, job *Job) error {
 	// If this track belongs to an album/playlist, update the parent's completed count
 	if item.ParentID != "" {
 		// Log before updating parent progress
-		if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-			fmt.Fprintf(logFile, "[%s] Track %s completed, updating parent %s progress\n", time.Now().Format("2006-01-02 15:04:05"), item.ID, item.ParentID)
-			logFile.Close()
-		}
+		m.logInfo(item.ID, "Track completed", zap.String("parent_id", item.ParentID))
 		m.updateParentProgress(item.ParentID)
 	}
 
 	// Add to history
-	if err := m.queueStore.AddToHistory(
+	if err := m.queueStore.AddToHistoryWithBitrate(
 		job.TrackID,
 		track.Title,
 		track.Artist.Name,
 		track.Album.Title,
 		outputPath,
-		m.config.Download.Quality,
+		quality,
 		result.FileSize,
+		bitrate,
+		qualityWarning,
 	); err != nil {
 		// Log error but don't fail the download
 		fmt.Printf("Failed to add to history: %v\n", err)
+	} else if track.ISRC != "" {
+		m.queueStore.SetHistoryISRC(outputPath, track.ISRC)
 	}
 
 	// Notify completed
@@ -821,33 +966,36 @@ func (m *Manager) downloadTrackJob(ctx context.Context, job *Job) error {
 
 // downloadAlbumJob downloads all tracks in an album
 func (m *Manager) downloadAlbumJob(ctx context.Context, job *Job) error {
-	// No mutex needed anymore - we eliminated database contention by removing batch inserts
-	// Album jobs now just submit track jobs directly without database writes
+	// Album jobs submit track jobs directly without database writes; the album slot
+	// staggers albums so their lookups and submissions don't all land at once
+	release, err := m.acquireAlbumSlot(ctx)
+	if err != nil {
+		return err
+	}
+	// Released by the submitting goroutine once it takes over
+	submitting := false
+	defer func() {
+		if !submitting {
+			release()
+		}
+	}()
 	
 	// Log to temp file
-	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		fmt.Fprintf(logFile, "[%s] downloadAlbumJob started for album %s\n", time.Now().Format("2006-01-02 15:04:05"), job.AlbumID)
-		logFile.Close()
-	}
+	m.logInfo(job.ID, "Album job started", zap.String("album_id", job.AlbumID))
 
 	// Mark album as downloading to prevent reprocessing
 	if albumItem, err := m.queueStore.GetByID(job.ID); err == nil && albumItem != nil {
+		cacheAlbumDiscography(job.AlbumID, albumItem.Discography)
 		albumItem.Status = "downloading"
 		if err := m.queueStore.Update(albumItem); err != nil {
-			if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-				fmt.Fprintf(logFile, "[%s] WARNING: Failed to update album status to downloading: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
-				logFile.Close()
-			}
+			m.logWarn(job.ID, "Failed to update album status to downloading", zap.Error(err))
 		}
 	}
 
 	// Get album details
 	album, err := m.deezerAPI.GetAlbum(ctx, job.AlbumID)
 	if err != nil {
-		if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-			fmt.Fprintf(logFile, "[%s] ERROR getting album details: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
-			logFile.Close()
-		}
+		m.logError(job.ID, "Failed to get album details", zap.Error(err))
 		return fmt.Errorf("failed to get album details: %w", err)
 	}
 	
@@ -858,8 +1006,15 @@ func (m *Manager) downloadAlbumJob(ctx context.Context, job *Job) error {
 	// Check if this is a compilation or soundtrack
 	isCompilation := false
 	
-	// Method 1: Check RecordType
-	if album.RecordType == "compilation" {
+	// Method 0: A user override always wins over detection
+	if forced, ok := m.compilationOverride(job.AlbumID); ok {
+		isCompilation = forced
+		if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+			fmt.Fprintf(logFile, "[%s] Album %s compilation override from config: %v\n", time.Now().Format("2006-01-02 15:04:05"), job.AlbumID, forced)
+			logFile.Close()
+		}
+	} else if album.RecordType == "compilation" {
+		// Method 1: Check RecordType
 		isCompilation = true
 		if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
 			fmt.Fprintf(logFile, "[%s] Album %s is a compilation (RecordType=%s)\n", time.Now().Format("2006-01-02 15:04:05"), job.AlbumID, album.RecordType)
@@ -868,11 +1023,8 @@ func (m *Manager) downloadAlbumJob(ctx context.Context, job *Job) error {
 	}
 	
 	// Method 2: If RecordType is blank/empty, check for soundtrack keywords AND multiple artists
-	if !isCompilation && (album.RecordType == "" || album.RecordType == "album") {
-		albumTitleLower := strings.ToLower(album.Title)
-		hasSoundtrackKeyword := strings.Contains(albumTitleLower, "soundtrack") ||
-		                        strings.Contains(albumTitleLower, "original score") ||
-		                        strings.Contains(albumTitleLower, "original motion picture")
+	if _, overridden := m.compilationOverride(job.AlbumID); !overridden && !isCompilation && (album.RecordType == "" || album.RecordType == "album") {
+		hasSoundtrackKeyword := m.hasCompilationKeyword(album.Title)
 		
 		// Check if album has multiple artists (contributors)
 		hasMultipleArtists := len(album.Contributors) > 1
@@ -887,9 +1039,22 @@ func (m *Manager) downloadAlbumJob(ctx context.Context, job *Job) error {
 		}
 	}
 	
+	// Method 3: Deezer sometimes credits a single-artist album to its label or to Various Artists;
+	// when every track shares one artist, file the album under that artist instead
+	var sharedArtist *api.Artist
+	if _, overridden := m.compilationOverride(job.AlbumID); !overridden {
+		sharedArtist = m.sharedAlbumArtist(album)
+	}
+	if sharedArtist != nil {
+		m.logInfo(job.ID, "All tracks share one artist, using it as the album artist", zap.String("artist", sharedArtist.Name), zap.Bool("was_compilation", isCompilation))
+		isCompilation = false
+	}
+	
 	// Set album artist based on compilation status
 	if isCompilation {
-		albumArtistName = "Various Artists"
+		albumArtistName = m.variousArtistsName()
+	} else if sharedArtist != nil {
+		albumArtistName = sharedArtist.Name
 	} else if album.Artist != nil && album.Artist.Name != "" {
 		albumArtistName = album.Artist.Name
 	}
@@ -905,9 +1070,16 @@ func (m *Manager) downloadAlbumJob(ctx context.Context, job *Job) error {
 	}
 
 	totalTracks := len(album.Tracks.Data)
-	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		fmt.Fprintf(logFile, "[%s] Album has %d tracks\n", time.Now().Format("2006-01-02 15:04:05"), totalTracks)
-		logFile.Close()
+	m.logDebug(job.ID, "Album tracks listed", zap.Int("tracks", totalTracks))
+
+	// Cache the track total next to the album artist so every track is tagged "n/total"
+	if album.TrackCount > 0 {
+		cacheAlbumTrackCount(job.AlbumID, album.TrackCount)
+	} else if totalTracks > 0 {
+		cacheAlbumTrackCount(job.AlbumID, totalTracks)
+	}
+	if album.RecordType != "" {
+		cacheAlbumRecordType(job.AlbumID, album.RecordType)
 	}
 
 	// Detect if this is a multi-disc album
@@ -925,10 +1097,10 @@ func (m *Manager) downloadAlbumJob(ctx context.Context, job *Job) error {
 		}
 	}
 	
-	// Method 3: If still not detected as multi-disc OR if we need to find total disc count,
-	// fetch sample tracks to check. This is necessary because album API often doesn't include disc numbers
-	// Check tracks from beginning, middle, and end to find disc 2+ tracks and determine total discs
-	if len(album.Tracks.Data) > 0 && (totalDiscs == 0 || !isMultiDisc) {
+	// Method 3: Fetch sample tracks, since the album API often doesn't include disc numbers.
+	// Check tracks from beginning, middle, and end to find disc 2+ tracks and determine total discs;
+	// download.multi_disc_detection decides when that's worth the extra requests
+	if m.sampleDiscNumbers(album) {
 		totalTracks := len(album.Tracks.Data)
 		
 		// Sample tracks to check: first, middle, last, and a few in between
@@ -955,7 +1127,10 @@ func (m *Manager) downloadAlbumJob(ctx context.Context, job *Job) error {
 			logFile.Close()
 		}
 		
-		for _, idx := range indicesToCheck {
+		// Sampling makes a request per track, so let the UI know the album is being looked at
+		discovery := m.newDiscoveryReporter(job.ID, len(indicesToCheck))
+		for n, idx := range indicesToCheck {
+			discovery.report(n)
 			if idx >= totalTracks {
 				continue
 			}
@@ -992,6 +1167,7 @@ func (m *Manager) downloadAlbumJob(ctx context.Context, job *Job) error {
 				// Don't break - continue checking to find the maximum disc number
 			}
 		}
+		discovery.report(len(indicesToCheck))
 	}
 	
 	// Ensure totalDiscs is at least 1 for single-disc albums, and at least 2 for multi-disc
@@ -1030,6 +1206,24 @@ func (m *Manager) downloadAlbumJob(ctx context.Context, job *Job) error {
 		// Note: We keep the original disc number for metadata even in single-disc albums
 	}
 
+	// Create every artist/album/disc folder now rather than lazily per track
+	if m.config.Download.PrecreateFolders {
+		if err := m.createFolders(m.albumFolders(album, album.Tracks.Data)); err != nil {
+			if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
+				fmt.Fprintf(logFile, "[%s] Failed to pre-create folders for album %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), albumID, err)
+				logFile.Close()
+			}
+		}
+	}
+
+	// Save the cover now so it doesn't depend on the album's first track succeeding
+	if m.config.Download.SaveAlbumCover && m.config.Download.AlbumCoverUpfront {
+		m.queueAlbumCovers(ctx, album)
+	}
+	if m.config.Download.SaveBooklet {
+		m.queueAlbumCoverSet(ctx, album)
+	}
+
 	// Update album item with total tracks
 	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
 		fmt.Fprintf(logFile, "[%s] Trying to update album item %s with %d total tracks\n", time.Now().Format("2006-01-02 15:04:05"), job.ID, totalTracks)
@@ -1054,6 +1248,7 @@ func (m *Manager) downloadAlbumJob(ctx context.Context, job *Job) error {
 			TotalTracks:     totalTracks,
 			CompletedTracks: 0,
 		}
+		albumItem.SetMetadata(newAlbumDiscLayout(album.Tracks.Data))
 		
 		if addErr := m.queueStore.Add(albumItem); addErr != nil {
 			if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
@@ -1067,10 +1262,11 @@ func (m *Manager) downloadAlbumJob(ctx context.Context, job *Job) error {
 			}
 		}
 	} else {
-		// Album item exists - update it
+		// Album item exists - update it, keeping progress from tracks finished before a restart
 		albumItem.TotalTracks = totalTracks
-		albumItem.CompletedTracks = 0
+		albumItem.CompletedTracks = m.queueStore.CountCompletedChildren(job.ID)
 		albumItem.Status = "downloading"
+		albumItem.SetMetadata(newAlbumDiscLayout(album.Tracks.Data))
 		if updateErr := m.queueStore.Update(albumItem); updateErr != nil {
 			if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
 				fmt.Fprintf(logFile, "[%s] ERROR: Failed to update album item %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), job.ID, updateErr)
@@ -1085,17 +1281,18 @@ func (m *Manager) downloadAlbumJob(ctx context.Context, job *Job) error {
 	}
 
 	// Create jobs for each track
-	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		fmt.Fprintf(logFile, "[%s] Starting track submission loop for %d tracks\n", time.Now().Format("2006-01-02 15:04:05"), len(album.Tracks.Data))
-		logFile.Close()
-	}
+	m.logDebug(job.ID, "Submitting album tracks", zap.Int("tracks", len(album.Tracks.Data)))
+	
+	// Submit in disc/track order (by default) so files appear in sequence
+	tracks := orderedAlbumTracks(album, m.config.Download.AlbumTrackOrder)
 	
 	// Submit track jobs directly without database insert
 	// The database insert will happen when the track actually starts downloading
 	// This eliminates database contention from album job processing
 	// Submit all tracks asynchronously without waiting
 	// This prevents blocking when the worker pool is full
-	for _, track := range album.Tracks.Data {
+	var trackJobs []*Job
+	for _, track := range tracks {
 		// Check if cancelled
 		select {
 		case <-ctx.Done():
@@ -1107,76 +1304,149 @@ func (m *Manager) downloadAlbumJob(ctx context.Context, job *Job) error {
 		
 		// Skip if already active in worker pool
 		if m.workerPool.IsJobActive(trackID) {
-			if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-				fmt.Fprintf(logFile, "[%s] Track %s already active in worker pool, skipping\n", time.Now().Format("2006-01-02 15:04:05"), trackID)
-				logFile.Close()
-			}
+			m.logDebug(trackID, "Track already active in worker pool, skipping")
 			continue
 		}
 		
-		trackJob := &Job{
+		trackJobs = append(trackJobs, &Job{
 			ID:      trackID,
 			Type:    JobTypeTrack,
 			TrackID: track.ID.String(),
-		}
+		})
+	}
 
-		// Submit asynchronously in a goroutine to avoid blocking
-		// The worker pool will handle the job when a worker becomes available
-		go func(job *Job, tid string) {
-			// Try to submit with a timeout
-			submitCtx, submitCancel := context.WithTimeout(ctx, 10*time.Second)
-			defer submitCancel()
-			
+	// Submit from a single goroutine so the worker pool receives tracks in disc/track order
+	// The worker pool will handle each job when a worker becomes available
+	submitting = true
+	go func(jobs []*Job) {
+		defer release()
+		for _, job := range jobs {
 			select {
-			case <-submitCtx.Done():
-				// Timeout or context cancelled
+			case <-ctx.Done():
+				// Context cancelled
+				m.logInfo(job.ID, "Album cancelled, not submitting remaining tracks")
+				return
+			default:
+			}
+			
+			if err := m.workerPool.Submit(job); err != nil {
 				if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-					fmt.Fprintf(logFile, "[%s] Timeout submitting track %s, will retry later\n", time.Now().Format("2006-01-02 15:04:05"), tid)
+					fmt.Fprintf(logFile, "[%s] Failed to submit track %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), job.ID, err)
 					logFile.Close()
 				}
-			default:
-				// Try to submit
-				if err := m.workerPool.Submit(job); err != nil {
-					if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-						fmt.Fprintf(logFile, "[%s] Failed to submit track %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), tid, err)
-						logFile.Close()
-					}
-				}
 			}
-		}(trackJob, trackID)
-	}
+		}
+	}(trackJobs)
 
 	// Don't mark album as completed yet - it will be marked completed when all tracks finish
 	// The updateParentProgress function will handle this
-	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		fmt.Fprintf(logFile, "[%s] Album job completed - tracks will be processed by workers\n", time.Now().Format("2006-01-02 15:04:05"))
-		logFile.Close()
+	m.logDebug(job.ID, "Album tracks submitted to workers")
+
+	m.logInfo(job.ID, "Album job completed")
+
+	return nil
+}
+
+// sampleDiscNumbers decides whether downloadAlbumJob fetches sample tracks to find disc
+// numbers, per download.multi_disc_detection: "off" never does and trusts nb_disk, "api"
+// always does, and "auto" only when nb_disk says there are several discs or the disc
+// numbers are ambiguous - missing with no nb_disk, or disagreeing with it
+func (m *Manager) sampleDiscNumbers(album *api.Album) bool {
+	if album.Tracks == nil || len(album.Tracks.Data) == 0 {
+		return false
+	}
+	switch m.config.Download.MultiDiscDetection {
+	case "off":
+		return false
+	case "api":
+		return true
 	}
 
-	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		fmt.Fprintf(logFile, "[%s] downloadAlbumJob completed successfully\n", time.Now().Format("2006-01-02 15:04:05"))
-		logFile.Close()
+	if album.DiscCount > 1 {
+		return true
+	}
+	for _, track := range album.Tracks.Data {
+		if (track.DiscNumber == 0 && album.DiscCount == 0) || track.DiscNumber > 1 {
+			return true
+		}
 	}
+	return false
+}
 
-	return nil
+// orderedAlbumTracks returns a copy of an album's tracks in download.album_track_order. The
+// album may be GetAlbum's cached copy, which other jobs read, so it is never reordered in place.
+func orderedAlbumTracks(album *api.Album, order string) []*api.Track {
+	tracks := append([]*api.Track(nil), album.Tracks.Data...)
+	orderAlbumTracks(tracks, order)
+	return tracks
+}
+
+// orderAlbumTracks puts album tracks in download.album_track_order: "as_is" keeps Deezer's
+// order, "reverse" queues the last disc and track first, and anything else sorts by disc
+// then track number
+func orderAlbumTracks(tracks []*api.Track, order string) {
+	switch order {
+	case "as_is":
+	case "reverse":
+		sortAlbumTracks(tracks)
+		for i, j := 0, len(tracks)-1; i < j; i, j = i+1, j-1 {
+			tracks[i], tracks[j] = tracks[j], tracks[i]
+		}
+	default:
+		sortAlbumTracks(tracks)
+	}
+}
+
+// sortAlbumTracks stable-sorts album tracks by disc number then track number.
+// Without disc numbers, per-disc track numbers repeat across discs, so the API order
+// (which is already disc-sequential) is kept in that case.
+func sortAlbumTracks(tracks []*api.Track) {
+	discsKnown := true
+	seenNumbers := make(map[int]bool, len(tracks))
+	duplicateNumbers := false
+	for _, track := range tracks {
+		if track.DiscNumber == 0 {
+			discsKnown = false
+		}
+		if seenNumbers[track.GetTrackNumber()] {
+			duplicateNumbers = true
+		}
+		seenNumbers[track.GetTrackNumber()] = true
+	}
+	if !discsKnown && duplicateNumbers {
+		return
+	}
+
+	disc := func(t *api.Track) int {
+		if t.DiscNumber == 0 {
+			return 1
+		}
+		return t.DiscNumber
+	}
+	sort.SliceStable(tracks, func(i, j int) bool {
+		if disc(tracks[i]) != disc(tracks[j]) {
+			return disc(tracks[i]) < disc(tracks[j])
+		}
+		return tracks[i].GetTrackNumber() < tracks[j].GetTrackNumber()
+	})
 }
 
 // downloadPlaylistJob downloads all tracks in a playlist
 func (m *Manager) downloadPlaylistJob(ctx context.Context, job *Job) error {
-	// Log to temp file
-	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		fmt.Fprintf(logFile, "[%s] downloadPlaylistJob started for playlist %s (custom: %v)\n", time.Now().Format("2006-01-02 15:04:05"), job.PlaylistID, job.IsCustom)
-		logFile.Close()
+	release, slotErr := m.acquireAlbumSlot(ctx)
+	if slotErr != nil {
+		return slotErr
 	}
+	defer release()
+
+	// Log to temp file
+	m.logInfo(job.ID, "Playlist job started", zap.String("playlist_id", job.PlaylistID), zap.Bool("custom", job.IsCustom))
 
 	// Mark playlist as downloading to prevent reprocessing
 	if playlistItem, err := m.queueStore.GetByID(job.ID); err == nil && playlistItem != nil {
 		playlistItem.Status = "downloading"
 		if err := m.queueStore.Update(playlistItem); err != nil {
-			if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-				fmt.Fprintf(logFile, "[%s] WARNING: Failed to update playlist status to downloading: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
-				logFile.Close()
-			}
+			m.logWarn(job.ID, "Failed to update playlist status to downloading", zap.Error(err))
 		}
 	}
 
@@ -1227,10 +1497,7 @@ func (m *Manager) downloadPlaylistJob(ctx context.Context, job *Job) error {
 		// Get playlist details from Deezer
 		playlist, err := m.deezerAPI.GetPlaylist(ctx, job.PlaylistID)
 		if err != nil {
-			if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-				fmt.Fprintf(logFile, "[%s] ERROR getting playlist details: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
-				logFile.Close()
-			}
+			m.logError(job.ID, "Failed to get playlist details", zap.Error(err))
 			return fmt.Errorf("failed to get playlist details: %w", err)
 		}
 		
@@ -1239,17 +1506,18 @@ func (m *Manager) downloadPlaylistJob(ctx context.Context, job *Job) error {
 		}
 	}
 
+	// A track listed twice maps to the same child item and file, so only queue it once
+	listed := len(trackIDs)
+	trackIDs = dedupeTrackIDs(trackIDs)
+
 	totalTracks := len(trackIDs)
-	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		fmt.Fprintf(logFile, "[%s] Playlist has %d tracks\n", time.Now().Format("2006-01-02 15:04:05"), totalTracks)
-		logFile.Close()
-	}
+	m.logDebug(job.ID, "Playlist tracks listed", zap.Int("tracks", totalTracks), zap.Int("duplicates_dropped", listed-totalTracks))
 
 	// Update playlist item with total tracks
 	playlistItem, err := m.queueStore.GetByID(job.ID)
 	if err == nil && playlistItem != nil {
 		playlistItem.TotalTracks = totalTracks
-		playlistItem.CompletedTracks = 0
+		playlistItem.CompletedTracks = m.queueStore.CountCompletedChildren(job.ID)
 		if updateErr := m.queueStore.Update(playlistItem); updateErr != nil {
 			if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
 				fmt.Fprintf(logFile, "[%s] ERROR: Failed to update playlist item %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), job.ID, updateErr)
@@ -1258,6 +1526,9 @@ func (m *Manager) downloadPlaylistJob(ctx context.Context, job *Job) error {
 		}
 	}
 
+	// Each new track is looked up before it's queued, which takes a while on large playlists
+	discovery := m.newDiscoveryReporter(job.ID, totalTracks)
+
 	// Create jobs for each track
 	for i, trackIDStr := range trackIDs {
 		// Check if cancelled
@@ -1266,6 +1537,7 @@ func (m *Manager) downloadPlaylistJob(ctx context.Context, job *Job) error {
 			return ctx.Err()
 		default:
 		}
+		discovery.report(i)
 
 		queueTrackID := fmt.Sprintf("track_%s_%s", job.PlaylistID, trackIDStr)
 
@@ -1274,10 +1546,7 @@ func (m *Manager) downloadPlaylistJob(ctx context.Context, job *Job) error {
 		if err == nil && existingTrack != nil {
 			// Track exists - check if it needs to be reprocessed
 			if existingTrack.Status == "completed" {
-				if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-					fmt.Fprintf(logFile, "[%s] Track %d already completed, skipping\n", time.Now().Format("2006-01-02 15:04:05"), i)
-					logFile.Close()
-				}
+				m.logDebug(job.ID, "Playlist track already completed, skipping", zap.Int("position", i))
 				continue
 			}
 			
@@ -1348,122 +1617,185 @@ func (m *Manager) downloadPlaylistJob(ctx context.Context, job *Job) error {
 			continue
 		}
 		
-		if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-			fmt.Fprintf(logFile, "[%s] New track %d submitted: %s\n", time.Now().Format("2006-01-02 15:04:05"), i, trackItem.ID)
-			logFile.Close()
-		}
+		m.logDebug(trackItem.ID, "Playlist track submitted", zap.Int("position", i))
 	}
 
-	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		fmt.Fprintf(logFile, "[%s] downloadPlaylistJob completed successfully\n", time.Now().Format("2006-01-02 15:04:05"))
-		logFile.Close()
-	}
+	discovery.report(totalTracks)
+
+	m.logInfo(job.ID, "Playlist job completed")
 
 	return nil
 }
 
-// processResults processes job results from the worker pool
-func (m *Manager) processResults() {
-	for result := range m.workerPool.Results() {
-		if !result.Success && result.Error != nil {
-			// Get queue item
-			item, err := m.queueStore.GetByID(result.JobID)
-			if err != nil {
-				continue
+// processResults records job results from one run of the worker pool, until its results
+// channel is closed or stop is
+func (m *Manager) processResults(results <-chan *Result, stop <-chan struct{}) {
+	for {
+		var result *Result
+		select {
+		case r, ok := <-results:
+			if !ok {
+				return
 			}
+			result = r
+		case <-stop:
+			return
+		}
 
-			// Increment retry count FIRST, then check if we should retry
-			item.RetryCount++
-			
-			// Check if we should retry (retry count must be LESS THAN OR EQUAL to max retries)
-			// Example: MaxRetries=3 means we try once + 3 retries = 4 total attempts
-			// So we retry when RetryCount is 1, 2, 3 (not 4+)
-			shouldRetry := item.RetryCount <= m.config.Network.MaxRetries
-			
-			if shouldRetry {
-				// Update status to failed temporarily (will be reset to pending on retry)
-				item.Status = "failed"
-				item.ErrorMessage = result.Error.Error()
-				m.queueStore.Update(item)
-				
-				// Log retry attempt
-				if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-					fmt.Fprintf(logFile, "[%s] Track %s failed (attempt %d/%d), will retry: %v\n", 
-						time.Now().Format("2006-01-02 15:04:05"), item.ID, item.RetryCount, m.config.Network.MaxRetries, result.Error)
-					logFile.Close()
-				}
+		// A worker is free again - fill it without waiting for the next tick
+		m.triggerDispatch()
 
-				// Extract track ID from item ID (format: track_ALBUMID_TRACKID or just TRACKID)
-				trackID := item.ID
-				if strings.HasPrefix(item.ID, "track_") {
-					parts := strings.Split(item.ID, "_")
-					if len(parts) >= 3 {
-						trackID = parts[2] // Extract actual track ID
-					} else if len(parts) == 2 {
-						trackID = parts[1]
-					}
-				}
-				
-				// Create retry job
-				job := &Job{
-					ID:         item.ID,
-					Type:       JobType(item.Type),
-					TrackID:    trackID,
-					AlbumID:    strings.TrimPrefix(item.ParentID, "album_"),
-					PlaylistID: strings.TrimPrefix(item.ParentID, "playlist_"),
-					RetryCount: item.RetryCount,
-				}
+		if result.Success {
+			m.recordTrackResult(result.JobID)
+		} else if result.Error != nil {
+			m.handleFailedResult(result)
+		}
 
-				// Submit with exponential backoff delay
-				go func(j *Job, retryNum int) {
-					delay := time.Duration(retryNum) * 2 * time.Second
-					if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-						fmt.Fprintf(logFile, "[%s] Scheduling retry for %s in %v\n", 
-							time.Now().Format("2006-01-02 15:04:05"), j.ID, delay)
-						logFile.Close()
-					}
-					time.Sleep(delay)
-					m.workerPool.Submit(j)
-				}(job, item.RetryCount)
-			} else {
-				// Max retries exceeded - mark as permanently failed
-				item.Status = "failed"
-				item.ErrorMessage = result.Error.Error()
-				m.queueStore.Update(item)
+		m.checkQueueIdle()
+	}
+}
 
-				// Notify failed
-				if m.notifier != nil {
-					m.notifier.NotifyFailed(result.JobID, result.Error)
-				}
-				
-				// Record failed track and update parent progress
-				if item.ParentID != "" {
-					if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-						fmt.Fprintf(logFile, "[%s] Track %s PERMANENTLY FAILED after %d attempts (max: %d), recording failure for parent %s\n", 
-							time.Now().Format("2006-01-02 15:04:05"), item.ID, item.RetryCount, m.config.Network.MaxRetries, item.ParentID)
-						logFile.Close()
-					}
-					
-					// Record the failed track with details
-					if err := m.queueStore.AddFailedTrack(
-						item.ParentID,
-						item.ID,
-						item.Title,
-						item.Artist,
-						item.ErrorMessage,
-						item.RetryCount,
-					); err != nil {
-						if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
-							fmt.Fprintf(logFile, "[%s] Failed to record failed track: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
-							logFile.Close()
-						}
-					}
-					
-					m.updateParentProgress(item.ParentID)
-				}
+// handleFailedResult retries a failed track job or marks it permanently failed
+func (m *Manager) handleFailedResult(result *Result) {
+	// Get queue item
+	item, err := m.queueStore.GetByID(result.JobID)
+	if err != nil {
+		return
+	}
+
+	// Jobs stopped by CancelDownload end with an error, but nothing failed
+	if m.cancelledByUser(item) {
+		return
+	}
+
+	// Deezer is throttling us - cut back before the retries add more requests
+	if errors.Is(result.Error, api.ErrRateLimited) {
+		m.throttled(item.ID)
+	}
+
+	// Album/playlist jobs fail while fetching their details (e.g. geo-blocked album),
+	// so there is no track to retry - fail the parent itself. A cancelled job keeps
+	// whatever status Pause/Cancel gave it.
+	if item.Type == string(JobTypeAlbum) || item.Type == string(JobTypePlaylist) {
+		if !errors.Is(result.Error, context.Canceled) {
+			m.failParent(item, result.Error)
+		}
+		return
+	}
+
+	m.recordBreakerResult(item.ParentID, result.Error, errors.Is(result.Error, api.ErrRateLimited))
+
+	// Increment retry count FIRST, then check if we should retry
+	item.RetryCount++
+	
+	// Check if we should retry (retry count must be LESS THAN OR EQUAL to max retries)
+	// Example: MaxRetries=3 means we try once + 3 retries = 4 total attempts
+	// So we retry when RetryCount is 1, 2, 3 (not 4+)
+	// Geo-blocked tracks never succeed, so skip the retries and their backoff
+	geoBlocked := errors.Is(result.Error, api.ErrGeoBlocked)
+	// Nor do IDs Deezer has no data for (after download.search_fallback found no replacement)
+	notFound := errors.Is(result.Error, api.ErrNotFound)
+	// download.strict_quality retries a low-bitrate file once; ErrorMessage still holds the previous attempt's error
+	lowQualityAgain := errors.Is(result.Error, ErrLowQuality) && strings.Contains(item.ErrorMessage, LowQualityReason)
+	shouldRetry := !geoBlocked && !notFound && !lowQualityAgain && item.RetryCount <= m.config.Network.MaxRetries
+	
+	if shouldRetry {
+		// Update status to failed temporarily (will be reset to pending on retry)
+		item.Status = "failed"
+		item.ErrorMessage = result.Error.Error()
+		m.queueStore.Update(item)
+		
+		// Log retry attempt
+		m.logWarn(item.ID, "Track failed, will retry", zap.Int("attempt", item.RetryCount), zap.Int("max_retries", m.config.Network.MaxRetries), zap.Error(result.Error))
+
+		// Extract track ID from item ID (format: track_ALBUMID_TRACKID or just TRACKID)
+		trackID := item.ID
+		if strings.HasPrefix(item.ID, "track_") {
+			parts := strings.Split(item.ID, "_")
+			if len(parts) >= 3 {
+				trackID = parts[2] // Extract actual track ID
+			} else if len(parts) == 2 {
+				trackID = parts[1]
+			}
+		}
+		
+		// Create retry job
+		job := &Job{
+			ID:         item.ID,
+			Type:       JobType(item.Type),
+			TrackID:    trackID,
+			AlbumID:    strings.TrimPrefix(item.ParentID, "album_"),
+			PlaylistID: strings.TrimPrefix(item.ParentID, "playlist_"),
+			RetryCount: item.RetryCount,
+		}
+
+		// Submit with exponential backoff delay
+		m.completion.retryScheduled()
+		go func(j *Job, retryNum int) {
+			defer m.completion.retrySubmitted()
+			delay := time.Duration(retryNum) * 2 * time.Second
+			m.logDebug(j.ID, "Scheduling retry", zap.Duration("delay", delay))
+			time.Sleep(delay)
+			m.workerPool.Submit(j)
+		}(job, item.RetryCount)
+	} else {
+		// Max retries exceeded - mark as permanently failed
+		item.Status = "failed"
+		item.ErrorMessage = result.Error.Error()
+		if geoBlocked {
+			item.ErrorMessage = api.ErrGeoBlocked.Error()
+		} else if notFound {
+			item.ErrorMessage = api.ErrNotFound.Error()
+		}
+		m.queueStore.Update(item)
+
+		// Notify failed
+		if m.notifier != nil {
+			m.notifier.NotifyFailed(result.JobID, result.Error)
+		}
+		m.completion.record(item.Type, false)
+		
+		// Record failed track and update parent progress
+		if item.ParentID != "" {
+			m.logError(item.ID, "Track permanently failed", zap.Int("attempts", item.RetryCount), zap.Int("max_retries", m.config.Network.MaxRetries), zap.String("parent_id", item.ParentID))
+			
+			// Record the failed track with details
+			if err := m.queueStore.AddFailedTrack(
+				item.ParentID,
+				item.ID,
+				item.Title,
+				item.Artist,
+				item.ErrorMessage,
+				item.RetryCount,
+			); err != nil {
+				m.logWarn(item.ID, "Failed to record failed track", zap.Error(err))
 			}
+			
+			m.updateParentProgress(item.ParentID)
+		}
+	}
+}
+
+// failParent marks an album or playlist whose own job failed as permanently failed
+func (m *Manager) failParent(item *store.QueueItem, jobErr error) {
+	item.RetryCount++
+	item.Status = "failed"
+	item.ErrorMessage = jobErr.Error()
+	if err := m.queueStore.Update(item); err != nil {
+		if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
+			fmt.Fprintf(logFile, "[%s] Failed to mark %s %s as failed: %v\n", time.Now().Format("2006-01-02 15:04:05"), item.Type, item.ID, err)
+			logFile.Close()
 		}
 	}
+
+	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+		fmt.Fprintf(logFile, "[%s] %s %s FAILED: %v\n", time.Now().Format("2006-01-02 15:04:05"), item.Type, item.ID, jobErr)
+		logFile.Close()
+	}
+
+	if m.notifier != nil {
+		m.notifier.NotifyFailed(item.ID, jobErr)
+	}
 }
 
 // processQueue continuously processes pending queue items
@@ -1476,7 +1808,8 @@ func (m *Manager) processQueue(ctx context.Context) {
 	}
 	
 	fmt.Fprintf(os.Stderr, "[INFO] processQueue goroutine started\n")
-	ticker := time.NewTicker(5 * time.Second)
+	interval := m.dispatchInterval()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -1492,15 +1825,57 @@ func (m *Manager) processQueue(ctx context.Context) {
 				fmt.Fprintf(logFile, "[%s] processQueue TICK - checking for pending items\n", time.Now().Format("2006-01-02 15:04:05"))
 			}
 			fmt.Fprintf(os.Stderr, "[DEBUG] processQueue tick - checking for pending items\n")
+			// Pick up a changed download.dispatch_interval
+			if next := m.dispatchInterval(); next != interval {
+				interval = next
+				ticker.Reset(interval)
+			}
+			if !m.dispatchAllowed() || !m.diskSpaceAllowed() {
+				continue
+			}
+			m.processPendingItems()
+		case <-m.dispatchNow:
+			if logFile != nil {
+				fmt.Fprintf(logFile, "[%s] processQueue WAKE - a job finished, checking for pending items\n", time.Now().Format("2006-01-02 15:04:05"))
+			}
+			if !m.dispatchAllowed() || !m.diskSpaceAllowed() {
+				continue
+			}
 			m.processPendingItems()
 		}
 	}
 }
 
+// triggerDispatch asks processQueue to look for pending items now. Requests made while
+// one is already waiting are merged, so a burst of finished jobs causes a single dispatch.
+func (m *Manager) triggerDispatch() {
+	select {
+	case m.dispatchNow <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchInterval returns how often processQueue checks for pending items
+func (m *Manager) dispatchInterval() time.Duration {
+	if m.config.Download.DispatchInterval < 1 {
+		return 5 * time.Second
+	}
+	return time.Duration(m.config.Download.DispatchInterval) * time.Second
+}
+
+// pendingFetchLimit returns how many pending items processPendingItems considers at once
+func (m *Manager) pendingFetchLimit() int {
+	multiplier := m.config.Download.PendingMultiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	return m.config.Download.ConcurrentDownloads * multiplier
+}
+
 // processPendingItems processes pending items in the queue
 func (m *Manager) processPendingItems() {
 	// Get pending items - only get a few to process in order
-	items, err := m.queueStore.GetPending(m.config.Download.ConcurrentDownloads * 2)
+	items, err := m.queueStore.GetPending(m.pendingFetchLimit())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "[ERROR] Failed to get pending items: %v\n", err)
 		// Also log to temp file
@@ -1571,13 +1946,19 @@ func (m *Manager) processPendingItems() {
 			continue
 		}
 
-		// Check if paused
-		if m.isJobPaused(item.ID) {
+		// Check if paused (directly or through its album/playlist)
+		if m.isJobPaused(item.ID) || (item.ParentID != "" && m.isJobPaused(item.ParentID)) {
 			if logFile != nil {
 				fmt.Fprintf(logFile, "[%s]   Skipping %s - paused\n", time.Now().Format("2006-01-02 15:04:05"), item.ID)
 			}
 			continue
 		}
+		if m.coolingDown(item.ParentID) {
+			if logFile != nil {
+				fmt.Fprintf(logFile, "[%s]   Skipping %s - parent cooling down after rate limits\n", time.Now().Format("2006-01-02 15:04:05"), item.ID)
+			}
+			continue
+		}
 		
 		// For albums/playlists, enforce sequential downloading with smart concurrency
 		if item.Type == "album" || item.Type == "playlist" {
@@ -1666,6 +2047,11 @@ func (m *Manager) processPendingItems() {
 			continue
 		}
 		
+		// A queue starting from idle ramps its downloads up again (download.slow_start_seconds)
+		if m.completion.begin() {
+			m.slowStart.restart()
+		}
+		
 		if logFile != nil {
 			fmt.Fprintf(logFile, "[%s]   Job %s submitted successfully\n", time.Now().Format("2006-01-02 15:04:05"), job.ID)
 		}
@@ -1680,6 +2066,10 @@ func (m *Manager) DownloadTrack(ctx context.Context, trackID string) error {
 		return fmt.Errorf("failed to get track details: %w", err)
 	}
 
+	if m.trackOnDisk(track) {
+		return ErrAlreadyDownloaded
+	}
+
 	// Create queue item
 	item := &store.QueueItem{
 		ID:     fmt.Sprintf("track_%s", trackID),
@@ -1699,6 +2089,16 @@ func (m *Manager) DownloadTrack(ctx context.Context, trackID string) error {
 
 // DownloadAlbum adds an album to the download queue
 func (m *Manager) DownloadAlbum(ctx context.Context, albumID string) error {
+	return m.downloadAlbum(ctx, albumID, false)
+}
+
+// DownloadDiscographyAlbum adds an album to the download queue as part of its artist's
+// discography, where download.group_artist_singles collects singles in Artist/Singles
+func (m *Manager) DownloadDiscographyAlbum(ctx context.Context, albumID string) error {
+	return m.downloadAlbum(ctx, albumID, true)
+}
+
+func (m *Manager) downloadAlbum(ctx context.Context, albumID string, discography bool) error {
 	fmt.Printf("[Manager] DownloadAlbum called with albumID: '%s'\n", albumID)
 	
 	// Get album details
@@ -1716,6 +2116,18 @@ func (m *Manager) DownloadAlbum(ctx context.Context, albumID string) error {
 	
 	// Check if item already exists
 	existingItem, err := m.queueStore.GetByID(itemID)
+	
+	// Nothing to do if every track is already on disk, unless the album is still queued
+	queued := err == nil && existingItem != nil && (existingItem.Status == "pending" || existingItem.Status == "downloading")
+	if !queued {
+		// Look for the tracks where this download would put them
+		cacheAlbumDiscography(albumID, discography)
+	}
+	if !queued && m.albumOnDisk(album) {
+		fmt.Printf("[Manager] All %d tracks of album %s already downloaded\n", len(album.Tracks.Data), albumID)
+		return ErrAlreadyDownloaded
+	}
+	
 	if err == nil && existingItem != nil {
 		fmt.Printf("[Manager] Album already in queue with status: %s\n", existingItem.Status)
 		// If it's pending or downloading, return error to notify user
@@ -1752,6 +2164,9 @@ func (m *Manager) DownloadAlbum(ctx context.Context, albumID string) error {
 			return fmt.Errorf("failed to add to queue: %w", err)
 		}
 	}
+	if err := m.queueStore.SetDiscography(itemID, discography); err != nil {
+		return fmt.Errorf("failed to update queue item: %w", err)
+	}
 
 	// Don't submit job immediately - let processPendingItems handle queue ordering
 	// This ensures albums are downloaded in the order they were added to the queue
@@ -1779,52 +2194,112 @@ func (m *Manager) DownloadCustomPlaylist(ctx context.Context, playlistJSON strin
 	
 	fmt.Printf("[Manager] Custom playlist: %s (%d tracks)\n", customPlaylist.Title, len(customPlaylist.TrackIDs))
 	
-	itemID := fmt.Sprintf("playlist_%s", customPlaylist.ID)
+	if err := m.queueCustomPlaylist(customPlaylist.ID, customPlaylist.Title, customPlaylist.Creator, customPlaylist.Description, customPlaylist.PictureURL, dedupeTrackIDs(customPlaylist.TrackIDs)); err != nil {
+		return err
+	}
 	
-	// Check if item already exists
-	existingItem, err := m.queueStore.GetByID(itemID)
-	if err == nil && existingItem != nil {
-		fmt.Printf("[Manager] Custom playlist already in queue with status: %s\n", existingItem.Status)
-		// If it's pending or downloading, return error to notify user
-		if existingItem.Status == "pending" || existingItem.Status == "downloading" {
-			return fmt.Errorf("playlist already in queue")
-		}
-		// If it's failed or completed, reset it to pending
+	// Don't submit job immediately - let processPendingItems handle queue ordering
+	// This ensures custom playlists are downloaded in the order they were added to the queue
+	fmt.Printf("[Manager] Custom playlist added to queue, will be processed in order: %s\n", customPlaylist.Title)
+	return nil
+}
+
+// DownloadTrackList queues an arbitrary batch of Deezer track IDs under a synthetic custom playlist
+// so the tracks roll up into one progress parent. Duplicate and empty IDs are dropped.
+func (m *Manager) DownloadTrackList(ctx context.Context, ids []string, name string) error {
+	trackIDs := dedupeTrackIDs(ids)
+	if len(trackIDs) == 0 {
+		return fmt.Errorf("no track IDs provided")
 	}
 	
-	// Create queue item
-	queueItem := &store.QueueItem{
+	if strings.TrimSpace(name) == "" {
+		name = fmt.Sprintf("Track List (%d tracks)", len(trackIDs))
+	}
+	
+	playlistID := trackListID(trackIDs)
+	fmt.Printf("[Manager] DownloadTrackList: %s (%d tracks) as playlist_%s\n", name, len(trackIDs), playlistID)
+	
+	if err := m.queueCustomPlaylist(playlistID, name, m.variousArtistsName(), "", "", trackIDs); err != nil {
+		return err
+	}
+	
+	fmt.Printf("[Manager] Track list added to queue, will be processed in order: %s\n", name)
+	return nil
+}
+
+// queueCustomPlaylist adds (or re-queues) a custom playlist parent whose tracks are stored in metadata
+func (m *Manager) queueCustomPlaylist(playlistID, title, creator, description, pictureURL string, trackIDs []string) error {
+	itemID := fmt.Sprintf("playlist_%s", playlistID)
+	
+	// Check if item already exists
+	existingItem, err := m.queueStore.GetByID(itemID)
+	if err == nil && existingItem != nil {
+		fmt.Printf("[Manager] Custom playlist already in queue with status: %s\n", existingItem.Status)
+		// If it's pending or downloading, return error to notify user
+		if existingItem.Status == "pending" || existingItem.Status == "downloading" {
+			return fmt.Errorf("playlist already in queue")
+		}
+	}
+	
+	// Create queue item
+	queueItem := &store.QueueItem{
 		ID:          itemID,
 		Type:        "playlist",
-		Title:       customPlaylist.Title,
-		Artist:      customPlaylist.Creator,
+		Title:       title,
+		Artist:      creator,
 		Status:      "pending",
-		TotalTracks: len(customPlaylist.TrackIDs),
+		TotalTracks: len(trackIDs),
 	}
 	
 	// Store custom playlist data in metadata
 	metadata := map[string]interface{}{
 		"is_custom":     true,
-		"custom_tracks": customPlaylist.TrackIDs,
-		"playlist_id":   customPlaylist.ID,
-		"description":   customPlaylist.Description,
-		"picture_url":   customPlaylist.PictureURL,
+		"custom_tracks": trackIDs,
+		"playlist_id":   playlistID,
+		"description":   description,
+		"picture_url":   pictureURL,
 	}
 	if err := queueItem.SetMetadata(metadata); err != nil {
 		return fmt.Errorf("failed to set metadata: %w", err)
 	}
 	
+	// If it's failed or completed, reset it to pending with the new track list
+	if existingItem != nil {
+		if err := m.queueStore.Update(queueItem); err != nil {
+			return fmt.Errorf("failed to update custom playlist in queue: %w", err)
+		}
+		return nil
+	}
+	
 	// Save to database
 	if err := m.queueStore.Add(queueItem); err != nil {
 		return fmt.Errorf("failed to add custom playlist to queue: %w", err)
 	}
-	
-	// Don't submit job immediately - let processPendingItems handle queue ordering
-	// This ensures custom playlists are downloaded in the order they were added to the queue
-	fmt.Printf("[Manager] Custom playlist added to queue, will be processed in order: %s\n", customPlaylist.Title)
 	return nil
 }
 
+// dedupeTrackIDs trims IDs and drops empty and duplicate entries, keeping the first occurrence
+func dedupeTrackIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
+
+// trackListID derives a stable playlist ID for a track batch so re-submitting the same list is detected.
+// It must not contain underscores since child item IDs are split on them.
+func trackListID(trackIDs []string) string {
+	sum := sha1.Sum([]byte(strings.Join(trackIDs, ",")))
+	return fmt.Sprintf("tracklist-%x", sum[:6])
+}
+
 // DownloadPlaylist adds a playlist to the download queue
 func (m *Manager) DownloadPlaylist(ctx context.Context, playlistID string) error {
 	fmt.Printf("[Manager] DownloadPlaylist called with playlistID: '%s'\n", playlistID)
@@ -1839,6 +2314,16 @@ func (m *Manager) DownloadPlaylist(ctx context.Context, playlistID string) error
 	}
 	fmt.Printf("[Manager] Got playlist: %s by %s (%d tracks) in %v\n", playlist.Title, playlist.Creator.Name, playlist.TrackCount, time.Since(apiStart))
 
+	// Count each track once; the job drops duplicate entries when expanding the playlist
+	totalTracks := playlist.TrackCount
+	if playlist.Tracks != nil && len(playlist.Tracks.Data) > 0 {
+		ids := make([]string, 0, len(playlist.Tracks.Data))
+		for _, track := range playlist.Tracks.Data {
+			ids = append(ids, track.ID.String())
+		}
+		totalTracks = len(dedupeTrackIDs(ids))
+	}
+
 	// Create queue item for playlist
 	itemID := fmt.Sprintf("playlist_%s", playlistID)
 	
@@ -1855,7 +2340,7 @@ func (m *Manager) DownloadPlaylist(ctx context.Context, playlistID string) error
 			existingItem.Status = "pending"
 			existingItem.ErrorMessage = ""
 			existingItem.RetryCount = 0
-			existingItem.TotalTracks = playlist.TrackCount
+			existingItem.TotalTracks = totalTracks
 			existingItem.CompletedTracks = 0
 			if err := m.queueStore.Update(existingItem); err != nil {
 				fmt.Printf("[Manager] Failed to update existing item: %v\n", err)
@@ -1869,10 +2354,10 @@ func (m *Manager) DownloadPlaylist(ctx context.Context, playlistID string) error
 			ID:              itemID,
 			Type:            "playlist",
 			Title:           playlist.Title,
-			Artist:          "Various Artists",
+			Artist:          m.variousArtistsName(),
 			Album:           playlist.Title,
 			Status:          "pending",
-			TotalTracks:     playlist.TrackCount,
+			TotalTracks:     totalTracks,
 			CompletedTracks: 0,
 		}
 
@@ -1889,11 +2374,113 @@ func (m *Manager) DownloadPlaylist(ctx context.Context, playlistID string) error
 	return nil
 }
 
+// SyncPlaylist re-checks a previously downloaded playlist against Deezer and queues only
+// the tracks added since. Tracks dropped from the playlist are removed from the queue, and
+// their files are deleted too when download.mirror_playlist is set. Returns the number of
+// tracks queued.
+func (m *Manager) SyncPlaylist(ctx context.Context, playlistID string) (int, error) {
+	playlist, err := m.deezerAPI.GetPlaylist(ctx, playlistID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get playlist details: %w", err)
+	}
+
+	trackIDs := make([]string, 0, len(playlist.Tracks.Data))
+	for _, track := range playlist.Tracks.Data {
+		trackIDs = append(trackIDs, track.ID.String())
+	}
+	trackIDs = dedupeTrackIDs(trackIDs)
+
+	itemID := fmt.Sprintf("playlist_%s", playlistID)
+	parent, err := m.queueStore.GetByID(itemID)
+	if err != nil || parent == nil {
+		// Never downloaded (or cleared from the queue): fall back to a normal download.
+		// Tracks whose files are already on disk are only re-tagged, not downloaded again.
+		if err := m.DownloadPlaylist(ctx, playlistID); err != nil {
+			return 0, err
+		}
+		return len(trackIDs), nil
+	}
+
+	return m.syncPlaylistTracks(parent, playlistID, trackIDs)
+}
+
+// syncPlaylistTracks diffs an existing playlist item's children against the current
+// tracklist and leaves the parent pending if there is anything new to download
+func (m *Manager) syncPlaylistTracks(parent *store.QueueItem, playlistID string, trackIDs []string) (int, error) {
+	if parent.Status == "pending" || parent.Status == "downloading" {
+		return 0, fmt.Errorf("playlist already in queue")
+	}
+
+	children, err := m.queueStore.GetChildren(parent.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get playlist tracks: %w", err)
+	}
+
+	current := make(map[string]bool, len(trackIDs))
+	for _, id := range trackIDs {
+		current[id] = true
+	}
+
+	childPrefix := fmt.Sprintf("track_%s_", playlistID)
+	completed := 0
+	removed := 0
+	for _, child := range children {
+		if current[strings.TrimPrefix(child.ID, childPrefix)] {
+			if child.Status == "completed" {
+				completed++
+			}
+			continue
+		}
+
+		// Dropped from the playlist since the last download
+		if m.config.Download.MirrorPlaylist && child.OutputPath != "" {
+			if err := os.Remove(child.OutputPath); err != nil && !os.IsNotExist(err) {
+				if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
+					fmt.Fprintf(logFile, "[%s] SyncPlaylist: failed to remove %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), child.OutputPath, err)
+					logFile.Close()
+				}
+			}
+			for _, ext := range lyricsSidecarExts {
+				os.Remove(strings.TrimSuffix(child.OutputPath, filepath.Ext(child.OutputPath)) + ext)
+			}
+		}
+		if err := m.queueStore.Delete(child.ID); err == nil {
+			removed++
+		}
+	}
+
+	added := len(trackIDs) - completed
+
+	parent.TotalTracks = len(trackIDs)
+	parent.CompletedTracks = completed
+	if added > 0 {
+		// downloadPlaylistJob skips children that are already completed
+		parent.Status = "pending"
+		parent.ErrorMessage = ""
+		parent.RetryCount = 0
+		parent.Progress = (completed * 100) / len(trackIDs)
+	} else {
+		parent.Status = "completed"
+		parent.Progress = 100
+	}
+	if err := m.queueStore.Update(parent); err != nil {
+		return 0, fmt.Errorf("failed to update queue item: %w", err)
+	}
+
+	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+		fmt.Fprintf(logFile, "[%s] SyncPlaylist %s: %d tracks, %d already downloaded, %d to download, %d removed\n", time.Now().Format("2006-01-02 15:04:05"), parent.ID, len(trackIDs), completed, added, removed)
+		logFile.Close()
+	}
+
+	return added, nil
+}
+
 // PauseDownload pauses a download
 func (m *Manager) PauseDownload(itemID string) error {
 	m.mu.Lock()
 	m.pausedJobs[itemID] = true
 	m.mu.Unlock()
+	m.savePausedJobs()
 
 	// Cancel the job if it's active
 	if err := m.workerPool.CancelJob(itemID); err != nil {
@@ -1921,6 +2508,7 @@ func (m *Manager) ResumeDownload(itemID string) error {
 	m.mu.Lock()
 	delete(m.pausedJobs, itemID)
 	m.mu.Unlock()
+	m.savePausedJobs()
 
 	// Update queue item status
 	item, err := m.queueStore.GetByID(itemID)
@@ -1928,6 +2516,13 @@ func (m *Manager) ResumeDownload(itemID string) error {
 		return fmt.Errorf("failed to get queue item: %w", err)
 	}
 
+	// A cancelled album or playlist gets its cancelled tracks back
+	if item.Status == "cancelled" {
+		if _, err := m.queueStore.ResetCancelledChildren(itemID); err != nil {
+			return err
+		}
+	}
+
 	if item.Status != "completed" && item.Status != "downloading" {
 		item.Status = "pending"
 		if err := m.queueStore.Update(item); err != nil {
@@ -1938,19 +2533,59 @@ func (m *Manager) ResumeDownload(itemID string) error {
 	return nil
 }
 
-// CancelDownload cancels a download and removes it from the queue
+// CancelDownload stops a download and marks it cancelled, so the queue keeps a record that
+// the user cancelled it rather than it failing. An album or playlist's unfinished tracks are
+// cancelled with it; tracks that already completed are kept.
 func (m *Manager) CancelDownload(itemID string) error {
-	// Cancel the job if it's active
-	if err := m.workerPool.CancelJob(itemID); err != nil {
-		// Job might not be active, that's okay
+	item, err := m.queueStore.GetByID(itemID)
+	if err != nil {
+		return fmt.Errorf("failed to get queue item: %w", err)
 	}
 
-	// Remove from paused jobs
-	m.mu.Lock()
-	delete(m.pausedJobs, itemID)
-	m.mu.Unlock()
+	// The status is set before the jobs are stopped so processResults sees a cancel, not a failure
+	item.Status = "cancelled"
+	item.ErrorMessage = ""
+	item.CompletedAt = nil
+	if err := m.queueStore.Update(item); err != nil {
+		return fmt.Errorf("failed to update queue item: %w", err)
+	}
+	m.stopJob(itemID)
+
+	if item.Type == "album" || item.Type == "playlist" {
+		children, err := m.queueStore.GetChildren(itemID)
+		if err != nil {
+			return fmt.Errorf("failed to get tracks: %w", err)
+		}
+		for _, child := range children {
+			if child.Status == "completed" || child.Status == "cancelled" {
+				continue
+			}
+			child.Status = "cancelled"
+			child.ErrorMessage = ""
+			if err := m.queueStore.Update(child); err != nil {
+				return fmt.Errorf("failed to update track %s: %w", child.ID, err)
+			}
+			m.stopJob(child.ID)
+		}
+	}
+
+	return nil
+}
+
+// RemoveItem stops a download and deletes it from the queue. Albums and playlists are
+// removed together with their tracks.
+func (m *Manager) RemoveItem(itemID string) error {
+	// Tracks of an album/playlist go first so none are left behind without a parent
+	if children, err := m.queueStore.GetChildren(itemID); err == nil {
+		for _, child := range children {
+			m.stopJob(child.ID)
+			m.queueStore.Delete(child.ID)
+		}
+	}
+
+	m.stopJob(itemID)
+	m.parentRates.forget(itemID)
 
-	// Delete from queue
 	if err := m.queueStore.Delete(itemID); err != nil {
 		return fmt.Errorf("failed to delete queue item: %w", err)
 	}
@@ -1958,6 +2593,63 @@ func (m *Manager) CancelDownload(itemID string) error {
 	return nil
 }
 
+// stopJob cancels an item's job if it's running and forgets that it was paused
+func (m *Manager) stopJob(itemID string) {
+	// Job might not be active, that's okay
+	m.workerPool.CancelJob(itemID)
+
+	m.mu.Lock()
+	_, paused := m.pausedJobs[itemID]
+	delete(m.pausedJobs, itemID)
+	m.mu.Unlock()
+	if paused {
+		m.savePausedJobs()
+	}
+}
+
+// cancelledByUser reports whether a track should be left alone because the user cancelled
+// it or its album/playlist
+func (m *Manager) cancelledByUser(item *store.QueueItem) bool {
+	if item.Status == "cancelled" {
+		return true
+	}
+	if item.ParentID == "" {
+		return false
+	}
+	parent, err := m.queueStore.GetByID(item.ParentID)
+	return err == nil && parent.Status == "cancelled"
+}
+
+// CancelByStatus removes every queue entry with the given status, stopping any that are
+// running. Albums and playlists are removed together with their tracks. Returns how many
+// entries were removed.
+func (m *Manager) CancelByStatus(status string) (int, error) {
+	switch status {
+	case "pending", "downloading", "completed", "failed", "cancelled":
+	default:
+		return 0, fmt.Errorf("invalid status: %s", status)
+	}
+
+	ids, err := m.queueStore.GetTopLevelIDsByStatus(status)
+	if err != nil {
+		return 0, err
+	}
+
+	cancelled := 0
+	for _, id := range ids {
+		if err := m.RemoveItem(id); err != nil {
+			if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
+				fmt.Fprintf(logFile, "[%s] CancelByStatus: failed to cancel %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), id, err)
+				logFile.Close()
+			}
+			continue
+		}
+		cancelled++
+	}
+
+	return cancelled, nil
+}
+
 // isJobPaused checks if a job is paused
 func (m *Manager) isJobPaused(jobID string) bool {
 	m.mu.RLock()
@@ -1965,16 +2657,173 @@ func (m *Manager) isJobPaused(jobID string) bool {
 	return m.pausedJobs[jobID]
 }
 
-// buildOutputPath builds the output file path for a track
+// pausedJobsCacheKey is the config cache key used to persist paused item IDs
+const pausedJobsCacheKey = "paused_jobs"
+
+// loadPausedJobs restores paused item IDs saved by a previous session
+func (m *Manager) loadPausedJobs() {
+	if m.queueStore == nil {
+		return
+	}
+	value, err := m.queueStore.GetConfigCache(pausedJobsCacheKey)
+	if err != nil || value == "" {
+		return
+	}
+	var ids []string
+	if err := json.Unmarshal([]byte(value), &ids); err != nil {
+		return
+	}
+	m.mu.Lock()
+	for _, id := range ids {
+		m.pausedJobs[id] = true
+	}
+	m.mu.Unlock()
+}
+
+// savePausedJobs persists the current paused item IDs so they survive a restart
+func (m *Manager) savePausedJobs() {
+	if m.queueStore == nil {
+		return
+	}
+	m.mu.RLock()
+	ids := make([]string, 0, len(m.pausedJobs))
+	for id := range m.pausedJobs {
+		ids = append(ids, id)
+	}
+	m.mu.RUnlock()
+	sort.Strings(ids)
+
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return
+	}
+	if err := m.queueStore.SetConfigCache(pausedJobsCacheKey, string(data)); err != nil {
+		if logFile, logErr := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); logErr == nil {
+			fmt.Fprintf(logFile, "[%s] Failed to persist paused jobs: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
+			logFile.Close()
+		}
+	}
+}
+
+// PausedChild describes a child track of a paused album/playlist
+type PausedChild struct {
+	ID             string `json:"id"`
+	Title          string `json:"title"`
+	Artist         string `json:"artist"`
+	Status         string `json:"status"`
+	Paused         bool   `json:"paused"`           // Held back, either directly or by its parent
+	PausedByParent bool   `json:"paused_by_parent"` // Held back only because the parent is paused
+}
+
+// PausedParent describes a paused album/playlist and the pause state of its children
+type PausedParent struct {
+	ID                string         `json:"id"`
+	Type              string         `json:"type"`
+	Title             string         `json:"title"`
+	Artist            string         `json:"artist"`
+	Status            string         `json:"status"`
+	TotalTracks       int            `json:"total_tracks"`
+	CompletedTracks   int            `json:"completed_tracks"`
+	PausedChildren    int            `json:"paused_children"`
+	AllChildrenPaused bool           `json:"all_children_paused"`
+	Children          []*PausedChild `json:"children"`
+}
+
+// GetPausedDownloads returns the paused albums/playlists with the pause state of each child track
+func (m *Manager) GetPausedDownloads() ([]*PausedParent, error) {
+	m.mu.RLock()
+	ids := make([]string, 0, len(m.pausedJobs))
+	for id := range m.pausedJobs {
+		if strings.HasPrefix(id, "album_") || strings.HasPrefix(id, "playlist_") {
+			ids = append(ids, id)
+		}
+	}
+	m.mu.RUnlock()
+	sort.Strings(ids)
+
+	parents := make([]*PausedParent, 0, len(ids))
+	for _, id := range ids {
+		item, err := m.queueStore.GetByID(id)
+		if err != nil {
+			// Item was removed from the queue - nothing left to report
+			continue
+		}
+
+		children, err := m.queueStore.GetChildren(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get children for %s: %w", id, err)
+		}
+
+		parent := &PausedParent{
+			ID:              item.ID,
+			Type:            item.Type,
+			Title:           item.Title,
+			Artist:          item.Artist,
+			Status:          item.Status,
+			TotalTracks:     item.TotalTracks,
+			CompletedTracks: item.CompletedTracks,
+			Children:        make([]*PausedChild, 0, len(children)),
+		}
+
+		unfinished := 0
+		for _, child := range children {
+			finished := child.Status == "completed" || child.Status == "failed"
+			direct := m.isJobPaused(child.ID)
+			pc := &PausedChild{
+				ID:             child.ID,
+				Title:          child.Title,
+				Artist:         child.Artist,
+				Status:         child.Status,
+				Paused:         !finished && !m.workerPool.IsJobActive(child.ID),
+				PausedByParent: !finished && !direct,
+			}
+			if !finished {
+				unfinished++
+			}
+			if pc.Paused {
+				parent.PausedChildren++
+			} else {
+				pc.PausedByParent = false
+			}
+			parent.Children = append(parent.Children, pc)
+		}
+		parent.AllChildrenPaused = parent.PausedChildren == unfinished
+
+		parents = append(parents, parent)
+	}
+
+	return parents, nil
+}
+
+// buildOutputPath builds the output file path for a track and makes sure its folder exists
 func (m *Manager) buildOutputPath(track *api.Track, format string) string {
+	fullPath := m.resolveOutputPath(track, format)
+	
+	// Ensure directory exists
+	if err := m.ensureDir(filepath.Dir(fullPath)); err != nil {
+		// Fallback to flat structure if directory creation fails
+		safeFilename := fmt.Sprintf("track_%s%s", track.ID, filepath.Ext(fullPath))
+		fullPath = filepath.Join(m.config.Download.OutputDir, safeFilename)
+	}
+	
+	return fullPath
+}
+
+// ensureDir creates dir unless this manager already created or found it, so tracks sharing
+// an album folder don't each pay for MkdirAll (slow on network shares)
+func (m *Manager) ensureDir(dir string) error {
+	return m.knownDirs.Ensure(dir)
+}
+
+// resolveOutputPath computes the output file path for a track without touching the disk
+func (m *Manager) resolveOutputPath(track *api.Track, format string) string {
 	// Sanitize names
 	artist := sanitizeFilename(track.Artist.Name)
-	albumArtist := sanitizeFilename(track.AlbumArtist)
-	if albumArtist == "" {
-		albumArtist = artist // Fallback to track artist if not set
+	albumArtist := artist // Fallback to track artist if not set
+	if track.AlbumArtist != "" {
+		albumArtist = sanitizeFilename(track.AlbumArtist)
 	}
 	album := sanitizeFilename(track.Album.Title)
-	title := sanitizeFilename(track.Title)
 	
 	// Determine file extension from format
 	fileExt := ".mp3" // default
@@ -1996,75 +2845,86 @@ func (m *Manager) buildOutputPath(track *api.Track, format string) string {
 			albumYear = track.Album.ReleaseDate[:4]
 		}
 	}
+
+	expand := m.templateExpander(track)
 	
 	var folderPath string
 	var filename string
 	
 	// Check if this is a playlist download
 	if track.Playlist != nil && m.config.Download.CreatePlaylistFolder {
-		// Playlist download - use "Various Artists/Playlist" folder structure
+		// Playlist download - use "Various Artists/Playlist" (or just "Playlist") folder structure
 		playlistName := sanitizeFilename(track.Playlist.Title)
 		
 		// Use playlist folder template if configured
-		playlistFolderTemplate := m.config.Download.PlaylistFolderTemplate
-		if playlistFolderTemplate == "" {
-			playlistFolderTemplate = "{playlist}"
-		}
+		templates := m.config.Download.Templates()
+		playlistFolderTemplate := templates.PlaylistFolder
 		
 		// Replace placeholders
-		playlistFolder := strings.ReplaceAll(playlistFolderTemplate, "{playlist}", playlistName)
+		playlistFolder := expand(playlistFolderTemplate, m.variousArtistsName(), playlistName)
 		
-		// Always use "Various Artists" as the album artist for playlists
-		folderPath = filepath.Join("Various Artists", playlistFolder)
+		// Playlists go under "Various Artists" unless playlist_flat puts them in the output root
+		folderPath = playlistFolder
+		if !m.config.Download.PlaylistFlat {
+			folderPath = filepath.Join(sanitizeFilename(m.variousArtistsName()), playlistFolder)
+		}
 		
 		// Use playlist track template for filename
-		playlistTrackTemplate := m.config.Download.PlaylistTrackTemplate
-		if playlistTrackTemplate == "" {
-			playlistTrackTemplate = "{playlist_position:02d} - {artist} - {title}"
-		}
+		playlistTrackTemplate := templates.PlaylistTrack
 		
 		// Get album artist (will be "Various Artists" for playlists in metadata)
-		albumArtist := "Various Artists"
+		albumArtist := m.variousArtistsName()
 		
 		// Replace placeholders in filename
-		filename = playlistTrackTemplate
-		filename = strings.ReplaceAll(filename, "{playlist_position:02d}", fmt.Sprintf("%02d", track.PlaylistPosition))
-		filename = strings.ReplaceAll(filename, "{playlist_position}", fmt.Sprintf("%d", track.PlaylistPosition))
-		filename = strings.ReplaceAll(filename, "{artist}", artist)
-		filename = strings.ReplaceAll(filename, "{album_artist}", albumArtist)
-		filename = strings.ReplaceAll(filename, "{title}", title)
-		filename = strings.ReplaceAll(filename, "{album}", album)
-		filename = strings.ReplaceAll(filename, "{playlist}", playlistName)
-		filename = strings.ReplaceAll(filename, "{playlist_name}", playlistName)
-		filename += fileExt
+		filename = expand(playlistTrackTemplate, albumArtist, playlistName) + fileExt
 		
 		if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
 			fmt.Fprintf(logFile, "[%s] Playlist track path: %s (Playlist=%s, Position=%d)\n", 
 				time.Now().Format("2006-01-02 15:04:05"), filepath.Join(folderPath, filename), playlistName, track.PlaylistPosition)
 			logFile.Close()
 		}
+	} else if m.inSinglesFolder(track) {
+		// download.group_artist_singles collects the artist's singles in one folder,
+		// prefixing each file with its release year so they sort by release
+		templates := m.config.Download.Templates()
+		folderPath = filepath.Join(strings.Join(m.artistFolders(track, expand), "/"), singlesFolderName)
+		filename = expand(templates.SingleTrack, albumArtist, "")
+		if albumYear != "" {
+			filename = albumYear + " - " + filename
+		}
+		filename += fileExt
 	} else {
 		// Album or single track download - use album artist/album folder structure
 		// This ensures compilations/soundtracks go to "Various Artists" folder
 		
-		// Check if we need to disambiguate album folders with the same name but different albums
-		albumFolder := m.getDisambiguatedAlbumFolder(albumArtist, album, albumYear, track.Album.ID.String())
-		folderPath = filepath.Join(albumArtist, albumFolder)
+		// The artist and album templates may each span several folder levels
+		templates := m.config.Download.Templates()
+		artistFolder := strings.Join(m.artistFolders(track, expand), "/")
+		albumFolders := templateFolders(expand(templates.AlbumFolder, albumArtist, ""))
+		if len(albumFolders) == 0 {
+			albumFolders = []string{album}
+		}
+		
+		// Check if we need to disambiguate album folders with the same name but different albums;
+		// only the last level is renamed, anything above it belongs with the artist folder
+		albumParent := strings.Join(append([]string{artistFolder}, albumFolders[:len(albumFolders)-1]...), "/")
+		suffixYear := albumYear
+		if strings.Contains(templates.AlbumFolder, "{year}") || strings.Contains(templates.AlbumFolder, "{date}") {
+			// The folder already carries the year, so only the album ID can tell two apart
+			suffixYear = ""
+		}
+		albumFolder := m.getDisambiguatedAlbumFolder(albumParent, albumFolders[len(albumFolders)-1], suffixYear, track.Album.ID.String())
+		folderPath = filepath.Join(albumParent, albumFolder)
 		
 		if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-			fmt.Fprintf(logFile, "[%s] Building folder path: AlbumArtist='%s', Album='%s', AlbumFolder='%s', Year='%s', AlbumID='%s'\n", 
-				time.Now().Format("2006-01-02 15:04:05"), albumArtist, album, albumFolder, albumYear, track.Album.ID.String())
+			fmt.Fprintf(logFile, "[%s] Building folder path: AlbumArtist='%s', Album='%s', ArtistFolder='%s', AlbumFolder='%s', Year='%s', AlbumID='%s'\n", 
+				time.Now().Format("2006-01-02 15:04:05"), albumArtist, album, artistFolder, albumFolder, albumYear, track.Album.ID.String())
 			logFile.Close()
 		}
 		
 		// Add CD folder for multi-disc albums if enabled
 		if m.config.Download.CreateCDFolder && track.IsMultiDiscAlbum && track.DiscNumber > 0 {
-			cdFolderTemplate := m.config.Download.CDFolderTemplate
-			if cdFolderTemplate == "" {
-				cdFolderTemplate = "CD {disc_number}"
-			}
-			
-			cdFolder := strings.ReplaceAll(cdFolderTemplate, "{disc_number}", fmt.Sprintf("%d", track.DiscNumber))
+			cdFolder := expand(templates.CDFolder, albumArtist, "")
 			folderPath = filepath.Join(folderPath, cdFolder)
 			
 			if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
@@ -2076,44 +2936,173 @@ func (m *Manager) buildOutputPath(track *api.Track, format string) string {
 		
 		// Build filename using track number if available
 		if track.TrackNumber > 0 {
-			// Album track format
-			filename = fmt.Sprintf("%02d - %s - %s%s", track.TrackNumber, artist, title, fileExt)
+			filename = expand(m.albumTrackTemplate(track), albumArtist, "") + fileExt
 		} else {
-			// Single track format
-			filename = fmt.Sprintf("%s - %s%s", artist, title, fileExt)
+			filename = expand(templates.SingleTrack, albumArtist, "") + fileExt
 		}
 	}
 	
+	// Keep every folder and file name within the filesystem's per-name byte limit, so long
+	// multi-byte titles don't overflow it and fall through to the flat naming below
+	maxBytes := m.maxFilenameBytes()
+	folderParts := strings.Split(filepath.ToSlash(folderPath), "/")
+	for i, part := range folderParts {
+		folderParts[i] = truncateNameBytes(part, maxBytes)
+	}
+	folderPath = filepath.Join(folderParts...)
+	ext := filepath.Ext(filename)
+	filename = truncateNameBytes(strings.TrimSuffix(filename, ext), maxBytes-len(ext)) + ext
+
 	// Combine base dir, folder structure, and filename
-	fullPath := filepath.Join(m.config.Download.OutputDir, folderPath, filename)
-	
-	// Ensure directory exists
-	dir := filepath.Dir(fullPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		// Fallback to flat structure if directory creation fails
-		safeFilename := fmt.Sprintf("track_%s%s", track.ID, fileExt)
-		fullPath = filepath.Join(m.config.Download.OutputDir, safeFilename)
+	return filepath.Join(m.config.Download.OutputDir, folderPath, filename)
+}
+
+// albumTrackTemplate returns the album track filename template. download.always_include_disc
+// puts the disc number in front of the track number ("1-01 - ..."), single-disc albums
+// included, for media servers that expect it; a template without a track number gets a
+// "disc-track - " prefix instead. Templates that already place {disc_number} are left alone.
+func (m *Manager) albumTrackTemplate(track *api.Track) string {
+	template := m.config.Download.Templates().AlbumTrack
+	if !m.config.Download.AlwaysIncludeDisc || strings.Contains(template, "{disc_number}") {
+		return template
 	}
-	
-	return fullPath
+
+	disc := track.DiscNumber
+	if disc < 1 {
+		disc = 1
+	}
+	if strings.Contains(template, "{track_number") {
+		return strings.Replace(template, "{track_number", fmt.Sprintf("%d-{track_number", disc), 1)
+	}
+	return fmt.Sprintf("%d-{track_number:02d} - %s", disc, template)
+}
+
+// singlesFolderName is the folder under the artist that download.group_artist_singles
+// collects singles in
+const singlesFolderName = "Singles"
+
+// inSinglesFolder reports whether a track from a single goes in its artist's shared Singles
+// folder rather than a folder of its own. Only singles queued as part of a discography are
+// moved there; compilations and playlists never are.
+func (m *Manager) inSinglesFolder(track *api.Track) bool {
+	return m.config.Download.GroupArtistSingles && track.Playlist == nil && track.Album != nil &&
+		track.Album.RecordType == "single" && isDiscographyAlbum(track.Album.ID.String()) &&
+		!track.IsCompilation && track.AlbumArtist != m.variousArtistsName()
+}
+
+// templateExpander returns a function filling in a folder or file template for the track.
+// Values are sanitized before they're substituted, so only a "/" written in the template
+// itself starts a new folder level.
+func (m *Manager) templateExpander(track *api.Track) func(template, albumArtist, playlistName string) string {
+	artist := sanitizeFilename(track.Artist.Name)
+	albumArtist := artist
+	if track.AlbumArtist != "" {
+		albumArtist = sanitizeFilename(track.AlbumArtist)
+	}
+	album := sanitizeFilename(track.Album.Title)
+	title := sanitizeFilename(track.Title)
+
+	// Placeholders shared by every template; {year} and {date} are empty when Deezer has no date
+	templateYear := ""
+	if year := extractYear(track.Album.ReleaseDate); year > 0 {
+		templateYear = fmt.Sprintf("%04d", year)
+	}
+	templateDate := fullReleaseDate(track.Album.ReleaseDate)
+	if templateDate == "" {
+		templateDate = templateYear
+	}
+	return func(template string, albumArtistName, playlistName string) string {
+		if albumArtistName == "" {
+			albumArtistName = albumArtist
+		}
+		result := strings.NewReplacer(
+			"{playlist_position:02d}", fmt.Sprintf("%02d", track.PlaylistPosition),
+			"{playlist_position}", fmt.Sprintf("%d", track.PlaylistPosition),
+			"{track_number:02d}", fmt.Sprintf("%02d", track.TrackNumber),
+			"{track_number}", fmt.Sprintf("%d", track.TrackNumber),
+			"{disc_number}", fmt.Sprintf("%d", track.DiscNumber),
+			"{artist}", artist,
+			"{album_artist}", albumArtistName,
+			"{title}", title,
+			"{album}", album,
+			"{playlist}", playlistName,
+			"{playlist_name}", playlistName,
+			"{year}", templateYear,
+			"{date}", templateDate,
+		).Replace(template)
+		if templateYear == "" {
+			// Tidy each folder level on its own, so "{year}/{album}" doesn't leave a stray separator
+			parts := strings.Split(result, "/")
+			for i, part := range parts {
+				parts[i] = tidyDatelessName(part)
+			}
+			result = strings.Join(parts, "/")
+		}
+		return result
+	}
+}
+
+// artistFolders expands the artist folder template into its folder levels, falling back to
+// the album artist when the template leaves nothing. {artist} names the album artist here as
+// well, so a compilation or an album with featured artists stays in one folder.
+func (m *Manager) artistFolders(track *api.Track, expand func(template, albumArtist, playlistName string) string) []string {
+	template := strings.ReplaceAll(m.config.Download.Templates().ArtistFolder, "{artist}", "{album_artist}")
+	folders := templateFolders(expand(template, "", ""))
+	if len(folders) == 0 {
+		albumArtist := track.AlbumArtist
+		if albumArtist == "" {
+			albumArtist = track.Artist.Name
+		}
+		folders = []string{sanitizeFilename(albumArtist)}
+	}
+	return folders
+}
+
+// artistFolderPath returns the artist folder an album track is written under, relative to
+// the output folder, e.g. for the artist's folder.jpg
+func (m *Manager) artistFolderPath(track *api.Track) string {
+	folders := m.artistFolders(track, m.templateExpander(track))
+	maxBytes := m.maxFilenameBytes()
+	for i, folder := range folders {
+		folders[i] = truncateNameBytes(folder, maxBytes)
+	}
+	return filepath.Join(folders...)
+}
+
+// templateFolders splits an expanded folder template on "/" (or "\") into folder names,
+// sanitizing each one and dropping levels that came out empty
+func templateFolders(expanded string) []string {
+	var folders []string
+	for _, part := range strings.Split(strings.ReplaceAll(expanded, "\\", "/"), "/") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		folders = append(folders, sanitizeFilename(part))
+	}
+	return folders
 }
 
-// Cache for album folder disambiguation - maps "artistFolder/albumFolder" -> albumID
+// Cache for album folder disambiguation - maps "artistFolder/albumFolder" -> albumID, where
+// artistFolder is everything above the album folder and may span several levels
 var albumFolderCache = make(map[string]string)
 var albumFolderCacheMu sync.RWMutex
 
 // getDisambiguatedAlbumFolder returns the album folder name, adding year if needed to avoid conflicts
 // This prevents albums with the same name but different release years from mixing tracks
 func (m *Manager) getDisambiguatedAlbumFolder(artistFolder, albumName, albumYear, albumID string) string {
-	// First, check if we've already determined the folder for this album ID
-	albumFolderCacheMu.RLock()
+	// Tracks without an album have nothing to tell apart or remember
+	if albumID == "" {
+		return albumName
+	}
+	
+	// First, check if we've already determined the folder for this album ID
+	albumFolderCacheMu.RLock()
 	for folderKey, cachedAlbumID := range albumFolderCache {
 		if cachedAlbumID == albumID {
 			// We've already assigned a folder to this album, extract and return the album part
-			parts := strings.SplitN(folderKey, "/", 2)
-			if len(parts) == 2 {
+			if i := strings.LastIndex(folderKey, "/"); i >= 0 {
 				albumFolderCacheMu.RUnlock()
-				return parts[1]
+				return folderKey[i+1:]
 			}
 		}
 	}
@@ -2211,6 +3200,9 @@ var multiDiscCacheMu sync.RWMutex
 
 // Cache for album artists to ensure consistent folder structure
 var albumArtistCache = make(map[string]string) // albumID -> artist name
+var albumTrackCountCache = make(map[string]int) // albumID -> total tracks, guarded by albumArtistCacheMu
+var albumRecordTypeCache = make(map[string]string) // albumID -> record type, guarded by albumArtistCacheMu
+var albumDiscographyCache = make(map[string]bool) // albumID -> queued from a discography, guarded by albumArtistCacheMu
 var albumArtistCacheMu sync.RWMutex
 
 // cacheAlbumArtist stores the album artist for an album
@@ -2228,6 +3220,189 @@ func getCachedAlbumArtist(albumID string) (string, bool) {
 	return artist, ok
 }
 
+// cacheAlbumTrackCount stores an album's total track count for the TRCK "n/total" tag
+func cacheAlbumTrackCount(albumID string, count int) {
+	albumArtistCacheMu.Lock()
+	defer albumArtistCacheMu.Unlock()
+	albumTrackCountCache[albumID] = count
+}
+
+// getCachedAlbumTrackCount retrieves the cached total track count
+func getCachedAlbumTrackCount(albumID string) (int, bool) {
+	albumArtistCacheMu.RLock()
+	defer albumArtistCacheMu.RUnlock()
+	count, ok := albumTrackCountCache[albumID]
+	return count, ok
+}
+
+// cacheAlbumRecordType stores an album's record type, which GetTrack's album doesn't include
+func cacheAlbumRecordType(albumID, recordType string) {
+	albumArtistCacheMu.Lock()
+	defer albumArtistCacheMu.Unlock()
+	albumRecordTypeCache[albumID] = recordType
+}
+
+// getCachedAlbumRecordType retrieves the cached record type
+func getCachedAlbumRecordType(albumID string) (string, bool) {
+	albumArtistCacheMu.RLock()
+	defer albumArtistCacheMu.RUnlock()
+	recordType, ok := albumRecordTypeCache[albumID]
+	return recordType, ok
+}
+
+// cacheAlbumDiscography stores whether an album is being downloaded as part of its artist's
+// discography, so its path lookups know whether a single goes in the Singles folder
+func cacheAlbumDiscography(albumID string, discography bool) {
+	albumArtistCacheMu.Lock()
+	defer albumArtistCacheMu.Unlock()
+	albumDiscographyCache[albumID] = discography
+}
+
+// isDiscographyAlbum reports whether the album is being downloaded as part of a discography
+func isDiscographyAlbum(albumID string) bool {
+	albumArtistCacheMu.RLock()
+	defer albumArtistCacheMu.RUnlock()
+	return albumDiscographyCache[albumID]
+}
+
+// variousArtistsName returns the configured label used as album artist for
+// compilations, soundtracks and playlists
+func (m *Manager) variousArtistsName() string {
+	if m.config == nil || m.config.Download.VariousArtistsName == "" {
+		return "Various Artists"
+	}
+	return m.config.Download.VariousArtistsName
+}
+
+// compilationOverride returns the user's forced compilation setting for an album, if any
+func (m *Manager) compilationOverride(albumID string) (bool, bool) {
+	if m.config == nil || len(m.config.Download.CompilationOverrides) == 0 {
+		return false, false
+	}
+	forced, ok := m.config.Download.CompilationOverrides[strings.TrimPrefix(albumID, "album_")]
+	return forced, ok
+}
+
+// hasCompilationKeyword checks an album title against the configured compilation keywords
+func (m *Manager) hasCompilationKeyword(title string) bool {
+	if m.config == nil {
+		return false
+	}
+	titleLower := strings.ToLower(title)
+	for _, keyword := range m.config.Download.CompilationKeywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword != "" && strings.Contains(titleLower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// sharedAlbumArtist returns the artist every track of the album shares when the album-level
+// artist (or compilation detection) says otherwise, or nil to keep the album's own artist.
+// Titles matching the compilation keywords are left alone.
+func (m *Manager) sharedAlbumArtist(album *api.Album) *api.Artist {
+	if m.config == nil || !m.config.Download.SingleArtistAlbums || album.Tracks == nil || len(album.Tracks.Data) < 2 {
+		return nil
+	}
+	if m.hasCompilationKeyword(album.Title) {
+		return nil
+	}
+	var shared *api.Artist
+	for _, track := range album.Tracks.Data {
+		if track.Artist == nil || track.Artist.ID == "" || track.Artist.Name == "" {
+			return nil
+		}
+		if shared == nil {
+			shared = track.Artist
+		} else if track.Artist.ID != shared.ID {
+			return nil
+		}
+	}
+	if album.RecordType != "compilation" && album.Artist != nil && album.Artist.ID == shared.ID {
+		return nil // Deezer already credits the album to this artist
+	}
+	return shared
+}
+
+// cacheSingleTrackSharedArtist applies download.single_artist_albums to a track downloaded on
+// its own. GetTrack's album has no track list, so the full album is fetched and its shared
+// artist cached for resolveAlbumArtist, as the album job does for its tracks.
+func (m *Manager) cacheSingleTrackSharedArtist(ctx context.Context, jobID string, track *api.Track) {
+	if !m.sharedArtistLookupWanted(track) {
+		return
+	}
+	album, err := m.deezerAPI.GetAlbum(ctx, track.Album.ID.String())
+	if err != nil {
+		m.logWarn(jobID, "Failed to fetch the album to check for a shared artist", zap.Error(err))
+		return
+	}
+	if shared := m.sharedAlbumArtist(album); shared != nil {
+		m.logInfo(jobID, "All tracks share one artist, using it as the album artist", zap.String("artist", shared.Name))
+		cacheAlbumArtist(track.Album.ID.String(), shared.Name)
+	}
+}
+
+// sharedArtistLookupWanted reports whether a single track's album could be filed under a
+// shared artist: it would otherwise go to Various Artists or to another artist than the
+// track's, and no album job or compilation override has already decided
+func (m *Manager) sharedArtistLookupWanted(track *api.Track) bool {
+	if m.config == nil || !m.config.Download.SingleArtistAlbums || track.Playlist != nil || track.Album == nil || track.Album.ID == "" {
+		return false
+	}
+	albumID := track.Album.ID.String()
+	if _, ok := getCachedAlbumArtist(albumID); ok {
+		return false
+	}
+	if _, overridden := m.compilationOverride(albumID); overridden {
+		return false
+	}
+	if m.isTrackAlbumCompilation(track.Album) {
+		return true
+	}
+	return track.Album.Artist != nil && track.Album.Artist.ID != "" && track.Artist != nil && track.Album.Artist.ID != track.Artist.ID
+}
+
+// searchFallbackConfidence is the match score download.search_fallback needs before it swaps
+// a dead track ID for a search result
+const searchFallbackConfidence = 0.85
+
+// searchFallbackTrack looks up a track whose ID Deezer no longer knows by the title and artist
+// stored on its queue item. A confident match replaces job.TrackID so the rest of the job
+// downloads it; otherwise the not-found error stands. A search that fails returns its own
+// error, so a network error or rate limit is retried rather than failing as NOT_FOUND. The
+// removed track's length isn't stored, so only title and artist are compared.
+func (m *Manager) searchFallbackTrack(ctx context.Context, job *Job, item *store.QueueItem) (*api.Track, error) {
+	if item.Title == "" || item.Artist == "" {
+		return nil, fmt.Errorf("%w: track %s has no stored title and artist to search for", api.ErrNotFound, job.TrackID)
+	}
+
+	match, confidence, err := m.deezerAPI.FindTrack(ctx, item.Title, item.Artist, 0)
+	if err != nil {
+		return nil, fmt.Errorf("search for %s - %s failed: %w", item.Artist, item.Title, err)
+	}
+	if match == nil || confidence < searchFallbackConfidence {
+		m.logWarn(job.ID, "No confident search match for removed track", zap.String("track_id", job.TrackID), zap.Float64("confidence", confidence))
+		return nil, fmt.Errorf("%w: no confident match for %s - %s", api.ErrNotFound, item.Artist, item.Title)
+	}
+
+	m.logInfo(job.ID, "Track ID not found, using search match", zap.String("old_track_id", job.TrackID), zap.String("new_track_id", match.ID.String()), zap.Float64("confidence", confidence))
+	job.TrackID = match.ID.String()
+	return m.deezerAPI.GetTrack(ctx, job.TrackID)
+}
+
+// isTrackAlbumCompilation decides whether a track's album should be filed under
+// "Various Artists" when no album job has cached the album artist
+func (m *Manager) isTrackAlbumCompilation(album *api.Album) bool {
+	if forced, ok := m.compilationOverride(album.ID.String()); ok {
+		return forced
+	}
+	if album.RecordType == "single" || album.RecordType == "ep" {
+		return false
+	}
+	return album.RecordType == "compilation" || m.hasCompilationKeyword(album.Title)
+}
+
 // isAlbumMultiDisc checks if an album has multiple discs
 // This uses a cache to avoid repeated API calls
 func (m *Manager) isAlbumMultiDisc(albumID string) bool {
@@ -2329,6 +3504,110 @@ func sanitizeFilename(name string) string {
 	return sanitized
 }
 
+// tagProfile returns which tags get written: "full", "basic" or "none"
+func (m *Manager) tagProfile() string {
+	if m.config == nil || m.config.Download.TagProfile == "" {
+		return "full"
+	}
+	return m.config.Download.TagProfile
+}
+
+// maxFilenameBytes returns the configured per-name byte limit
+func (m *Manager) maxFilenameBytes() int {
+	if m.config == nil || m.config.Download.MaxFilenameBytes < 1 {
+		return 255
+	}
+	return m.config.Download.MaxFilenameBytes
+}
+
+// truncateNameBytes shortens a single path component to at most maxBytes bytes of UTF-8,
+// cutting on a rune boundary so multi-byte characters are never split
+func truncateNameBytes(name string, maxBytes int) string {
+	if len(name) <= maxBytes {
+		return name
+	}
+	if maxBytes < 1 {
+		return ""
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(name[cut]) {
+		cut--
+	}
+
+	// Windows rejects names ending in a space or dot
+	return strings.TrimRight(name[:cut], " .")
+}
+
+// errFilenameCollision is returned when on_collision is "skip" and another track already
+// owns the output filename
+var errFilenameCollision = errors.New("output file already belongs to a different track")
+
+// onCollision returns how filename collisions between different tracks are handled
+func (m *Manager) onCollision() string {
+	if m.config == nil || m.config.Download.OnCollision == "" {
+		return "number"
+	}
+	return m.config.Download.OnCollision
+}
+
+// resolveCollision checks whether outputPath is already taken by a different track and,
+// depending on download.on_collision, returns a numbered path ("Name (2).flac"), removes
+// the other file, or fails with errFilenameCollision. A file from the same track is left
+// alone so resuming keeps working.
+func (m *Manager) resolveCollision(outputPath string, track *api.Track) (string, error) {
+	if !m.isOtherTracksFile(outputPath, track) {
+		return outputPath, nil
+	}
+
+	switch m.onCollision() {
+	case "overwrite":
+		if err := os.Remove(outputPath); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to remove colliding file: %w", err)
+		}
+		return outputPath, nil
+	case "skip":
+		return "", fmt.Errorf("%w: %s", errFilenameCollision, outputPath)
+	}
+
+	ext := filepath.Ext(outputPath)
+	base := strings.TrimSuffix(filepath.Base(outputPath), ext)
+	dir := filepath.Dir(outputPath)
+	for n := 2; n < 100; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		name := truncateNameBytes(base, m.maxFilenameBytes()-len(suffix)-len(ext)) + suffix + ext
+		candidate := filepath.Join(dir, name)
+		if !m.isOtherTracksFile(candidate, track) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no free numbered name for %s", errFilenameCollision, outputPath)
+}
+
+// isOtherTracksFile reports whether path exists and was written for a different track.
+// The download history is checked first, then the file's ISRC tag. Files that can't be
+// attributed are assumed to be ours.
+func (m *Manager) isOtherTracksFile(path string, track *api.Track) bool {
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		return false
+	}
+
+	if m.queueStore != nil {
+		if trackID, err := m.queueStore.GetHistoryTrackIDByPath(path); err == nil && trackID != "" {
+			return trackID != track.ID.String()
+		}
+	}
+
+	if track.ISRC == "" {
+		return false
+	}
+	existing, err := metadata.NewManager(&metadata.Config{}).GetMetadata(path)
+	if err != nil || existing.ISRC == "" {
+		return false
+	}
+	return !strings.EqualFold(existing.ISRC, track.ISRC)
+}
+
 // GetStats returns download statistics
 func (m *Manager) GetStats() (map[string]interface{}, error) {
 	queueStats, err := m.queueStore.GetStats()
@@ -2342,11 +3621,204 @@ func (m *Manager) GetStats() (map[string]interface{}, error) {
 		"queue_downloading": queueStats.Downloading,
 		"queue_completed":   queueStats.Completed,
 		"queue_failed":      queueStats.Failed,
+		"queue_cancelled":   queueStats.Cancelled,
 		"active_downloads":  m.workerPool.GetActiveJobCount(),
 		"max_workers":       m.workerPool.GetMaxWorkers(),
 	}, nil
 }
 
+// GetWorkerPoolStatus returns the worker pool's active and buffered jobs
+func (m *Manager) GetWorkerPoolStatus() *PoolStatus {
+	return m.workerPool.Status()
+}
+
+// ActiveDownload describes a track that is currently being downloaded
+type ActiveDownload struct {
+	ItemID         string  `json:"item_id"`
+	ParentID       string  `json:"parent_id,omitempty"`
+	Title          string  `json:"title"`
+	Artist         string  `json:"artist"`
+	Album          string  `json:"album"`
+	Progress       int     `json:"progress"`
+	BytesProcessed int64   `json:"bytes_processed"`
+	TotalBytes     int64   `json:"total_bytes"`
+	Speed          string  `json:"speed"`
+	SpeedBytes     float64 `json:"speed_bytes"` // bytes per second
+	ETA            string  `json:"eta"`
+	ETASeconds     int     `json:"eta_seconds"`
+}
+
+// GetActiveDownloads returns the tracks currently downloading with their live speed and ETA
+// Stats come from the notifier, so this only works when it implements StatsProvider
+func (m *Manager) GetActiveDownloads() []*ActiveDownload {
+	active := []*ActiveDownload{}
+
+	provider, ok := m.notifier.(StatsProvider)
+	if !ok {
+		return active
+	}
+
+	stats := provider.GetAllDownloadStats()
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].StartTime.Before(stats[j].StartTime)
+	})
+
+	for _, st := range stats {
+		// Parents also report progress through the notifier - only list tracks in flight
+		if !strings.HasPrefix(st.ItemID, "track_") || !m.workerPool.IsJobActive(st.ItemID) {
+			continue
+		}
+
+		download := &ActiveDownload{
+			ItemID:         st.ItemID,
+			BytesProcessed: st.BytesProcessed,
+			TotalBytes:     st.TotalBytes,
+			Speed:          FormatSpeed(st.Speed),
+			SpeedBytes:     st.Speed,
+			ETA:            FormatETA(st.ETA),
+			ETASeconds:     st.ETA,
+		}
+		if st.TotalBytes > 0 {
+			download.Progress = int(st.BytesProcessed * 100 / st.TotalBytes)
+		}
+
+		if item, err := m.queueStore.GetByID(st.ItemID); err == nil {
+			download.ParentID = item.ParentID
+			download.Title = item.Title
+			download.Artist = item.Artist
+			download.Album = item.Album
+		}
+
+		active = append(active, download)
+	}
+
+	return active
+}
+
+// queueTrackArtwork queues the cover.jpg sidecar for the track's folder (download.save_album_cover)
+// and, for albums that aren't compilations, the artist's folder.jpg (download.embed_artwork).
+// Images that already exist are left alone.
+func (m *Manager) queueTrackArtwork(ctx context.Context, track *api.Track, trackDir string) {
+	if track.Playlist != nil {
+		// Playlist download - download playlist cover
+		playlist := track.Playlist
+		if m.config.Download.SaveAlbumCover {
+			m.queueImageDownload(ctx, filepath.Join(trackDir, "cover.jpg"), "playlist artwork", func(ctx context.Context) error {
+				return m.downloadPlaylistArtwork(ctx, playlist, trackDir)
+			})
+		}
+		// No artist image for playlists
+	} else {
+		// Album download - download album artwork. The Singles folder is shared by many
+		// releases, so it gets no cover.jpg
+		album := track.Album
+		if m.config.Download.SaveAlbumCover && !m.inSinglesFolder(track) {
+			m.queueImageDownload(ctx, filepath.Join(trackDir, "cover.jpg"), "album artwork", func(ctx context.Context) error {
+				return m.downloadAlbumArtwork(ctx, album, trackDir)
+			})
+		}
+		
+		// Download artist image (to artist folder) - but NOT for compilations/soundtracks
+		// Now with extensive logging to identify crash location
+		if m.config.Download.SaveArtistImage && track.AlbumArtist != m.variousArtistsName() {
+			// The artist folder comes from the artist folder template, however many album
+			// and CD levels sit between it and trackDir
+			artistDir := filepath.Join(m.config.Download.OutputDir, m.artistFolderPath(track))
+			
+			if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+				fmt.Fprintf(logFile, "[%s] [ARTIST_IMG] Track download complete, attempting artist image for: %s\n", time.Now().Format("2006-01-02 15:04:05"), track.AlbumArtist)
+				logFile.Close()
+			}
+			
+			// Get artist ID - prefer album artist, fallback to track artist
+			var artistID api.FlexibleID
+			var artistName string
+			var hasArtist bool
+			
+			if track.Album != nil && track.Album.Artist != nil {
+				artistID = track.Album.Artist.ID
+				artistName = track.AlbumArtist
+				hasArtist = true
+				if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+					fmt.Fprintf(logFile, "[%s] [ARTIST_IMG] Using album artist ID: %v\n", time.Now().Format("2006-01-02 15:04:05"), artistID)
+					logFile.Close()
+				}
+			} else if track.Artist != nil {
+				artistID = track.Artist.ID
+				artistName = track.AlbumArtist
+				hasArtist = true
+				if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+					fmt.Fprintf(logFile, "[%s] [ARTIST_IMG] Using track artist ID: %v\n", time.Now().Format("2006-01-02 15:04:05"), artistID)
+					logFile.Close()
+				}
+			} else {
+				if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+					fmt.Fprintf(logFile, "[%s] [ARTIST_IMG] ERROR: No artist ID available for %s\n", time.Now().Format("2006-01-02 15:04:05"), track.AlbumArtist)
+					logFile.Close()
+				}
+			}
+			
+			if hasArtist {
+				albumArtist := &api.Artist{
+					ID:   artistID,
+					Name: artistName,
+				}
+				
+				if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+					fmt.Fprintf(logFile, "[%s] [ARTIST_IMG] Queueing downloadArtistImage for %s\n", time.Now().Format("2006-01-02 15:04:05"), artistName)
+					logFile.Close()
+				}
+				
+				m.queueImageDownload(ctx, filepath.Join(artistDir, "folder.jpg"), "artist image for "+artistName, func(ctx context.Context) error {
+					return m.downloadArtistImage(ctx, albumArtist, artistDir)
+				})
+			}
+		}
+	}
+}
+
+// sidecarArtworkWanted reports whether queueTrackArtwork has anything to save: cover.jpg with
+// download.save_album_cover and the artist's folder.jpg with download.save_artist_image.
+// Embedding the cover is decided separately by applyMetadataTags, so embed-only, sidecar-only,
+// both and neither all work.
+func (m *Manager) sidecarArtworkWanted() bool {
+	return m.config.Download.SaveAlbumCover || m.config.Download.SaveArtistImage
+}
+
+// imageClient returns the shared client for artwork and artist images
+func (m *Manager) imageClient() *http.Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.imageHTTP
+}
+
+// newImageClient builds the image client, bounded by network.image_timeout. Artwork and artist
+// images all come from dzcdn.net, which answers too many parallel connections with 403s, so
+// connections per host are capped by network.image_conns_per_host.
+func newImageClient(cfg *config.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxConnsPerHost = imageConnsPerHost(cfg)
+	transport.MaxIdleConnsPerHost = transport.MaxConnsPerHost
+	return &http.Client{
+		Timeout:   imageTimeout(cfg),
+		Transport: transport,
+	}
+}
+
+func imageTimeout(cfg *config.Config) time.Duration {
+	if cfg == nil || cfg.Network.ImageTimeout < 1 {
+		return 30 * time.Second
+	}
+	return time.Duration(cfg.Network.ImageTimeout) * time.Second
+}
+
+func imageConnsPerHost(cfg *config.Config) int {
+	if cfg == nil || cfg.Network.ImageConnsPerHost < 1 {
+		return 4
+	}
+	return cfg.Network.ImageConnsPerHost
+}
+
 // downloadAlbumArtwork downloads the album cover art to the album directory
 func (m *Manager) downloadAlbumArtwork(ctx context.Context, album *api.Album, albumDir string) error {
 	// Check if artwork file already exists
@@ -2387,7 +3859,7 @@ func (m *Manager) downloadAlbumArtwork(ctx context.Context, album *api.Album, al
 		return fmt.Errorf("failed to create artwork request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := m.imageClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to download artwork: %w", err)
 	}
@@ -2397,16 +3869,8 @@ func (m *Manager) downloadAlbumArtwork(ctx context.Context, album *api.Album, al
 		return fmt.Errorf("artwork download failed with status: %d", resp.StatusCode)
 	}
 
-	// Create the artwork file
-	artworkFile, err := os.Create(artworkPath)
-	if err != nil {
-		return fmt.Errorf("failed to create artwork file: %w", err)
-	}
-	defer artworkFile.Close()
-
-	// Copy the artwork data
-	_, err = io.Copy(artworkFile, resp.Body)
-	if err != nil {
+	// Save the artwork; tagging may read cover.jpg concurrently now that images download in the background
+	if err := writeFileAtomic(artworkPath, resp.Body); err != nil {
 		return fmt.Errorf("failed to save artwork: %w", err)
 	}
 
@@ -2474,7 +3938,7 @@ func (m *Manager) downloadPlaylistArtwork(ctx context.Context, playlist *api.Pla
 		return fmt.Errorf("failed to create playlist artwork request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := m.imageClient().Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to download playlist artwork: %w", err)
 	}
@@ -2489,19 +3953,37 @@ func (m *Manager) downloadPlaylistArtwork(ctx context.Context, playlist *api.Pla
 		return fmt.Errorf("failed to create playlist directory: %w", err)
 	}
 
-	// Create the artwork file
-	artworkFile, err := os.Create(artworkPath)
-	if err != nil {
-		return fmt.Errorf("failed to create playlist artwork file: %w", err)
+	// Save the artwork
+	if err := writeFileAtomic(artworkPath, resp.Body); err != nil {
+		return fmt.Errorf("failed to save playlist artwork: %w", err)
 	}
-	defer artworkFile.Close()
 
-	// Copy the artwork data
-	_, err = io.Copy(artworkFile, resp.Body)
+	return nil
+}
+
+// writeFileAtomic writes r to a temporary file next to path and renames it into place,
+// so readers never see a partially written file
+func writeFileAtomic(path string, r io.Reader) error {
+	tmpPath := path + ".part"
+	file, err := os.Create(tmpPath)
 	if err != nil {
-		return fmt.Errorf("failed to save playlist artwork: %w", err)
+		return err
+	}
+
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
 	}
 
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
 	return nil
 }
 
@@ -2656,7 +4138,7 @@ func (m *Manager) downloadArtistImage(ctx context.Context, artist *api.Artist, a
 
 	// Download the artist image with timeout
 	// Create a context with timeout to prevent hanging
-	downloadCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	downloadCtx, cancel := context.WithTimeout(ctx, time.Duration(m.config.Network.ImageTimeout)*time.Second)
 	defer cancel()
 	
 	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
@@ -2678,12 +4160,7 @@ func (m *Manager) downloadArtistImage(ctx context.Context, artist *api.Artist, a
 		logFile.Close()
 	}
 
-	// Use a client with timeout instead of DefaultClient
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-	
-	resp, err := client.Do(req)
+	resp, err := m.imageClient().Do(req)
 	if err != nil {
 		if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
 			fmt.Fprintf(logFile, "[%s] [ARTIST_IMG] HTTP request failed: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
@@ -2775,13 +4252,35 @@ func (m *Manager) StopAll() error {
 	return nil
 }
 
-// updateParentProgress updates the completed track count for a parent album/playlist
+// parentLockSet serializes work per album/playlist over a fixed set of mutexes striped by a hash
+// of the parent ID, so nothing has to be cleaned up when parents leave the queue. Parents that
+// share a stripe just wait on each other briefly
+type parentLockSet [64]sync.Mutex
+
+// get returns the mutex for the given parent ID
+func (l *parentLockSet) get(parentID string) *sync.Mutex {
+	h := fnv.New32a()
+	h.Write([]byte(parentID))
+	return &l[h.Sum32()%uint32(len(l))]
+}
+
+// updateParentProgress updates the completed track count for a parent album/playlist.
+// Calls for the same parent run one at a time: its last tracks can finish together, and
+// each would otherwise verify the tracklist and queue the missing tracks again.
 func (m *Manager) updateParentProgress(parentID string) {
+	lock := m.parentLocks.get(parentID)
+	lock.Lock()
+	defer lock.Unlock()
+
 	// Get parent item
 	parent, err := m.queueStore.GetByID(parentID)
-	if err != nil {
+	if err != nil || parent.Status == "cancelled" {
 		return
 	}
+	wasCompleted := parent.Status == "completed"
+	// Set when this call is the one that completed the parent; concurrent calls for the
+	// parent's last tracks can all see it unfinished, but only one wins MarkCompleted
+	justCompleted := false
 
 	// Count completed child tracks
 	completedCount := m.queueStore.CountCompletedChildren(parentID)
@@ -2795,8 +4294,18 @@ func (m *Manager) updateParentProgress(parentID string) {
 		parent.Progress = (completedCount * 100) / parent.TotalTracks
 	}
 	
+	// Before completing an album, make sure Deezer doesn't list tracks we never queued
+	allFinished := finishedCount >= parent.TotalTracks && parent.TotalTracks > 0
+	if allFinished && parent.Type == "album" && m.config.Download.VerifyAlbumTrackCount {
+		if queued := m.queueMissingAlbumTracks(parent); queued > 0 {
+			parent.TotalTracks += queued
+			parent.Progress = (completedCount * 100) / parent.TotalTracks
+			allFinished = false
+		}
+	}
+
 	// Mark parent as completed if all tracks are done (including failed ones)
-	if finishedCount >= parent.TotalTracks && parent.TotalTracks > 0 {
+	if allFinished {
 		if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
 			fmt.Fprintf(logFile, "[%s] Marking album %s as completed: %d/%d tracks\n", time.Now().Format("2006-01-02 15:04:05"), parentID, completedCount, parent.TotalTracks)
 			logFile.Close()
@@ -2804,6 +4313,16 @@ func (m *Manager) updateParentProgress(parentID string) {
 		parent.Status = "completed"
 		now := time.Now()
 		parent.CompletedAt = &now
+		if !wasCompleted {
+			if justCompleted, err = m.queueStore.MarkCompleted(parentID, now); err != nil {
+				m.logWarn(parentID, "Failed to mark parent completed", zap.Error(err))
+			}
+		}
+		parent.ErrorMessage = ""
+		if completedCount < parent.TotalTracks {
+			failed, _ := m.queueStore.GetFailedTracks(parentID)
+			parent.ErrorMessage = failedTracksSummary(parent.TotalTracks-completedCount, failed)
+		}
 		
 		// DISABLED: Post-album artist image download causes crashes
 		// The inline download during track processing is sufficient
@@ -2835,9 +4354,28 @@ func (m *Manager) updateParentProgress(parentID string) {
 		}
 	}
 	
-	// Notify progress update for parent
+	// Auto-clear the finished parent so the queue doesn't grow without bound
+	// Done before notifying so the queue update sent with NotifyCompleted reflects the removal
+	if err == nil && parent.Status == "completed" && m.config.Download.AutoClearCompleted {
+		cleared, clearErr := m.queueStore.ClearCompletedParent(parentID)
+		if logFile, logErr := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); logErr == nil {
+			fmt.Fprintf(logFile, "[%s] Auto-clear parent %s: cleared=%v, err=%v\n", time.Now().Format("2006-01-02 15:04:05"), parentID, cleared, clearErr)
+			logFile.Close()
+		}
+	}
+	
+	if err == nil && justCompleted {
+		m.pruneParentStaging(parentID)
+		m.notifyParentCompletion(parent)
+	}
+	
+	// Notify progress update for parent, with an ETA for notifiers that can show one
 	if m.notifier != nil {
-		m.notifier.NotifyProgress(parentID, parent.Progress, int64(completedCount), int64(parent.TotalTracks))
+		if pn, ok := m.notifier.(ParentProgressNotifier); ok {
+			pn.NotifyParentProgress(m.parentProgress(parent, completedCount, finishedCount))
+		} else {
+			m.notifier.NotifyProgress(parentID, parent.Progress, int64(completedCount), int64(parent.TotalTracks))
+		}
 		
 		// If parent just completed, also send status notification
 		if parent.Status == "completed" {
@@ -2846,6 +4384,97 @@ func (m *Manager) updateParentProgress(parentID string) {
 	}
 }
 
+// failedTracksSummary describes the tracks of a finished album/playlist that didn't download,
+// e.g. "3 tracks failed (2 geo-blocked)"
+func failedTracksSummary(failedCount int, failed []*store.FailedTrack) string {
+	noun := "tracks"
+	if failedCount == 1 {
+		noun = "track"
+	}
+	summary := fmt.Sprintf("%d %s failed", failedCount, noun)
+
+	geoBlocked := make(map[string]bool)
+	for _, track := range failed {
+		if strings.HasPrefix(track.ErrorMessage, api.GeoBlockedReason) {
+			geoBlocked[track.TrackID] = true
+		}
+	}
+	if len(geoBlocked) > 0 {
+		summary += fmt.Sprintf(" (%d geo-blocked)", len(geoBlocked))
+	}
+	return summary
+}
+
+// queueMissingAlbumTracks re-fetches an album's tracklist from Deezer, bypassing the cache,
+// and submits jobs for tracks that have no queue entry yet. This catches bonus tracks added
+// after the album was queued and tracklists that were truncated on the first fetch.
+// Returns the number of tracks submitted.
+func (m *Manager) queueMissingAlbumTracks(parent *store.QueueItem) int {
+	if m.deezerAPI == nil {
+		return 0
+	}
+
+	albumID := strings.TrimPrefix(parent.ID, "album_")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	album, err := m.deezerAPI.RefreshAlbum(ctx, albumID)
+	if err != nil || album.Tracks == nil {
+		if logFile, logErr := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); logErr == nil {
+			fmt.Fprintf(logFile, "[%s] Could not verify track count for %s, completing as is: %v\n", time.Now().Format("2006-01-02 15:04:05"), parent.ID, err)
+			logFile.Close()
+		}
+		return 0
+	}
+
+	children, err := m.queueStore.GetChildren(parent.ID)
+	if err != nil {
+		return 0
+	}
+
+	missing := missingAlbumTracks(albumID, album.Tracks.Data, children)
+	if logFile, logErr := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); logErr == nil {
+		fmt.Fprintf(logFile, "[%s] Verified %s against Deezer: %d tracks listed (nb_tracks=%d), %d queued, %d missing\n", time.Now().Format("2006-01-02 15:04:05"), parent.ID, len(album.Tracks.Data), album.TrackCount, len(children), len(missing))
+		logFile.Close()
+	}
+
+	orderAlbumTracks(missing, m.config.Download.AlbumTrackOrder)
+	submitted := 0
+	for _, track := range missing {
+		job := &Job{
+			ID:      fmt.Sprintf("track_%s_%s", albumID, track.ID),
+			Type:    JobTypeTrack,
+			TrackID: track.ID.String(),
+		}
+		if err := m.workerPool.Submit(job); err != nil {
+			if logFile, logErr := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); logErr == nil {
+				fmt.Fprintf(logFile, "[%s] Failed to submit missing track %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), job.ID, err)
+				logFile.Close()
+			}
+			continue
+		}
+		submitted++
+	}
+
+	return submitted
+}
+
+// missingAlbumTracks returns the album tracks that have no child queue entry
+func missingAlbumTracks(albumID string, tracks []*api.Track, children []*store.QueueItem) []*api.Track {
+	queued := make(map[string]bool, len(children))
+	for _, child := range children {
+		queued[child.ID] = true
+	}
+
+	var missing []*api.Track
+	for _, track := range tracks {
+		if !queued[fmt.Sprintf("track_%s_%s", albumID, track.ID)] {
+			missing = append(missing, track)
+		}
+	}
+	return missing
+}
+
 // downloadMissingArtistImages scans the album folder and downloads missing artist images
 func (m *Manager) downloadMissingArtistImages(ctx context.Context, albumID string) {
 	// Add panic recovery to prevent crashes
@@ -2868,7 +4497,7 @@ func (m *Manager) downloadMissingArtistImages(ctx context.Context, albumID strin
 	}
 	
 	// Check if this is a compilation/soundtrack - if so, don't download artist images
-	if cachedArtist, ok := getCachedAlbumArtist(numericID); ok && cachedArtist == "Various Artists" {
+	if cachedArtist, ok := getCachedAlbumArtist(numericID); ok && cachedArtist == m.variousArtistsName() {
 		if logFile, logErr := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); logErr == nil {
 			fmt.Fprintf(logFile, "[%s] Skipping artist images for compilation/soundtrack album %s\n", 
 				time.Now().Format("2006-01-02 15:04:05"), albumID)
@@ -2885,12 +4514,12 @@ func (m *Manager) downloadMissingArtistImages(ctx context.Context, albumID strin
 	
 	// Get the cached album artist - this is the definitive artist for this album
 	cachedArtist, hasCached := getCachedAlbumArtist(numericID)
-	if !hasCached || cachedArtist == "" || cachedArtist == "Various Artists" {
+	if !hasCached || cachedArtist == "" || cachedArtist == m.variousArtistsName() {
 		return // No cached artist or it's Various Artists
 	}
 	
 	// Build artist folder path using the cached album artist
-	artistDir := filepath.Join(baseDir, cachedArtist)
+	artistDir := filepath.Join(baseDir, m.artistFolderPath(&api.Track{Artist: album.Artist, Album: album, AlbumArtist: cachedArtist}))
 	artistImagePath := filepath.Join(artistDir, "folder.jpg")
 	
 	// Check if artist image already exists
@@ -2913,20 +4542,111 @@ func (m *Manager) downloadMissingArtistImages(ctx context.Context, albumID strin
 	}
 }
 
-// applyMetadataTags applies metadata tags to a downloaded audio file
-func (m *Manager) applyMetadataTags(ctx context.Context, filePath string, track *api.Track) error {
+// keepEncryptedDir returns where encrypted downloads that fail to decrypt are kept when
+// system.keep_encrypted_on_error is on, or "" to delete them as usual
+func (m *Manager) keepEncryptedDir() string {
+	if !m.config.System.KeepEncryptedOnError {
+		return ""
+	}
+	return filepath.Join(os.TempDir(), "deemusic-encrypted")
+}
+
+// resolveAlbumArtist sets track.AlbumArtist, which names the artist folder and the album
+// artist tag. It ALWAYS prefers the album-level artist over the track artist to keep all
+// tracks in one folder, so albums aren't split when individual tracks have different artists.
+// single is true for a track downloaded on its own, without an album or playlist parent.
+func (m *Manager) resolveAlbumArtist(track *api.Track, single bool) {
+	track.AlbumArtist = track.Artist.Name // Default fallback
+	
+	// For playlist downloads, use the configured "Various Artists" label
+	if track.Playlist != nil {
+		track.AlbumArtist = m.variousArtistsName()
+	} else if track.Album != nil {
+		// First, check if we have a cached album artist from the album download job
+		// This ensures ALL tracks in an album use the same artist folder
+		albumID := fmt.Sprintf("%v", track.Album.ID)
+		if cachedArtist, ok := getCachedAlbumArtist(albumID); ok {
+			track.AlbumArtist = cachedArtist
+			track.IsCompilation = cachedArtist == m.variousArtistsName()
+			if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+				fmt.Fprintf(logFile, "[%s] Using cached album artist for album %s: %s\n", 
+					time.Now().Format("2006-01-02 15:04:05"), albumID, cachedArtist)
+				logFile.Close()
+			}
+		} else if m.isTrackAlbumCompilation(track.Album) {
+			// For compilations and soundtracks, use "Various Artists"
+			track.AlbumArtist = m.variousArtistsName()
+			track.IsCompilation = true
+			
+			if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+				fmt.Fprintf(logFile, "[%s] Compilation/Soundtrack detected for folder structure: Album='%s', RecordType='%s', using AlbumArtist=%s\n", 
+					time.Now().Format("2006-01-02 15:04:05"), track.Album.Title, track.Album.RecordType, track.AlbumArtist)
+				logFile.Close()
+			}
+		} else if track.Album.Artist != nil && track.Album.Artist.Name != "" {
+			// Use album-level artist from track's album object
+			track.AlbumArtist = track.Album.Artist.Name
+		}
+	}
+
+	// A single can carry its featured artists in the artist or album artist name; file it
+	// under the primary artist. The artist tag still gets the feat string from buildArtistString.
+	if single && !track.IsCompilation && track.Playlist == nil {
+		track.AlbumArtist = primaryArtistName(track.AlbumArtist)
+	}
+}
+
+// featuringPattern matches a featured-artist suffix such as " feat. B", " (ft. B)" or " featuring B"
+var featuringPattern = regexp.MustCompile(`(?i)\s+[(\[]?(?:feat\.?|ft\.?|featuring)\s.*$`)
+
+// primaryArtistName strips any featured artists from an artist name
+func primaryArtistName(name string) string {
+	if primary := featuringPattern.ReplaceAllString(name, ""); primary != "" {
+		return primary
+	}
+	return name
+}
+
+// standaloneAlbum stands in for the album of tracks Deezer returns without one (user uploads,
+// podcast-style episodes): the track is treated as its own release, with no date
+func standaloneAlbum(track *api.Track) *api.Album {
+	return &api.Album{Title: track.Title, Artist: track.Artist}
+}
+
+// id3Version returns the ID3v2 version MP3s are tagged with, from download.id3_version
+func (m *Manager) id3Version() byte {
+	if m.config.Download.ID3Version == "2.4" {
+		return metadata.ID3v24
+	}
+	return metadata.ID3v23
+}
+
+// applyMetadataTags applies metadata tags to a downloaded audio file. For a file that was
+// already there (existingFile) and download.preserve_existing_tags, only tags the file is
+// missing are written.
+func (m *Manager) applyMetadataTags(ctx context.Context, filePath string, track *api.Track, existingFile bool) error {
 	// Nil checks
 	if track == nil {
 		return fmt.Errorf("track is nil")
 	}
-	if track.Artist == nil || track.Album == nil {
-		return fmt.Errorf("track artist or album is nil")
+	if track.Artist == nil {
+		return fmt.Errorf("track artist is nil")
+	}
+	if track.Album == nil {
+		// Missing album details aren't worth failing a finished download over
+		track.Album = standaloneAlbum(track)
+	}
+
+	profile := m.tagProfile()
+	if profile == "none" {
+		return nil
 	}
 
 	// Create metadata manager
 	metadataManager := metadata.NewManager(&metadata.Config{
 		EmbedArtwork: m.config.Download.EmbedArtwork,
 		ArtworkSize:  1200,
+		ID3Version:   m.id3Version(),
 	})
 
 	// Prepare metadata with safe access
@@ -2935,11 +4655,12 @@ func (m *Manager) applyMetadataTags(ctx context.Context, filePath string, track
 	albumArtist := track.AlbumArtist
 	if albumArtist == "" {
 		// Fallback if AlbumArtist wasn't set (shouldn't happen, but be safe)
-		albumArtist = track.Artist.Name
+		albumArtist = primaryArtistName(track.Artist.Name)
 	}
 	
 	albumTitle := track.Album.Title
 	trackNumber := track.TrackNumber
+	totalTracks := track.TotalTracks
 	discNumber := track.DiscNumber
 	totalDiscs := track.TotalDiscs
 	
@@ -2952,7 +4673,7 @@ func (m *Manager) applyMetadataTags(ctx context.Context, filePath string, track
 	
 	// For playlist downloads, override with playlist-specific values
 	if track.Playlist != nil {
-		albumArtist = "Various Artists"
+		albumArtist = m.variousArtistsName()
 		albumTitle = track.Playlist.Title
 		trackNumber = track.PlaylistPosition // Use playlist position as track number
 		discNumber = 0                        // No disc number for playlists
@@ -2976,20 +4697,47 @@ func (m *Manager) applyMetadataTags(ctx context.Context, filePath string, track
 		Album:       albumTitle,
 		AlbumArtist: albumArtist,
 		TrackNumber: trackNumber,
+		TotalTracks: totalTracks,
 		DiscNumber:  discNumber,
 		TotalDiscs:  totalDiscs,
 		Year:        extractYear(track.Album.ReleaseDate),
+		ReleaseDate: fullReleaseDate(track.Album.ReleaseDate),
 		Genre:       "", // Deezer doesn't provide genre in track API
 		Duration:    track.Duration,
 		ISRC:        track.ISRC,
 		Label:       track.Album.Label,
 		Copyright:   "", // Not available in API
+		Compilation: m.config.Download.WriteCompilationTag && track.IsCompilation,
+	}
+
+	// The basic profile keeps identifying fields only - no ISRC, label, dates or numbering
+	if profile == "basic" {
+		trackMetadata = &metadata.TrackMetadata{
+			Title:  trackMetadata.Title,
+			Artist: trackMetadata.Artist,
+			Album:  trackMetadata.Album,
+		}
+	}
+
+	// Reissues carry this release's date; tag the original separately when Deezer knows it
+	if profile == "full" && track.Album.ID != "" && m.deezerAPI != nil {
+		if originalDate, err := m.deezerAPI.GetAlbumOriginalReleaseDate(ctx, track.Album.ID.String()); err == nil {
+			trackMetadata.OriginalDate = originalDate
+		} else if logFile, logErr := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); logErr == nil {
+			fmt.Fprintf(logFile, "[%s] Could not get original release date for album %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), track.Album.ID.String(), err)
+			logFile.Close()
+		}
+	}
+
+	// Deezer's normalization gain stands in for a ReplayGain analysis
+	if profile == "full" && m.config.Download.WriteGainTag && track.Gain != 0 {
+		trackMetadata.ReplayGain = metadata.DeezerReplayGain(track.Gain)
 	}
 
 	// Debug log metadata values
 	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-		fmt.Fprintf(logFile, "[%s] Metadata: Artist=%s, AlbumArtist=%s, DiscNumber=%d/%d, TrackNumber=%d\n", 
-			time.Now().Format("2006-01-02 15:04:05"), trackMetadata.Artist, trackMetadata.AlbumArtist, trackMetadata.DiscNumber, trackMetadata.TotalDiscs, trackMetadata.TrackNumber)
+		fmt.Fprintf(logFile, "[%s] Metadata: Artist=%s, AlbumArtist=%s, DiscNumber=%d/%d, TrackNumber=%d/%d\n", 
+			time.Now().Format("2006-01-02 15:04:05"), trackMetadata.Artist, trackMetadata.AlbumArtist, trackMetadata.DiscNumber, trackMetadata.TotalDiscs, trackMetadata.TrackNumber, trackMetadata.TotalTracks)
 		logFile.Close()
 	}
 
@@ -3004,10 +4752,202 @@ func (m *Manager) applyMetadataTags(ctx context.Context, filePath string, track
 		}
 	}
 
-	// Apply metadata to file
+	// Fall back to a cover image already sitting next to the track (previous run or user-supplied)
+	if m.config.Download.EmbedArtwork && len(trackMetadata.ArtworkData) == 0 {
+		if artworkData, mimeType, coverPath, err := m.loadLocalArtwork(filePath, track.IsMultiDiscAlbum && m.config.Download.CreateCDFolder); err == nil {
+			trackMetadata.ArtworkData = artworkData
+			trackMetadata.ArtworkMIME = mimeType
+			if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+				fmt.Fprintf(logFile, "[%s] Embedding local artwork %s (%s) for %s\n", 
+					time.Now().Format("2006-01-02 15:04:05"), coverPath, mimeType, filePath)
+				logFile.Close()
+			}
+		}
+	}
+
+	// The artist image goes in as a separate "artist" picture; the metadata package skips it
+	// if it's the same image as the cover
+	if m.config.Download.EmbedArtwork && m.config.Download.EmbedArtistImage && track.Artist != nil {
+		if picture, err := m.artistPicture(ctx, track.Artist); err == nil {
+			trackMetadata.Pictures = append(trackMetadata.Pictures, *picture)
+		} else if logFile, logErr := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); logErr == nil {
+			fmt.Fprintf(logFile, "[%s] Could not embed artist image for %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), track.Artist.Name, err)
+			logFile.Close()
+		}
+	}
+
+	// Apply metadata to file, bounded so many tracks finishing at once don't thrash the disk
+	release, err := m.acquireMetadataSlot(ctx, filePath)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if existingFile && m.config.Download.PreserveExistingTags {
+		return metadataManager.ApplyMissingMetadata(filePath, trackMetadata)
+	}
 	return metadataManager.ApplyMetadata(filePath, trackMetadata)
 }
 
+// metadataRetryDelay is the base delay between tagging attempts (grows linearly per attempt)
+const metadataRetryDelay = time.Second
+
+// applyMetadataTagsWithRetry retries applyMetadataTags up to download.metadata_retries extra
+// times, since tagging mostly fails on transient file locks (antivirus, indexers, players)
+func (m *Manager) applyMetadataTagsWithRetry(ctx context.Context, filePath string, track *api.Track, existingFile bool) error {
+	retries := m.config.Download.MetadataRetries
+	if retries < 0 {
+		retries = 0
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(time.Duration(attempt) * metadataRetryDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		lastErr = m.applyMetadataTags(ctx, filePath, track, existingFile)
+		if lastErr == nil {
+			return nil
+		}
+
+		if logFile, logErr := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); logErr == nil {
+			fmt.Fprintf(logFile, "[%s] Metadata attempt %d/%d failed for %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), attempt+1, retries+1, filePath, lastErr)
+			logFile.Close()
+		}
+	}
+
+	return lastErr
+}
+
+// metadataConcurrency returns the configured number of concurrent metadata applies
+func metadataConcurrency(cfg *config.Config) int {
+	if cfg == nil || cfg.Download.MetadataConcurrency < 1 {
+		return 2
+	}
+	return cfg.Download.MetadataConcurrency
+}
+
+// imageConcurrency returns how many artwork/artist image downloads may run at once
+func imageConcurrency(cfg *config.Config) int {
+	if cfg == nil || cfg.Download.ImageConcurrency < 1 {
+		return 2
+	}
+	return cfg.Download.ImageConcurrency
+}
+
+// albumConcurrency returns how many album/playlist jobs may submit tracks at once
+func albumConcurrency(cfg *config.Config) int {
+	if cfg == nil || cfg.Download.ConcurrentAlbums < 1 {
+		return 2
+	}
+	return cfg.Download.ConcurrentAlbums
+}
+
+// acquireAlbumSlot waits for a free album slot; the returned func releases it
+func (m *Manager) acquireAlbumSlot(ctx context.Context) (func(), error) {
+	m.mu.RLock()
+	sem := m.albumSem
+	m.mu.RUnlock()
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// queueImageDownload runs an artwork/artist image download in the background, bounded by
+// the image semaphore, so track workers never wait on image I/O. Downloads are deduplicated
+// by destination path: if the file exists or a download for it is already queued, it's skipped.
+func (m *Manager) queueImageDownload(ctx context.Context, destPath string, label string, download func(ctx context.Context) error) {
+	m.imageMu.Lock()
+	if m.imageQueued[destPath] {
+		m.imageMu.Unlock()
+		return
+	}
+	if _, err := os.Stat(destPath); err == nil {
+		m.imageMu.Unlock()
+		return
+	}
+	m.imageQueued[destPath] = true
+	m.imageMu.Unlock()
+
+	m.mu.RLock()
+	sem := m.imageSem
+	m.mu.RUnlock()
+
+	m.backgroundWG.Add(1)
+	go func() {
+		defer m.backgroundWG.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+					fmt.Fprintf(logFile, "[%s] PANIC downloading %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), label, r)
+					logFile.Close()
+				}
+			}
+		}()
+		defer func() {
+			m.imageMu.Lock()
+			delete(m.imageQueued, destPath)
+			m.imageMu.Unlock()
+		}()
+
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err := download(ctx); err != nil {
+			// Log error but don't fail the download
+			if logFile, logErr := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); logErr == nil {
+				fmt.Fprintf(logFile, "[%s] Failed to download %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), label, err)
+				logFile.Close()
+			}
+		}
+	}()
+}
+
+// acquireMetadataSlot waits for a free metadata slot, and for FLAC files also
+// for exclusive access since go-flac rewrites the whole file
+func (m *Manager) acquireMetadataSlot(ctx context.Context, filePath string) (func(), error) {
+	m.mu.RLock()
+	sem := m.metadataSem
+	m.mu.RUnlock()
+
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	isFLAC := strings.EqualFold(filepath.Ext(filePath), ".flac")
+	if isFLAC {
+		m.flacTagMu.Lock()
+	}
+
+	return func() {
+		if isFLAC {
+			m.flacTagMu.Unlock()
+		}
+		if sem != nil {
+			<-sem
+		}
+	}, nil
+}
+
 // downloadArtworkData downloads artwork and returns the raw data
 func (m *Manager) downloadArtworkData(ctx context.Context, artworkURL string) ([]byte, string, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", artworkURL, nil)
@@ -3015,7 +4955,7 @@ func (m *Manager) downloadArtworkData(ctx context.Context, artworkURL string) ([
 		return nil, "", err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := m.imageClient().Do(req)
 	if err != nil {
 		return nil, "", err
 	}
@@ -3038,6 +4978,81 @@ func (m *Manager) downloadArtworkData(ctx context.Context, artworkURL string) ([
 	return data, mimeType, nil
 }
 
+// artistPicture downloads the artist's picture at the configured artwork size for embedding
+func (m *Manager) artistPicture(ctx context.Context, artist *api.Artist) (*metadata.Picture, error) {
+	pictureURL := artist.PictureXL
+	if pictureURL == "" && m.deezerAPI != nil {
+		// Artists nested in a track often come without pictures
+		if fullArtist, err := m.deezerAPI.GetArtist(ctx, artist.ID.String()); err == nil {
+			pictureURL = fullArtist.PictureXL
+			if pictureURL == "" {
+				pictureURL = fullArtist.PictureBig
+			}
+		}
+	}
+	if pictureURL == "" {
+		return nil, fmt.Errorf("no artist picture available")
+	}
+
+	data, mimeType, err := m.downloadArtworkData(ctx, getHighResArtworkURL(pictureURL, m.config.Download.ArtworkSize))
+	if err != nil {
+		return nil, err
+	}
+	return &metadata.Picture{Type: metadata.PictureArtist, Data: data, MIME: mimeType}, nil
+}
+
+// loadLocalArtwork looks for an existing cover image in the track's folder
+// (or the album folder above a CD folder) and returns its data and MIME type
+func (m *Manager) loadLocalArtwork(audioFilePath string, inDiscFolder bool) ([]byte, string, string, error) {
+	names := []string{}
+	if m.config.Download.AlbumCoverFilename != "" {
+		names = append(names, m.config.Download.AlbumCoverFilename)
+	}
+	names = append(names, "cover.jpg", "cover.jpeg", "cover.png")
+
+	trackDir := filepath.Dir(audioFilePath)
+	dirs := []string{trackDir}
+	if inDiscFolder {
+		dirs = append(dirs, filepath.Dir(trackDir))
+	}
+
+	for _, dir := range dirs {
+		for _, name := range names {
+			coverPath := filepath.Join(dir, name)
+			data, err := os.ReadFile(coverPath)
+			if err != nil || len(data) == 0 {
+				continue
+			}
+
+			// Sniff the content rather than trusting the extension
+			mimeType := http.DetectContentType(data)
+			if mimeType != "image/jpeg" && mimeType != "image/png" {
+				continue
+			}
+			return data, mimeType, coverPath, nil
+		}
+	}
+
+	return nil, "", "", fmt.Errorf("no local artwork found for %s", audioFilePath)
+}
+
+// fullReleaseDate returns dateStr if it is a complete YYYY-MM-DD date, or "" for year-only
+// and placeholder dates such as "0000-00-00"
+func fullReleaseDate(dateStr string) string {
+	if _, err := time.Parse("2006-01-02", dateStr); err != nil || extractYear(dateStr) == 0 {
+		return ""
+	}
+	return dateStr
+}
+
+// tidyDatelessName cleans up what a template like "{year} - {album}" or "{album} ({year})"
+// leaves behind when the date is unknown
+func tidyDatelessName(name string) string {
+	name = strings.NewReplacer("()", "", "[]", "").Replace(name)
+	name = strings.Join(strings.Fields(name), " ")
+	return strings.Trim(name, " -_")
+}
+
 // extractYear extracts the year from a date string (YYYY-MM-DD format)
 func extractYear(dateStr string) int {
 	if len(dateStr) >= 4 {
@@ -3048,30 +5063,152 @@ func extractYear(dateStr string) int {
 	return 0
 }
 
+// FetchLyricsForFile downloads lyrics for an already-downloaded file and writes the .lrc
+// and/or embeds them according to the lyrics settings, without touching the audio
+func (m *Manager) FetchLyricsForFile(ctx context.Context, filePath string, trackID string) error {
+	if trackID == "" {
+		return fmt.Errorf("track ID cannot be empty")
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("audio file not found: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("path is a directory: %s", filePath)
+	}
+
+	if !m.lyricsWanted() {
+		return fmt.Errorf("lyrics are disabled in settings")
+	}
+
+	return m.downloadAndSaveLyrics(ctx, filePath, trackID)
+}
+
 // downloadAndSaveLyrics downloads and saves lyrics for a track
-func (m *Manager) downloadAndSaveLyrics(ctx context.Context, audioFilePath string, track *api.Track) error {
-	// Get lyrics from API
-	lyrics, err := m.deezerAPI.GetLyrics(ctx, track.ID.String())
+func (m *Manager) downloadAndSaveLyrics(ctx context.Context, audioFilePath string, trackID string) error {
+	// Get lyrics from API, retrying transient failures
+	lyrics, err := fetchLyricsWithRetry(ctx, m.config.Lyrics.FetchRetries, lyricsRetryDelay, func() (*api.Lyrics, error) {
+		return m.deezerAPI.GetLyrics(ctx, trackID)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to get lyrics: %w", err)
 	}
 
-	// Check if synced lyrics are available
-	if lyrics.SyncedLyrics == "" {
-		return nil // No lyrics available, not an error
+	// Write synced lyrics to a sidecar (.lrc, or .srt for the srt format) if enabled
+	if m.config.Lyrics.SaveSyncedFile && lyrics.SyncedLyrics != "" {
+		sidecar, ext, err := metadata.NewManager(&metadata.Config{}).ConvertSyncedLyrics(lyrics.SyncedLyrics, m.config.Lyrics.SyncedFormat)
+		if err != nil {
+			return fmt.Errorf("failed to convert lyrics: %w", err)
+		}
+
+		// Same directory and name as the audio file
+		lyricsPath := strings.TrimSuffix(audioFilePath, filepath.Ext(audioFilePath)) + ext
+
+		// Write lyrics to file
+		if err := os.WriteFile(lyricsPath, []byte(sidecar), 0644); err != nil {
+			return fmt.Errorf("failed to write lyrics file: %w", err)
+		}
+	}
+
+	// Embed lyrics in the audio file (SYLT/USLT for MP3, Vorbis comments for FLAC)
+	embedded := &metadata.Lyrics{Language: lyricsLanguage(m.config.Lyrics.Language)}
+	if m.config.Lyrics.EmbedInFile && m.config.Lyrics.EmbedSynced {
+		embedded.SyncedLyrics = lyrics.SyncedLyrics
+	}
+	if m.config.Lyrics.EmbedInFile && m.config.Lyrics.EmbedUnsynced {
+		embedded.UnsyncedLyrics = lyrics.UnsyncedLyrics
+	}
+	if embedded.SyncedLyrics == "" && embedded.UnsyncedLyrics == "" {
+		return nil // Nothing to embed, not an error
 	}
 
-	// Determine lyrics file path (same directory and name as audio file, but with .lrc extension)
-	lyricsPath := strings.TrimSuffix(audioFilePath, filepath.Ext(audioFilePath)) + ".lrc"
+	release, err := m.acquireMetadataSlot(ctx, audioFilePath)
+	if err != nil {
+		return err
+	}
+	defer release()
 
-	// Write lyrics to file
-	if err := os.WriteFile(lyricsPath, []byte(lyrics.SyncedLyrics), 0644); err != nil {
-		return fmt.Errorf("failed to write lyrics file: %w", err)
+	metadataManager := metadata.NewManager(&metadata.Config{ID3Version: m.id3Version()})
+	if err := metadataManager.EmbedLyrics(audioFilePath, embedded, &metadata.LyricsConfig{
+		EmbedInFile: true,
+		Language:    embedded.Language,
+	}); err != nil {
+		return fmt.Errorf("failed to embed lyrics: %w", err)
 	}
 
 	return nil
 }
 
+// lyricsSidecarExts are the extensions a synced lyrics sidecar can have, depending on lyrics.synced_format
+var lyricsSidecarExts = []string{".lrc", ".srt"}
+
+// lyricsRetryDelay is the base delay between lyrics fetch attempts (grows linearly per attempt)
+const lyricsRetryDelay = 2 * time.Second
+
+// fetchLyricsWithRetry calls fetch up to retries+1 times, waiting between failed attempts.
+// Lyrics retries are independent of track retries so a lyrics hiccup never re-downloads audio.
+func fetchLyricsWithRetry(ctx context.Context, retries int, delay time.Duration, fetch func() (*api.Lyrics, error)) (*api.Lyrics, error) {
+	if retries < 0 {
+		retries = 0
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(time.Duration(attempt) * delay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		lyrics, err := fetch()
+		if err == nil {
+			return lyrics, nil
+		}
+		lastErr = err
+
+		if logFile, logErr := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); logErr == nil {
+			fmt.Fprintf(logFile, "[%s] Lyrics fetch attempt %d/%d failed: %v\n", time.Now().Format("2006-01-02 15:04:05"), attempt+1, retries+1, err)
+			logFile.Close()
+		}
+	}
+
+	return nil, lastErr
+}
+
+// lyricsWanted reports whether any lyrics output (sidecar or embedded) is enabled
+func (m *Manager) lyricsWanted() bool {
+	lyricsCfg := m.config.Lyrics
+	embed := lyricsCfg.EmbedInFile && (lyricsCfg.EmbedSynced || lyricsCfg.EmbedUnsynced)
+	return lyricsCfg.Enabled && (lyricsCfg.SaveSyncedFile || embed)
+}
+
+// lyricsLanguage converts the configured language to the 3-letter code ID3 lyrics frames require
+func lyricsLanguage(language string) string {
+	switch strings.ToLower(language) {
+	case "", "en", "eng":
+		return "eng"
+	case "fr", "fra", "fre":
+		return "fra"
+	case "de", "deu", "ger":
+		return "deu"
+	case "es", "spa":
+		return "spa"
+	case "it", "ita":
+		return "ita"
+	case "pt", "por":
+		return "por"
+	case "nl", "nld", "dut":
+		return "nld"
+	}
+	if len(language) == 3 {
+		return strings.ToLower(language)
+	}
+	return "eng"
+}
+
 // getHighResArtworkURL modifies a Deezer cover URL to request a specific size
 func getHighResArtworkURL(coverURL string, size int) string {
 	// Deezer cover URLs are in format: https://e-cdns-images.dzcdn.net/images/cover/{hash}/{size}x{size}.jpg
@@ -0,0 +1,1643 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bogem/id3v2/v2"
+	"github.com/deemusic/deemusic-go/internal/api"
+	"github.com/deemusic/deemusic-go/internal/config"
+	"github.com/deemusic/deemusic-go/internal/metadata"
+	"github.com/deemusic/deemusic-go/internal/store"
+)
+
+func TestLoadLocalArtwork(t *testing.T) {
+	tmpDir := t.TempDir()
+	discDir := filepath.Join(tmpDir, "CD 1")
+	if err := os.MkdirAll(discDir, 0755); err != nil {
+		t.Fatalf("Failed to create disc folder: %v", err)
+	}
+
+	// Minimal PNG signature is enough for content sniffing
+	pngData := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	if err := os.WriteFile(filepath.Join(tmpDir, "cover.jpg"), pngData, 0644); err != nil {
+		t.Fatalf("Failed to write cover: %v", err)
+	}
+
+	m := &Manager{config: &config.Config{}}
+	trackPath := filepath.Join(discDir, "01 - Artist - Title.mp3")
+
+	// Cover lives in the album folder, so it's only found from inside a CD folder
+	if _, _, _, err := m.loadLocalArtwork(trackPath, false); err == nil {
+		t.Error("Expected no artwork without searching the album folder")
+	}
+
+	data, mimeType, _, err := m.loadLocalArtwork(trackPath, true)
+	if err != nil {
+		t.Fatalf("Expected artwork from album folder: %v", err)
+	}
+	if mimeType != "image/png" {
+		t.Errorf("Expected image/png, got %s", mimeType)
+	}
+	if len(data) != len(pngData) {
+		t.Errorf("Expected %d bytes, got %d", len(pngData), len(data))
+	}
+}
+
+func TestAcquireMetadataSlotSerializesFLAC(t *testing.T) {
+	m := &Manager{
+		config:      &config.Config{},
+		metadataSem: make(chan struct{}, 4),
+	}
+	ctx := context.Background()
+
+	release, err := m.acquireMetadataSlot(ctx, "a.flac")
+	if err != nil {
+		t.Fatalf("Failed to acquire slot: %v", err)
+	}
+
+	// A second FLAC apply must wait for the first even though slots are free
+	acquired := make(chan struct{})
+	go func() {
+		release2, err := m.acquireMetadataSlot(ctx, "b.flac")
+		if err == nil {
+			release2()
+		}
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Second FLAC apply should wait for the first")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// MP3 tagging is only bounded by the semaphore
+	releaseMP3, err := m.acquireMetadataSlot(ctx, "c.mp3")
+	if err != nil {
+		t.Fatalf("Failed to acquire MP3 slot: %v", err)
+	}
+	releaseMP3()
+
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("Second FLAC apply never acquired its slot")
+	}
+}
+
+func TestLyricsLanguage(t *testing.T) {
+	tests := map[string]string{
+		"":    "eng",
+		"en":  "eng",
+		"de":  "deu",
+		"FRA": "fra",
+		"jpn": "jpn",
+		"xx":  "eng",
+	}
+
+	for input, expected := range tests {
+		if got := lyricsLanguage(input); got != expected {
+			t.Errorf("lyricsLanguage(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestFetchLyricsWithRetry(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	lyrics, err := fetchLyricsWithRetry(ctx, 2, time.Millisecond, func() (*api.Lyrics, error) {
+		calls++
+		if calls < 3 {
+			return nil, errors.New("temporary failure")
+		}
+		return &api.Lyrics{TrackID: "1", SyncedLyrics: "[00:01.00]Hello"}, nil
+	})
+	if err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if calls != 3 || lyrics.SyncedLyrics == "" {
+		t.Errorf("Expected 3 calls and lyrics, got %d calls", calls)
+	}
+
+	calls = 0
+	_, err = fetchLyricsWithRetry(ctx, 1, time.Millisecond, func() (*api.Lyrics, error) {
+		calls++
+		return nil, errors.New("still failing")
+	})
+	if err == nil {
+		t.Error("Expected error after retries are exhausted")
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls)
+	}
+
+	// Cancellation stops waiting between attempts
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	calls = 0
+	_, err = fetchLyricsWithRetry(cancelCtx, 3, time.Hour, func() (*api.Lyrics, error) {
+		calls++
+		return nil, errors.New("failure")
+	})
+	if err != context.Canceled || calls != 1 {
+		t.Errorf("Expected cancellation after first attempt, got err=%v calls=%d", err, calls)
+	}
+}
+
+func TestDownloadTrackList(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &config.Config{}
+	cfg.Download.VariousArtistsName = "Various Artists"
+	queueStore := store.NewQueueStore(db)
+	mgr := NewManager(cfg, queueStore, nil, nil)
+
+	ids := []string{"101", " 102 ", "101", "", "103"}
+	if err := mgr.DownloadTrackList(context.Background(), ids, "CSV Import"); err != nil {
+		t.Fatalf("DownloadTrackList failed: %v", err)
+	}
+
+	expected := []string{"101", "102", "103"}
+	itemID := "playlist_" + trackListID(expected)
+	item, err := queueStore.GetByID(itemID)
+	if err != nil {
+		t.Fatalf("Expected parent %s to be queued: %v", itemID, err)
+	}
+	if item.Title != "CSV Import" || item.TotalTracks != 3 || item.Status != "pending" {
+		t.Errorf("Unexpected parent: title=%q total=%d status=%s", item.Title, item.TotalTracks, item.Status)
+	}
+
+	var metadata map[string]interface{}
+	if err := item.GetMetadata(&metadata); err != nil {
+		t.Fatalf("Failed to read metadata: %v", err)
+	}
+	if metadata["is_custom"] != true {
+		t.Error("Expected track list to be stored as a custom playlist")
+	}
+	tracks, _ := metadata["custom_tracks"].([]interface{})
+	if len(tracks) != 3 || tracks[1] != "102" {
+		t.Errorf("Expected deduped track IDs, got %v", tracks)
+	}
+
+	// The same list is detected while it is still queued
+	if err := mgr.DownloadTrackList(context.Background(), expected, "Again"); err == nil {
+		t.Error("Expected duplicate track list to be rejected while pending")
+	}
+
+	if err := mgr.DownloadTrackList(context.Background(), []string{" ", ""}, ""); err == nil {
+		t.Error("Expected error for empty track list")
+	}
+}
+
+func TestDownloadCustomPlaylistDropsDuplicates(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	queueStore := store.NewQueueStore(db)
+	mgr := NewManager(&config.Config{}, queueStore, nil, nil)
+
+	playlistJSON := `{"id": "mix", "title": "Mix", "track_ids": ["1", "2", "1", "3", "2"]}`
+	if err := mgr.DownloadCustomPlaylist(context.Background(), playlistJSON); err != nil {
+		t.Fatalf("DownloadCustomPlaylist failed: %v", err)
+	}
+
+	item, err := queueStore.GetByID("playlist_mix")
+	if err != nil {
+		t.Fatalf("Expected playlist to be queued: %v", err)
+	}
+	if item.TotalTracks != 3 {
+		t.Errorf("Expected 3 unique tracks, got %d", item.TotalTracks)
+	}
+}
+
+func TestFetchLyricsForFileValidation(t *testing.T) {
+	cfg := &config.Config{}
+	mgr := NewManager(cfg, nil, nil, nil)
+	ctx := context.Background()
+
+	if err := mgr.FetchLyricsForFile(ctx, filepath.Join(t.TempDir(), "missing.mp3"), "123"); err == nil {
+		t.Error("Expected error for missing file")
+	}
+
+	filePath := filepath.Join(t.TempDir(), "song.mp3")
+	if err := os.WriteFile(filePath, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := mgr.FetchLyricsForFile(ctx, filePath, ""); err == nil {
+		t.Error("Expected error for empty track ID")
+	}
+
+	// Lyrics disabled: nothing to write, so the API is never called
+	if err := mgr.FetchLyricsForFile(ctx, filePath, "123"); err == nil {
+		t.Error("Expected error when lyrics are disabled")
+	}
+}
+
+func TestResumeInterruptedAlbum(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	db.SetMaxOpenConns(25) // Same pool size as the app; FixStuckAlbums queries while iterating rows
+
+	cfg := &config.Config{}
+	cfg.Network.MaxRetries = 3
+	queueStore := store.NewQueueStore(db)
+
+	// Crash at 5/12: five tracks done, one mid-download, six never created
+	album := &store.QueueItem{ID: "album_1", Type: "album", Title: "Album", Status: "downloading", TotalTracks: 12, CompletedTracks: 5}
+	if err := queueStore.Add(album); err != nil {
+		t.Fatalf("Failed to add album: %v", err)
+	}
+	for i := 1; i <= 6; i++ {
+		status := "completed"
+		if i == 6 {
+			status = "downloading"
+		}
+		track := &store.QueueItem{ID: fmt.Sprintf("track_1_%d", i), Type: "track", Status: status, ParentID: "album_1"}
+		if err := queueStore.Add(track); err != nil {
+			t.Fatalf("Failed to add track: %v", err)
+		}
+	}
+
+	// A parent whose retry was lost with the crash, and one that exhausted its retries
+	queueStore.Add(&store.QueueItem{ID: "album_2", Type: "album", Status: "failed", RetryCount: 1, TotalTracks: 10, CompletedTracks: 3})
+	queueStore.Add(&store.QueueItem{ID: "album_3", Type: "album", Status: "failed", RetryCount: 4, TotalTracks: 10})
+
+	// Restart, with the stuck album cleanup after the resume as InitializeApp runs it
+	mgr := NewManager(cfg, queueStore, nil, nil)
+	mgr.resetInterruptedDownloads()
+	if _, err := queueStore.FixStuckAlbums(); err != nil {
+		t.Fatalf("FixStuckAlbums failed: %v", err)
+	}
+
+	item, _ := queueStore.GetByID("album_1")
+	if item.Status != "pending" || item.CompletedTracks != 5 || item.Progress != 41 {
+		t.Errorf("Expected album_1 re-enqueued at 5/12, got status=%s completed=%d progress=%d", item.Status, item.CompletedTracks, item.Progress)
+	}
+	if track, _ := queueStore.GetByID("track_1_6"); track.Status != "pending" {
+		t.Errorf("Expected interrupted track to be reset to pending, got %s", track.Status)
+	}
+	if item, _ := queueStore.GetByID("album_2"); item.Status != "pending" {
+		t.Errorf("Expected album_2 with retries left to be re-enqueued, got %s", item.Status)
+	}
+	if item, _ := queueStore.GetByID("album_3"); item.Status != "failed" {
+		t.Errorf("Expected permanently failed album_3 to stay failed, got %s", item.Status)
+	}
+
+	// The re-run album job resubmits every track; the remaining seven complete
+	track, _ := queueStore.GetByID("track_1_6")
+	track.Status = "completed"
+	queueStore.Update(track)
+	for i := 7; i <= 12; i++ {
+		queueStore.Add(&store.QueueItem{ID: fmt.Sprintf("track_1_%d", i), Type: "track", Status: "completed", ParentID: "album_1"})
+	}
+	mgr.updateParentProgress("album_1")
+
+	item, _ = queueStore.GetByID("album_1")
+	if item.Status != "completed" || item.CompletedTracks != 12 || item.Progress != 100 {
+		t.Errorf("Expected album_1 completed at 12/12, got status=%s completed=%d progress=%d", item.Status, item.CompletedTracks, item.Progress)
+	}
+}
+
+func TestResumeStaleInterruptedAlbum(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	db.SetMaxOpenConns(25) // Same pool size as the app; FixStuckAlbums queries while iterating rows
+
+	cfg := &config.Config{}
+	cfg.Network.MaxRetries = 3
+	queueStore := store.NewQueueStore(db)
+
+	// Crashed mid-submission: four of twelve tracks created and finished, then the app
+	// stayed closed for longer than FixStuckAlbums' staleness window
+	queueStore.Add(&store.QueueItem{ID: "album_1", Type: "album", Title: "Album", Status: "downloading", TotalTracks: 12, CompletedTracks: 4})
+	for i := 1; i <= 4; i++ {
+		queueStore.Add(&store.QueueItem{ID: fmt.Sprintf("track_1_%d", i), Type: "track", Status: "completed", ParentID: "album_1"})
+	}
+	if _, err := db.Exec("UPDATE queue_items SET updated_at = ? WHERE id = 'album_1'", time.Now().Add(-10*time.Minute)); err != nil {
+		t.Fatalf("Failed to age album: %v", err)
+	}
+
+	mgr := NewManager(cfg, queueStore, nil, nil)
+	mgr.resetInterruptedDownloads()
+	fixed, err := queueStore.FixStuckAlbums()
+	if err != nil {
+		t.Fatalf("FixStuckAlbums failed: %v", err)
+	}
+
+	item, _ := queueStore.GetByID("album_1")
+	if fixed != 0 || item.Status != "pending" || item.CompletedTracks != 4 {
+		t.Errorf("Expected the stale album re-enqueued at 4/12, got status=%s completed=%d (fixed %d)", item.Status, item.CompletedTracks, fixed)
+	}
+}
+
+func TestSortAlbumTracks(t *testing.T) {
+	order := func(tracks []*api.Track) string {
+		result := ""
+		for _, track := range tracks {
+			result += fmt.Sprintf("%d.%d ", track.DiscNumber, track.TrackNumber)
+		}
+		return result
+	}
+
+	tracks := []*api.Track{
+		{DiscNumber: 2, TrackNumber: 1},
+		{DiscNumber: 1, TrackNumber: 2},
+		{DiscNumber: 1, TrackNumber: 1},
+		{DiscNumber: 2, TrackNumber: 2},
+	}
+	sortAlbumTracks(tracks)
+	if got := order(tracks); got != "1.1 1.2 2.1 2.2 " {
+		t.Errorf("Expected disc/track order, got %s", got)
+	}
+
+	// Single disc without disc numbers is sorted by track number
+	tracks = []*api.Track{{TrackNumber: 3}, {TrackNumber: 1}, {TrackNumber: 2}}
+	sortAlbumTracks(tracks)
+	if got := order(tracks); got != "0.1 0.2 0.3 " {
+		t.Errorf("Expected track order, got %s", got)
+	}
+
+	// Unknown discs with repeating track numbers keep the API order
+	tracks = []*api.Track{{TrackNumber: 1}, {TrackNumber: 2}, {TrackNumber: 1}, {TrackNumber: 2}}
+	sortAlbumTracks(tracks)
+	if got := order(tracks); got != "0.1 0.2 0.1 0.2 " {
+		t.Errorf("Expected API order to be kept, got %s", got)
+	}
+}
+
+func TestOrderAlbumTracks(t *testing.T) {
+	order := func(tracks []*api.Track) string {
+		var s string
+		for _, track := range tracks {
+			s += fmt.Sprintf("%d.%d ", track.DiscNumber, track.TrackNumber)
+		}
+		return s
+	}
+	interleaved := func() []*api.Track {
+		return []*api.Track{{DiscNumber: 1, TrackNumber: 2}, {DiscNumber: 2, TrackNumber: 1}, {DiscNumber: 1, TrackNumber: 1}}
+	}
+
+	for _, tc := range []struct {
+		order, want string
+	}{
+		{"track", "1.1 1.2 2.1 "},
+		{"", "1.1 1.2 2.1 "},
+		{"reverse", "2.1 1.2 1.1 "},
+		{"as_is", "1.2 2.1 1.1 "},
+	} {
+		tracks := interleaved()
+		orderAlbumTracks(tracks, tc.order)
+		if got := order(tracks); got != tc.want {
+			t.Errorf("order %q: expected %s, got %s", tc.order, tc.want, got)
+		}
+	}
+}
+
+func TestOrderedAlbumTracksLeavesAlbum(t *testing.T) {
+	album := &api.Album{Tracks: &api.Tracks{Data: []*api.Track{
+		{ID: "2", DiscNumber: 1, TrackNumber: 2},
+		{ID: "3", DiscNumber: 2, TrackNumber: 1},
+		{ID: "1", DiscNumber: 1, TrackNumber: 1},
+	}}}
+
+	tracks := orderedAlbumTracks(album, "track")
+	if len(tracks) != 3 || tracks[0].ID != "1" || tracks[1].ID != "2" || tracks[2].ID != "3" {
+		t.Errorf("Expected tracks in disc/track order, got %v", tracks)
+	}
+	if data := album.Tracks.Data; data[0].ID != "2" || data[1].ID != "3" || data[2].ID != "1" {
+		t.Errorf("Expected the cached album's tracks to keep Deezer's order, got %v", data)
+	}
+}
+
+func TestMissingAlbumTracks(t *testing.T) {
+	tracks := []*api.Track{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+	children := []*store.QueueItem{
+		{ID: "track_9_1", ParentID: "album_9"},
+		{ID: "track_9_3", ParentID: "album_9"},
+	}
+
+	missing := missingAlbumTracks("9", tracks, children)
+	if len(missing) != 1 || missing[0].ID != "2" {
+		t.Errorf("Expected only track 2 to be missing, got %v", missing)
+	}
+
+	if missing := missingAlbumTracks("9", tracks[:1], children); len(missing) != 0 {
+		t.Errorf("Expected nothing missing when every track is queued, got %d", len(missing))
+	}
+}
+
+func TestQueueImageDownload(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.ImageConcurrency = 1
+	mgr := NewManager(cfg, nil, nil, nil)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	release := make(chan struct{})
+	started := make(chan string, 4)
+	download := func(name string) func(context.Context) error {
+		return func(ctx context.Context) error {
+			started <- name
+			<-release
+			return os.WriteFile(filepath.Join(dir, name), []byte("img"), 0644)
+		}
+	}
+
+	mgr.queueImageDownload(ctx, filepath.Join(dir, "cover.jpg"), "first", download("cover.jpg"))
+	mgr.queueImageDownload(ctx, filepath.Join(dir, "cover.jpg"), "duplicate", download("cover.jpg"))
+	mgr.queueImageDownload(ctx, filepath.Join(dir, "folder.jpg"), "second", download("folder.jpg"))
+
+	// Only one image runs at a time
+	<-started
+	select {
+	case name := <-started:
+		t.Fatalf("Expected second download to wait for a free slot, but %s started", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected second download to start after the first finished")
+	}
+
+	// The duplicate for cover.jpg was skipped
+	select {
+	case name := <-started:
+		t.Errorf("Expected duplicate to be skipped, but %s started again", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Existing files are not downloaded again
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mgr.imageMu.Lock()
+		pending := len(mgr.imageQueued)
+		mgr.imageMu.Unlock()
+		if pending == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mgr.queueImageDownload(ctx, filepath.Join(dir, "cover.jpg"), "existing", download("cover.jpg"))
+	select {
+	case <-started:
+		t.Error("Expected existing file to be skipped")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestImageClientShared(t *testing.T) {
+	cfg := &config.Config{}
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	client := mgr.imageClient()
+	if client != mgr.imageClient() {
+		t.Fatal("Expected image downloads to share one client")
+	}
+	if conns := client.Transport.(*http.Transport).MaxConnsPerHost; conns != 4 {
+		t.Errorf("Expected the default cap of 4 connections per host, got %d", conns)
+	}
+
+	// Unrelated settings keep the client and its pooled connections
+	mgr.UpdateConfig(&config.Config{})
+	if mgr.imageClient() != client {
+		t.Error("Expected the client to be kept when image settings don't change")
+	}
+
+	newCfg := &config.Config{}
+	newCfg.Network.ImageConnsPerHost = 2
+	mgr.UpdateConfig(newCfg)
+	if conns := mgr.imageClient().Transport.(*http.Transport).MaxConnsPerHost; conns != 2 {
+		t.Errorf("Expected the new cap to apply, got %d", conns)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cover.jpg")
+	if err := writeFileAtomic(path, strings.NewReader("image data")); err != nil {
+		t.Fatalf("writeFileAtomic failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "image data" {
+		t.Errorf("Expected written data, got %q (%v)", data, err)
+	}
+	if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+		t.Error("Expected temporary file to be removed")
+	}
+}
+
+func TestFailedAlbumJobMarksParentFailed(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &config.Config{}
+	cfg.Download.ConcurrentDownloads = 1
+	cfg.Network.MaxRetries = 3
+	queueStore := store.NewQueueStore(db)
+
+	// An empty album ID makes GetAlbum fail without touching the network
+	album := &store.QueueItem{ID: "album_", Type: "album", Title: "Bad Album", Status: "pending"}
+	if err := queueStore.Add(album); err != nil {
+		t.Fatalf("Failed to add album: %v", err)
+	}
+
+	failed := make(chan string, 1)
+	notifier := NewCallbackNotifier()
+	notifier.SetStatusCallback(func(itemID string, status string, errorMsg string) {
+		if status == "failed" {
+			failed <- itemID
+		}
+	})
+
+	mgr := NewManager(cfg, queueStore, api.NewDeezerClient(time.Second), notifier)
+	if err := mgr.workerPool.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start worker pool: %v", err)
+	}
+	defer mgr.workerPool.Stop()
+	go mgr.processResults(mgr.workerPool.Results(), nil)
+
+	if err := mgr.workerPool.Submit(&Job{ID: album.ID, Type: JobTypeAlbum, AlbumID: ""}); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+
+	select {
+	case itemID := <-failed:
+		if itemID != album.ID {
+			t.Errorf("Expected failure for %s, got %s", album.ID, itemID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for album failure notification")
+	}
+
+	item, err := queueStore.GetByID(album.ID)
+	if err != nil {
+		t.Fatalf("Failed to get album: %v", err)
+	}
+	if item.Status != "failed" {
+		t.Errorf("Expected album status failed, got %s", item.Status)
+	}
+	if !strings.Contains(item.ErrorMessage, "failed to get album details") {
+		t.Errorf("Expected album error message to be recorded, got %q", item.ErrorMessage)
+	}
+}
+
+func TestTruncateNameBytes(t *testing.T) {
+	title := strings.Repeat("日本語", 10) // 90 bytes, 3 per rune
+
+	got := truncateNameBytes(title, 20)
+	if len(got) > 20 || !utf8.ValidString(got) {
+		t.Errorf("truncateNameBytes() = %q (%d bytes), want valid UTF-8 within 20 bytes", got, len(got))
+	}
+	if got != "日本語日本語" {
+		t.Errorf("Expected cut on a rune boundary, got %q", got)
+	}
+
+	if got := truncateNameBytes("Short", 20); got != "Short" {
+		t.Errorf("Expected short names unchanged, got %q", got)
+	}
+}
+
+func TestBuildOutputPathLongMultiByteTitle(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = t.TempDir()
+	cfg.Download.MaxFilenameBytes = 255
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	track := &api.Track{
+		ID:          "1",
+		Title:       strings.Repeat("長い曲名", 50), // 600 bytes
+		TrackNumber: 1,
+		Artist:      &api.Artist{Name: "Artist"},
+		Album:       &api.Album{ID: "1", Title: strings.Repeat("アルバム", 40)},
+	}
+
+	outputPath := mgr.buildOutputPath(track, "FLAC")
+	rel, err := filepath.Rel(cfg.Download.OutputDir, outputPath)
+	if err != nil {
+		t.Fatalf("Output path %s is outside the output dir: %v", outputPath, err)
+	}
+
+	if strings.HasPrefix(filepath.Base(rel), "track_") {
+		t.Fatalf("Expected structured path, fell back to flat naming: %s", rel)
+	}
+	if !strings.HasSuffix(rel, ".flac") {
+		t.Errorf("Expected .flac extension to survive truncation, got %s", rel)
+	}
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		if len(part) > 255 || !utf8.ValidString(part) {
+			t.Errorf("Path component %q is %d bytes or invalid UTF-8", part, len(part))
+		}
+	}
+}
+
+func TestBuildOutputPathYearTemplate(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = t.TempDir()
+	cfg.Download.AlbumFolderTemplate = "{year} - {album}"
+	cfg.Download.AlbumTrackTemplate = "{track_number:02d} - {title} [{date}]"
+	cfg.Download.CustomTrackFilenames = true
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	// Album folders are cached per album ID across the package, so each case uses its own album
+	tests := []struct {
+		albumID     string
+		album       string
+		releaseDate string
+		wantFolder  string
+		wantFile    string
+	}{
+		{"year-1", "Full Date", "2011-03-07", "2011 - Full Date", "03 - Title [2011-03-07].mp3"},
+		{"year-2", "Year Only", "2011", "2011 - Year Only", "03 - Title [2011].mp3"},
+		{"year-3", "No Date", "", "No Date", "03 - Title.mp3"},
+		{"year-4", "Zero Date", "0000-00-00", "Zero Date", "03 - Title.mp3"},
+	}
+
+	for _, tt := range tests {
+		track := &api.Track{
+			ID:          "1",
+			Title:       "Title",
+			TrackNumber: 3,
+			Artist:      &api.Artist{Name: "Artist"},
+			Album:       &api.Album{ID: api.FlexibleID(tt.albumID), Title: tt.album, ReleaseDate: tt.releaseDate},
+		}
+
+		outputPath := mgr.buildOutputPath(track, "MP3_320")
+		if folder := filepath.Base(filepath.Dir(outputPath)); folder != tt.wantFolder {
+			t.Errorf("release date %q: expected folder %q, got %q", tt.releaseDate, tt.wantFolder, folder)
+		}
+		if file := filepath.Base(outputPath); file != tt.wantFile {
+			t.Errorf("release date %q: expected file %q, got %q", tt.releaseDate, tt.wantFile, file)
+		}
+	}
+
+	// Another album of the same name and year can't be told apart by a year suffix
+	track := &api.Track{
+		ID:          "2",
+		Title:       "Title",
+		TrackNumber: 3,
+		Artist:      &api.Artist{Name: "Artist"},
+		Album:       &api.Album{ID: "year-5", Title: "Full Date", ReleaseDate: "2011-09-01"},
+	}
+	if folder := filepath.Base(filepath.Dir(mgr.buildOutputPath(track, "MP3_320"))); folder != "2011 - Full Date (year-5)" {
+		t.Errorf("Expected the album ID suffix, got %q", folder)
+	}
+}
+
+func TestBuildOutputPathNestedTemplates(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = t.TempDir()
+	cfg.Download.ArtistFolderTemplate = "Music/{album_artist}"
+	cfg.Download.AlbumFolderTemplate = "{year}/{year} - {album}"
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	tests := []struct {
+		albumID     string
+		album       string
+		releaseDate string
+		want        string
+	}{
+		{"nested-1", "Discovery", "2001-03-07", "Music/Daft Punk/2001/2001 - Discovery/01 - Daft Punk - One More Time.mp3"},
+		// Levels that come out empty are dropped rather than creating blank folders
+		{"nested-2", "Homework", "", "Music/Daft Punk/Homework/01 - Daft Punk - One More Time.mp3"},
+		// Slashes in values never add levels
+		{"nested-3", "Alive 1997/2007", "2007-11-19", "Music/Daft Punk/2007/2007 - Alive 1997_2007/01 - Daft Punk - One More Time.mp3"},
+	}
+
+	for _, tt := range tests {
+		track := &api.Track{
+			ID:          "1",
+			Title:       "One More Time",
+			TrackNumber: 1,
+			Artist:      &api.Artist{Name: "Daft Punk"},
+			AlbumArtist: "Daft Punk",
+			Album:       &api.Album{ID: api.FlexibleID(tt.albumID), Title: tt.album, ReleaseDate: tt.releaseDate},
+		}
+
+		rel, err := filepath.Rel(cfg.Download.OutputDir, mgr.resolveOutputPath(track, "MP3_320"))
+		if err != nil || filepath.ToSlash(rel) != tt.want {
+			t.Errorf("album %q: expected %q, got %q", tt.album, tt.want, rel)
+		}
+	}
+
+	track := &api.Track{Artist: &api.Artist{Name: "Daft Punk"}, AlbumArtist: "Daft Punk", Album: &api.Album{Title: "Discovery"}}
+	if got := filepath.ToSlash(mgr.artistFolderPath(track)); got != "Music/Daft Punk" {
+		t.Errorf("Expected artist folder Music/Daft Punk, got %q", got)
+	}
+
+	// Empty templates keep the Artist/Album layout
+	cfg.Download.ArtistFolderTemplate = ""
+	cfg.Download.AlbumFolderTemplate = ""
+	track.ID = "1"
+	track.Title = "One More Time"
+	track.TrackNumber = 1
+	track.Album.ID = "nested-4"
+	rel, _ := filepath.Rel(cfg.Download.OutputDir, mgr.resolveOutputPath(track, "MP3_320"))
+	if filepath.ToSlash(rel) != "Daft Punk/Discovery/01 - Daft Punk - One More Time.mp3" {
+		t.Errorf("Expected the default layout, got %q", rel)
+	}
+}
+
+func TestBuildOutputPathCompilationArtistFolder(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = t.TempDir()
+	// The desktop app's default artist folder and album track templates; the latter only
+	// applies with custom_track_filenames
+	cfg.Download.ArtistFolderTemplate = "{artist}"
+	cfg.Download.AlbumTrackTemplate = "{track_number:02d} - {album_artist} - {title}"
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	for i, artist := range []string{"Artist One", "Artist Two"} {
+		track := &api.Track{
+			ID:          api.FlexibleID(fmt.Sprintf("%d", i+1)),
+			Title:       "Title",
+			TrackNumber: i + 1,
+			Artist:      &api.Artist{Name: artist},
+			AlbumArtist: "Various Artists",
+			Album:       &api.Album{ID: "compilation-1", Title: "Hits"},
+		}
+
+		rel, _ := filepath.Rel(cfg.Download.OutputDir, mgr.resolveOutputPath(track, "MP3_320"))
+		want := fmt.Sprintf("Various Artists/Hits/%02d - %s - Title.mp3", i+1, artist)
+		if filepath.ToSlash(rel) != want {
+			t.Errorf("Expected %q, got %q", want, rel)
+		}
+	}
+}
+
+func TestBuildOutputPathPlaylistFlat(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = t.TempDir()
+	cfg.Download.CreatePlaylistFolder = true
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	track := &api.Track{
+		ID:               "1",
+		Title:            "Title",
+		TrackNumber:      4,
+		PlaylistPosition: 1,
+		Artist:           &api.Artist{Name: "Artist"},
+		Album:            &api.Album{ID: "flat-1", Title: "Album"},
+		Playlist:         &api.Playlist{ID: "1", Title: "Crate"},
+	}
+
+	expected := filepath.Join(cfg.Download.OutputDir, "Various Artists", "Crate", "01 - Artist - Title.mp3")
+	if got := mgr.buildOutputPath(track, "MP3_320"); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	cfg.Download.PlaylistFlat = true
+	expected = filepath.Join(cfg.Download.OutputDir, "Crate", "01 - Artist - Title.mp3")
+	if got := mgr.buildOutputPath(track, "MP3_320"); got != expected {
+		t.Errorf("Expected %s with playlist_flat, got %s", expected, got)
+	}
+}
+
+func TestBuildOutputPathSinglesFolder(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = t.TempDir()
+	cfg.Download.GroupArtistSingles = true
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	single := &api.Track{
+		ID:          "1",
+		Title:       "Title",
+		TrackNumber: 1,
+		Artist:      &api.Artist{Name: "Artist"},
+		AlbumArtist: "Artist",
+		Album:       &api.Album{ID: "single-1", Title: "Title", RecordType: "single", ReleaseDate: "2021-06-04"},
+	}
+	ownFolder := filepath.Join(cfg.Download.OutputDir, "Artist", "Title", "01 - Artist - Title.mp3")
+	if got := mgr.buildOutputPath(single, "MP3_320"); got != ownFolder {
+		t.Errorf("Expected a single downloaded on its own in its own folder at %s, got %s", ownFolder, got)
+	}
+
+	cacheAlbumDiscography("single-1", true)
+	defer cacheAlbumDiscography("single-1", false)
+	expected := filepath.Join(cfg.Download.OutputDir, "Artist", "Singles", "2021 - Artist - Title.mp3")
+	if got := mgr.buildOutputPath(single, "MP3_320"); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	// Albums keep their own folder
+	album := *single
+	album.Album = &api.Album{ID: "album-1", Title: "Album", RecordType: "album", ReleaseDate: "2021-06-04"}
+	cacheAlbumDiscography("album-1", true)
+	defer cacheAlbumDiscography("album-1", false)
+	expected = filepath.Join(cfg.Download.OutputDir, "Artist", "Album", "01 - Artist - Title.mp3")
+	if got := mgr.buildOutputPath(&album, "MP3_320"); got != expected {
+		t.Errorf("Expected an album track in its own folder at %s, got %s", expected, got)
+	}
+
+	cfg.Download.GroupArtistSingles = false
+	if got := mgr.buildOutputPath(single, "MP3_320"); got != ownFolder {
+		t.Errorf("Expected a folder per single with group_artist_singles off at %s, got %s", ownFolder, got)
+	}
+}
+
+func TestAlbumTrackTemplateAlwaysIncludeDisc(t *testing.T) {
+	cfg := &config.Config{}
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	track := &api.Track{TrackNumber: 3}
+	if got := mgr.albumTrackTemplate(track); got != config.DefaultAlbumTrackTemplate {
+		t.Errorf("Expected the template unchanged with always_include_disc off, got %s", got)
+	}
+
+	cfg.Download.AlwaysIncludeDisc = true
+	cfg.Download.CustomTrackFilenames = true
+	for _, tc := range []struct {
+		template string
+		disc     int
+		want     string
+	}{
+		{"", 0, "1-{track_number:02d} - {artist} - {title}"},
+		{"", 2, "2-{track_number:02d} - {artist} - {title}"},
+		{"{title} ({track_number})", 1, "{title} (1-{track_number})"},
+		{"{artist} - {title}", 1, "1-{track_number:02d} - {artist} - {title}"},
+		{"{disc_number}-{track_number:02d} - {title}", 2, "{disc_number}-{track_number:02d} - {title}"},
+		{"{disc_number} - {title}", 1, "{disc_number} - {title}"},
+	} {
+		cfg.Download.AlbumTrackTemplate = tc.template
+		track.DiscNumber = tc.disc
+		if got := mgr.albumTrackTemplate(track); got != tc.want {
+			t.Errorf("Template %q on disc %d: expected %s, got %s", tc.template, tc.disc, tc.want, got)
+		}
+	}
+}
+
+func TestResolveCollision(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	queueStore := store.NewQueueStore(db)
+	cfg := &config.Config{}
+	mgr := NewManager(cfg, queueStore, nil, nil)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Artist - Song.mp3")
+	if err := os.WriteFile(path, []byte("first track"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := queueStore.AddToHistory("1", "Song", "Artist", "Album", path, "MP3_320", 11); err != nil {
+		t.Fatalf("Failed to add history: %v", err)
+	}
+
+	// The same track resumes into its own file
+	if got, err := mgr.resolveCollision(path, &api.Track{ID: "1"}); err != nil || got != path {
+		t.Errorf("Expected same track to keep %s, got %s (%v)", path, got, err)
+	}
+
+	// A different track gets a numbered name by default
+	numbered := filepath.Join(dir, "Artist - Song (2).mp3")
+	if got, err := mgr.resolveCollision(path, &api.Track{ID: "2"}); err != nil || got != numbered {
+		t.Errorf("Expected %s, got %s (%v)", numbered, got, err)
+	}
+
+	cfg.Download.OnCollision = "skip"
+	if _, err := mgr.resolveCollision(path, &api.Track{ID: "2"}); !errors.Is(err, errFilenameCollision) {
+		t.Errorf("Expected errFilenameCollision, got %v", err)
+	}
+
+	cfg.Download.OnCollision = "overwrite"
+	if got, err := mgr.resolveCollision(path, &api.Track{ID: "2"}); err != nil || got != path {
+		t.Errorf("Expected overwrite to reuse %s, got %s (%v)", path, got, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected the other track's file to be removed")
+	}
+}
+
+func TestApplyMetadataTagsProfiles(t *testing.T) {
+	track := &api.Track{
+		ID:          "1",
+		Title:       "Song",
+		ISRC:        "USRC17607839",
+		TrackNumber: 3,
+		Artist:      &api.Artist{Name: "Artist"},
+		AlbumArtist: "Artist",
+		Album:       &api.Album{Title: "Album", Label: "Label", ReleaseDate: "2011-05-01"},
+	}
+
+	for _, profile := range []string{"full", "basic", "none"} {
+		t.Run(profile, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Download.TagProfile = profile
+			mgr := NewManager(cfg, nil, nil, nil)
+
+			filePath := filepath.Join(t.TempDir(), "song.mp3")
+			if err := os.WriteFile(filePath, []byte{}, 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			if err := mgr.applyMetadataTags(context.Background(), filePath, track, false); err != nil {
+				t.Fatalf("applyMetadataTags failed: %v", err)
+			}
+
+			if profile == "none" {
+				if info, err := os.Stat(filePath); err != nil || info.Size() != 0 {
+					t.Errorf("Expected file untouched with tag profile none")
+				}
+				return
+			}
+
+			tags, err := metadata.NewManager(nil).GetMetadata(filePath)
+			if err != nil {
+				t.Fatalf("GetMetadata failed: %v", err)
+			}
+			if tags.Title != "Song" || tags.Artist != "Artist" || tags.Album != "Album" {
+				t.Errorf("Expected title/artist/album written, got %+v", tags)
+			}
+
+			wantExtras := profile == "full"
+			if (tags.Year == 2011) != wantExtras || (tags.TrackNumber == 3) != wantExtras {
+				t.Errorf("Profile %s: unexpected year=%d track=%d", profile, tags.Year, tags.TrackNumber)
+			}
+		})
+	}
+}
+
+func TestApplyMetadataTagsNoAlbum(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.TagProfile = "full"
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	filePath := filepath.Join(t.TempDir(), "upload.mp3")
+	if err := os.WriteFile(filePath, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	track := &api.Track{ID: "-1", Title: "Voice Memo", Artist: &api.Artist{Name: "Me"}}
+	if err := mgr.applyMetadataTags(context.Background(), filePath, track, false); err != nil {
+		t.Fatalf("Expected a track without an album to be tagged, got %v", err)
+	}
+
+	tags, err := metadata.NewManager(nil).GetMetadata(filePath)
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	if tags.Title != "Voice Memo" || tags.Artist != "Me" || tags.Album != "Voice Memo" || tags.Year != 0 {
+		t.Errorf("Expected the title to stand in for the album, got %+v", tags)
+	}
+
+	// The album-less track still gets a normal path
+	rel, _ := filepath.Rel(cfg.Download.OutputDir, mgr.resolveOutputPath(track, "MP3_320"))
+	if filepath.ToSlash(rel) != "Me/Voice Memo/Me - Voice Memo.mp3" {
+		t.Errorf("Unexpected path %q", rel)
+	}
+}
+
+func TestFeaturedSingleUsesPrimaryArtist(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = t.TempDir()
+	cfg.Download.TagProfile = "full"
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	newTrack := func() *api.Track {
+		return &api.Track{
+			ID:           "3135556",
+			Title:        "Song",
+			Artist:       &api.Artist{ID: "1", Name: "Main"},
+			Contributors: []*api.Artist{{ID: "1", Name: "Main", Role: "Main"}, {ID: "2", Name: "Guest", Role: "Featured"}},
+			Album:        &api.Album{ID: "featured-single", Title: "Song", RecordType: "single", Artist: &api.Artist{Name: "Main feat. Guest"}},
+		}
+	}
+
+	track := newTrack()
+	mgr.resolveAlbumArtist(track, true)
+	if track.AlbumArtist != "Main" {
+		t.Fatalf("Expected the primary artist for a single, got %q", track.AlbumArtist)
+	}
+
+	outputPath := mgr.resolveOutputPath(track, "MP3_320")
+	rel, _ := filepath.Rel(cfg.Download.OutputDir, outputPath)
+	if folder := strings.Split(filepath.ToSlash(rel), "/")[0]; folder != "Main" {
+		t.Errorf("Expected the single filed under the primary artist, got %q", rel)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(outputPath, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := mgr.applyMetadataTags(context.Background(), outputPath, track, false); err != nil {
+		t.Fatalf("applyMetadataTags failed: %v", err)
+	}
+	tags, err := metadata.NewManager(nil).GetMetadata(outputPath)
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	if tags.AlbumArtist != "Main" || tags.Artist != "Main feat. Guest" {
+		t.Errorf("Expected album artist Main and artist with the feat string, got %q / %q", tags.AlbumArtist, tags.Artist)
+	}
+
+	// Tracks of an album keep the album's artist as listed
+	track = newTrack()
+	mgr.resolveAlbumArtist(track, false)
+	if track.AlbumArtist != "Main feat. Guest" {
+		t.Errorf("Expected album tracks to keep the album artist, got %q", track.AlbumArtist)
+	}
+}
+
+func TestPrimaryArtistName(t *testing.T) {
+	for name, want := range map[string]string{
+		"Main":                 "Main",
+		"Main feat. Guest":     "Main",
+		"Main (feat. Guest)":   "Main",
+		"Main ft. Guest":       "Main",
+		"Main Featuring Guest": "Main",
+		"Daft Punk":            "Daft Punk",
+		"Feat. Guest":          "Feat. Guest",
+	} {
+		if got := primaryArtistName(name); got != want {
+			t.Errorf("primaryArtistName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestDispatchSettings(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.ConcurrentDownloads = 4
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	// Configs built in code fall back to the old fixed values
+	if mgr.pendingFetchLimit() != 8 || mgr.dispatchInterval() != 5*time.Second {
+		t.Errorf("Expected 8 items every 5s, got %d every %v", mgr.pendingFetchLimit(), mgr.dispatchInterval())
+	}
+
+	cfg.Download.PendingMultiplier = 5
+	cfg.Download.DispatchInterval = 1
+	if mgr.pendingFetchLimit() != 20 || mgr.dispatchInterval() != time.Second {
+		t.Errorf("Expected 20 items every 1s, got %d every %v", mgr.pendingFetchLimit(), mgr.dispatchInterval())
+	}
+
+	// Several finished jobs before processQueue wakes up make one dispatch
+	mgr.triggerDispatch()
+	mgr.triggerDispatch()
+	<-mgr.dispatchNow
+	select {
+	case <-mgr.dispatchNow:
+		t.Error("Expected repeated triggers to be merged")
+	default:
+	}
+}
+
+func TestCancelByStatus(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	queueStore := store.NewQueueStore(db)
+	mgr := NewManager(&config.Config{}, queueStore, nil, nil)
+
+	items := []*store.QueueItem{
+		{ID: "album_1", Type: "album", Status: "pending", TotalTracks: 2},
+		{ID: "track_1_10", Type: "track", Status: "completed", ParentID: "album_1"},
+		{ID: "track_1_11", Type: "track", Status: "pending", ParentID: "album_1"},
+		{ID: "track_20", Type: "track", Status: "pending"},
+		{ID: "track_30", Type: "track", Status: "completed"},
+	}
+	for _, item := range items {
+		if err := queueStore.Add(item); err != nil {
+			t.Fatalf("Failed to add %s: %v", item.ID, err)
+		}
+	}
+
+	cancelled, err := mgr.CancelByStatus("pending")
+	if err != nil {
+		t.Fatalf("CancelByStatus failed: %v", err)
+	}
+	if cancelled != 2 {
+		t.Errorf("Expected 2 entries cancelled, got %d", cancelled)
+	}
+
+	for _, id := range []string{"album_1", "track_1_10", "track_1_11", "track_20"} {
+		if _, err := queueStore.GetByID(id); err == nil {
+			t.Errorf("Expected %s to be removed", id)
+		}
+	}
+	if _, err := queueStore.GetByID("track_30"); err != nil {
+		t.Errorf("Expected completed track_30 to be kept: %v", err)
+	}
+
+	if _, err := mgr.CancelByStatus("bogus"); err == nil {
+		t.Error("Expected error for invalid status")
+	}
+}
+
+func TestCancelDownload(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	queueStore := store.NewQueueStore(db)
+	mgr := NewManager(&config.Config{}, queueStore, nil, nil)
+
+	items := []*store.QueueItem{
+		{ID: "album_5", Type: "album", Status: "downloading", TotalTracks: 3},
+		{ID: "track_5_1", Type: "track", Status: "completed", ParentID: "album_5"},
+		{ID: "track_5_2", Type: "track", Status: "downloading", ParentID: "album_5"},
+		{ID: "track_5_3", Type: "track", Status: "pending", ParentID: "album_5"},
+	}
+	for _, item := range items {
+		queueStore.Add(item)
+	}
+
+	if err := mgr.CancelDownload("album_5"); err != nil {
+		t.Fatalf("CancelDownload failed: %v", err)
+	}
+
+	want := map[string]string{"album_5": "cancelled", "track_5_1": "completed", "track_5_2": "cancelled", "track_5_3": "cancelled"}
+	for id, status := range want {
+		item, err := queueStore.GetByID(id)
+		if err != nil {
+			t.Fatalf("Expected %s to be kept: %v", id, err)
+		}
+		if item.Status != status {
+			t.Errorf("Expected %s to be %s, got %s", id, status, item.Status)
+		}
+	}
+
+	// Tracks of a cancelled album are skipped, and finishing one doesn't revive the album
+	track, _ := queueStore.GetByID("track_5_3")
+	if !mgr.cancelledByUser(track) {
+		t.Error("Expected a track of a cancelled album to count as cancelled")
+	}
+	mgr.updateParentProgress("album_5")
+	if album, _ := queueStore.GetByID("album_5"); album.Status != "cancelled" {
+		t.Errorf("Expected the album to stay cancelled, got %s", album.Status)
+	}
+
+	// Resuming brings the cancelled tracks back
+	if err := mgr.ResumeDownload("album_5"); err != nil {
+		t.Fatalf("ResumeDownload failed: %v", err)
+	}
+	if track, _ := queueStore.GetByID("track_5_3"); track.Status != "pending" || mgr.cancelledByUser(track) {
+		t.Errorf("Expected track_5_3 to be pending again, got %s", track.Status)
+	}
+
+	if err := mgr.RemoveItem("album_5"); err != nil {
+		t.Fatalf("RemoveItem failed: %v", err)
+	}
+	for id := range want {
+		if _, err := queueStore.GetByID(id); err == nil {
+			t.Errorf("Expected %s to be removed", id)
+		}
+	}
+
+	if err := mgr.CancelDownload("album_missing"); err == nil {
+		t.Error("Expected an error cancelling an unknown item")
+	}
+}
+
+func TestSyncPlaylistTracks(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	dir := t.TempDir()
+	droppedPath := filepath.Join(dir, "dropped.mp3")
+	keptPath := filepath.Join(dir, "kept.mp3")
+	for _, path := range []string{droppedPath, keptPath, strings.TrimSuffix(droppedPath, ".mp3") + ".lrc"} {
+		if err := os.WriteFile(path, []byte("audio"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	cfg := &config.Config{}
+	cfg.Download.MirrorPlaylist = true
+	queueStore := store.NewQueueStore(db)
+	mgr := NewManager(cfg, queueStore, nil, nil)
+
+	items := []*store.QueueItem{
+		{ID: "playlist_5", Type: "playlist", Status: "completed", TotalTracks: 3, CompletedTracks: 3, Progress: 100},
+		{ID: "track_5_1", Type: "track", Status: "completed", ParentID: "playlist_5", OutputPath: keptPath},
+		{ID: "track_5_2", Type: "track", Status: "completed", ParentID: "playlist_5", OutputPath: droppedPath},
+		{ID: "track_5_3", Type: "track", Status: "failed", ParentID: "playlist_5"},
+	}
+	for _, item := range items {
+		if err := queueStore.Add(item); err != nil {
+			t.Fatalf("Failed to add %s: %v", item.ID, err)
+		}
+	}
+
+	// Track 2 was dropped, track 4 was added and track 3 still needs downloading
+	parent, _ := queueStore.GetByID("playlist_5")
+	added, err := mgr.syncPlaylistTracks(parent, "5", []string{"1", "3", "4"})
+	if err != nil {
+		t.Fatalf("syncPlaylistTracks failed: %v", err)
+	}
+	if added != 2 {
+		t.Errorf("Expected 2 tracks to download, got %d", added)
+	}
+
+	parent, _ = queueStore.GetByID("playlist_5")
+	if parent.Status != "pending" || parent.TotalTracks != 3 || parent.CompletedTracks != 1 {
+		t.Errorf("Unexpected parent: status=%s total=%d completed=%d", parent.Status, parent.TotalTracks, parent.CompletedTracks)
+	}
+	if _, err := queueStore.GetByID("track_5_2"); err == nil {
+		t.Error("Expected dropped track to be removed from the queue")
+	}
+	if _, err := os.Stat(droppedPath); !os.IsNotExist(err) {
+		t.Error("Expected dropped track file to be deleted")
+	}
+	if _, err := os.Stat(keptPath); err != nil {
+		t.Errorf("Expected kept track file to remain: %v", err)
+	}
+
+	// A queued playlist can't be synced until it finishes
+	if _, err := mgr.syncPlaylistTracks(parent, "5", []string{"1"}); err == nil {
+		t.Error("Expected error syncing a pending playlist")
+	}
+}
+
+func TestStopWaitsForBackgroundWork(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(25) // Same pool size as the app; FixStuckAlbums queries while iterating rows
+
+	cfg := &config.Config{}
+	cfg.Download.ConcurrentDownloads = 1
+	mgr := NewManager(cfg, store.NewQueueStore(db), nil, nil)
+	if err := mgr.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Stand-in for a tagging goroutine started by a finished job
+	tagged := make(chan struct{})
+	mgr.backgroundWG.Add(1)
+	go func() {
+		defer mgr.backgroundWG.Done()
+		time.Sleep(100 * time.Millisecond)
+		close(tagged)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := mgr.Stop(ctx); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+
+	select {
+	case <-tagged:
+	default:
+		t.Error("Expected Stop to wait for background tagging")
+	}
+}
+
+func TestFailedTracksSummary(t *testing.T) {
+	failed := []*store.FailedTrack{
+		{TrackID: "track_1_10", ErrorMessage: api.ErrGeoBlocked.Error()},
+		{TrackID: "track_1_11", ErrorMessage: "download failed: connection reset"},
+		{TrackID: "track_1_12", ErrorMessage: api.ErrGeoBlocked.Error()},
+		{TrackID: "track_1_12", ErrorMessage: api.ErrGeoBlocked.Error()}, // recorded again after a retry
+	}
+
+	if got := failedTracksSummary(3, failed); got != "3 tracks failed (2 geo-blocked)" {
+		t.Errorf("Unexpected summary: %q", got)
+	}
+	if got := failedTracksSummary(1, failed[1:2]); got != "1 track failed" {
+		t.Errorf("Unexpected summary without geo-blocked tracks: %q", got)
+	}
+}
+
+func TestArtworkEmbedSidecarMatrix(t *testing.T) {
+	for _, tc := range []struct {
+		embed, sidecar, artist bool
+	}{{true, true, false}, {true, false, false}, {true, false, true}, {false, true, false}, {false, false, true}, {false, false, false}} {
+		cfg := &config.Config{}
+		cfg.Download.TagProfile = "full"
+		cfg.Download.EmbedArtwork = tc.embed
+		cfg.Download.SaveAlbumCover = tc.sidecar
+		cfg.Download.SaveArtistImage = tc.artist
+		cfg.Download.ImageConcurrency = 1
+		cfg.Download.OutputDir = t.TempDir()
+		mgr := NewManager(cfg, nil, nil, nil)
+		dir := t.TempDir()
+
+		// Hold the only image slot so queued sidecars stay queued and never hit the network
+		mgr.imageSem <- struct{}{}
+		ctx, cancel := context.WithCancel(context.Background())
+		artist := &api.Artist{ID: api.FlexibleID("27"), Name: "Artist"}
+		track := &api.Track{Title: "Song", Artist: artist, Album: &api.Album{Title: "Album", Artist: artist}, AlbumArtist: "Artist"}
+		mgr.queueTrackArtwork(ctx, track, dir)
+		mgr.imageMu.Lock()
+		queued := mgr.imageQueued[filepath.Join(dir, "cover.jpg")]
+		artistQueued := mgr.imageQueued[filepath.Join(cfg.Download.OutputDir, mgr.artistFolderPath(track), "folder.jpg")]
+		mgr.imageMu.Unlock()
+		cancel()
+		mgr.backgroundWG.Wait()
+
+		if queued != tc.sidecar {
+			t.Errorf("embed=%v sidecar=%v: expected cover.jpg queued=%v, got %v", tc.embed, tc.sidecar, tc.sidecar, queued)
+		}
+		// The artist image follows save_artist_image alone, not embed_artwork
+		if artistQueued != tc.artist {
+			t.Errorf("embed=%v artist=%v: expected folder.jpg queued=%v, got %v", tc.embed, tc.artist, tc.artist, artistQueued)
+		}
+		if mgr.sidecarArtworkWanted() != (tc.sidecar || tc.artist) {
+			t.Errorf("embed=%v sidecar=%v artist=%v: unexpected sidecarArtworkWanted", tc.embed, tc.sidecar, tc.artist)
+		}
+
+		// Embedding reads a cover already next to the file, so it needs no network either
+		os.WriteFile(filepath.Join(dir, "cover.jpg"), []byte{0xFF, 0xD8, 0xFF, 0xE0, 'J', 'F', 'I', 'F'}, 0644)
+		filePath := filepath.Join(dir, "song.mp3")
+		os.WriteFile(filePath, []byte{}, 0644)
+		if err := mgr.applyMetadataTags(context.Background(), filePath, track, false); err != nil {
+			t.Fatalf("applyMetadataTags failed: %v", err)
+		}
+		tag, err := id3v2.Open(filePath, id3v2.Options{Parse: true})
+		if err != nil {
+			t.Fatalf("Failed to read tags: %v", err)
+		}
+		embedded := len(tag.GetFrames(tag.CommonID("Attached picture"))) > 0
+		tag.Close()
+		if embedded != tc.embed {
+			t.Errorf("embed=%v sidecar=%v: expected embedded artwork=%v, got %v", tc.embed, tc.sidecar, tc.embed, embedded)
+		}
+	}
+}
+
+func TestRunDiagnosticsWithoutSession(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = filepath.Join(t.TempDir(), "music")
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	report := mgr.RunDiagnostics(context.Background())
+	if report.Passed {
+		t.Error("Expected the report to fail without an ARL")
+	}
+	results := make(map[string]*DiagnosticCheck)
+	for _, check := range report.Checks {
+		results[check.Name] = check
+	}
+	for name, passed := range map[string]bool{"arl": false, "api": false, "download_url": false, "output_dir": true, "disk_space": true} {
+		check, ok := results[name]
+		if !ok {
+			t.Errorf("Missing %s check", name)
+			continue
+		}
+		if check.Passed != passed || check.Message == "" {
+			t.Errorf("Unexpected %s check %+v", name, check)
+		}
+	}
+	if _, err := os.Stat(cfg.Download.OutputDir); err != nil {
+		t.Errorf("Expected the output folder to be created: %v", err)
+	}
+	entries, _ := os.ReadDir(cfg.Download.OutputDir)
+	if len(entries) != 0 {
+		t.Errorf("Expected the write probe to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestAcquireAlbumSlot(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.ConcurrentAlbums = 1
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	release, err := mgr.acquireAlbumSlot(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to acquire the first album slot: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := mgr.acquireAlbumSlot(ctx); err == nil {
+		t.Fatal("Expected a second album to wait while the only slot is taken")
+	}
+
+	release()
+	release, err = mgr.acquireAlbumSlot(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the released slot to be free: %v", err)
+	}
+	release()
+
+	updated := *cfg
+	updated.Download.ConcurrentAlbums = 3
+	mgr.UpdateConfig(&updated)
+	if cap(mgr.albumSem) != 3 {
+		t.Errorf("Expected 3 album slots after the update, got %d", cap(mgr.albumSem))
+	}
+	if albumConcurrency(&config.Config{}) != 2 {
+		t.Error("Expected 2 album slots by default")
+	}
+}
+
+func TestManagerRestartDoesNotLeakGoroutines(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &config.Config{}
+	cfg.Download.ConcurrentDownloads = 4
+	mgr := NewManager(cfg, store.NewQueueStore(db), nil, nil)
+	baseline := runtime.NumGoroutine()
+
+	for run := 0; run < 3; run++ {
+		if err := mgr.Start(context.Background()); err != nil {
+			t.Fatalf("Run %d: Start failed: %v", run, err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		err := mgr.Stop(ctx)
+		cancel()
+		if err != nil {
+			t.Fatalf("Run %d: Stop failed: %v", run, err)
+		}
+	}
+
+	// Workers and loops exit asynchronously after their channels close
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > baseline {
+		t.Errorf("Expected goroutines to return to %d after restarts, got %d", baseline, n)
+	}
+}
+
+func TestSharedAlbumArtist(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.SingleArtistAlbums = true
+	cfg.Download.CompilationKeywords = []string{"soundtrack"}
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	artist := &api.Artist{ID: "27", Name: "Daft Punk"}
+	album := func(title string, albumArtist *api.Artist, trackArtists ...*api.Artist) *api.Album {
+		a := &api.Album{Title: title, Artist: albumArtist, Tracks: &api.Tracks{}}
+		for _, trackArtist := range trackArtists {
+			a.Tracks.Data = append(a.Tracks.Data, &api.Track{Artist: trackArtist})
+		}
+		return a
+	}
+	various := &api.Artist{ID: "5080", Name: "Various Artists"}
+
+	if got := mgr.sharedAlbumArtist(album("Discovery", various, artist, artist)); got == nil || got.Name != "Daft Punk" {
+		t.Errorf("Expected the shared track artist to replace Various Artists, got %v", got)
+	}
+	compilation := album("Alive", artist, artist, artist)
+	compilation.RecordType = "compilation"
+	if got := mgr.sharedAlbumArtist(compilation); got == nil || got.Name != "Daft Punk" {
+		t.Errorf("Expected a single-artist compilation to use the artist, got %v", got)
+	}
+	if got := mgr.sharedAlbumArtist(album("Discovery", artist, artist, artist)); got != nil {
+		t.Errorf("Expected no override when the album already credits the artist, got %v", got)
+	}
+	if got := mgr.sharedAlbumArtist(album("Mixed", various, artist, &api.Artist{ID: "28", Name: "Justice"})); got != nil {
+		t.Errorf("Expected no override when tracks have different artists, got %v", got)
+	}
+	if got := mgr.sharedAlbumArtist(album("Tron: Legacy (Soundtrack)", various, artist, artist)); got != nil {
+		t.Errorf("Expected compilation keywords to keep the album as is, got %v", got)
+	}
+
+	// Tracks downloaded on their own look the album up when it would go elsewhere
+	single := func(albumID, recordType string, albumArtist *api.Artist) *api.Track {
+		return &api.Track{Title: "One More Time", Artist: artist, Album: &api.Album{ID: api.FlexibleID(albumID), Title: "Discovery", RecordType: recordType, Artist: albumArtist}}
+	}
+	if !mgr.sharedArtistLookupWanted(single("shared-1", "compilation", various)) {
+		t.Error("Expected a single track from a compilation to look for a shared artist")
+	}
+	if !mgr.sharedArtistLookupWanted(single("shared-2", "album", &api.Artist{ID: "99", Name: "Label"})) {
+		t.Error("Expected a single track from an album credited to another artist to look for a shared artist")
+	}
+	if mgr.sharedArtistLookupWanted(single("shared-3", "album", artist)) {
+		t.Error("Expected no lookup when the album already credits the track's artist")
+	}
+	cacheAlbumArtist("shared-4", "Daft Punk")
+	if mgr.sharedArtistLookupWanted(single("shared-4", "compilation", various)) {
+		t.Error("Expected no lookup once the album artist is cached")
+	}
+
+	// The cached shared artist files the track under it rather than Various Artists
+	track := single("shared-4", "compilation", various)
+	mgr.resolveAlbumArtist(track, true)
+	if track.AlbumArtist != "Daft Punk" || track.IsCompilation {
+		t.Errorf("Expected the cached shared artist, got %q (compilation %v)", track.AlbumArtist, track.IsCompilation)
+	}
+
+	cfg.Download.SingleArtistAlbums = false
+	if got := mgr.sharedAlbumArtist(album("Discovery", various, artist, artist)); got != nil {
+		t.Errorf("Expected no override when single_artist_albums is off, got %v", got)
+	}
+	if mgr.sharedArtistLookupWanted(single("shared-1", "compilation", various)) {
+		t.Error("Expected no lookup when single_artist_albums is off")
+	}
+}
+
+func TestSearchFallbackNeedsTitleAndArtist(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.SearchFallback = true
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	job := &Job{ID: "track_404", TrackID: "404"}
+	_, err := mgr.searchFallbackTrack(context.Background(), job, &store.QueueItem{ID: "track_404"})
+	if !errors.Is(err, api.ErrNotFound) {
+		t.Errorf("Expected a NOT_FOUND error without a title to search for, got %v", err)
+	}
+	if job.TrackID != "404" {
+		t.Errorf("Expected the track ID to be kept, got %s", job.TrackID)
+	}
+}
+
+func TestSearchFallbackSearchFails(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.SearchFallback = true
+	mgr := NewManager(cfg, nil, api.NewDeezerClient(time.Second), nil)
+
+	// A cancelled context fails the search before it reaches Deezer
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	job := &Job{ID: "track_404", TrackID: "404"}
+	_, err := mgr.searchFallbackTrack(ctx, job, &store.QueueItem{ID: "track_404", Title: "One More Time", Artist: "Daft Punk"})
+	if err == nil || errors.Is(err, api.ErrNotFound) {
+		t.Errorf("Expected the search error rather than NOT_FOUND, got %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the search error to be wrapped, got %v", err)
+	}
+	if job.TrackID != "404" {
+		t.Errorf("Expected the track ID to be kept, got %s", job.TrackID)
+	}
+}
+
+func TestSampleDiscNumbers(t *testing.T) {
+	cfg := &config.Config{}
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	album := func(discCount int, discNumbers ...int) *api.Album {
+		a := &api.Album{DiscCount: discCount, Tracks: &api.Tracks{}}
+		for _, disc := range discNumbers {
+			a.Tracks.Data = append(a.Tracks.Data, &api.Track{DiscNumber: disc})
+		}
+		return a
+	}
+
+	tests := []struct {
+		mode  string
+		album *api.Album
+		want  bool
+	}{
+		{"auto", album(1, 0, 0), false}, // Single disc per nb_disk
+		{"auto", album(1, 1, 1), false}, // Disc numbers agree
+		{"auto", album(2, 0, 0), true},  // Several discs
+		{"auto", album(0, 0, 0), true},  // Nothing to go on
+		{"auto", album(1, 1, 2), true},  // Disc numbers disagree with nb_disk
+		{"", album(1, 0, 0), false},     // Unset means auto
+		{"api", album(1, 1, 1), true},   // Always samples
+		{"off", album(0, 0, 0), false},  // Never samples
+		{"auto", album(2), false},       // No tracks to sample
+	}
+	for _, tc := range tests {
+		cfg.Download.MultiDiscDetection = tc.mode
+		if got := mgr.sampleDiscNumbers(tc.album); got != tc.want {
+			t.Errorf("%q with nb_disk=%d: expected %v, got %v", tc.mode, tc.album.DiscCount, tc.want, got)
+		}
+	}
+}
@@ -76,7 +76,7 @@ type ProgressNotifier struct {
 	register      chan *Client
 	unregister    chan *Client
 	mu            sync.RWMutex
-	stats         map[string]*DownloadStats
+	tracker       *StatsTracker
 	statsMu       sync.RWMutex
 	successCount  int
 	failureCount  int
@@ -94,6 +94,118 @@ type DownloadStats struct {
 	ETA            int     // seconds remaining
 }
 
+// StatsTracker computes live speed and ETA for in-flight downloads
+// It is shared by every Notifier implementation so stats are tracked the same way
+type StatsTracker struct {
+	stats map[string]*DownloadStats
+	mu    sync.RWMutex
+}
+
+// NewStatsTracker creates a new stats tracker
+func NewStatsTracker() *StatsTracker {
+	return &StatsTracker{
+		stats: make(map[string]*DownloadStats),
+	}
+}
+
+// Start initializes stats for a download that has just started
+func (st *StatsTracker) Start(itemID string) {
+	now := time.Now()
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.stats[itemID] = &DownloadStats{
+		ItemID:     itemID,
+		StartTime:  now,
+		LastUpdate: now,
+	}
+}
+
+// Update records progress for a download and returns the current speed and ETA
+func (st *StatsTracker) Update(itemID string, bytesProcessed, totalBytes int64) (float64, int) {
+	now := time.Now()
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	stats, exists := st.stats[itemID]
+	if !exists {
+		stats = &DownloadStats{
+			ItemID:    itemID,
+			StartTime: now,
+		}
+		st.stats[itemID] = stats
+	}
+
+	// Calculate speed and ETA (Start sets LastUpdate, so the first update already has a baseline)
+	elapsed := now.Sub(stats.LastUpdate).Seconds()
+	if elapsed > 0 && !stats.LastUpdate.IsZero() {
+		bytesDelta := bytesProcessed - stats.BytesProcessed
+		stats.Speed = float64(bytesDelta) / elapsed
+	}
+
+	stats.BytesProcessed = bytesProcessed
+	stats.TotalBytes = totalBytes
+	stats.LastUpdate = now
+
+	// Calculate ETA
+	if stats.Speed > 0 && totalBytes > 0 {
+		remaining := totalBytes - bytesProcessed
+		stats.ETA = int(float64(remaining) / stats.Speed)
+	}
+
+	return stats.Speed, stats.ETA
+}
+
+// Remove drops stats for a download that is no longer active
+func (st *StatsTracker) Remove(itemID string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.stats, itemID)
+}
+
+// Count returns the number of tracked downloads
+func (st *StatsTracker) Count() int {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return len(st.stats)
+}
+
+// Reset clears all tracked downloads
+func (st *StatsTracker) Reset() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.stats = make(map[string]*DownloadStats)
+}
+
+// GetDownloadStats returns statistics for a specific download
+func (st *StatsTracker) GetDownloadStats(itemID string) *DownloadStats {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+
+	if stats, ok := st.stats[itemID]; ok {
+		// Return a copy
+		copied := *stats
+		return &copied
+	}
+
+	return nil
+}
+
+// GetAllDownloadStats returns statistics for all active downloads
+func (st *StatsTracker) GetAllDownloadStats() []*DownloadStats {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+
+	stats := make([]*DownloadStats, 0, len(st.stats))
+	for _, s := range st.stats {
+		copied := *s
+		stats = append(stats, &copied)
+	}
+
+	return stats
+}
+
 // NewProgressNotifier creates a new progress notifier
 func NewProgressNotifier() *ProgressNotifier {
 	return &ProgressNotifier{
@@ -101,7 +213,7 @@ func NewProgressNotifier() *ProgressNotifier {
 		broadcast:  make(chan *Message, 256),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
-		stats:      make(map[string]*DownloadStats),
+		tracker:    NewStatsTracker(),
 	}
 }
 
@@ -174,36 +286,7 @@ func (pn *ProgressNotifier) NotifyProgress(itemID string, progress int, bytesPro
 	now := time.Now()
 
 	// Update stats
-	pn.statsMu.Lock()
-	stats, exists := pn.stats[itemID]
-	if !exists {
-		stats = &DownloadStats{
-			ItemID:    itemID,
-			StartTime: now,
-		}
-		pn.stats[itemID] = stats
-	}
-
-	// Calculate speed and ETA
-	elapsed := now.Sub(stats.LastUpdate).Seconds()
-	if elapsed > 0 && stats.LastUpdate.After(stats.StartTime) {
-		bytesDelta := bytesProcessed - stats.BytesProcessed
-		stats.Speed = float64(bytesDelta) / elapsed
-	}
-
-	stats.BytesProcessed = bytesProcessed
-	stats.TotalBytes = totalBytes
-	stats.LastUpdate = now
-
-	// Calculate ETA
-	if stats.Speed > 0 && totalBytes > 0 {
-		remaining := totalBytes - bytesProcessed
-		stats.ETA = int(float64(remaining) / stats.Speed)
-	}
-
-	speed := stats.Speed
-	eta := stats.ETA
-	pn.statsMu.Unlock()
+	speed, eta := pn.tracker.Update(itemID, bytesProcessed, totalBytes)
 
 	// Create progress update
 	update := &ProgressUpdate{
@@ -229,17 +312,38 @@ func (pn *ProgressNotifier) NotifyProgress(itemID string, progress int, bytesPro
 	}
 }
 
+// NotifyParentProgress notifies an album or playlist's progress. Bytes are tracks here, and
+// speed is tracks per second.
+func (pn *ProgressNotifier) NotifyParentProgress(progress *ParentProgress) {
+	update := &ProgressUpdate{
+		ItemID:         progress.ItemID,
+		Progress:       progress.Progress,
+		BytesProcessed: int64(progress.CompletedTracks),
+		TotalBytes:     int64(progress.TotalTracks),
+		Speed:          progress.TracksPerMinute / 60,
+		ETA:            progress.ETASeconds,
+		Timestamp:      time.Now(),
+	}
+
+	message := &Message{
+		Type:    "progress",
+		Payload: update,
+	}
+
+	select {
+	case pn.broadcast <- message:
+	default:
+		// Broadcast channel full, drop message
+	}
+}
+
 // NotifyStarted notifies that a download has started
 func (pn *ProgressNotifier) NotifyStarted(itemID string) {
 	now := time.Now()
 
 	// Initialize stats
+	pn.tracker.Start(itemID)
 	pn.statsMu.Lock()
-	pn.stats[itemID] = &DownloadStats{
-		ItemID:    itemID,
-		StartTime: now,
-		LastUpdate: now,
-	}
 	pn.totalDownloads++
 	pn.statsMu.Unlock()
 
@@ -267,8 +371,8 @@ func (pn *ProgressNotifier) NotifyCompleted(itemID string) {
 	now := time.Now()
 
 	// Update stats
+	pn.tracker.Remove(itemID)
 	pn.statsMu.Lock()
-	delete(pn.stats, itemID)
 	pn.successCount++
 	pn.statsMu.Unlock()
 
@@ -296,8 +400,8 @@ func (pn *ProgressNotifier) NotifyFailed(itemID string, err error) {
 	now := time.Now()
 
 	// Update stats
+	pn.tracker.Remove(itemID)
 	pn.statsMu.Lock()
-	delete(pn.stats, itemID)
 	pn.failureCount++
 	pn.statsMu.Unlock()
 
@@ -326,7 +430,7 @@ func (pn *ProgressNotifier) GetStats() map[string]interface{} {
 	pn.statsMu.RLock()
 	defer pn.statsMu.RUnlock()
 
-	activeDownloads := len(pn.stats)
+	activeDownloads := pn.tracker.Count()
 	successRate := 0.0
 	if pn.totalDownloads > 0 {
 		successRate = float64(pn.successCount) / float64(pn.totalDownloads) * 100
@@ -343,44 +447,12 @@ func (pn *ProgressNotifier) GetStats() map[string]interface{} {
 
 // GetDownloadStats returns statistics for a specific download
 func (pn *ProgressNotifier) GetDownloadStats(itemID string) *DownloadStats {
-	pn.statsMu.RLock()
-	defer pn.statsMu.RUnlock()
-
-	if stats, ok := pn.stats[itemID]; ok {
-		// Return a copy
-		return &DownloadStats{
-			ItemID:         stats.ItemID,
-			StartTime:      stats.StartTime,
-			LastUpdate:     stats.LastUpdate,
-			BytesProcessed: stats.BytesProcessed,
-			TotalBytes:     stats.TotalBytes,
-			Speed:          stats.Speed,
-			ETA:            stats.ETA,
-		}
-	}
-
-	return nil
+	return pn.tracker.GetDownloadStats(itemID)
 }
 
 // GetAllDownloadStats returns statistics for all active downloads
 func (pn *ProgressNotifier) GetAllDownloadStats() []*DownloadStats {
-	pn.statsMu.RLock()
-	defer pn.statsMu.RUnlock()
-
-	stats := make([]*DownloadStats, 0, len(pn.stats))
-	for _, s := range pn.stats {
-		stats = append(stats, &DownloadStats{
-			ItemID:         s.ItemID,
-			StartTime:      s.StartTime,
-			LastUpdate:     s.LastUpdate,
-			BytesProcessed: s.BytesProcessed,
-			TotalBytes:     s.TotalBytes,
-			Speed:          s.Speed,
-			ETA:            s.ETA,
-		})
-	}
-
-	return stats
+	return pn.tracker.GetAllDownloadStats()
 }
 
 // GetClientCount returns the number of connected clients
@@ -405,10 +477,11 @@ func (pn *ProgressNotifier) BroadcastCustomMessage(messageType string, payload i
 
 // ResetStats resets all statistics
 func (pn *ProgressNotifier) ResetStats() {
+	pn.tracker.Reset()
+
 	pn.statsMu.Lock()
 	defer pn.statsMu.Unlock()
 
-	pn.stats = make(map[string]*DownloadStats)
 	pn.successCount = 0
 	pn.failureCount = 0
 	pn.totalDownloads = 0
@@ -446,14 +519,13 @@ type CallbackNotifier struct {
 	progressCallback func(itemID string, progress int, speed string, eta string)
 	statusCallback   func(itemID string, status string, errorMsg string)
 	mu               sync.RWMutex
-	stats            map[string]*DownloadStats
-	statsMu          sync.RWMutex
+	tracker          *StatsTracker
 }
 
 // NewCallbackNotifier creates a new callback-based notifier
 func NewCallbackNotifier() *CallbackNotifier {
 	return &CallbackNotifier{
-		stats: make(map[string]*DownloadStats),
+		tracker: NewStatsTracker(),
 	}
 }
 
@@ -473,39 +545,10 @@ func (cn *CallbackNotifier) SetStatusCallback(callback func(itemID string, statu
 
 // NotifyProgress notifies progress for a download via callback
 func (cn *CallbackNotifier) NotifyProgress(itemID string, progress int, bytesProcessed, totalBytes int64) {
-	now := time.Now()
-
 	// Update stats
-	cn.statsMu.Lock()
-	stats, exists := cn.stats[itemID]
-	if !exists {
-		stats = &DownloadStats{
-			ItemID:    itemID,
-			StartTime: now,
-		}
-		cn.stats[itemID] = stats
-	}
-
-	// Calculate speed and ETA
-	elapsed := now.Sub(stats.LastUpdate).Seconds()
-	if elapsed > 0 && stats.LastUpdate.After(stats.StartTime) {
-		bytesDelta := bytesProcessed - stats.BytesProcessed
-		stats.Speed = float64(bytesDelta) / elapsed
-	}
-
-	stats.BytesProcessed = bytesProcessed
-	stats.TotalBytes = totalBytes
-	stats.LastUpdate = now
-
-	// Calculate ETA
-	if stats.Speed > 0 && totalBytes > 0 {
-		remaining := totalBytes - bytesProcessed
-		stats.ETA = int(float64(remaining) / stats.Speed)
-	}
-
-	speed := FormatSpeed(stats.Speed)
-	eta := FormatETA(stats.ETA)
-	cn.statsMu.Unlock()
+	rawSpeed, rawETA := cn.tracker.Update(itemID, bytesProcessed, totalBytes)
+	speed := FormatSpeed(rawSpeed)
+	eta := FormatETA(rawETA)
 
 	// Invoke callback if set
 	cn.mu.RLock()
@@ -526,18 +569,34 @@ func (cn *CallbackNotifier) NotifyProgress(itemID string, progress int, bytesPro
 	}
 }
 
+// NotifyParentProgress notifies an album or playlist's progress via callback, with its speed
+// in tracks per minute
+func (cn *CallbackNotifier) NotifyParentProgress(progress *ParentProgress) {
+	speed := ""
+	if progress.TracksPerMinute > 0 {
+		speed = fmt.Sprintf("%.1f tracks/min", progress.TracksPerMinute)
+	}
+
+	cn.mu.RLock()
+	callback := cn.progressCallback
+	cn.mu.RUnlock()
+
+	if callback != nil {
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					fmt.Printf("Progress callback panicked: %v\n", r)
+				}
+			}()
+			callback(progress.ItemID, progress.Progress, speed, progress.ETA)
+		}()
+	}
+}
+
 // NotifyStarted notifies that a download has started via callback
 func (cn *CallbackNotifier) NotifyStarted(itemID string) {
-	now := time.Now()
-
 	// Initialize stats
-	cn.statsMu.Lock()
-	cn.stats[itemID] = &DownloadStats{
-		ItemID:     itemID,
-		StartTime:  now,
-		LastUpdate: now,
-	}
-	cn.statsMu.Unlock()
+	cn.tracker.Start(itemID)
 
 	// Invoke callback if set
 	cn.mu.RLock()
@@ -559,9 +618,7 @@ func (cn *CallbackNotifier) NotifyStarted(itemID string) {
 // NotifyCompleted notifies that a download has completed via callback
 func (cn *CallbackNotifier) NotifyCompleted(itemID string) {
 	// Clean up stats
-	cn.statsMu.Lock()
-	delete(cn.stats, itemID)
-	cn.statsMu.Unlock()
+	cn.tracker.Remove(itemID)
 
 	// Invoke callback if set
 	cn.mu.RLock()
@@ -583,9 +640,7 @@ func (cn *CallbackNotifier) NotifyCompleted(itemID string) {
 // NotifyFailed notifies that a download has failed via callback
 func (cn *CallbackNotifier) NotifyFailed(itemID string, err error) {
 	// Clean up stats
-	cn.statsMu.Lock()
-	delete(cn.stats, itemID)
-	cn.statsMu.Unlock()
+	cn.tracker.Remove(itemID)
 
 	errorMsg := ""
 	if err != nil {
@@ -608,3 +663,8 @@ func (cn *CallbackNotifier) NotifyFailed(itemID string, err error) {
 		}()
 	}
 }
+
+// GetAllDownloadStats returns statistics for all active downloads
+func (cn *CallbackNotifier) GetAllDownloadStats() []*DownloadStats {
+	return cn.tracker.GetAllDownloadStats()
+}
@@ -0,0 +1,57 @@
+package download
+
+import (
+	"testing"
+	"time"
+)
+
+// nopNotifier implements Notifier with methods that do nothing; test recorders embed it and
+// add the optional notifier method they record
+type nopNotifier struct{}
+
+func (nopNotifier) NotifyProgress(itemID string, progress int, bytesProcessed, totalBytes int64) {}
+func (nopNotifier) NotifyStarted(itemID string)                                                  {}
+func (nopNotifier) NotifyCompleted(itemID string)                                                {}
+func (nopNotifier) NotifyFailed(itemID string, err error)                                        {}
+
+func TestStatsTracker(t *testing.T) {
+	tracker := NewStatsTracker()
+
+	tracker.Start("track_1")
+	time.Sleep(10 * time.Millisecond)
+	speed, eta := tracker.Update("track_1", 1000, 1000000000)
+
+	if speed <= 0 {
+		t.Errorf("Expected positive speed, got %f", speed)
+	}
+	if eta <= 0 {
+		t.Errorf("Expected positive ETA, got %d", eta)
+	}
+
+	stats := tracker.GetAllDownloadStats()
+	if len(stats) != 1 || stats[0].BytesProcessed != 1000 {
+		t.Fatalf("Expected one tracked download with 1000 bytes, got %+v", stats)
+	}
+
+	tracker.Remove("track_1")
+	if tracker.Count() != 0 {
+		t.Errorf("Expected no tracked downloads after remove, got %d", tracker.Count())
+	}
+}
+
+func TestCallbackNotifierTracksStats(t *testing.T) {
+	var provider StatsProvider = NewCallbackNotifier()
+	notifier := provider.(*CallbackNotifier)
+
+	notifier.NotifyStarted("track_1")
+	notifier.NotifyProgress("track_1", 10, 100, 1000)
+
+	if stats := provider.GetAllDownloadStats(); len(stats) != 1 {
+		t.Fatalf("Expected 1 active download, got %d", len(stats))
+	}
+
+	notifier.NotifyCompleted("track_1")
+	if stats := provider.GetAllDownloadStats(); len(stats) != 0 {
+		t.Errorf("Expected no active downloads after completion, got %d", len(stats))
+	}
+}
@@ -0,0 +1,93 @@
+package download
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+	"github.com/deemusic/deemusic-go/internal/store"
+	"go.uber.org/zap"
+)
+
+// SetItemQuality sets the quality a queued track, album or playlist downloads in instead of
+// download.quality; an album or playlist's tracks follow it. An empty quality goes back to the
+// config. Items that are already downloading or completed can't be changed.
+func (m *Manager) SetItemQuality(itemID, quality string) error {
+	switch quality {
+	case "", "MP3_320":
+	case "FLAC":
+		if m.deezerAPI != nil {
+			if maxQuality := m.deezerAPI.MaxQuality(); maxQuality != "" && maxQuality != "FLAC" {
+				return fmt.Errorf("FLAC requires a Deezer HiFi account; this account is limited to %s", maxQuality)
+			}
+		}
+	default:
+		return fmt.Errorf("invalid quality: %s", quality)
+	}
+
+	if err := m.queueStore.SetQuality(itemID, quality); err != nil {
+		return err
+	}
+	m.logInfo(itemID, "Item quality changed", zap.String("quality", quality))
+	return nil
+}
+
+// SetItemOutputPath sets the folder a queued track, album or playlist downloads into instead of
+// download.output_dir; the folder templates still apply below it. An empty path goes back to
+// the config. Items that are already downloading or completed can't be changed.
+func (m *Manager) SetItemOutputPath(itemID, path string) error {
+	if path != "" {
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("output path must be absolute: %s", path)
+		}
+		path = filepath.Clean(path)
+	}
+
+	if err := m.queueStore.SetTargetDir(itemID, path); err != nil {
+		return err
+	}
+	m.logInfo(itemID, "Item output path changed", zap.String("path", path))
+	return nil
+}
+
+// itemSettings returns the quality and output folder a track downloads with: its own, else
+// its album/playlist's, else download.quality and an empty folder (download.output_dir)
+func (m *Manager) itemSettings(item *store.QueueItem) (string, string) {
+	quality, targetDir := item.Quality, item.TargetDir
+	if (quality == "" || targetDir == "") && item.ParentID != "" {
+		if parent, err := m.queueStore.GetByID(item.ParentID); err == nil {
+			if quality == "" {
+				quality = parent.Quality
+			}
+			if targetDir == "" {
+				targetDir = parent.TargetDir
+			}
+		}
+	}
+	if quality == "" {
+		quality = m.config.Download.Quality
+	}
+	return quality, targetDir
+}
+
+// itemOutputPath builds a track's output path like buildOutputPath, but under targetDir
+// rather than download.output_dir when the item has one
+func (m *Manager) itemOutputPath(track *api.Track, format, targetDir string) string {
+	if targetDir == "" {
+		return m.buildOutputPath(track, format)
+	}
+
+	fullPath := m.resolveOutputPath(track, format)
+	rel, err := filepath.Rel(m.config.Download.OutputDir, fullPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		rel = filepath.Base(fullPath)
+	}
+	fullPath = filepath.Join(targetDir, rel)
+
+	if err := m.ensureDir(filepath.Dir(fullPath)); err != nil {
+		// Same flat fallback as buildOutputPath
+		fullPath = filepath.Join(targetDir, fmt.Sprintf("track_%s%s", track.ID, filepath.Ext(fullPath)))
+	}
+	return fullPath
+}
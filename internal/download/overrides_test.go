@@ -0,0 +1,72 @@
+package download
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/deemusic/deemusic-go/internal/api"
+	"github.com/deemusic/deemusic-go/internal/config"
+	"github.com/deemusic/deemusic-go/internal/store"
+)
+
+func TestItemSettings(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	outputDir := t.TempDir()
+	targetDir := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Download.Quality = "MP3_320"
+	cfg.Download.OutputDir = outputDir
+	queueStore := store.NewQueueStore(db)
+	mgr := NewManager(cfg, queueStore, nil, nil)
+
+	queueStore.Add(&store.QueueItem{ID: "album_5", Type: "album", Status: "pending"})
+	queueStore.Add(&store.QueueItem{ID: "track_5_1", Type: "track", Status: "pending", ParentID: "album_5"})
+	queueStore.Add(&store.QueueItem{ID: "track_6", Type: "track", Status: "completed"})
+
+	if err := mgr.SetItemQuality("album_5", "FLAC"); err != nil {
+		t.Fatalf("SetItemQuality failed: %v", err)
+	}
+	if err := mgr.SetItemOutputPath("album_5", targetDir); err != nil {
+		t.Fatalf("SetItemOutputPath failed: %v", err)
+	}
+	if err := mgr.SetItemQuality("album_5", "MP3_128"); err == nil {
+		t.Error("Expected an unknown quality to be refused")
+	}
+	if err := mgr.SetItemOutputPath("album_5", "relative/folder"); err == nil {
+		t.Error("Expected a relative path to be refused")
+	}
+	if err := mgr.SetItemQuality("track_6", "FLAC"); err == nil {
+		t.Error("Expected a completed item to be refused")
+	}
+
+	// The album's track follows the album
+	track, _ := queueStore.GetByID("track_5_1")
+	if quality, dir := mgr.itemSettings(track); quality != "FLAC" || dir != targetDir {
+		t.Errorf("Expected the track to inherit FLAC and %s, got %s and %s", targetDir, quality, dir)
+	}
+	other, _ := queueStore.GetByID("track_6")
+	if quality, dir := mgr.itemSettings(other); quality != "MP3_320" || dir != "" {
+		t.Errorf("Expected the config quality and no folder, got %s and %q", quality, dir)
+	}
+
+	apiTrack := &api.Track{
+		ID:          "1",
+		Title:       "Song",
+		TrackNumber: 1,
+		Artist:      &api.Artist{Name: "Artist"},
+		Album:       &api.Album{ID: "5", Title: "Album"},
+	}
+	want := mgr.resolveOutputPath(apiTrack, "FLAC")
+	rel, _ := filepath.Rel(outputDir, want)
+	if got := mgr.itemOutputPath(apiTrack, "FLAC", targetDir); got != filepath.Join(targetDir, rel) {
+		t.Errorf("Expected the usual layout under %s, got %s", targetDir, got)
+	}
+	if got := mgr.itemOutputPath(apiTrack, "FLAC", ""); got != want {
+		t.Errorf("Expected the output folder without an override, got %s", got)
+	}
+}
@@ -0,0 +1,98 @@
+package download
+
+import (
+	"sync"
+	"time"
+
+	"github.com/deemusic/deemusic-go/internal/store"
+)
+
+// parentETAWindow is how many recent track completions an album/playlist ETA is averaged over
+const parentETAWindow = 8
+
+// ParentProgress is an album or playlist's progress with an estimate of the time left, from
+// the rate its most recent tracks finished
+type ParentProgress struct {
+	ItemID          string  `json:"item_id"`
+	Progress        int     `json:"progress"`
+	CompletedTracks int     `json:"completed_tracks"`
+	TotalTracks     int     `json:"total_tracks"`
+	TracksPerMinute float64 `json:"tracks_per_minute"` // 0 until two tracks have finished
+	ETASeconds      int     `json:"eta_seconds"`       // 0 when unknown or done
+	ETA             string  `json:"eta,omitempty"`
+}
+
+// ParentProgressNotifier is implemented by notifiers that show album/playlist ETAs. For
+// albums and playlists they get NotifyParentProgress in place of NotifyProgress.
+type ParentProgressNotifier interface {
+	NotifyParentProgress(progress *ParentProgress)
+}
+
+// parentRates records when each album/playlist's tracks finished
+type parentRates struct {
+	mu      sync.Mutex
+	samples map[string][]rateSample
+}
+
+type rateSample struct {
+	at       time.Time
+	finished int
+}
+
+func newParentRates() *parentRates {
+	return &parentRates{samples: make(map[string][]rateSample)}
+}
+
+// record notes that finished tracks of parentID are done at now and returns the rate, in
+// tracks per second, over the last parentETAWindow completions (0 until there are two)
+func (r *parentRates) record(parentID string, finished int, now time.Time) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	samples := r.samples[parentID]
+	if len(samples) == 0 || finished > samples[len(samples)-1].finished {
+		samples = append(samples, rateSample{at: now, finished: finished})
+		if len(samples) > parentETAWindow+1 {
+			samples = samples[len(samples)-parentETAWindow-1:]
+		}
+		r.samples[parentID] = samples
+	}
+
+	first, last := samples[0], samples[len(samples)-1]
+	elapsed := last.at.Sub(first.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(last.finished-first.finished) / elapsed
+}
+
+// forget drops the samples of a finished or removed album/playlist
+func (r *parentRates) forget(parentID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.samples, parentID)
+}
+
+// parentProgress builds the progress notification for an album or playlist. finished counts
+// failed tracks too, since they don't take any more time.
+func (m *Manager) parentProgress(parent *store.QueueItem, completed, finished int) *ParentProgress {
+	progress := &ParentProgress{
+		ItemID:          parent.ID,
+		Progress:        parent.Progress,
+		CompletedTracks: completed,
+		TotalTracks:     parent.TotalTracks,
+	}
+	if parent.Status == "completed" {
+		m.parentRates.forget(parent.ID)
+		return progress
+	}
+
+	rate := m.parentRates.record(parent.ID, finished, time.Now())
+	remaining := parent.TotalTracks - finished
+	if rate > 0 && remaining > 0 {
+		progress.TracksPerMinute = rate * 60
+		progress.ETASeconds = int(float64(remaining) / rate)
+		progress.ETA = FormatETA(progress.ETASeconds)
+	}
+	return progress
+}
@@ -0,0 +1,64 @@
+package download
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+	"github.com/deemusic/deemusic-go/internal/store"
+)
+
+func TestParentRates(t *testing.T) {
+	r := newParentRates()
+	start := time.Now()
+	if rate := r.record("album_1", 1, start); rate != 0 {
+		t.Errorf("Expected no rate from a single completion, got %v", rate)
+	}
+	if rate := r.record("album_1", 3, start.Add(10*time.Second)); rate != 0.2 {
+		t.Errorf("Expected 2 tracks in 10s to be 0.2/s, got %v", rate)
+	}
+	// A repeat count (e.g. a failed track's retry) adds no sample
+	if rate := r.record("album_1", 3, start.Add(time.Minute)); rate != 0.2 {
+		t.Errorf("Expected an unchanged count to keep the rate, got %v", rate)
+	}
+
+	// Only the last parentETAWindow completions count, so an early burst fades out
+	for i := 1; i <= parentETAWindow; i++ {
+		r.record("album_1", 3+i, start.Add(10*time.Second+time.Duration(i)*30*time.Second))
+	}
+	if rate := r.record("album_1", 3+parentETAWindow, start); rate < 0.0333 || rate > 0.0334 {
+		t.Errorf("Expected the rate of the last %d tracks, one per 30s, got %v", parentETAWindow, rate)
+	}
+
+	r.forget("album_1")
+	if rate := r.record("album_1", 20, start); rate != 0 {
+		t.Errorf("Expected forget to drop the samples, got %v", rate)
+	}
+}
+
+func TestParentProgressETA(t *testing.T) {
+	mgr := NewManager(&config.Config{}, nil, nil, nil)
+	parent := &store.QueueItem{ID: "album_2", Type: "album", Status: "downloading", TotalTracks: 14}
+
+	if p := mgr.parentProgress(parent, 1, 1); p.ETA != "" || p.ETASeconds != 0 {
+		t.Errorf("Expected no ETA after the first track, got %+v", p)
+	}
+	mgr.parentRates.samples["album_2"][0].at = time.Now().Add(-time.Minute)
+
+	// 4 more tracks in a minute, 1 failed: 9 tracks left at 4 a minute
+	p := mgr.parentProgress(parent, 4, 5)
+	if p.TracksPerMinute < 3.9 || p.TracksPerMinute > 4.1 || p.ETASeconds < 130 || p.ETASeconds > 140 || p.ETA == "" {
+		t.Errorf("Expected about 4 tracks/min and 2m 15s left, got %+v", p)
+	}
+	if p.CompletedTracks != 4 || p.TotalTracks != 14 {
+		t.Errorf("Expected the track counts in the notification, got %+v", p)
+	}
+
+	parent.Status = "completed"
+	if p := mgr.parentProgress(parent, 14, 14); p.ETA != "" {
+		t.Errorf("Expected no ETA once the album is done, got %+v", p)
+	}
+	if _, ok := mgr.parentRates.samples["album_2"]; ok {
+		t.Error("Expected the album's samples to be dropped once it completed")
+	}
+}
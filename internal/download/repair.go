@@ -0,0 +1,93 @@
+package download
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RepairResult reports what RepairAlbum found and changed
+type RepairResult struct {
+	ItemID          string `json:"item_id"`
+	Status          string `json:"status"`
+	TotalTracks     int    `json:"total_tracks"`
+	CompletedTracks int    `json:"completed_tracks"`
+	Resubmitted     int    `json:"resubmitted"` // Unfinished tracks handed back to the worker pool
+}
+
+// RepairAlbum unsticks an album or playlist whose progress stopped short, e.g. at 13/14
+// because a track's completion was lost. Tracks that aren't completed, failed or cancelled
+// and aren't running are submitted again, then the parent is re-counted the same way a finishing
+// track would, which completes it if every track is in fact done.
+func (m *Manager) RepairAlbum(itemID string) (*RepairResult, error) {
+	parent, err := m.queueStore.GetByID(itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue item: %w", err)
+	}
+	if parent.Type != "album" && parent.Type != "playlist" {
+		return nil, fmt.Errorf("%s is not an album or playlist", itemID)
+	}
+
+	children, err := m.queueStore.GetChildren(itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracks: %w", err)
+	}
+
+	var jobs []*Job
+	for _, child := range children {
+		if child.Status == "completed" || child.Status == "failed" || child.Status == "cancelled" || m.workerPool.IsJobActive(child.ID) {
+			continue
+		}
+		jobs = append(jobs, &Job{
+			ID:         child.ID,
+			Type:       JobTypeTrack,
+			TrackID:    queueItemTrackID(child.ID),
+			RetryCount: child.RetryCount,
+		})
+	}
+
+	if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+		fmt.Fprintf(logFile, "[%s] Repairing %s: %d tracks queued, %d/%d completed, resubmitting %d (status=%s)\n",
+			time.Now().Format("2006-01-02 15:04:05"), itemID, len(children), parent.CompletedTracks, parent.TotalTracks, len(jobs), parent.Status)
+		logFile.Close()
+	}
+
+	if len(jobs) > 0 {
+		// The parent is downloading again; leaving it pending would re-run the whole album job
+		parent.Status = "downloading"
+		parent.CompletedAt = nil
+		parent.ErrorMessage = ""
+		if err := m.queueStore.Update(parent); err != nil {
+			return nil, fmt.Errorf("failed to update queue item: %w", err)
+		}
+
+		// Submit blocks while the pool is busy, so hand the jobs over in the background
+		go func(jobs []*Job) {
+			for _, job := range jobs {
+				if err := m.workerPool.Submit(job); err != nil {
+					if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
+						fmt.Fprintf(logFile, "[%s] Failed to resubmit track %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), job.ID, err)
+						logFile.Close()
+					}
+				}
+			}
+		}(jobs)
+	}
+
+	m.updateParentProgress(itemID)
+
+	repaired, err := m.queueStore.GetByID(itemID)
+	if err != nil {
+		// Auto-clear removed the parent once it completed
+		repaired = parent
+		repaired.Status = "completed"
+	}
+	return &RepairResult{
+		ItemID:          itemID,
+		Status:          repaired.Status,
+		TotalTracks:     repaired.TotalTracks,
+		CompletedTracks: repaired.CompletedTracks,
+		Resubmitted:     len(jobs),
+	}, nil
+}
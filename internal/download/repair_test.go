@@ -0,0 +1,58 @@
+package download
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+	"github.com/deemusic/deemusic-go/internal/store"
+)
+
+func TestRepairAlbum(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	queueStore := store.NewQueueStore(db)
+	mgr := NewManager(&config.Config{}, queueStore, nil, nil)
+
+	// Stuck at 1/3 even though every track finished
+	stuck := &store.QueueItem{ID: "album_41", Type: "album", Status: "downloading", TotalTracks: 3, CompletedTracks: 1}
+	queueStore.Add(stuck)
+	for i, status := range []string{"completed", "completed", "failed"} {
+		queueStore.Add(&store.QueueItem{ID: fmt.Sprintf("track_41_%d", i), Type: "track", Status: status, ParentID: "album_41"})
+	}
+
+	result, err := mgr.RepairAlbum("album_41")
+	if err != nil {
+		t.Fatalf("RepairAlbum failed: %v", err)
+	}
+	if result.Status != "completed" || result.CompletedTracks != 2 || result.Resubmitted != 0 {
+		t.Errorf("Expected completed with 2 tracks and nothing resubmitted, got %+v", result)
+	}
+
+	// A failed album with a track that never finished gets that track back
+	failed := &store.QueueItem{ID: "album_42", Type: "album", Status: "failed", TotalTracks: 2}
+	queueStore.Add(failed)
+	queueStore.Add(&store.QueueItem{ID: "track_42_1", Type: "track", Status: "completed", ParentID: "album_42"})
+	queueStore.Add(&store.QueueItem{ID: "track_42_2", Type: "track", Status: "downloading", ParentID: "album_42"})
+
+	result, err = mgr.RepairAlbum("album_42")
+	if err != nil {
+		t.Fatalf("RepairAlbum failed: %v", err)
+	}
+	if result.Status != "downloading" || result.CompletedTracks != 1 || result.Resubmitted != 1 {
+		t.Errorf("Expected downloading with 1 track resubmitted, got %+v", result)
+	}
+
+	queueStore.Add(&store.QueueItem{ID: "track_43", Type: "track", Status: "pending"})
+	if _, err := mgr.RepairAlbum("track_43"); err == nil {
+		t.Error("Expected an error repairing a single track")
+	}
+	if _, err := mgr.RepairAlbum("album_missing"); err == nil {
+		t.Error("Expected an error for an unknown item")
+	}
+}
@@ -0,0 +1,90 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RetagUntagged re-applies metadata to every completed track whose tagging failed,
+// clearing the untagged flag on success. Returns the number of files re-tagged;
+// tracks that still fail (or whose file is gone) stay flagged for a later attempt.
+func (m *Manager) RetagUntagged(ctx context.Context) (int, error) {
+	items, err := m.queueStore.GetUntagged()
+	if err != nil {
+		return 0, err
+	}
+
+	retagged := 0
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return retagged, ctx.Err()
+		}
+
+		if err := m.retagItem(ctx, item.ID, item.ParentID, item.OutputPath); err != nil {
+			if logFile, err2 := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err2 == nil {
+				fmt.Fprintf(logFile, "[%s] Re-tag failed for %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), item.ID, err)
+				logFile.Close()
+			}
+			continue
+		}
+
+		if err := m.queueStore.SetTagged(item.ID, true); err != nil {
+			return retagged, err
+		}
+		retagged++
+	}
+
+	return retagged, nil
+}
+
+// retagItem fetches the track's details again and writes its tags to the downloaded file
+func (m *Manager) retagItem(ctx context.Context, itemID, parentID, filePath string) error {
+	if filePath == "" {
+		return fmt.Errorf("no output path recorded")
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		return fmt.Errorf("file not found: %w", err)
+	}
+
+	track, err := m.deezerAPI.GetTrack(ctx, queueItemTrackID(itemID))
+	if err != nil {
+		return fmt.Errorf("failed to get track details: %w", err)
+	}
+	if track.Artist == nil || track.Album == nil {
+		return fmt.Errorf("track artist or album is nil")
+	}
+
+	// Match the album artist the original download used
+	if strings.HasPrefix(parentID, "playlist_") {
+		track.AlbumArtist = m.variousArtistsName()
+	} else if albumArtist := m.folderAlbumArtist(track.Album); albumArtist != "" {
+		track.AlbumArtist = albumArtist
+	} else {
+		track.AlbumArtist = track.Artist.Name
+	}
+
+	multiDiscCacheMu.RLock()
+	discInfo, cached := multiDiscCache[track.Album.ID.String()]
+	multiDiscCacheMu.RUnlock()
+	if cached {
+		track.IsMultiDiscAlbum = discInfo.IsMultiDisc
+		track.TotalDiscs = discInfo.TotalDiscs
+	}
+
+	// Tagging failed before, so whatever tags the file has are incomplete rather than edited
+	return m.applyMetadataTagsWithRetry(ctx, filePath, track, false)
+}
+
+// queueItemTrackID extracts the Deezer track ID from a queue item ID
+// (track_TRACKID, or track_ALBUMID_TRACKID for album and playlist tracks)
+func queueItemTrackID(itemID string) string {
+	if !strings.HasPrefix(itemID, "track_") {
+		return itemID
+	}
+	parts := strings.Split(itemID, "_")
+	return parts[len(parts)-1]
+}
@@ -0,0 +1,57 @@
+package download
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+	"github.com/deemusic/deemusic-go/internal/store"
+)
+
+func TestQueueItemTrackID(t *testing.T) {
+	tests := map[string]string{
+		"track_123":          "123",
+		"track_456_123":      "123",
+		"track_custom_9_123": "123",
+		"123":                "123",
+	}
+
+	for input, expected := range tests {
+		if got := queueItemTrackID(input); got != expected {
+			t.Errorf("queueItemTrackID(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestRetagUntaggedKeepsMissingFilesFlagged(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	queueStore := store.NewQueueStore(db)
+	mgr := NewManager(&config.Config{}, queueStore, nil, nil)
+
+	item := &store.QueueItem{ID: "track_77", Type: "track", Status: "completed", OutputPath: filepath.Join(t.TempDir(), "gone.mp3")}
+	if err := queueStore.Add(item); err != nil {
+		t.Fatalf("Failed to add item: %v", err)
+	}
+	if err := queueStore.SetTagged(item.ID, false); err != nil {
+		t.Fatalf("SetTagged failed: %v", err)
+	}
+
+	retagged, err := mgr.RetagUntagged(context.Background())
+	if err != nil {
+		t.Fatalf("RetagUntagged failed: %v", err)
+	}
+	if retagged != 0 {
+		t.Errorf("Expected nothing re-tagged, got %d", retagged)
+	}
+
+	untagged, err := queueStore.GetUntagged()
+	if err != nil || len(untagged) != 1 {
+		t.Errorf("Expected the track to stay flagged, got %d (%v)", len(untagged), err)
+	}
+}
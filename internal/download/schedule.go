@@ -0,0 +1,88 @@
+package download
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+)
+
+// ScheduleStatus reports whether download.schedule is holding back the queue
+type ScheduleStatus struct {
+	Enabled     bool       `json:"enabled"`
+	Paused      bool       `json:"paused"`
+	PausedUntil *time.Time `json:"paused_until,omitempty"`
+	Message     string     `json:"message,omitempty"` // e.g. "paused until 1:00"
+}
+
+// ScheduleNotifier is implemented by notifiers that tell the UI when the schedule
+// pauses or resumes dispatch
+type ScheduleNotifier interface {
+	NotifySchedule(status *ScheduleStatus)
+}
+
+// GetScheduleStatus returns the current state of the download window
+func (m *Manager) GetScheduleStatus() *ScheduleStatus {
+	return scheduleStatus(m.config.Download.Schedule, time.Now())
+}
+
+// scheduleStatus works out whether now falls inside the schedule's daily window and,
+// if not, when the window next opens. An unparsable schedule never pauses the queue.
+func scheduleStatus(schedule config.ScheduleConfig, now time.Time) *ScheduleStatus {
+	status := &ScheduleStatus{Enabled: schedule.Enabled}
+	if !schedule.Enabled {
+		return status
+	}
+
+	start, end, loc, err := schedule.Window()
+	if err != nil || start == end {
+		return status
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	var open bool
+	if start < end {
+		open = minute >= start && minute < end
+	} else {
+		// The window spans midnight, e.g. 22:00-06:00
+		open = minute >= start || minute < end
+	}
+	if open {
+		return status
+	}
+
+	next := time.Date(local.Year(), local.Month(), local.Day(), start/60, start%60, 0, 0, loc)
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	status.Paused = true
+	status.PausedUntil = &next
+	status.Message = fmt.Sprintf("paused until %d:%02d", next.Hour(), next.Minute())
+	return status
+}
+
+// dispatchAllowed checks the schedule before processQueue dispatches pending items,
+// notifying the UI when the window closes or opens. Jobs already running are left to finish.
+func (m *Manager) dispatchAllowed() bool {
+	status := m.GetScheduleStatus()
+
+	if status.Paused != m.schedulePaused {
+		m.schedulePaused = status.Paused
+		if logFile, err := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+			if status.Paused {
+				fmt.Fprintf(logFile, "[%s] Outside download schedule, queue %s\n", time.Now().Format("2006-01-02 15:04:05"), status.Message)
+			} else {
+				fmt.Fprintf(logFile, "[%s] Download schedule window open, resuming queue\n", time.Now().Format("2006-01-02 15:04:05"))
+			}
+			logFile.Close()
+		}
+		if notifier, ok := m.notifier.(ScheduleNotifier); ok {
+			notifier.NotifySchedule(status)
+		}
+	}
+
+	return !status.Paused
+}
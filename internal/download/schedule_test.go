@@ -0,0 +1,109 @@
+package download
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+)
+
+func TestScheduleStatus(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 3, 10, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name        string
+		schedule    config.ScheduleConfig
+		now         time.Time
+		paused      bool
+		pausedUntil time.Time
+		message     string
+	}{
+		{
+			name:     "disabled",
+			schedule: config.ScheduleConfig{Start: "01:00", End: "07:00"},
+			now:      at(12, 0),
+		},
+		{
+			name:     "inside window",
+			schedule: config.ScheduleConfig{Enabled: true, Start: "01:00", End: "07:00"},
+			now:      at(3, 30),
+		},
+		{
+			name:        "after window",
+			schedule:    config.ScheduleConfig{Enabled: true, Start: "01:00", End: "07:00"},
+			now:         at(7, 0),
+			paused:      true,
+			pausedUntil: time.Date(2024, 3, 11, 1, 0, 0, 0, loc),
+			message:     "paused until 1:00",
+		},
+		{
+			name:        "before window",
+			schedule:    config.ScheduleConfig{Enabled: true, Start: "01:30", End: "07:00"},
+			now:         at(0, 15),
+			paused:      true,
+			pausedUntil: time.Date(2024, 3, 10, 1, 30, 0, 0, loc),
+			message:     "paused until 1:30",
+		},
+		{
+			name:     "spans midnight, late evening",
+			schedule: config.ScheduleConfig{Enabled: true, Start: "22:00", End: "06:00"},
+			now:      at(23, 0),
+		},
+		{
+			name:     "spans midnight, early morning",
+			schedule: config.ScheduleConfig{Enabled: true, Start: "22:00", End: "06:00"},
+			now:      at(5, 59),
+		},
+		{
+			name:        "spans midnight, daytime",
+			schedule:    config.ScheduleConfig{Enabled: true, Start: "22:00", End: "06:00"},
+			now:         at(6, 0),
+			paused:      true,
+			pausedUntil: time.Date(2024, 3, 10, 22, 0, 0, 0, loc),
+			message:     "paused until 22:00",
+		},
+		{
+			name:     "invalid schedule never pauses",
+			schedule: config.ScheduleConfig{Enabled: true, Start: "late", End: "07:00"},
+			now:      at(12, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The window is in the system timezone by default
+			defer func(local *time.Location) { time.Local = local }(time.Local)
+			time.Local = loc
+
+			status := scheduleStatus(tt.schedule, tt.now)
+			if status.Paused != tt.paused {
+				t.Fatalf("Expected paused=%v, got %v", tt.paused, status.Paused)
+			}
+			if !tt.paused {
+				return
+			}
+			if status.PausedUntil == nil || !status.PausedUntil.Equal(tt.pausedUntil) {
+				t.Errorf("Expected paused until %v, got %v", tt.pausedUntil, status.PausedUntil)
+			}
+			if status.Message != tt.message {
+				t.Errorf("Expected message %q, got %q", tt.message, status.Message)
+			}
+		})
+	}
+}
+
+func TestScheduleStatusTimezone(t *testing.T) {
+	schedule := config.ScheduleConfig{Enabled: true, Start: "01:00", End: "07:00", Timezone: "Asia/Tokyo"}
+
+	// 18:00 UTC is 03:00 in Tokyo
+	if status := scheduleStatus(schedule, time.Date(2024, 3, 10, 18, 0, 0, 0, time.UTC)); status.Paused {
+		t.Errorf("Expected the window to be open in the configured timezone, got %+v", status)
+	}
+	// 00:00 UTC is 09:00 in Tokyo
+	if status := scheduleStatus(schedule, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)); !status.Paused {
+		t.Error("Expected the window to be closed in the configured timezone")
+	}
+}
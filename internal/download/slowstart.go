@@ -0,0 +1,127 @@
+package download
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+	"go.uber.org/zap"
+)
+
+// slowStartPoll is how often a waiting track re-checks the ramp, which otherwise only wakes
+// waiters when a download finishes or the ramp restarts
+const slowStartPoll = 250 * time.Millisecond
+
+// slowStart ramps how many track downloads run at once, from 1 up to concurrent_downloads over
+// download.slow_start_seconds, each time the queue starts from idle. A rate-limit error from
+// Deezer halves the current limit and ramps up again from there.
+type slowStart struct {
+	mu      sync.Mutex
+	active  int
+	floor   int           // Limit when the ramp began
+	start   time.Time     // When the ramp began; zero until the first batch
+	changed chan struct{} // Closed and replaced when a download finishes or the ramp restarts
+}
+
+func newSlowStart() *slowStart {
+	return &slowStart{floor: 1, changed: make(chan struct{})}
+}
+
+// limit returns how many downloads may run at now; callers hold mu
+func (s *slowStart) limit(now time.Time, max int, window time.Duration) int {
+	if s.start.IsZero() || now.Sub(s.start) >= window || s.floor >= max {
+		return max
+	}
+	ramped := s.floor + int(float64(max-s.floor)*now.Sub(s.start).Seconds()/window.Seconds())
+	if ramped < 1 {
+		return 1
+	}
+	return ramped
+}
+
+// notify wakes waiting downloads; callers hold mu
+func (s *slowStart) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// acquire waits until the ramp allows another download. The returned func must be called
+// when the download finishes.
+func (s *slowStart) acquire(ctx context.Context, max int, window time.Duration) (func(), error) {
+	for {
+		s.mu.Lock()
+		if s.active < s.limit(time.Now(), max, window) {
+			s.active++
+			s.mu.Unlock()
+			return s.release, nil
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		case <-time.After(slowStartPoll):
+		}
+	}
+}
+
+func (s *slowStart) release() {
+	s.mu.Lock()
+	s.active--
+	s.notify()
+	s.mu.Unlock()
+}
+
+// restart begins a new ramp from a single download
+func (s *slowStart) restart() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.floor = 1
+	s.start = time.Now()
+	s.notify()
+}
+
+// backoff halves the current limit and ramps up again from there, returning the new floor
+func (s *slowStart) backoff(max int, window time.Duration) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.floor = s.limit(now, max, window) / 2
+	if s.floor < 1 {
+		s.floor = 1
+	}
+	s.start = now
+	s.notify()
+	return s.floor
+}
+
+// slowStartWindow returns the ramp-up duration, or 0 when download.slow_start_seconds is off
+func slowStartWindow(cfg *config.Config) time.Duration {
+	if cfg == nil || cfg.Download.SlowStartSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(cfg.Download.SlowStartSeconds) * time.Second
+}
+
+// acquireDownloadSlot waits for the slow start ramp to allow another track download.
+// Without a ramp configured it returns straight away.
+func (m *Manager) acquireDownloadSlot(ctx context.Context) (func(), error) {
+	window := slowStartWindow(m.config)
+	if window == 0 || m.config.Download.ConcurrentDownloads <= 0 {
+		return func() {}, nil
+	}
+	return m.slowStart.acquire(ctx, m.config.Download.ConcurrentDownloads, window)
+}
+
+// throttled slows the ramp down after Deezer rate limited a request
+func (m *Manager) throttled(itemID string) {
+	window := slowStartWindow(m.config)
+	if window == 0 || m.config.Download.ConcurrentDownloads <= 0 {
+		return
+	}
+	limit := m.slowStart.backoff(m.config.Download.ConcurrentDownloads, window)
+	m.logWarn(itemID, "Rate limited by Deezer, reducing concurrent downloads", zap.Int("limit", limit))
+}
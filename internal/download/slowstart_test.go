@@ -0,0 +1,87 @@
+package download
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+)
+
+func TestSlowStartRamp(t *testing.T) {
+	s := newSlowStart()
+	window := 10 * time.Second
+	if got := s.limit(time.Now(), 8, window); got != 8 {
+		t.Errorf("Expected no limit before the first batch, got %d", got)
+	}
+
+	s.restart()
+	start := s.start
+	for _, tc := range []struct {
+		elapsed time.Duration
+		want    int
+	}{
+		{0, 1},
+		{5 * time.Second, 4},
+		{9 * time.Second, 7},
+		{10 * time.Second, 8},
+	} {
+		if got := s.limit(start.Add(tc.elapsed), 8, window); got != tc.want {
+			t.Errorf("After %v expected a limit of %d, got %d", tc.elapsed, tc.want, got)
+		}
+	}
+
+	// Half way up the ramp is 4 at once; a rate limit halves that
+	s.start = time.Now().Add(-5 * time.Second)
+	if floor := s.backoff(8, window); floor != 2 {
+		t.Errorf("Expected the backoff to halve the limit to 2, got %d", floor)
+	}
+	s.start = time.Now().Add(-time.Hour)
+	if floor := s.backoff(1, window); floor != 1 {
+		t.Errorf("Expected the backoff to keep at least one download, got %d", floor)
+	}
+}
+
+func TestAcquireDownloadSlot(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.ConcurrentDownloads = 4
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	// Without slow_start_seconds every download starts straight away
+	for i := 0; i < 8; i++ {
+		if _, err := mgr.acquireDownloadSlot(context.Background()); err != nil {
+			t.Fatalf("Expected no ramp when slow start is off: %v", err)
+		}
+	}
+
+	cfg.Download.SlowStartSeconds = 60
+	mgr.slowStart.restart()
+	release, err := mgr.acquireDownloadSlot(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to acquire the first download slot: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := mgr.acquireDownloadSlot(ctx); err == nil {
+		t.Fatal("Expected a second download to wait at the start of the ramp")
+	}
+
+	// The waiting download starts as soon as the first one finishes
+	done := make(chan error, 1)
+	go func() {
+		release, err := mgr.acquireDownloadSlot(context.Background())
+		if err == nil {
+			release()
+		}
+		done <- err
+	}()
+	release()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected the waiting download to start: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected the waiting download to start once a slot was released")
+	}
+}
@@ -0,0 +1,103 @@
+package download
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stagingPath returns where a track bound for libraryPath should be downloaded and tagged.
+// Without a staging folder that is the library path itself; otherwise the same relative
+// path under the staging folder, so tracks with equal names in different albums don't clash.
+func (m *Manager) stagingPath(libraryPath string) string {
+	stagingDir := m.config.Download.StagingDir
+	if stagingDir == "" {
+		return libraryPath
+	}
+
+	rel, err := filepath.Rel(m.config.Download.OutputDir, libraryPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		rel = filepath.Base(libraryPath)
+	}
+	return filepath.Join(stagingDir, rel)
+}
+
+// moveStagedTrack moves a finished track and its lyrics sidecar from the staging folder into
+// the library. It is a no-op when staging is disabled.
+func (m *Manager) moveStagedTrack(stagedPath, libraryPath string) error {
+	if stagedPath == libraryPath {
+		return nil
+	}
+
+	if err := moveFile(stagedPath, libraryPath); err != nil {
+		return fmt.Errorf("failed to move %s into library: %w", stagedPath, err)
+	}
+
+	for _, ext := range lyricsSidecarExts {
+		stagedLyrics := strings.TrimSuffix(stagedPath, filepath.Ext(stagedPath)) + ext
+		if _, err := os.Stat(stagedLyrics); err == nil {
+			libraryLyrics := strings.TrimSuffix(libraryPath, filepath.Ext(libraryPath)) + ext
+			if err := moveFile(stagedLyrics, libraryLyrics); err != nil {
+				return fmt.Errorf("failed to move lyrics into library: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// pruneStaging drops the folders a staged track leaves empty in the staging area, from its
+// album folder up. It only runs once nothing else writes there: after a standalone track or
+// once its album/playlist has finished, so a sibling track never loses its folder mid-download.
+func (m *Manager) pruneStaging(stagedPath string) {
+	if m.config.Download.StagingDir == "" {
+		return
+	}
+	stagingRoot := filepath.Clean(m.config.Download.StagingDir)
+	for dir := filepath.Dir(stagedPath); dir != stagingRoot && strings.HasPrefix(dir, stagingRoot); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+}
+
+// pruneParentStaging drops the staging folders left by a finished album or playlist's tracks
+func (m *Manager) pruneParentStaging(parentID string) {
+	if m.config.Download.StagingDir == "" {
+		return
+	}
+	children, err := m.queueStore.GetChildren(parentID)
+	if err != nil {
+		return
+	}
+	for _, child := range children {
+		if child.OutputPath != "" {
+			m.pruneStaging(m.stagingPath(child.OutputPath))
+		}
+	}
+}
+
+// moveFile moves src to dst so dst only ever appears complete. A rename is used when both
+// are on the same volume; otherwise the file is copied next to dst and renamed into place.
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(dst, in)
+	in.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(src)
+}
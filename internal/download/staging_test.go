@@ -0,0 +1,85 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+)
+
+func TestStagingPath(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = filepath.Join("music", "library")
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	libraryPath := filepath.Join("music", "library", "Artist", "Album", "01 - Song.flac")
+	if got := mgr.stagingPath(libraryPath); got != libraryPath {
+		t.Errorf("Expected library path without staging, got %s", got)
+	}
+
+	cfg.Download.StagingDir = filepath.Join("music", "staging")
+	expected := filepath.Join("music", "staging", "Artist", "Album", "01 - Song.flac")
+	if got := mgr.stagingPath(libraryPath); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
+
+func TestMoveStagedTrack(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = filepath.Join(root, "library")
+	cfg.Download.StagingDir = filepath.Join(root, "staging")
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	libraryPath := filepath.Join(cfg.Download.OutputDir, "Artist", "Album", "01 - Song.mp3")
+	stagedPath := mgr.stagingPath(libraryPath)
+	if err := os.MkdirAll(filepath.Dir(stagedPath), 0755); err != nil {
+		t.Fatalf("Failed to create staging folder: %v", err)
+	}
+	if err := os.WriteFile(stagedPath, []byte("audio"), 0644); err != nil {
+		t.Fatalf("Failed to write staged track: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(stagedPath), "01 - Song.lrc"), []byte("[00:01.00]la"), 0644); err != nil {
+		t.Fatalf("Failed to write staged lyrics: %v", err)
+	}
+
+	if err := mgr.moveStagedTrack(stagedPath, libraryPath); err != nil {
+		t.Fatalf("moveStagedTrack failed: %v", err)
+	}
+
+	if data, err := os.ReadFile(libraryPath); err != nil || string(data) != "audio" {
+		t.Errorf("Expected track in library, got %q (%v)", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(libraryPath), "01 - Song.lrc")); err != nil {
+		t.Errorf("Expected lyrics sidecar in library: %v", err)
+	}
+	// Other tracks of the album may still be staged there until it finishes
+	if _, err := os.Stat(filepath.Dir(stagedPath)); err != nil {
+		t.Errorf("Expected the staging folder to be kept until pruned: %v", err)
+	}
+
+	mgr.pruneStaging(stagedPath)
+	if _, err := os.Stat(filepath.Join(cfg.Download.StagingDir, "Artist")); !os.IsNotExist(err) {
+		t.Error("Expected empty staging folders to be removed")
+	}
+	if _, err := os.Stat(cfg.Download.StagingDir); err != nil {
+		t.Errorf("Expected staging root to be kept: %v", err)
+	}
+}
+
+func TestMoveStagedTrackMissing(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{}
+	cfg.Download.OutputDir = filepath.Join(root, "library")
+	cfg.Download.StagingDir = filepath.Join(root, "staging")
+	mgr := NewManager(cfg, nil, nil, nil)
+
+	libraryPath := filepath.Join(cfg.Download.OutputDir, "Artist", "Album", "01 - Song.mp3")
+	if err := mgr.moveStagedTrack(mgr.stagingPath(libraryPath), libraryPath); err == nil {
+		t.Error("Expected an error moving a track that isn't staged")
+	}
+	if _, err := os.Stat(libraryPath); !os.IsNotExist(err) {
+		t.Error("Expected nothing in the library")
+	}
+}
@@ -0,0 +1,233 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// throughputSampleInterval is how often aggregate download throughput is sampled
+	throughputSampleInterval = 10 * time.Second
+
+	// minThroughputSamples is how many samples a concurrency level needs before it counts
+	// towards the suggested worker count
+	minThroughputSamples = 3
+
+	// throughputCacheKey is the config cache key used to persist throughput telemetry
+	throughputCacheKey = "throughput_stats"
+)
+
+// ThroughputLevel aggregates throughput measured while a given number of downloads ran at once
+type ThroughputLevel struct {
+	Concurrency    int     `json:"concurrency"`
+	Samples        int     `json:"samples"`
+	TotalBytes     int64   `json:"total_bytes"`
+	TotalSeconds   float64 `json:"total_seconds"`
+	BytesPerSecond float64 `json:"bytes_per_second"`
+}
+
+// ThroughputReport summarizes throughput per concurrency level and suggests a worker count
+type ThroughputReport struct {
+	Levels           []*ThroughputLevel `json:"levels"`
+	CurrentWorkers   int                `json:"current_workers"`
+	SuggestedWorkers int                `json:"suggested_workers"` // 0 until there is enough data
+}
+
+// throughputTracker measures bytes downloaded against the time-weighted number of
+// concurrent downloads, so each sample can be attributed to a concurrency level
+type throughputTracker struct {
+	mu             sync.Mutex
+	active         int
+	lastChange     time.Time
+	activeIntegral float64 // Sum of active downloads x seconds since the last sample
+	sampleStart    time.Time
+	pendingBytes   int64
+	levels         map[int]*ThroughputLevel
+}
+
+func newThroughputTracker() *throughputTracker {
+	now := time.Now()
+	return &throughputTracker{
+		lastChange:  now,
+		sampleStart: now,
+		levels:      make(map[int]*ThroughputLevel),
+	}
+}
+
+// advance accumulates the active-download integral up to now; callers hold mu
+func (t *throughputTracker) advance(now time.Time) {
+	t.activeIntegral += float64(t.active) * now.Sub(t.lastChange).Seconds()
+	t.lastChange = now
+}
+
+// begin records that a download started
+func (t *throughputTracker) begin() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.advance(time.Now())
+	t.active++
+}
+
+// end records that a download finished, crediting bytes if it succeeded
+func (t *throughputTracker) end(bytes int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.advance(time.Now())
+	if t.active > 0 {
+		t.active--
+	}
+	t.pendingBytes += bytes
+}
+
+// sample closes the current sampling window and attributes its bytes to the average
+// concurrency during the window. Idle windows are discarded. Returns true if recorded.
+func (t *throughputTracker) sample(now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.advance(now)
+	elapsed := now.Sub(t.sampleStart).Seconds()
+	integral := t.activeIntegral
+	bytes := t.pendingBytes
+
+	t.sampleStart = now
+	t.activeIntegral = 0
+	t.pendingBytes = 0
+
+	if elapsed <= 0 || integral <= 0 || bytes <= 0 {
+		return false
+	}
+
+	concurrency := int(math.Round(integral / elapsed))
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	level := t.levels[concurrency]
+	if level == nil {
+		level = &ThroughputLevel{Concurrency: concurrency}
+		t.levels[concurrency] = level
+	}
+	level.Samples++
+	level.TotalBytes += bytes
+	level.TotalSeconds += elapsed
+	level.BytesPerSecond = float64(level.TotalBytes) / level.TotalSeconds
+	return true
+}
+
+// report returns a snapshot of all levels, ordered by concurrency
+func (t *throughputTracker) report(currentWorkers int) *ThroughputReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	levels := make([]*ThroughputLevel, 0, len(t.levels))
+	for _, level := range t.levels {
+		copied := *level
+		levels = append(levels, &copied)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].Concurrency < levels[j].Concurrency
+	})
+
+	return &ThroughputReport{
+		Levels:           levels,
+		CurrentWorkers:   currentWorkers,
+		SuggestedWorkers: suggestWorkers(levels),
+	}
+}
+
+// suggestWorkers picks the fewest workers that reach 95% of the best measured throughput.
+// Past that point extra workers mostly add load without making downloads faster.
+func suggestWorkers(levels []*ThroughputLevel) int {
+	best := 0.0
+	for _, level := range levels {
+		if level.Samples >= minThroughputSamples && level.BytesPerSecond > best {
+			best = level.BytesPerSecond
+		}
+	}
+	if best == 0 {
+		return 0
+	}
+
+	for _, level := range levels {
+		if level.Samples >= minThroughputSamples && level.BytesPerSecond >= best*0.95 {
+			return level.Concurrency
+		}
+	}
+	return 0
+}
+
+// GetThroughputReport returns measured throughput per concurrency level and a suggested
+// concurrent_downloads value
+func (m *Manager) GetThroughputReport() *ThroughputReport {
+	m.mu.RLock()
+	currentWorkers := m.config.Download.ConcurrentDownloads
+	m.mu.RUnlock()
+
+	return m.throughput.report(currentWorkers)
+}
+
+// sampleThroughput periodically closes throughput sampling windows until ctx is done
+func (m *Manager) sampleThroughput(ctx context.Context) {
+	ticker := time.NewTicker(throughputSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if m.throughput.sample(now) {
+				m.saveThroughput()
+			}
+		}
+	}
+}
+
+// loadThroughput restores throughput telemetry saved by previous sessions
+func (m *Manager) loadThroughput() {
+	if m.queueStore == nil {
+		return
+	}
+	value, err := m.queueStore.GetConfigCache(throughputCacheKey)
+	if err != nil || value == "" {
+		return
+	}
+	var levels []*ThroughputLevel
+	if err := json.Unmarshal([]byte(value), &levels); err != nil {
+		return
+	}
+
+	m.throughput.mu.Lock()
+	defer m.throughput.mu.Unlock()
+	for _, level := range levels {
+		if level.Concurrency > 0 && level.TotalSeconds > 0 {
+			m.throughput.levels[level.Concurrency] = level
+		}
+	}
+}
+
+// saveThroughput persists throughput telemetry so suggestions improve across sessions
+func (m *Manager) saveThroughput() {
+	if m.queueStore == nil {
+		return
+	}
+
+	data, err := json.Marshal(m.throughput.report(0).Levels)
+	if err != nil {
+		return
+	}
+	if err := m.queueStore.SetConfigCache(throughputCacheKey, string(data)); err != nil {
+		if logFile, logErr := os.OpenFile(filepath.Join(os.TempDir(), "deemusic-download-debug.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); logErr == nil {
+			fmt.Fprintf(logFile, "[%s] Failed to persist throughput stats: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
+			logFile.Close()
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package download
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/deemusic/deemusic-go/internal/config"
+	"github.com/deemusic/deemusic-go/internal/store"
+)
+
+// recordWindow feeds the tracker a 10s window with the given concurrency and bytes
+func recordWindow(t *testing.T, tracker *throughputTracker, start time.Time, active int, bytes int64) time.Time {
+	t.Helper()
+	tracker.mu.Lock()
+	tracker.active = active
+	tracker.lastChange = start
+	tracker.sampleStart = start
+	tracker.activeIntegral = 0
+	tracker.pendingBytes = bytes
+	tracker.mu.Unlock()
+
+	end := start.Add(10 * time.Second)
+	if !tracker.sample(end) {
+		t.Fatalf("Expected window with %d active downloads to be recorded", active)
+	}
+	return end
+}
+
+func TestThroughputTrackerSuggestsWorkers(t *testing.T) {
+	tracker := newThroughputTracker()
+	now := time.Now()
+
+	for i := 0; i < minThroughputSamples; i++ {
+		now = recordWindow(t, tracker, now, 2, 20_000_000) // 2 MB/s
+		now = recordWindow(t, tracker, now, 4, 39_000_000) // 3.9 MB/s
+		now = recordWindow(t, tracker, now, 8, 40_000_000) // 4 MB/s
+	}
+
+	report := tracker.report(8)
+	if len(report.Levels) != 3 {
+		t.Fatalf("Expected 3 levels, got %d", len(report.Levels))
+	}
+	if report.Levels[0].Concurrency != 2 || report.Levels[0].BytesPerSecond != 2_000_000 {
+		t.Errorf("Unexpected first level: %+v", report.Levels[0])
+	}
+	// 4 workers reach 97.5% of the best rate, so more workers aren't worth it
+	if report.SuggestedWorkers != 4 {
+		t.Errorf("Expected 4 suggested workers, got %d", report.SuggestedWorkers)
+	}
+	if report.CurrentWorkers != 8 {
+		t.Errorf("Expected current workers 8, got %d", report.CurrentWorkers)
+	}
+}
+
+func TestThroughputTrackerIgnoresIdleAndSparseData(t *testing.T) {
+	tracker := newThroughputTracker()
+
+	// Nothing downloaded: the window is discarded
+	if tracker.sample(time.Now().Add(time.Second)) {
+		t.Error("Expected idle window not to be recorded")
+	}
+
+	recordWindow(t, tracker, time.Now(), 3, 10_000_000)
+	if suggested := tracker.report(3).SuggestedWorkers; suggested != 0 {
+		t.Errorf("Expected no suggestion from a single sample, got %d", suggested)
+	}
+}
+
+func TestThroughputPersistence(t *testing.T) {
+	db, err := store.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize test database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &config.Config{}
+	queueStore := store.NewQueueStore(db)
+
+	mgr := NewManager(cfg, queueStore, nil, nil)
+	recordWindow(t, mgr.throughput, time.Now(), 2, 30_000_000)
+	mgr.saveThroughput()
+
+	restored := NewManager(cfg, queueStore, nil, nil)
+	report := restored.GetThroughputReport()
+	if len(report.Levels) != 1 || report.Levels[0].Concurrency != 2 || report.Levels[0].TotalBytes != 30_000_000 {
+		t.Errorf("Expected restored level for 2 workers, got %+v", report.Levels)
+	}
+}
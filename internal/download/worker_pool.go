@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"sync"
 	
 	"github.com/deemusic/deemusic-go/internal/store"
@@ -43,15 +44,34 @@ type Result struct {
 // WorkerPool manages a pool of worker goroutines for concurrent downloads
 type WorkerPool struct {
 	maxWorkers int
-	jobs       chan *Job
-	results    chan *Result
+	run        *poolRun // Current (or, once stopped, last) run; guarded by mu
 	activeJobs sync.Map // map[string]*Job
-	wg         sync.WaitGroup
-	ctx        context.Context
-	cancel     context.CancelFunc
 	handler    JobHandler
 	mu         sync.RWMutex
 	started    bool
+	stopping   bool // Set by Shutdown; no new jobs are accepted
+}
+
+// poolRun is one Start to Stop/Shutdown cycle of the pool. Each run has its own channels
+// and workers, so a pool restarted after a stop never hands jobs or results to goroutines
+// of the previous run.
+type poolRun struct {
+	jobs    chan *Job
+	results chan *Result
+	quit    chan struct{} // Closed by Shutdown so idle workers exit
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+}
+
+// newPoolRun creates the channels for a run
+func newPoolRun(maxWorkers int) *poolRun {
+	return &poolRun{
+		jobs:    make(chan *Job, 10000), // Very large buffer to handle thousands of albums/tracks
+		results: make(chan *Result, maxWorkers*10),
+		quit:    make(chan struct{}),
+	}
 }
 
 // JobHandler is a function that processes a job
@@ -65,14 +85,15 @@ func NewWorkerPool(maxWorkers int, handler JobHandler) *WorkerPool {
 
 	return &WorkerPool{
 		maxWorkers: maxWorkers,
-		jobs:       make(chan *Job, 10000), // Very large buffer to handle thousands of albums/tracks
-		results:    make(chan *Result, maxWorkers*10),
+		run:        newPoolRun(maxWorkers),
 		handler:    handler,
 		started:    false,
 	}
 }
 
-// Start spawns worker goroutines and begins processing jobs
+// Start spawns worker goroutines and begins processing jobs. A pool that was stopped can
+// be started again; it starts with fresh job and results channels, so Results must be
+// called again after each Start. Jobs still queued at the stop are dropped with that run.
 func (wp *WorkerPool) Start(ctx context.Context) error {
 	wp.mu.Lock()
 	defer wp.mu.Unlock()
@@ -85,13 +106,20 @@ func (wp *WorkerPool) Start(ctx context.Context) error {
 		return fmt.Errorf("job handler not set")
 	}
 
+	if wp.run.started {
+		wp.run = newPoolRun(wp.maxWorkers)
+	}
+	run := wp.run
+	run.started = true
+	wp.stopping = false
+
 	// Use the provided context instead of creating a new one
-	wp.ctx, wp.cancel = context.WithCancel(ctx)
+	run.ctx, run.cancel = context.WithCancel(ctx)
 
 	// Spawn worker goroutines
 	for i := 0; i < wp.maxWorkers; i++ {
-		wp.wg.Add(1)
-		go wp.worker(i)
+		run.wg.Add(1)
+		go wp.worker(run, i)
 	}
 
 	wp.started = true
@@ -99,19 +127,31 @@ func (wp *WorkerPool) Start(ctx context.Context) error {
 }
 
 // worker is the main worker goroutine that processes jobs
-func (wp *WorkerPool) worker(id int) {
-	defer wp.wg.Done()
+func (wp *WorkerPool) worker(run *poolRun, id int) {
+	defer run.wg.Done()
 	
 	fmt.Fprintf(os.Stderr, "[DEBUG] Worker %d started\n", id)
 
 	for {
+		// Don't pick up another job once Shutdown has started
 		select {
-		case <-wp.ctx.Done():
+		case <-run.quit:
+			fmt.Fprintf(os.Stderr, "[INFO] Worker %d stopped for shutdown\n", id)
+			return
+		default:
+		}
+
+		select {
+		case <-run.ctx.Done():
 			// Worker pool is shutting down
-			fmt.Fprintf(os.Stderr, "[WARN] Worker %d shutting down due to context cancellation: %v\n", id, wp.ctx.Err())
+			fmt.Fprintf(os.Stderr, "[WARN] Worker %d shutting down due to context cancellation: %v\n", id, run.ctx.Err())
 			return
 
-		case job, ok := <-wp.jobs:
+		case <-run.quit:
+			fmt.Fprintf(os.Stderr, "[INFO] Worker %d stopped for shutdown\n", id)
+			return
+
+		case job, ok := <-run.jobs:
 			if !ok {
 				// Jobs channel closed
 				fmt.Fprintf(os.Stderr, "[WARN] Worker %d shutting down due to closed jobs channel\n", id)
@@ -119,25 +159,27 @@ func (wp *WorkerPool) worker(id int) {
 			}
 
 			// Process the job
-			wp.processJob(job)
+			wp.processJob(run, job)
 		}
 	}
 }
 
 // processJob processes a single job
-func (wp *WorkerPool) processJob(job *Job) {
+func (wp *WorkerPool) processJob(run *poolRun, job *Job) {
 	// Store active job
 	wp.activeJobs.Store(job.ID, job)
-	defer wp.activeJobs.Delete(job.ID)
 
 	// Create job context if not set
 	if job.ctx == nil {
-		job.ctx, job.cancel = context.WithCancel(wp.ctx)
+		job.ctx, job.cancel = context.WithCancel(run.ctx)
 	}
 
 	// Execute job handler
 	err := wp.handler(job.ctx, job)
 
+	// Free the slot before reporting, so the dispatch the result triggers sees it
+	wp.activeJobs.Delete(job.ID)
+
 	// Send result
 	result := &Result{
 		JobID:   job.ID,
@@ -146,9 +188,9 @@ func (wp *WorkerPool) processJob(job *Job) {
 	}
 
 	select {
-	case wp.results <- result:
+	case run.results <- result:
 		// Result sent successfully
-	case <-wp.ctx.Done():
+	case <-run.ctx.Done():
 		// Worker pool shutting down, discard result
 	}
 }
@@ -160,26 +202,35 @@ func (wp *WorkerPool) Submit(job *Job) error {
 		wp.mu.RUnlock()
 		return fmt.Errorf("worker pool not started")
 	}
+	if wp.stopping {
+		wp.mu.RUnlock()
+		return fmt.Errorf("worker pool is shutting down")
+	}
+	run := wp.run
 	wp.mu.RUnlock()
 
 	// Create job context
-	job.ctx, job.cancel = context.WithCancel(wp.ctx)
+	job.ctx, job.cancel = context.WithCancel(run.ctx)
 
 	select {
-	case wp.jobs <- job:
+	case run.jobs <- job:
 		return nil
-	case <-wp.ctx.Done():
+	case <-run.ctx.Done():
 		return fmt.Errorf("worker pool is shutting down")
 	}
 }
 
-// Stop gracefully stops the worker pool
+// Stop gracefully stops the worker pool, closing the results channel once every worker
+// has exited
 func (wp *WorkerPool) Stop() {
 	wp.mu.Lock()
 	if !wp.started {
 		wp.mu.Unlock()
 		return
 	}
+	// Refuse new jobs from here on, so nothing is sent on the closed jobs channel
+	wp.stopping = true
+	run := wp.run
 	wp.mu.Unlock()
 
 	// Cancel all active jobs
@@ -190,26 +241,80 @@ func (wp *WorkerPool) Stop() {
 		return true
 	})
 
-	// Signal shutdown
-	wp.cancel()
-
-	// Close jobs channel
-	close(wp.jobs)
+	// Signal shutdown. The jobs channel is left open: a Submit racing with Stop would
+	// panic sending on it, and workers exit on the cancelled context anyway.
+	run.cancel()
 
 	// Wait for all workers to finish
-	wp.wg.Wait()
+	run.wg.Wait()
 
 	// Close results channel
-	close(wp.results)
+	close(run.results)
 
 	wp.mu.Lock()
 	wp.started = false
 	wp.mu.Unlock()
 }
 
-// Results returns the results channel
+// Shutdown stops the pool gracefully: new and queued jobs are refused, and running jobs
+// get until ctx is done to finish. Jobs still running after that are cancelled and an
+// error is returned. Unlike Stop, a job mid-download is never cut off while there is time.
+func (wp *WorkerPool) Shutdown(ctx context.Context) error {
+	wp.mu.Lock()
+	if !wp.started || wp.stopping {
+		wp.mu.Unlock()
+		return nil
+	}
+	wp.stopping = true
+	run := wp.run
+	close(run.quit)
+	wp.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		run.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("%d jobs still running at shutdown: %w", wp.GetActiveJobCount(), ctx.Err())
+	}
+
+	// Cancel whatever is left and release job contexts
+	wp.activeJobs.Range(func(key, value interface{}) bool {
+		if job, ok := value.(*Job); ok && job.cancel != nil {
+			job.cancel()
+		}
+		return true
+	})
+	run.cancel()
+
+	// Workers that are still running may yet send a result, so close once they're gone
+	if err == nil {
+		close(run.results)
+	} else {
+		go func() {
+			run.wg.Wait()
+			close(run.results)
+		}()
+	}
+
+	wp.mu.Lock()
+	wp.started = false
+	wp.mu.Unlock()
+
+	return err
+}
+
+// Results returns the current run's results channel. It is closed when the run is
+// stopped, after the last result; a restarted pool has a new channel.
 func (wp *WorkerPool) Results() <-chan *Result {
-	return wp.results
+	wp.mu.RLock()
+	defer wp.mu.RUnlock()
+	return wp.run.results
 }
 
 // CancelJob cancels a specific job by ID
@@ -245,11 +350,15 @@ func (wp *WorkerPool) CancelAll() {
 	// Clear the active jobs map
 	wp.activeJobs = sync.Map{}
 	
+	wp.mu.RLock()
+	jobs := wp.run.jobs
+	wp.mu.RUnlock()
+
 	// Drain the job queue (non-blocking)
 	drained := 0
 	for {
 		select {
-		case <-wp.jobs:
+		case <-jobs:
 			drained++
 		default:
 			// Queue is empty
@@ -271,6 +380,37 @@ func (wp *WorkerPool) GetActiveJobCount() int {
 	return count
 }
 
+// PoolStatus is a snapshot of the worker pool, for diagnosing a queue that isn't moving
+type PoolStatus struct {
+	Running       bool     `json:"running"`
+	ActiveJobs    int      `json:"active_jobs"`
+	MaxWorkers    int      `json:"max_workers"`
+	QueuedJobs    int      `json:"queued_jobs"`    // Submitted jobs waiting in the buffer for a free worker
+	QueueCapacity int      `json:"queue_capacity"` // Size of that buffer; Submit blocks once it's full
+	ActiveJobIDs  []string `json:"active_job_ids"`
+}
+
+// Status returns the pool's current state. Active job IDs are sorted.
+func (wp *WorkerPool) Status() *PoolStatus {
+	wp.mu.RLock()
+	status := &PoolStatus{
+		Running:       wp.started && !wp.stopping,
+		MaxWorkers:    wp.maxWorkers,
+		QueuedJobs:    len(wp.run.jobs),
+		QueueCapacity: cap(wp.run.jobs),
+		ActiveJobIDs:  []string{},
+	}
+	wp.mu.RUnlock()
+
+	wp.activeJobs.Range(func(key, value interface{}) bool {
+		status.ActiveJobIDs = append(status.ActiveJobIDs, key.(string))
+		return true
+	})
+	sort.Strings(status.ActiveJobIDs)
+	status.ActiveJobs = len(status.ActiveJobIDs)
+	return status
+}
+
 // IsJobActive checks if a job is currently active
 func (wp *WorkerPool) IsJobActive(jobID string) bool {
 	_, ok := wp.activeJobs.Load(jobID)
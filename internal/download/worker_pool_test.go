@@ -3,6 +3,8 @@ package download
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 )
@@ -180,6 +182,44 @@ func TestWorkerPoolActiveJobCount(t *testing.T) {
 	}
 }
 
+func TestWorkerPoolStatus(t *testing.T) {
+	started := make(chan string, 4)
+	unblock := make(chan struct{})
+	handler := func(ctx context.Context, job *Job) error {
+		started <- job.ID
+		<-unblock
+		return nil
+	}
+
+	pool := NewWorkerPool(2, handler)
+	if status := pool.Status(); status.Running || status.ActiveJobs != 0 || len(status.ActiveJobIDs) != 0 {
+		t.Errorf("Expected an idle pool before Start, got %+v", status)
+	}
+
+	if err := pool.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pool: %v", err)
+	}
+	defer pool.Stop()
+	defer close(unblock)
+
+	for _, id := range []string{"track_b", "track_a", "track_c", "track_d"} {
+		pool.Submit(&Job{ID: id, Type: JobTypeTrack})
+	}
+	<-started
+	<-started
+
+	status := pool.Status()
+	if !status.Running || status.MaxWorkers != 2 || status.ActiveJobs != 2 || status.QueuedJobs != 2 {
+		t.Errorf("Expected 2 of 2 workers busy with 2 jobs buffered, got %+v", status)
+	}
+	if len(status.ActiveJobIDs) != 2 || status.ActiveJobIDs[0] != "track_a" || status.ActiveJobIDs[1] != "track_b" {
+		t.Errorf("Expected the first two jobs active in sorted order, got %v", status.ActiveJobIDs)
+	}
+	if status.QueueCapacity == 0 {
+		t.Error("Expected the buffer capacity to be reported")
+	}
+}
+
 func TestWorkerPoolErrorHandling(t *testing.T) {
 	expectedError := errors.New("test error")
 
@@ -223,3 +263,142 @@ func TestWorkerPoolErrorHandling(t *testing.T) {
 		t.Error("Timeout waiting for error job result")
 	}
 }
+
+func TestWorkerPoolShutdownWaitsForActiveJobs(t *testing.T) {
+	started := make(chan struct{})
+	finished := make(chan struct{})
+
+	handler := func(ctx context.Context, job *Job) error {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		close(finished)
+		return nil
+	}
+
+	pool := NewWorkerPool(1, handler)
+	if err := pool.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pool: %v", err)
+	}
+
+	if err := pool.Submit(&Job{ID: "job1", Type: JobTypeTrack}); err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown returned error: %v", err)
+	}
+
+	select {
+	case <-finished:
+	default:
+		t.Error("Expected the running job to finish before Shutdown returned")
+	}
+
+	if err := pool.Submit(&Job{ID: "job2", Type: JobTypeTrack}); err == nil {
+		t.Error("Expected Submit to fail after Shutdown")
+	}
+}
+
+func TestWorkerPoolShutdownTimeout(t *testing.T) {
+	cancelled := make(chan struct{})
+
+	handler := func(ctx context.Context, job *Job) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	}
+
+	pool := NewWorkerPool(1, handler)
+	if err := pool.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start pool: %v", err)
+	}
+	if err := pool.Submit(&Job{ID: "stuck", Type: JobTypeTrack}); err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+
+	// Wait for the job to become active
+	for i := 0; i < 100 && !pool.IsJobActive("stuck"); i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err == nil {
+		t.Error("Expected Shutdown to report the job still running")
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("Expected the stuck job to be cancelled after the timeout")
+	}
+}
+
+func TestWorkerPoolRestart(t *testing.T) {
+	var mu sync.Mutex
+	handled := make(map[string]int)
+	handler := func(ctx context.Context, job *Job) error {
+		mu.Lock()
+		handled[job.ID]++
+		mu.Unlock()
+		return nil
+	}
+
+	pool := NewWorkerPool(2, handler)
+	for i, stop := range []func(){
+		pool.Stop,
+		func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			if err := pool.Shutdown(ctx); err != nil {
+				t.Errorf("Shutdown failed: %v", err)
+			}
+		},
+		pool.Stop,
+	} {
+		if err := pool.Start(context.Background()); err != nil {
+			t.Fatalf("Run %d: failed to start pool: %v", i, err)
+		}
+		results := pool.Results()
+		jobID := fmt.Sprintf("job%d", i)
+		if err := pool.Submit(&Job{ID: jobID, Type: JobTypeTrack}); err != nil {
+			t.Fatalf("Run %d: submit failed: %v", i, err)
+		}
+
+		select {
+		case result := <-results:
+			if result.JobID != jobID || !result.Success {
+				t.Errorf("Run %d: unexpected result %+v", i, result)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Run %d: timed out waiting for the result", i)
+		}
+
+		stop()
+		select {
+		case _, ok := <-results:
+			if ok {
+				t.Errorf("Run %d: expected no further results", i)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("Run %d: expected the results channel to be closed on stop", i)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for jobID, count := range handled {
+		if count != 1 {
+			t.Errorf("Expected %s to be handled once, got %d", jobID, count)
+		}
+	}
+	if len(handled) != 3 {
+		t.Errorf("Expected a job per run, got %v", handled)
+	}
+}
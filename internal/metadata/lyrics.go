@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"unicode/utf16"
 
 	"github.com/bogem/id3v2/v2"
 	"github.com/go-flac/flacvorbis"
@@ -18,6 +20,10 @@ type LyricsConfig struct {
 	Language         string
 }
 
+// syltFrameID is the ID3v2 frame ID for synchronized lyrics
+// id3v2 has no common ID for it, so CommonID would return the description itself
+const syltFrameID = "SYLT"
+
 // Lyrics represents track lyrics
 type Lyrics struct {
 	SyncedLyrics   string
@@ -75,14 +81,20 @@ func (m *Manager) embedMP3Lyrics(filePath string, lyrics *Lyrics, config *Lyrics
 	}
 	defer tag.Close()
 
+	// Use the configured ID3 version, or keep the one the file was tagged with
+	if m.config.ID3Version != 0 {
+		tag.SetVersion(m.id3Version())
+	}
+	encoding := id3Encoding(tag.Version())
+
 	// Remove existing lyrics frames
 	tag.DeleteFrames(tag.CommonID("Unsynchronised lyrics/text transcription"))
-	tag.DeleteFrames(tag.CommonID("Synchronised lyrics/text"))
+	tag.DeleteFrames(syltFrameID)
 
 	// Add unsynchronized lyrics (USLT frame)
 	if lyrics.UnsyncedLyrics != "" {
 		usltFrame := id3v2.UnsynchronisedLyricsFrame{
-			Encoding:          id3v2.EncodingUTF8,
+			Encoding:          encoding,
 			Language:          config.Language,
 			ContentDescriptor: "",
 			Lyrics:            lyrics.UnsyncedLyrics,
@@ -93,9 +105,9 @@ func (m *Manager) embedMP3Lyrics(filePath string, lyrics *Lyrics, config *Lyrics
 	// Add synchronized lyrics (SYLT frame) if available
 	if lyrics.SyncedLyrics != "" {
 		// Parse LRC format to create SYLT frame
-		syltFrame := m.createSYLTFrame(lyrics.SyncedLyrics, config.Language)
+		syltFrame := m.createSYLTFrame(lyrics.SyncedLyrics, config.Language, encoding)
 		if syltFrame != nil {
-			tag.AddFrame(tag.CommonID("Synchronised lyrics/text"), syltFrame)
+			tag.AddFrame(syltFrameID, syltFrame)
 		}
 	}
 
@@ -107,11 +119,78 @@ func (m *Manager) embedMP3Lyrics(filePath string, lyrics *Lyrics, config *Lyrics
 	return nil
 }
 
+// SyncedLine is a single timed line of synchronized lyrics
+type SyncedLine struct {
+	Milliseconds int
+	Text         string
+}
+
+// ParseLRC parses LRC content into timed lines sorted by time
+// Lines with several timestamps ([00:12.00][01:30.00]text) produce one entry per timestamp,
+// ID tags like [ar:] and [ti:] are skipped, [offset:] is applied, and malformed lines are ignored
+func (m *Manager) ParseLRC(lrcLyrics string) []SyncedLine {
+	var lines []SyncedLine
+	offset := 0
+
+	for _, raw := range strings.Split(lrcLyrics, "\n") {
+		line := strings.TrimSpace(strings.TrimSuffix(raw, "\r"))
+		if !strings.HasPrefix(line, "[") {
+			continue
+		}
+
+		// Collect every leading [..] tag on the line
+		var timestamps []int
+		rest := line
+		for strings.HasPrefix(rest, "[") {
+			closeBracket := strings.Index(rest, "]")
+			if closeBracket == -1 {
+				break
+			}
+			tagContent := rest[1:closeBracket]
+			rest = rest[closeBracket+1:]
+
+			if ms := m.lrcTimestampToMilliseconds(tagContent); ms >= 0 {
+				timestamps = append(timestamps, ms)
+				continue
+			}
+
+			// [offset:+/-ms] shifts all timestamps (positive means lyrics appear sooner)
+			if strings.HasPrefix(strings.ToLower(tagContent), "offset:") {
+				var value int
+				if _, err := fmt.Sscanf(strings.TrimSpace(tagContent[len("offset:"):]), "%d", &value); err == nil {
+					offset = value
+				}
+			}
+		}
+
+		text := strings.TrimSpace(rest)
+		for _, ms := range timestamps {
+			lines = append(lines, SyncedLine{Milliseconds: ms, Text: text})
+		}
+	}
+
+	for i := range lines {
+		lines[i].Milliseconds -= offset
+		if lines[i].Milliseconds < 0 {
+			lines[i].Milliseconds = 0
+		}
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Milliseconds < lines[j].Milliseconds
+	})
+
+	return lines
+}
+
 // createSYLTFrame creates a SYLT (Synchronized Lyrics) frame from LRC format
-func (m *Manager) createSYLTFrame(lrcLyrics string, language string) id3v2.Framer {
-	// Parse LRC format
-	lines := strings.Split(lrcLyrics, "\n")
-	
+// Returns nil if the LRC content contains no valid timed lines
+func (m *Manager) createSYLTFrame(lrcLyrics string, language string, encoding id3v2.Encoding) id3v2.Framer {
+	lines := m.ParseLRC(lrcLyrics)
+	if len(lines) == 0 {
+		return nil
+	}
+
 	// Build SYLT frame data
 	// SYLT frame format:
 	// - Text encoding (1 byte)
@@ -123,8 +202,8 @@ func (m *Manager) createSYLTFrame(lrcLyrics string, language string) id3v2.Frame
 	
 	var frameData []byte
 	
-	// Text encoding (UTF-8)
-	frameData = append(frameData, id3v2.EncodingUTF8.Key)
+	// Text encoding (UTF-8, or UTF-16 for ID3v2.3)
+	frameData = append(frameData, encoding.Key)
 	
 	// Language (3 bytes)
 	if len(language) < 3 {
@@ -139,39 +218,18 @@ func (m *Manager) createSYLTFrame(lrcLyrics string, language string) id3v2.Frame
 	frameData = append(frameData, 0x01)
 	
 	// Content descriptor (empty, null-terminated)
-	frameData = append(frameData, 0x00)
+	frameData = append(frameData, encoding.TerminationBytes...)
 	
-	// Parse and add synchronized text
+	// Add synchronized text
+	timestamp := make([]byte, 4)
 	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || !strings.HasPrefix(line, "[") {
-			continue
-		}
-		
-		// Parse LRC line: [mm:ss.xx]text
-		closeBracket := strings.Index(line, "]")
-		if closeBracket == -1 {
-			continue
-		}
-		
-		timestamp := line[1:closeBracket]
-		text := strings.TrimSpace(line[closeBracket+1:])
-		
-		// Convert timestamp to milliseconds
-		ms := m.lrcTimestampToMilliseconds(timestamp)
-		if ms < 0 {
-			continue
-		}
-		
 		// Add text (null-terminated)
-		frameData = append(frameData, []byte(text)...)
-		frameData = append(frameData, 0x00)
+		frameData = append(frameData, encodeSYLTText(line.Text, encoding)...)
+		frameData = append(frameData, encoding.TerminationBytes...)
 		
 		// Add timestamp (4 bytes, big-endian)
-		frameData = append(frameData, byte(ms>>24))
-		frameData = append(frameData, byte(ms>>16))
-		frameData = append(frameData, byte(ms>>8))
-		frameData = append(frameData, byte(ms))
+		writeUint32BE(timestamp, uint32(line.Milliseconds))
+		frameData = append(frameData, timestamp...)
 	}
 	
 	// Create custom frame
@@ -203,9 +261,12 @@ func (m *Manager) lrcTimestampToMilliseconds(timestamp string) int {
 		return -1
 	}
 	
-	// Handle both .xx and .xxx formats
+	// Handle .x, .xx and .xxx formats
 	centiseconds := secondsParts[1]
-	if len(centiseconds) == 2 {
+	if len(centiseconds) == 0 || len(centiseconds) > 3 {
+		return -1
+	}
+	for len(centiseconds) < 3 {
 		centiseconds += "0"
 	}
 	var ms int
@@ -330,7 +391,7 @@ func (m *Manager) getMP3Lyrics(filePath string) (*Lyrics, error) {
 	}
 
 	// Get synchronized lyrics (SYLT frame)
-	syltFrames := tag.GetFrames(tag.CommonID("Synchronised lyrics/text"))
+	syltFrames := tag.GetFrames(syltFrameID)
 	if len(syltFrames) > 0 {
 		// Parse SYLT frame to LRC format
 		if sylt, ok := syltFrames[0].(id3v2.UnknownFrame); ok {
@@ -341,31 +402,73 @@ func (m *Manager) getMP3Lyrics(filePath string) (*Lyrics, error) {
 	return lyrics, nil
 }
 
+// encodeSYLTText encodes SYLT text; UTF-16 (ID3v2.3) is little-endian with a BOM
+func encodeSYLTText(text string, encoding id3v2.Encoding) []byte {
+	if encoding.Key != id3v2.EncodingUTF16.Key {
+		return []byte(text)
+	}
+	data := []byte{0xFF, 0xFE}
+	for _, unit := range utf16.Encode([]rune(text)) {
+		data = append(data, byte(unit), byte(unit>>8))
+	}
+	return data
+}
+
+// readSYLTText reads a null-terminated SYLT string starting at pos and returns it with
+// the position after its terminator. UTF-16 strings end with two null bytes on a
+// two-byte boundary.
+func readSYLTText(data []byte, pos int, encodingKey byte) (string, int) {
+	if encodingKey != id3v2.EncodingUTF16.Key && encodingKey != id3v2.EncodingUTF16BE.Key {
+		start := pos
+		for pos < len(data) && data[pos] != 0 {
+			pos++
+		}
+		return string(data[start:pos]), pos + 1
+	}
+
+	start := pos
+	for pos+1 < len(data) && (data[pos] != 0 || data[pos+1] != 0) {
+		pos += 2
+	}
+	raw := data[start:min(pos, len(data))]
+	end := pos + 2
+
+	bigEndian := encodingKey == id3v2.EncodingUTF16BE.Key
+	if len(raw) >= 2 && raw[0] == 0xFE && raw[1] == 0xFF {
+		bigEndian, raw = true, raw[2:]
+	} else if len(raw) >= 2 && raw[0] == 0xFF && raw[1] == 0xFE {
+		bigEndian, raw = false, raw[2:]
+	}
+	units := make([]uint16, 0, len(raw)/2)
+	for i := 0; i+1 < len(raw); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+		} else {
+			units = append(units, uint16(raw[i+1])<<8|uint16(raw[i]))
+		}
+	}
+	return string(utf16.Decode(units)), end
+}
+
 // parseSYLTFrame parses a SYLT frame to LRC format
 func (m *Manager) parseSYLTFrame(frameData []byte) string {
 	if len(frameData) < 6 {
 		return ""
 	}
+	encodingKey := frameData[0]
 
 	// Skip encoding (1), language (3), timestamp format (1), content type (1)
 	pos := 6
 
 	// Skip content descriptor (null-terminated string)
-	for pos < len(frameData) && frameData[pos] != 0 {
-		pos++
-	}
-	pos++ // Skip null terminator
+	_, pos = readSYLTText(frameData, pos, encodingKey)
 
 	// Parse synchronized text
 	var lrcBuilder strings.Builder
 	for pos < len(frameData) {
 		// Read text until null terminator
-		textStart := pos
-		for pos < len(frameData) && frameData[pos] != 0 {
-			pos++
-		}
-		text := string(frameData[textStart:pos])
-		pos++ // Skip null terminator
+		var text string
+		text, pos = readSYLTText(frameData, pos, encodingKey)
 
 		// Read timestamp (4 bytes)
 		if pos+4 > len(frameData) {
@@ -454,7 +557,7 @@ func (m *Manager) removeMP3Lyrics(filePath string) error {
 
 	// Remove lyrics frames
 	tag.DeleteFrames(tag.CommonID("Unsynchronised lyrics/text transcription"))
-	tag.DeleteFrames(tag.CommonID("Synchronised lyrics/text"))
+	tag.DeleteFrames(syltFrameID)
 
 	if err := tag.Save(); err != nil {
 		return fmt.Errorf("failed to save MP3 file: %w", err)
@@ -528,3 +631,91 @@ func (m *Manager) LoadLyricsFromFiles(audioFilePath string) (*Lyrics, error) {
 
 	return lyrics, nil
 }
+
+// Synced lyrics sidecar formats
+const (
+	SyncedFormatLRC         = "lrc"
+	SyncedFormatEnhancedLRC = "enhanced_lrc"
+	SyncedFormatSRT         = "srt"
+)
+
+// lastLineDurationMs is how long the final line lasts, since no later line marks its end
+const lastLineDurationMs = 5000
+
+// ConvertSyncedLyrics converts LRC lyrics to the given sidecar format and returns the
+// converted text with the file extension to save it under (".lrc" or ".srt").
+// Deezer only times whole lines, so enhanced LRC word timings are spread across each line
+// in proportion to word length.
+func (m *Manager) ConvertSyncedLyrics(lrcLyrics string, format string) (string, string, error) {
+	switch format {
+	case "", SyncedFormatLRC:
+		return lrcLyrics, ".lrc", nil
+	case SyncedFormatEnhancedLRC:
+		return m.toEnhancedLRC(m.ParseLRC(lrcLyrics)), ".lrc", nil
+	case SyncedFormatSRT:
+		return m.toSRT(m.ParseLRC(lrcLyrics)), ".srt", nil
+	default:
+		return "", "", fmt.Errorf("unsupported synced lyrics format: %s", format)
+	}
+}
+
+// lineEnd returns when lines[i] stops showing: the start of the next later line, or a fixed
+// duration after the last one
+func lineEnd(lines []SyncedLine, i int) int {
+	for j := i + 1; j < len(lines); j++ {
+		if lines[j].Milliseconds > lines[i].Milliseconds {
+			return lines[j].Milliseconds
+		}
+	}
+	return lines[i].Milliseconds + lastLineDurationMs
+}
+
+// toEnhancedLRC writes each line as [mm:ss.xx]<mm:ss.xx>word <mm:ss.xx>word ...
+func (m *Manager) toEnhancedLRC(lines []SyncedLine) string {
+	var b strings.Builder
+	for i, line := range lines {
+		fmt.Fprintf(&b, "[%s]", m.millisecondsToLRCTimestamp(line.Milliseconds))
+
+		words := strings.Fields(line.Text)
+		totalChars := 0
+		for _, word := range words {
+			totalChars += len([]rune(word))
+		}
+
+		start := line.Milliseconds
+		duration := lineEnd(lines, i) - start
+		elapsedChars := 0
+		for j, word := range words {
+			if j > 0 {
+				b.WriteString(" ")
+			}
+			wordStart := start + duration*elapsedChars/totalChars
+			fmt.Fprintf(&b, "<%s>%s", m.millisecondsToLRCTimestamp(wordStart), word)
+			elapsedChars += len([]rune(word))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// toSRT writes one numbered cue per non-empty line, lasting until the next line starts
+func (m *Manager) toSRT(lines []SyncedLine) string {
+	var b strings.Builder
+	cue := 0
+	for i, line := range lines {
+		if line.Text == "" {
+			continue // Instrumental gap - only ends the previous cue
+		}
+		cue++
+		if cue > 1 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n", cue, srtTimestamp(line.Milliseconds), srtTimestamp(lineEnd(lines, i)), line.Text)
+	}
+	return b.String()
+}
+
+// srtTimestamp formats milliseconds as HH:MM:SS,mmm
+func srtTimestamp(ms int) string {
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -21,25 +22,139 @@ type Manager struct {
 type Config struct {
 	EmbedArtwork bool
 	ArtworkSize  int
+	ID3Version   byte // ID3v2 major version for MP3 tags, ID3v23 or ID3v24; 0 means ID3v24
+}
+
+// ID3v2 major versions MP3 tags can be written as
+const (
+	ID3v23 byte = 3
+	ID3v24 byte = 4
+)
+
+// id3Version returns the configured ID3v2 version
+func (m *Manager) id3Version() byte {
+	if m.config.ID3Version == ID3v23 {
+		return ID3v23
+	}
+	return ID3v24
+}
+
+// id3Encoding returns the text encoding for an ID3v2 version. ID3v2.3 has no UTF-8, so
+// text there is UTF-16 to keep non-Latin titles intact.
+func id3Encoding(version byte) id3v2.Encoding {
+	if version == ID3v23 {
+		return id3v2.EncodingUTF16
+	}
+	return id3v2.EncodingUTF8
+}
+
+// id3Text prepares a text frame value. ID3v2.4 separates multiple values with a null
+// byte, which ID3v2.3 readers show as a cut-off string, so they are joined with "/" there.
+func id3Text(version byte, text string) string {
+	if version == ID3v23 {
+		return strings.ReplaceAll(text, "\x00", "/")
+	}
+	return text
+}
+
+// originalDateID returns the original release date frame: TORY (year only) in ID3v2.3,
+// TDOR in ID3v2.4
+func originalDateID(version byte) string {
+	if version == ID3v23 {
+		return "TORY"
+	}
+	return "TDOR"
 }
 
 // TrackMetadata contains all metadata for a track
 type TrackMetadata struct {
-	Title       string
-	Artist      string
-	Album       string
-	AlbumArtist string
-	TrackNumber int
-	DiscNumber  int
-	TotalDiscs  int    // Total number of discs in the album
-	Year        int
-	Genre       string
-	Duration    int
-	ISRC        string
-	Label       string
-	Copyright   string
-	ArtworkData []byte
-	ArtworkMIME string
+	Title        string
+	Artist       string
+	Album        string
+	AlbumArtist  string
+	TrackNumber  int
+	TotalTracks  int    // Total number of tracks in the album
+	DiscNumber   int
+	TotalDiscs   int    // Total number of discs in the album
+	Year         int
+	ReleaseDate  string // Full release date (YYYY-MM-DD); written instead of Year when set
+	OriginalDate string // Original release date (YYYY-MM-DD), differs from Year for reissues
+	Genre        string
+	Duration     int
+	ISRC         string
+	Label        string
+	Copyright    string
+	ArtworkData  []byte
+	ArtworkMIME  string
+	Pictures     []Picture // Further images to embed, e.g. the artist; ArtworkData is always the front cover
+	ReplayGain   string    // REPLAYGAIN_TRACK_GAIN value, e.g. "-6.40 dB"
+	Compilation  bool      // Part of a compilation (TCMP / COMPILATION), so players group it under the album artist
+}
+
+// replayGainTag is the tag name players look for, written as a TXXX description in MP3s
+const replayGainTag = "REPLAYGAIN_TRACK_GAIN"
+
+// replayGainReference is the loudness, in dB, that ReplayGain normalizes to
+const replayGainReference = -18.4
+
+// DeezerReplayGain converts a Deezer track gain (the track's loudness in dB) into a
+// ReplayGain track gain, without analysing the audio
+func DeezerReplayGain(gain float64) string {
+	return fmt.Sprintf("%.2f dB", replayGainReference-gain)
+}
+
+// Picture types shared by ID3v2 APIC frames and FLAC picture blocks
+const (
+	PictureFrontCover byte = 3
+	PictureBackCover  byte = 4
+	PictureArtist     byte = 8
+)
+
+// Picture is an embedded image and what it shows
+type Picture struct {
+	Type byte
+	Data []byte
+	MIME string
+}
+
+// description names the picture type; ID3v2 requires a distinct description per APIC frame
+func (p Picture) description() string {
+	switch p.Type {
+	case PictureFrontCover:
+		return "Front Cover"
+	case PictureBackCover:
+		return "Back Cover"
+	case PictureArtist:
+		return "Artist"
+	default:
+		return fmt.Sprintf("Picture %d", p.Type)
+	}
+}
+
+// embeddedPictures returns the front cover followed by the other pictures, one per type,
+// skipping any whose bytes are already embedded (e.g. an artist image that is the cover)
+func (metadata *TrackMetadata) embeddedPictures() []Picture {
+	var pictures []Picture
+	if len(metadata.ArtworkData) > 0 {
+		pictures = append(pictures, Picture{Type: PictureFrontCover, Data: metadata.ArtworkData, MIME: metadata.ArtworkMIME})
+	}
+
+	for _, picture := range metadata.Pictures {
+		if len(picture.Data) == 0 {
+			continue
+		}
+		duplicate := false
+		for _, embedded := range pictures {
+			if embedded.Type == picture.Type || bytes.Equal(embedded.Data, picture.Data) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			pictures = append(pictures, picture)
+		}
+	}
+	return pictures
 }
 
 // NewManager creates a new metadata manager
@@ -81,26 +196,66 @@ func (m *Manager) applyMP3Metadata(filePath string, metadata *TrackMetadata) err
 	}
 	defer tag.Close()
 
-	// Set ID3v2.4 version
-	tag.SetVersion(4)
+	// Set the ID3v2 version; SetVersion doesn't convert frames, so the text encoding is
+	// chosen here and date frames of the other version are removed below
+	version := m.id3Version()
+	tag.SetVersion(version)
+	encoding := id3Encoding(version)
+	tag.SetDefaultEncoding(encoding)
 
 	// Set basic text frames
 	if metadata.Title != "" {
-		tag.SetTitle(metadata.Title)
+		tag.SetTitle(id3Text(version, metadata.Title))
 	}
 	if metadata.Artist != "" {
-		tag.SetArtist(metadata.Artist)
+		tag.SetArtist(id3Text(version, metadata.Artist))
 	}
 	if metadata.Album != "" {
-		tag.SetAlbum(metadata.Album)
+		tag.SetAlbum(id3Text(version, metadata.Album))
 	}
 	if metadata.Genre != "" {
-		tag.SetGenre(metadata.Genre)
+		tag.SetGenre(id3Text(version, metadata.Genre))
 	}
 
-	// Set year
-	if metadata.Year > 0 {
-		tag.SetYear(strconv.Itoa(metadata.Year))
+	if version == ID3v23 {
+		tag.DeleteFrames("TDRC")
+		tag.DeleteFrames("TDOR")
+
+		// Set release year (TYER) and, from the full date, day and month (TDAT, DDMM)
+		year := metadata.Year
+		if metadata.ReleaseDate != "" {
+			if dateYear, _ := parseDate(metadata.ReleaseDate); dateYear > 0 {
+				year = dateYear
+			}
+		}
+		if year > 0 {
+			tag.SetYear(fmt.Sprintf("%04d", year))
+			tag.DeleteFrames("TDAT")
+			if len(metadata.ReleaseDate) >= 10 {
+				tag.AddTextFrame("TDAT", encoding, metadata.ReleaseDate[8:10]+metadata.ReleaseDate[5:7])
+			}
+		}
+	} else {
+		tag.DeleteFrames("TYER")
+		tag.DeleteFrames("TDAT")
+		tag.DeleteFrames("TORY")
+
+		// Set release date (TDRC - this release), as the full date when known
+		if metadata.ReleaseDate != "" {
+			tag.SetYear(metadata.ReleaseDate)
+		} else if metadata.Year > 0 {
+			tag.SetYear(strconv.Itoa(metadata.Year))
+		}
+	}
+
+	// Set original release date (TDOR, or just the year in TORY)
+	if originalYear, _ := parseDate(metadata.OriginalDate); originalYear > 0 {
+		originalDate := metadata.OriginalDate
+		if version == ID3v23 {
+			originalDate = originalDate[:4]
+		}
+		tag.DeleteFrames(originalDateID(version))
+		tag.AddTextFrame(originalDateID(version), encoding, originalDate)
 	}
 
 	// Set album artist (TPE2 frame)
@@ -108,13 +263,23 @@ func (m *Manager) applyMP3Metadata(filePath string, metadata *TrackMetadata) err
 		// Try to delete existing frame first
 		tag.DeleteFrames("TPE2")
 		// Add new frame
-		tag.AddTextFrame("TPE2", id3v2.EncodingUTF8, metadata.AlbumArtist)
+		tag.AddTextFrame("TPE2", encoding, id3Text(version, metadata.AlbumArtist))
+	}
+
+	// Set the iTunes compilation flag (TCMP frame)
+	if metadata.Compilation {
+		tag.DeleteFrames("TCMP")
+		tag.AddTextFrame("TCMP", encoding, "1")
 	}
 
-	// Set track number with disc number if multi-disc
+	// Set track number (TRCK frame)
+	// Format: "track/total" (e.g., "3/14") when the album's track count is known
 	if metadata.TrackNumber > 0 {
 		trackStr := strconv.Itoa(metadata.TrackNumber)
-		tag.AddTextFrame(tag.CommonID("Track number/Position in set"), id3v2.EncodingUTF8, trackStr)
+		if metadata.TotalTracks > 0 {
+			trackStr = fmt.Sprintf("%d/%d", metadata.TrackNumber, metadata.TotalTracks)
+		}
+		tag.AddTextFrame(tag.CommonID("Track number/Position in set"), encoding, trackStr)
 	}
 
 	// Set disc number for multi-disc albums (TPOS frame)
@@ -129,34 +294,71 @@ func (m *Manager) applyMP3Metadata(filePath string, metadata *TrackMetadata) err
 		// Try to delete existing frame first
 		tag.DeleteFrames("TPOS")
 		// Add new frame
-		tag.AddTextFrame("TPOS", id3v2.EncodingUTF8, discStr)
+		tag.AddTextFrame("TPOS", encoding, discStr)
 	}
 
 	// Set ISRC (International Standard Recording Code)
 	if metadata.ISRC != "" {
-		tag.AddTextFrame(tag.CommonID("ISRC"), id3v2.EncodingUTF8, metadata.ISRC)
+		tag.AddTextFrame(tag.CommonID("ISRC"), encoding, metadata.ISRC)
 	}
 
 	// Set label/publisher
 	if metadata.Label != "" {
-		tag.AddTextFrame(tag.CommonID("Publisher"), id3v2.EncodingUTF8, metadata.Label)
+		tag.AddTextFrame(tag.CommonID("Publisher"), encoding, id3Text(version, metadata.Label))
 	}
 
 	// Set copyright
 	if metadata.Copyright != "" {
-		tag.AddTextFrame(tag.CommonID("Copyright message"), id3v2.EncodingUTF8, metadata.Copyright)
+		tag.AddTextFrame(tag.CommonID("Copyright message"), encoding, id3Text(version, metadata.Copyright))
 	}
 
-	// Embed artwork if enabled and available
-	if m.config.EmbedArtwork && len(metadata.ArtworkData) > 0 {
-		pic := id3v2.PictureFrame{
-			Encoding:    id3v2.EncodingUTF8,
-			MimeType:    metadata.ArtworkMIME,
-			PictureType: id3v2.PTFrontCover,
-			Description: "Front Cover",
-			Picture:     metadata.ArtworkData,
+	// Set ReplayGain, replacing the value from an earlier tagging
+	if metadata.ReplayGain != "" {
+		txxxID := tag.CommonID("User defined text information frame")
+		existing := tag.GetFrames(txxxID)
+		tag.DeleteFrames(txxxID)
+		for _, frame := range existing {
+			if udtf, ok := frame.(id3v2.UserDefinedTextFrame); ok && udtf.Description != replayGainTag {
+				tag.AddUserDefinedTextFrame(udtf)
+			}
+		}
+		tag.AddUserDefinedTextFrame(id3v2.UserDefinedTextFrame{
+			Encoding:    encoding,
+			Description: replayGainTag,
+			Value:       metadata.ReplayGain,
+		})
+	}
+
+	// Embed artwork if enabled and available, replacing pictures of the same type from
+	// an earlier tagging so re-tagging doesn't stack them
+	if pictures := metadata.embeddedPictures(); m.config.EmbedArtwork && len(pictures) > 0 {
+		replaced := make(map[byte]bool, len(pictures))
+		for _, picture := range pictures {
+			replaced[picture.Type] = true
+		}
+
+		apicID := tag.CommonID("Attached picture")
+		existing := tag.GetFrames(apicID)
+		tag.DeleteFrames(apicID)
+		for _, frame := range existing {
+			if pic, ok := frame.(id3v2.PictureFrame); ok && !replaced[pic.PictureType] {
+				tag.AddAttachedPicture(pic)
+			}
+		}
+
+		for _, picture := range pictures {
+			mimeType := picture.MIME
+			if mimeType == "" {
+				mimeType = "image/jpeg"
+			}
+			tag.AddAttachedPicture(id3v2.PictureFrame{
+				Encoding:    encoding,
+				MimeType:    mimeType,
+				PictureType: picture.Type,
+				Description: picture.description(),
+				Picture:     picture.Data,
+			})
 		}
-		tag.AddAttachedPicture(pic)
 	}
 
 	// Save changes
@@ -212,15 +414,26 @@ func (m *Manager) applyFLACMetadata(filePath string, metadata *TrackMetadata) er
 	if metadata.AlbumArtist != "" {
 		cmt.Add("ALBUMARTIST", metadata.AlbumArtist)
 	}
+	if metadata.Compilation {
+		cmt.Add("COMPILATION", "1")
+	}
 	if metadata.Genre != "" {
 		cmt.Add("GENRE", metadata.Genre)
 	}
-	if metadata.Year > 0 {
+	if metadata.ReleaseDate != "" {
+		cmt.Add("DATE", metadata.ReleaseDate)
+	} else if metadata.Year > 0 {
 		cmt.Add("DATE", strconv.Itoa(metadata.Year))
 	}
+	if metadata.OriginalDate != "" {
+		cmt.Add("ORIGINALDATE", metadata.OriginalDate)
+	}
 	if metadata.TrackNumber > 0 {
 		cmt.Add("TRACKNUMBER", strconv.Itoa(metadata.TrackNumber))
 	}
+	if metadata.TotalTracks > 0 {
+		cmt.Add("TRACKTOTAL", strconv.Itoa(metadata.TotalTracks))
+	}
 	if metadata.DiscNumber > 0 {
 		if metadata.TotalDiscs > 0 {
 			cmt.Add("DISCNUMBER", fmt.Sprintf("%d/%d", metadata.DiscNumber, metadata.TotalDiscs))
@@ -229,6 +442,7 @@ func (m *Manager) applyFLACMetadata(filePath string, metadata *TrackMetadata) er
 		}
 	}
 	if metadata.TotalDiscs > 0 {
+		cmt.Add("DISCTOTAL", strconv.Itoa(metadata.TotalDiscs))
 		cmt.Add("TOTALDISCS", strconv.Itoa(metadata.TotalDiscs))
 	}
 	if metadata.ISRC != "" {
@@ -240,27 +454,31 @@ func (m *Manager) applyFLACMetadata(filePath string, metadata *TrackMetadata) er
 	if metadata.Copyright != "" {
 		cmt.Add("COPYRIGHT", metadata.Copyright)
 	}
+	if metadata.ReplayGain != "" {
+		cmt.Add(replayGainTag, metadata.ReplayGain)
+	}
 
 	// Marshal comments back to block
 	res := cmt.Marshal()
 	cmtBlock.Data = res.Data
 
 	// Handle artwork for FLAC
-	if m.config.EmbedArtwork && len(metadata.ArtworkData) > 0 {
-		// Check if picture block already exists
-		hasPicture := false
+	if pictures := metadata.embeddedPictures(); m.config.EmbedArtwork && len(pictures) > 0 {
+		// Keep picture blocks that are already there, adding only the missing types
+		hasPicture := make(map[byte]bool)
 		for _, block := range f.Meta {
-			if block.Type == flac.Picture {
-				hasPicture = true
-				break
+			if block.Type == flac.Picture && len(block.Data) >= 4 {
+				hasPicture[block.Data[3]] = true
 			}
 		}
 
-		// Add picture block if not present
-		if !hasPicture {
+		for _, picture := range pictures {
+			if hasPicture[picture.Type] {
+				continue
+			}
 			picBlock := &flac.MetaDataBlock{
 				Type: flac.Picture,
-				Data: m.createFLACPictureBlock(metadata.ArtworkData, metadata.ArtworkMIME),
+				Data: m.createFLACPictureBlock(picture),
 			}
 			f.Meta = append(f.Meta, picBlock)
 		}
@@ -275,9 +493,9 @@ func (m *Manager) applyFLACMetadata(filePath string, metadata *TrackMetadata) er
 }
 
 // createFLACPictureBlock creates a FLAC picture block from image data
-func (m *Manager) createFLACPictureBlock(imageData []byte, mimeType string) []byte {
+func (m *Manager) createFLACPictureBlock(picture Picture) []byte {
 	// FLAC picture block format:
-	// 4 bytes: picture type (3 = front cover)
+	// 4 bytes: picture type (3 = front cover, 4 = back cover, 8 = artist)
 	// 4 bytes: MIME type length
 	// n bytes: MIME type string
 	// 4 bytes: description length
@@ -289,11 +507,13 @@ func (m *Manager) createFLACPictureBlock(imageData []byte, mimeType string) []by
 	// 4 bytes: picture data length
 	// n bytes: picture data
 
+	imageData := picture.Data
+	mimeType := picture.MIME
 	if mimeType == "" {
 		mimeType = "image/jpeg"
 	}
 
-	description := "Front Cover"
+	description := picture.description()
 	
 	// Calculate total size
 	size := 4 + 4 + len(mimeType) + 4 + len(description) + 4 + 4 + 4 + 4 + 4 + len(imageData)
@@ -301,8 +521,8 @@ func (m *Manager) createFLACPictureBlock(imageData []byte, mimeType string) []by
 	
 	pos := 0
 	
-	// Picture type (3 = front cover)
-	writeUint32BE(data[pos:], 3)
+	// Picture type
+	writeUint32BE(data[pos:], uint32(picture.Type))
 	pos += 4
 	
 	// MIME type length and string
@@ -424,10 +644,36 @@ func (m *Manager) getMP3Metadata(filePath string) (*TrackMetadata, error) {
 		Genre:  tag.Genre(),
 	}
 
-	// Parse year
+	// Parse year and, if present, the full date (ID3v2.3 keeps day and month in TDAT)
 	if yearStr := tag.Year(); yearStr != "" {
-		if year, err := strconv.Atoi(yearStr); err == nil {
-			metadata.Year = year
+		metadata.Year, metadata.ReleaseDate = parseDate(yearStr)
+		if tdat := tag.GetTextFrame("TDAT").Text; tag.Version() == ID3v23 && metadata.Year > 0 && len(tdat) == 4 {
+			metadata.ReleaseDate = fmt.Sprintf("%04d-%s-%s", metadata.Year, tdat[2:], tdat[:2])
+		}
+	}
+
+	// Get original release date
+	if frames := tag.GetFrames(originalDateID(tag.Version())); len(frames) > 0 {
+		if tf, ok := frames[0].(id3v2.TextFrame); ok {
+			metadata.OriginalDate = tf.Text
+		}
+	}
+
+	// Get ISRC
+	if frames := tag.GetFrames(tag.CommonID("ISRC")); len(frames) > 0 {
+		if tf, ok := frames[0].(id3v2.TextFrame); ok {
+			metadata.ISRC = tf.Text
+		}
+	}
+
+	// Get label and copyright
+	metadata.Label = tag.GetTextFrame(tag.CommonID("Publisher")).Text
+	metadata.Copyright = tag.GetTextFrame(tag.CommonID("Copyright message")).Text
+
+	// Get ReplayGain
+	for _, frame := range tag.GetFrames(tag.CommonID("User defined text information frame")) {
+		if udtf, ok := frame.(id3v2.UserDefinedTextFrame); ok && udtf.Description == replayGainTag {
+			metadata.ReplayGain = udtf.Value
 		}
 	}
 
@@ -438,21 +684,24 @@ func (m *Manager) getMP3Metadata(filePath string) (*TrackMetadata, error) {
 		}
 	}
 
+	// Get compilation flag
+	if frames := tag.GetFrames("TCMP"); len(frames) > 0 {
+		if tf, ok := frames[0].(id3v2.TextFrame); ok {
+			metadata.Compilation = tf.Text == "1"
+		}
+	}
+
 	// Get track number
 	if frames := tag.GetFrames(tag.CommonID("Track number/Position in set")); len(frames) > 0 {
 		if tf, ok := frames[0].(id3v2.TextFrame); ok {
-			if trackNum, err := strconv.Atoi(strings.Split(tf.Text, "/")[0]); err == nil {
-				metadata.TrackNumber = trackNum
-			}
+			metadata.TrackNumber, metadata.TotalTracks = parsePosition(tf.Text)
 		}
 	}
 
 	// Get disc number
 	if frames := tag.GetFrames(tag.CommonID("Part of a set")); len(frames) > 0 {
 		if tf, ok := frames[0].(id3v2.TextFrame); ok {
-			if discNum, err := strconv.Atoi(strings.Split(tf.Text, "/")[0]); err == nil {
-				metadata.DiscNumber = discNum
-			}
+			metadata.DiscNumber, metadata.TotalDiscs = parsePosition(tf.Text)
 		}
 	}
 
@@ -489,22 +738,44 @@ func (m *Manager) getFLACMetadata(filePath string) (*TrackMetadata, error) {
 			if albumArtists, err := cmt.Get("ALBUMARTIST"); err == nil && len(albumArtists) > 0 {
 				metadata.AlbumArtist = albumArtists[0]
 			}
+			if compilations, err := cmt.Get("COMPILATION"); err == nil && len(compilations) > 0 {
+				metadata.Compilation = compilations[0] == "1"
+			}
 			if genres, err := cmt.Get("GENRE"); err == nil && len(genres) > 0 {
 				metadata.Genre = genres[0]
 			}
 			if dates, err := cmt.Get("DATE"); err == nil && len(dates) > 0 {
-				if year, err := strconv.Atoi(dates[0]); err == nil {
-					metadata.Year = year
-				}
+				metadata.Year, metadata.ReleaseDate = parseDate(dates[0])
+			}
+			if originalDates, err := cmt.Get("ORIGINALDATE"); err == nil && len(originalDates) > 0 {
+				metadata.OriginalDate = originalDates[0]
+			}
+			if isrcs, err := cmt.Get("ISRC"); err == nil && len(isrcs) > 0 {
+				metadata.ISRC = isrcs[0]
+			}
+			if labels, err := cmt.Get("LABEL"); err == nil && len(labels) > 0 {
+				metadata.Label = labels[0]
+			}
+			if copyrights, err := cmt.Get("COPYRIGHT"); err == nil && len(copyrights) > 0 {
+				metadata.Copyright = copyrights[0]
+			}
+			if gains, err := cmt.Get(replayGainTag); err == nil && len(gains) > 0 {
+				metadata.ReplayGain = gains[0]
 			}
 			if trackNums, err := cmt.Get("TRACKNUMBER"); err == nil && len(trackNums) > 0 {
-				if trackNum, err := strconv.Atoi(trackNums[0]); err == nil {
-					metadata.TrackNumber = trackNum
+				metadata.TrackNumber, metadata.TotalTracks = parsePosition(trackNums[0])
+			}
+			if totals, err := cmt.Get("TRACKTOTAL"); err == nil && len(totals) > 0 {
+				if total, err := strconv.Atoi(totals[0]); err == nil {
+					metadata.TotalTracks = total
 				}
 			}
 			if discNums, err := cmt.Get("DISCNUMBER"); err == nil && len(discNums) > 0 {
-				if discNum, err := strconv.Atoi(discNums[0]); err == nil {
-					metadata.DiscNumber = discNum
+				metadata.DiscNumber, metadata.TotalDiscs = parsePosition(discNums[0])
+			}
+			if totals, err := cmt.Get("DISCTOTAL"); err == nil && len(totals) > 0 {
+				if total, err := strconv.Atoi(totals[0]); err == nil {
+					metadata.TotalDiscs = total
 				}
 			}
 
@@ -515,6 +786,33 @@ func (m *Manager) getFLACMetadata(filePath string) (*TrackMetadata, error) {
 	return metadata, nil
 }
 
+// parsePosition splits a "n" or "n/total" track or disc tag value; total is 0 when absent
+func parsePosition(value string) (int, int) {
+	parts := strings.SplitN(value, "/", 2)
+	number, _ := strconv.Atoi(strings.TrimSpace(parts[0]))
+	total := 0
+	if len(parts) == 2 {
+		total, _ = strconv.Atoi(strings.TrimSpace(parts[1]))
+	}
+	return number, total
+}
+
+// parseDate splits a "YYYY" or "YYYY-MM-DD" tag value into the year and, when the value
+// is longer than a year, the full date
+func parseDate(value string) (int, string) {
+	if len(value) < 4 {
+		return 0, ""
+	}
+	year, err := strconv.Atoi(value[:4])
+	if err != nil {
+		return 0, ""
+	}
+	if len(value) > 4 {
+		return year, value
+	}
+	return year, ""
+}
+
 // FileExists checks if a file exists
 func FileExists(filePath string) bool {
 	_, err := os.Stat(filePath)
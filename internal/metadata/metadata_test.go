@@ -4,6 +4,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/bogem/id3v2/v2"
+	"github.com/go-flac/go-flac"
 )
 
 func TestNewManager(t *testing.T) {
@@ -238,3 +241,432 @@ func TestNewArtworkCacheErrors(t *testing.T) {
 		t.Error("NewArtworkCache should return error for empty cache dir")
 	}
 }
+
+func TestParseLRC(t *testing.T) {
+	manager := NewManager(nil)
+
+	lrc := "[ar:Artist]\n" +
+		"[ti:Title]\n" +
+		"[00:12.00]First line\n" +
+		"[00:05.5][01:00.25]Chorus\r\n" +
+		"not a lyric line\n" +
+		"[xx:yy.zz]Malformed\n" +
+		"[00:20.000\n" +
+		"[00:30.123]\n"
+
+	lines := manager.ParseLRC(lrc)
+
+	expected := []SyncedLine{
+		{Milliseconds: 5500, Text: "Chorus"},
+		{Milliseconds: 12000, Text: "First line"},
+		{Milliseconds: 30123, Text: ""},
+		{Milliseconds: 60250, Text: "Chorus"},
+	}
+
+	if len(lines) != len(expected) {
+		t.Fatalf("ParseLRC returned %d lines, expected %d: %+v", len(lines), len(expected), lines)
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("line %d = %+v, expected %+v", i, line, expected[i])
+		}
+	}
+
+	// Offset shifts lyrics earlier
+	shifted := manager.ParseLRC("[offset:500]\n[00:01.00]Hello")
+	if len(shifted) != 1 || shifted[0].Milliseconds != 500 {
+		t.Errorf("Expected offset to shift line to 500ms, got %+v", shifted)
+	}
+}
+
+func TestConvertSyncedLyrics(t *testing.T) {
+	manager := NewManager(nil)
+	lrc := "[00:01.00]Hi there\n[00:03.00]\n[00:04.00]Bye\n"
+
+	text, ext, err := manager.ConvertSyncedLyrics(lrc, SyncedFormatLRC)
+	if err != nil || text != lrc || ext != ".lrc" {
+		t.Errorf("Expected LRC unchanged, got %q %q %v", text, ext, err)
+	}
+
+	// "Hi" is 2 of 7 characters of a 2 second line
+	text, ext, err = manager.ConvertSyncedLyrics(lrc, SyncedFormatEnhancedLRC)
+	expectedEnhanced := "[00:01.00]<00:01.00>Hi <00:01.57>there\n[00:03.00]\n[00:04.00]<00:04.00>Bye\n"
+	if err != nil || text != expectedEnhanced || ext != ".lrc" {
+		t.Errorf("Unexpected enhanced LRC %q %q %v", text, ext, err)
+	}
+
+	// The empty line ends the first cue; the last cue gets a fixed duration
+	text, ext, err = manager.ConvertSyncedLyrics(lrc, SyncedFormatSRT)
+	expectedSRT := "1\n00:00:01,000 --> 00:00:03,000\nHi there\n\n2\n00:00:04,000 --> 00:00:09,000\nBye\n"
+	if err != nil || text != expectedSRT || ext != ".srt" {
+		t.Errorf("Unexpected SRT %q %q %v", text, ext, err)
+	}
+
+	if _, _, err := manager.ConvertSyncedLyrics(lrc, "vtt"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestEmbedSyncedLyricsMP3(t *testing.T) {
+	manager := NewManager(nil)
+	filePath := filepath.Join(t.TempDir(), "song.mp3")
+	if err := os.WriteFile(filePath, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	lyrics := &Lyrics{SyncedLyrics: "[00:01.00]Hello\n[00:02.50]World\n"}
+	if err := manager.EmbedLyrics(filePath, lyrics, &LyricsConfig{EmbedInFile: true, Language: "eng"}); err != nil {
+		t.Fatalf("EmbedLyrics failed: %v", err)
+	}
+
+	read, err := manager.GetLyrics(filePath)
+	if err != nil {
+		t.Fatalf("GetLyrics failed: %v", err)
+	}
+
+	if read.SyncedLyrics != "[00:01.00]Hello\n[00:02.50]World\n" {
+		t.Errorf("Unexpected synced lyrics round trip: %q", read.SyncedLyrics)
+	}
+}
+
+func TestOriginalDateMP3(t *testing.T) {
+	manager := NewManager(nil)
+	filePath := filepath.Join(t.TempDir(), "reissue.mp3")
+	if err := os.WriteFile(filePath, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if err := manager.ApplyMetadata(filePath, &TrackMetadata{Title: "Song", Year: 2011, OriginalDate: "1973-03-01"}); err != nil {
+		t.Fatalf("ApplyMetadata failed: %v", err)
+	}
+
+	read, err := manager.GetMetadata(filePath)
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+
+	if read.Year != 2011 {
+		t.Errorf("Expected release year 2011, got %d", read.Year)
+	}
+	if read.OriginalDate != "1973-03-01" {
+		t.Errorf("Expected original date 1973-03-01, got %q", read.OriginalDate)
+	}
+}
+
+func TestReleaseDateMP3(t *testing.T) {
+	manager := NewManager(nil)
+	filePath := filepath.Join(t.TempDir(), "dated.mp3")
+	if err := os.WriteFile(filePath, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if err := manager.ApplyMetadata(filePath, &TrackMetadata{Title: "Song", Year: 2011, ReleaseDate: "2011-09-26"}); err != nil {
+		t.Fatalf("ApplyMetadata failed: %v", err)
+	}
+
+	read, err := manager.GetMetadata(filePath)
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+
+	if read.Year != 2011 || read.ReleaseDate != "2011-09-26" {
+		t.Errorf("Expected 2011 / 2011-09-26, got %d / %q", read.Year, read.ReleaseDate)
+	}
+}
+
+func TestEmbeddedPicturesDeduplicates(t *testing.T) {
+	cover := []byte("cover")
+	metadata := &TrackMetadata{
+		ArtworkData: cover,
+		ArtworkMIME: "image/jpeg",
+		Pictures: []Picture{
+			{Type: PictureBackCover, Data: cover},                  // Same bytes as the front cover
+			{Type: PictureArtist, Data: []byte("artist")},
+			{Type: PictureArtist, Data: []byte("another artist")}, // Second picture of one type
+			{Type: PictureBackCover},                               // Nothing to embed
+		},
+	}
+
+	pictures := metadata.embeddedPictures()
+	if len(pictures) != 2 || pictures[0].Type != PictureFrontCover || pictures[1].Type != PictureArtist {
+		t.Fatalf("Expected front cover and artist pictures, got %+v", pictures)
+	}
+	if string(pictures[1].Data) != "artist" {
+		t.Errorf("Expected the first artist picture to win, got %q", pictures[1].Data)
+	}
+}
+
+func TestEmbedPicturesMP3(t *testing.T) {
+	manager := NewManager(nil)
+	filePath := filepath.Join(t.TempDir(), "pictures.mp3")
+	if err := os.WriteFile(filePath, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	metadata := &TrackMetadata{
+		Title:       "Song",
+		ArtworkData: []byte("cover"),
+		ArtworkMIME: "image/jpeg",
+		Pictures:    []Picture{{Type: PictureArtist, Data: []byte("artist"), MIME: "image/jpeg"}},
+	}
+	// Tagging twice must not stack a second copy of each picture
+	for i := 0; i < 2; i++ {
+		if err := manager.ApplyMetadata(filePath, metadata); err != nil {
+			t.Fatalf("ApplyMetadata failed: %v", err)
+		}
+	}
+
+	tag, err := id3v2.Open(filePath, id3v2.Options{Parse: true})
+	if err != nil {
+		t.Fatalf("Failed to open tag: %v", err)
+	}
+	defer tag.Close()
+
+	types := make(map[byte]string)
+	frames := tag.GetFrames(tag.CommonID("Attached picture"))
+	for _, frame := range frames {
+		pic := frame.(id3v2.PictureFrame)
+		types[pic.PictureType] = string(pic.Picture)
+	}
+	if len(frames) != 2 || types[PictureFrontCover] != "cover" || types[PictureArtist] != "artist" {
+		t.Errorf("Expected one front cover and one artist picture, got %d frames: %v", len(frames), types)
+	}
+}
+
+func TestEmbedPicturesFLAC(t *testing.T) {
+	manager := NewManager(nil)
+	filePath := filepath.Join(t.TempDir(), "pictures.flac")
+
+	// fLaC marker, a single (last) empty STREAMINFO block and the sync code of a first frame
+	data := append([]byte("fLaC"), 0x80, 0, 0, 34)
+	data = append(data, make([]byte, 34)...)
+	data = append(data, 0xff, 0xf8, 0, 0)
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	metadata := &TrackMetadata{
+		Title:       "Song",
+		ArtworkData: []byte("cover"),
+		Pictures:    []Picture{{Type: PictureArtist, Data: []byte("artist")}},
+	}
+	for i := 0; i < 2; i++ {
+		if err := manager.ApplyMetadata(filePath, metadata); err != nil {
+			t.Fatalf("ApplyMetadata failed: %v", err)
+		}
+	}
+
+	f, err := flac.ParseFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to parse FLAC: %v", err)
+	}
+	var types []byte
+	for _, block := range f.Meta {
+		if block.Type == flac.Picture {
+			types = append(types, block.Data[3])
+		}
+	}
+	if len(types) != 2 || types[0] != PictureFrontCover || types[1] != PictureArtist {
+		t.Errorf("Expected front cover and artist picture blocks, got types %v", types)
+	}
+}
+
+func TestDeezerReplayGain(t *testing.T) {
+	if got := DeezerReplayGain(-12.4); got != "-6.00 dB" {
+		t.Errorf("Expected -6.00 dB, got %q", got)
+	}
+	if got := DeezerReplayGain(-20.5); got != "2.10 dB" {
+		t.Errorf("Expected 2.10 dB, got %q", got)
+	}
+}
+
+func TestReplayGainMP3(t *testing.T) {
+	manager := NewManager(nil)
+	filePath := filepath.Join(t.TempDir(), "gain.mp3")
+	if err := os.WriteFile(filePath, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// Re-tagging replaces the gain instead of adding a second frame
+	for _, gain := range []string{"-5.00 dB", "-6.00 dB"} {
+		if err := manager.ApplyMetadata(filePath, &TrackMetadata{Title: "Song", ReplayGain: gain}); err != nil {
+			t.Fatalf("ApplyMetadata failed: %v", err)
+		}
+	}
+
+	read, err := manager.GetMetadata(filePath)
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	if read.ReplayGain != "-6.00 dB" {
+		t.Errorf("Expected -6.00 dB, got %q", read.ReplayGain)
+	}
+
+	tag, err := id3v2.Open(filePath, id3v2.Options{Parse: true})
+	if err != nil {
+		t.Fatalf("Failed to open tag: %v", err)
+	}
+	defer tag.Close()
+	if frames := tag.GetFrames(tag.CommonID("User defined text information frame")); len(frames) != 1 {
+		t.Errorf("Expected one TXXX frame, got %d", len(frames))
+	}
+}
+
+func TestReplayGainFLAC(t *testing.T) {
+	manager := NewManager(nil)
+	filePath := filepath.Join(t.TempDir(), "gain.flac")
+
+	data := append([]byte("fLaC"), 0x80, 0, 0, 34)
+	data = append(data, make([]byte, 34)...)
+	data = append(data, 0xff, 0xf8, 0, 0)
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if err := manager.ApplyMetadata(filePath, &TrackMetadata{Title: "Song", ReplayGain: "-6.00 dB"}); err != nil {
+		t.Fatalf("ApplyMetadata failed: %v", err)
+	}
+
+	read, err := manager.GetMetadata(filePath)
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	if read.ReplayGain != "-6.00 dB" {
+		t.Errorf("Expected -6.00 dB, got %q", read.ReplayGain)
+	}
+}
+
+func TestCompilationFlag(t *testing.T) {
+	manager := NewManager(nil)
+	dir := t.TempDir()
+
+	mp3Path := filepath.Join(dir, "compilation.mp3")
+	if err := os.WriteFile(mp3Path, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	flacPath := filepath.Join(dir, "compilation.flac")
+	data := append([]byte("fLaC"), 0x80, 0, 0, 34)
+	data = append(data, make([]byte, 34)...)
+	data = append(data, 0xff, 0xf8, 0, 0)
+	if err := os.WriteFile(flacPath, data, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	for _, filePath := range []string{mp3Path, flacPath} {
+		if err := manager.ApplyMetadata(filePath, &TrackMetadata{Title: "Song", AlbumArtist: "Various Artists", Compilation: true}); err != nil {
+			t.Fatalf("ApplyMetadata failed for %s: %v", filePath, err)
+		}
+		read, err := manager.GetMetadata(filePath)
+		if err != nil {
+			t.Fatalf("GetMetadata failed for %s: %v", filePath, err)
+		}
+		if !read.Compilation {
+			t.Errorf("Expected %s to be flagged as a compilation", filepath.Base(filePath))
+		}
+	}
+
+	// Regular albums don't get the flag
+	plainPath := filepath.Join(dir, "plain.mp3")
+	if err := os.WriteFile(plainPath, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := manager.ApplyMetadata(plainPath, &TrackMetadata{Title: "Song"}); err != nil {
+		t.Fatalf("ApplyMetadata failed: %v", err)
+	}
+	if read, _ := manager.GetMetadata(plainPath); read == nil || read.Compilation {
+		t.Error("Expected a regular track not to be flagged as a compilation")
+	}
+}
+
+func TestTrackAndDiscTotals(t *testing.T) {
+	manager := NewManager(nil)
+	dir := t.TempDir()
+
+	mp3Path := filepath.Join(dir, "totals.mp3")
+	if err := os.WriteFile(mp3Path, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	flacPath := filepath.Join(dir, "totals.flac")
+	data := append([]byte("fLaC"), 0x80, 0, 0, 34)
+	data = append(data, make([]byte, 34)...)
+	data = append(data, 0xff, 0xf8, 0, 0)
+	if err := os.WriteFile(flacPath, data, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	for _, filePath := range []string{mp3Path, flacPath} {
+		if err := manager.ApplyMetadata(filePath, &TrackMetadata{Title: "Song", TrackNumber: 3, TotalTracks: 14, DiscNumber: 1, TotalDiscs: 2}); err != nil {
+			t.Fatalf("ApplyMetadata failed for %s: %v", filePath, err)
+		}
+		read, err := manager.GetMetadata(filePath)
+		if err != nil {
+			t.Fatalf("GetMetadata failed for %s: %v", filePath, err)
+		}
+		if read.TrackNumber != 3 || read.TotalTracks != 14 || read.DiscNumber != 1 || read.TotalDiscs != 2 {
+			t.Errorf("Expected track 3/14 on disc 1/2 in %s, got %d/%d on %d/%d", filepath.Base(filePath),
+				read.TrackNumber, read.TotalTracks, read.DiscNumber, read.TotalDiscs)
+		}
+	}
+
+	if number, total := parsePosition("7"); number != 7 || total != 0 {
+		t.Errorf("Expected 7 with no total, got %d/%d", number, total)
+	}
+}
+
+func TestID3v23Tags(t *testing.T) {
+	manager := NewManager(&Config{EmbedArtwork: true, ID3Version: ID3v23})
+	filePath := filepath.Join(t.TempDir(), "compat.mp3")
+	if err := os.WriteFile(filePath, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if err := manager.ApplyMetadata(filePath, &TrackMetadata{
+		Title:        "Déjà vu – 東京",
+		Artist:       "Björk\x00Thom Yorke",
+		ReleaseDate:  "2011-09-26",
+		OriginalDate: "1973-03-01",
+	}); err != nil {
+		t.Fatalf("ApplyMetadata failed: %v", err)
+	}
+	lyrics := &Lyrics{SyncedLyrics: "[00:01.00]Привет\n[00:02.50]World\n"}
+	if err := manager.EmbedLyrics(filePath, lyrics, &LyricsConfig{EmbedInFile: true, Language: "eng"}); err != nil {
+		t.Fatalf("EmbedLyrics failed: %v", err)
+	}
+
+	tag, err := id3v2.Open(filePath, id3v2.Options{Parse: true})
+	if err != nil {
+		t.Fatalf("Failed to open tag: %v", err)
+	}
+	if tag.Version() != ID3v23 {
+		t.Errorf("Expected an ID3v2.3 tag, got v2.%d", tag.Version())
+	}
+	if title := tag.GetTextFrame("TIT2"); title.Encoding.Key != id3v2.EncodingUTF16.Key {
+		t.Errorf("Expected UTF-16 text, got encoding %d", title.Encoding.Key)
+	}
+	if tag.GetTextFrame("TYER").Text != "2011" || tag.GetTextFrame("TDAT").Text != "2609" || tag.GetTextFrame("TORY").Text != "1973" {
+		t.Errorf("Unexpected ID3v2.3 dates TYER=%q TDAT=%q TORY=%q", tag.GetTextFrame("TYER").Text, tag.GetTextFrame("TDAT").Text, tag.GetTextFrame("TORY").Text)
+	}
+	if len(tag.GetFrames("TDRC")) > 0 || len(tag.GetFrames("TDOR")) > 0 {
+		t.Error("Expected no ID3v2.4 date frames")
+	}
+	tag.Close()
+
+	read, err := manager.GetMetadata(filePath)
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	if read.Title != "Déjà vu – 東京" || read.Artist != "Björk/Thom Yorke" {
+		t.Errorf("Unexpected text round trip %q / %q", read.Title, read.Artist)
+	}
+	if read.Year != 2011 || read.ReleaseDate != "2011-09-26" || read.OriginalDate != "1973" {
+		t.Errorf("Unexpected dates %d / %q / %q", read.Year, read.ReleaseDate, read.OriginalDate)
+	}
+
+	readLyrics, err := manager.GetLyrics(filePath)
+	if err != nil {
+		t.Fatalf("GetLyrics failed: %v", err)
+	}
+	if readLyrics.SyncedLyrics != lyrics.SyncedLyrics {
+		t.Errorf("Unexpected UTF-16 synced lyrics round trip: %q", readLyrics.SyncedLyrics)
+	}
+}
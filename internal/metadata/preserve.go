@@ -0,0 +1,122 @@
+package metadata
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bogem/id3v2/v2"
+	"github.com/go-flac/go-flac"
+)
+
+// ApplyMissingMetadata writes only the tags the file doesn't have yet, so values edited by
+// hand (a corrected genre, say) survive re-tagging. A track or disc number is kept together
+// with its total, and a year with its full date, since they share a tag. Pictures are only
+// added for types the file doesn't embed, and an MP3 keeps its ID3 version so the existing
+// frames aren't converted.
+func (m *Manager) ApplyMissingMetadata(filePath string, metadata *TrackMetadata) error {
+	if metadata == nil {
+		return fmt.Errorf("metadata cannot be nil")
+	}
+
+	existing, err := m.GetMetadata(filePath)
+	if err != nil {
+		return err
+	}
+	pictureTypes, version, err := existingPictures(filePath)
+	if err != nil {
+		return err
+	}
+
+	filler := m
+	if version != 0 && version != m.id3Version() {
+		config := *m.config
+		config.ID3Version = version
+		filler = NewManager(&config)
+	}
+	return filler.ApplyMetadata(filePath, missingMetadata(existing, metadata, pictureTypes))
+}
+
+// missingMetadata returns the fields of wanted that existing has no value for
+func missingMetadata(existing, wanted *TrackMetadata, pictureTypes map[byte]bool) *TrackMetadata {
+	fill := func(have, want string) string {
+		if have != "" {
+			return ""
+		}
+		return want
+	}
+
+	missing := &TrackMetadata{
+		Title:        fill(existing.Title, wanted.Title),
+		Artist:       fill(existing.Artist, wanted.Artist),
+		Album:        fill(existing.Album, wanted.Album),
+		AlbumArtist:  fill(existing.AlbumArtist, wanted.AlbumArtist),
+		Genre:        fill(existing.Genre, wanted.Genre),
+		OriginalDate: fill(existing.OriginalDate, wanted.OriginalDate),
+		Duration:     wanted.Duration,
+		ISRC:         fill(existing.ISRC, wanted.ISRC),
+		Label:        fill(existing.Label, wanted.Label),
+		Copyright:    fill(existing.Copyright, wanted.Copyright),
+		ReplayGain:   fill(existing.ReplayGain, wanted.ReplayGain),
+		Compilation:  wanted.Compilation && !existing.Compilation,
+	}
+	if existing.Year == 0 && existing.ReleaseDate == "" {
+		missing.Year, missing.ReleaseDate = wanted.Year, wanted.ReleaseDate
+	}
+	if existing.TrackNumber == 0 {
+		missing.TrackNumber, missing.TotalTracks = wanted.TrackNumber, wanted.TotalTracks
+	}
+	if existing.DiscNumber == 0 {
+		missing.DiscNumber, missing.TotalDiscs = wanted.DiscNumber, wanted.TotalDiscs
+	}
+
+	for _, picture := range wanted.embeddedPictures() {
+		if pictureTypes[picture.Type] {
+			continue
+		}
+		if picture.Type == PictureFrontCover {
+			missing.ArtworkData, missing.ArtworkMIME = picture.Data, picture.MIME
+		} else {
+			missing.Pictures = append(missing.Pictures, picture)
+		}
+	}
+	return missing
+}
+
+// existingPictures returns the picture types a file embeds and, for an MP3 that already
+// has tags, its ID3v2 version (0 otherwise)
+func existingPictures(filePath string) (map[byte]bool, byte, error) {
+	types := make(map[byte]bool)
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".mp3":
+		tag, err := id3v2.Open(filePath, id3v2.Options{Parse: true})
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to open MP3 file: %w", err)
+		}
+		defer tag.Close()
+
+		for _, frame := range tag.GetFrames(tag.CommonID("Attached picture")) {
+			if pic, ok := frame.(id3v2.PictureFrame); ok {
+				types[pic.PictureType] = true
+			}
+		}
+		if tag.Count() == 0 {
+			return types, 0, nil
+		}
+		return types, tag.Version(), nil
+	case ".flac":
+		f, err := flac.ParseFile(filePath)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to parse FLAC file: %w", err)
+		}
+		for _, block := range f.Meta {
+			if block.Type == flac.Picture && len(block.Data) >= 4 {
+				types[block.Data[3]] = true
+			}
+		}
+		return types, 0, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported file format: %s", filepath.Ext(filePath))
+	}
+}
@@ -0,0 +1,101 @@
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bogem/id3v2/v2"
+)
+
+func TestApplyMissingMetadataMP3(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "edited.mp3")
+	if err := os.WriteFile(filePath, []byte{}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// Tagged as ID3v2.3 by an earlier download, then the genre was corrected by hand
+	original := NewManager(&Config{EmbedArtwork: true, ID3Version: ID3v23})
+	if err := original.ApplyMetadata(filePath, &TrackMetadata{
+		Title:       "Song",
+		Genre:       "Shoegaze",
+		ReleaseDate: "2011-09-26",
+		TrackNumber: 3,
+		TotalTracks: 10,
+		ArtworkData: []byte("edited cover"),
+	}); err != nil {
+		t.Fatalf("ApplyMetadata failed: %v", err)
+	}
+
+	manager := NewManager(&Config{EmbedArtwork: true, ID3Version: ID3v24})
+	if err := manager.ApplyMissingMetadata(filePath, &TrackMetadata{
+		Title:       "Song (Remastered)",
+		Genre:       "Rock",
+		Label:       "Label",
+		ReleaseDate: "2012-01-01",
+		TrackNumber: 4,
+		TotalTracks: 12,
+		DiscNumber:  1,
+		ArtworkData: []byte("deezer cover"),
+		Pictures:    []Picture{{Type: PictureArtist, Data: []byte("artist")}},
+	}); err != nil {
+		t.Fatalf("ApplyMissingMetadata failed: %v", err)
+	}
+
+	read, err := manager.GetMetadata(filePath)
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	if read.Title != "Song" || read.Genre != "Shoegaze" || read.ReleaseDate != "2011-09-26" || read.TrackNumber != 3 || read.TotalTracks != 10 {
+		t.Errorf("Expected existing tags to be kept, got %+v", read)
+	}
+	if read.Label != "Label" || read.DiscNumber != 1 {
+		t.Errorf("Expected missing tags to be filled, got label %q disc %d", read.Label, read.DiscNumber)
+	}
+
+	tag, err := id3v2.Open(filePath, id3v2.Options{Parse: true})
+	if err != nil {
+		t.Fatalf("Failed to open tag: %v", err)
+	}
+	defer tag.Close()
+	if tag.Version() != ID3v23 {
+		t.Errorf("Expected the file to stay ID3v2.3, got v2.%d", tag.Version())
+	}
+	pictures := make(map[byte]string)
+	for _, frame := range tag.GetFrames("APIC") {
+		if pic, ok := frame.(id3v2.PictureFrame); ok {
+			pictures[pic.PictureType] = string(pic.Picture)
+		}
+	}
+	if pictures[PictureFrontCover] != "edited cover" || pictures[PictureArtist] != "artist" {
+		t.Errorf("Expected the cover kept and the artist picture added, got %v", pictures)
+	}
+}
+
+func TestApplyMissingMetadataFLAC(t *testing.T) {
+	manager := NewManager(nil)
+	filePath := filepath.Join(t.TempDir(), "edited.flac")
+
+	// fLaC marker, a single (last) empty STREAMINFO block and the sync code of a first frame
+	data := append([]byte("fLaC"), 0x80, 0, 0, 34)
+	data = append(data, make([]byte, 34)...)
+	data = append(data, 0xff, 0xf8, 0, 0)
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if err := manager.ApplyMetadata(filePath, &TrackMetadata{Title: "Song", Genre: "Shoegaze"}); err != nil {
+		t.Fatalf("ApplyMetadata failed: %v", err)
+	}
+	if err := manager.ApplyMissingMetadata(filePath, &TrackMetadata{Title: "Other", Genre: "Rock", ISRC: "GBAYE0601498"}); err != nil {
+		t.Fatalf("ApplyMissingMetadata failed: %v", err)
+	}
+
+	read, err := manager.GetMetadata(filePath)
+	if err != nil {
+		t.Fatalf("GetMetadata failed: %v", err)
+	}
+	if read.Title != "Song" || read.Genre != "Shoegaze" || read.ISRC != "GBAYE0601498" {
+		t.Errorf("Expected title and genre kept and ISRC added, got %+v", read)
+	}
+}
@@ -0,0 +1,39 @@
+package network
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// NewBandwidthLimiter returns a limiter allowing bytesPerSecond, with a burst of one second's
+// worth, or nil when bytesPerSecond is 0 (unlimited)
+func NewBandwidthLimiter(bytesPerSecond int) *rate.Limiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), bytesPerSecond)
+}
+
+// WaitBandwidth blocks until n bytes just read are allowed by every limiter. Waiting on each
+// in turn means whichever bucket is slower sets the pace, so a global cap and a per-download
+// cap compose. Nil limiters are unlimited.
+func WaitBandwidth(ctx context.Context, n int, limiters ...*rate.Limiter) error {
+	for _, limiter := range limiters {
+		if limiter == nil {
+			continue
+		}
+		// WaitN rejects more than the burst at once, and a read can be larger than a low limit
+		for remaining := n; remaining > 0; {
+			chunk := remaining
+			if burst := limiter.Burst(); burst > 0 && chunk > burst {
+				chunk = burst
+			}
+			if err := limiter.WaitN(ctx, chunk); err != nil {
+				return err
+			}
+			remaining -= chunk
+		}
+	}
+	return nil
+}
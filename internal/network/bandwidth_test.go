@@ -0,0 +1,45 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestNewBandwidthLimiter(t *testing.T) {
+	if NewBandwidthLimiter(0) != nil {
+		t.Error("Expected no limiter for 0")
+	}
+	if limiter := NewBandwidthLimiter(50000); limiter == nil || limiter.Limit() != 50000 || limiter.Burst() != 50000 {
+		t.Errorf("Expected a 50000 B/s limiter with a one second burst, got %+v", limiter)
+	}
+}
+
+func TestWaitBandwidth(t *testing.T) {
+	ctx := context.Background()
+
+	// Nil limiters never wait
+	if err := WaitBandwidth(ctx, 1<<20, nil, nil); err != nil {
+		t.Fatalf("Expected nil limiters to pass, got %v", err)
+	}
+
+	// The slower bucket sets the pace: the burst passes at once, the rest takes half a second
+	slow := NewBandwidthLimiter(10000)
+	fast := rate.NewLimiter(rate.Inf, 0)
+	start := time.Now()
+	if err := WaitBandwidth(ctx, 15000, fast, slow); err != nil {
+		t.Fatalf("WaitBandwidth failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("Expected about 500ms under the slower limit, took %v", elapsed)
+	}
+
+	// A cancelled download stops waiting
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := WaitBandwidth(cancelled, 15000, NewBandwidthLimiter(10000)); err == nil {
+		t.Error("Expected a cancelled context to end the wait")
+	}
+}
@@ -0,0 +1,55 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DirCache remembers folders already created, so tracks sharing a folder don't each pay for
+// MkdirAll (slow on network shares). A nil DirCache creates the folder every time.
+type DirCache struct {
+	dirs sync.Map
+}
+
+// Ensure creates dir unless the cache already created or found it
+func (c *DirCache) Ensure(dir string) error {
+	if c == nil {
+		return os.MkdirAll(dir, 0755)
+	}
+	dir = filepath.Clean(dir)
+	if _, known := c.dirs.Load(dir); known {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	c.dirs.Store(dir, true)
+	return nil
+}
+
+// Known reports whether dir was created or found by Ensure
+func (c *DirCache) Known(dir string) bool {
+	if c == nil {
+		return false
+	}
+	_, known := c.dirs.Load(filepath.Clean(dir))
+	return known
+}
+
+// Forget drops dir and the folders below it, for when they were deleted or a write into
+// them failed because they are gone, so the next Ensure creates them again
+func (c *DirCache) Forget(dir string) {
+	if c == nil {
+		return
+	}
+	dir = filepath.Clean(dir)
+	prefix := dir + string(filepath.Separator)
+	c.dirs.Range(func(key, _ interface{}) bool {
+		if known := key.(string); known == dir || strings.HasPrefix(known, prefix) {
+			c.dirs.Delete(known)
+		}
+		return true
+	})
+}
@@ -0,0 +1,52 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirCache(t *testing.T) {
+	root := t.TempDir()
+	album := filepath.Join(root, "Artist", "Album")
+	disc := filepath.Join(album, "CD 1")
+	other := filepath.Join(root, "Other")
+
+	var dirs DirCache
+	for _, dir := range []string{album, disc, other} {
+		if err := dirs.Ensure(dir); err != nil {
+			t.Fatalf("Ensure(%s) failed: %v", dir, err)
+		}
+		if !dirs.Known(dir) {
+			t.Errorf("Expected %s to be remembered", dir)
+		}
+	}
+
+	// Deleted behind the cache's back: Ensure trusts it until it's forgotten
+	if err := os.RemoveAll(filepath.Join(root, "Artist")); err != nil {
+		t.Fatalf("Failed to remove folders: %v", err)
+	}
+	dirs.Forget(album)
+	if dirs.Known(album) || dirs.Known(disc) {
+		t.Error("Expected the folder and the ones below it to be forgotten")
+	}
+	if !dirs.Known(other) {
+		t.Error("Expected unrelated folders to stay remembered")
+	}
+
+	if err := dirs.Ensure(disc); err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+	if info, err := os.Stat(disc); err != nil || !info.IsDir() {
+		t.Errorf("Expected %s to be created again: %v", disc, err)
+	}
+
+	// A nil cache creates the folder every time
+	var none *DirCache
+	if err := none.Ensure(filepath.Join(root, "Nil")); err != nil {
+		t.Fatalf("Ensure on a nil cache failed: %v", err)
+	}
+	if none.Known(filepath.Join(root, "Nil")) {
+		t.Error("Expected a nil cache to remember nothing")
+	}
+}
@@ -0,0 +1,217 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrRangesNotSupported is returned by SegmentedDownload when the server can't serve
+// byte ranges. Callers should fall back to a single-stream download.
+var ErrRangesNotSupported = errors.New("server does not support range requests")
+
+// SegmentedDownloadConfig holds configuration for multi-connection downloads
+type SegmentedDownloadConfig struct {
+	URL              string
+	OutputPath       string
+	Connections      int
+	Alignment        int64 // Range boundaries are multiples of this (e.g. the decryption segment size)
+	Headers          map[string]string
+	Timeout          time.Duration
+	ProgressCallback func(downloaded, total int64)
+	Limiters         []*rate.Limiter // Bandwidth caps every range's reads wait on, shared across ranges; nil entries are unlimited
+	Dirs             *DirCache       // Creates the output folder once per folder; nil runs MkdirAll every time
+}
+
+// byteRange is an inclusive [Start, End] byte range
+type byteRange struct {
+	Start int64
+	End   int64
+}
+
+// SegmentedDownload downloads a file over several concurrent HTTP Range requests, writing
+// each range at its offset in the output file. It returns the total size downloaded.
+func SegmentedDownload(config *SegmentedDownloadConfig) (int64, error) {
+	supportsRange, totalSize, err := SupportsResume(config.URL, config.Headers, config.Timeout)
+	if err != nil {
+		// Some CDNs reject HEAD; let the caller fall back to a plain GET
+		return 0, fmt.Errorf("%w: %v", ErrRangesNotSupported, err)
+	}
+	if !supportsRange || totalSize <= 0 {
+		return 0, ErrRangesNotSupported
+	}
+
+	ranges := splitRanges(totalSize, config.Connections, config.Alignment)
+
+	// Ensure output directory exists
+	if err := config.Dirs.Ensure(filepath.Dir(config.OutputPath)); err != nil {
+		return 0, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	outFile, err := os.Create(config.OutputPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			// The folder was removed since it was created; make it again next time
+			config.Dirs.Forget(filepath.Dir(config.OutputPath))
+		}
+		return 0, fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer outFile.Close()
+
+	if err := outFile.Truncate(totalSize); err != nil {
+		return 0, fmt.Errorf("failed to allocate output file: %w", err)
+	}
+
+	client := GetDownloadClient(config.Timeout)
+
+	// Cancelled on the first failed range so the others stop early
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		wg         sync.WaitGroup
+		progressMu sync.Mutex
+		downloaded int64
+		errOnce    sync.Once
+		firstErr   error
+	)
+
+	// Progress callbacks are serialized so callers don't need to be goroutine-safe
+	reportProgress := func(n int64) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		downloaded += n
+		if config.ProgressCallback != nil {
+			config.ProgressCallback(downloaded, totalSize)
+		}
+	}
+
+	for _, r := range ranges {
+		wg.Add(1)
+		go func(r byteRange) {
+			defer wg.Done()
+			if err := downloadRange(ctx, client, config, outFile, r, reportProgress); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		os.Remove(config.OutputPath)
+		return 0, firstErr
+	}
+
+	return totalSize, nil
+}
+
+// downloadRange fetches a single byte range and writes it at its offset in outFile
+func downloadRange(ctx context.Context, client *http.Client, config *SegmentedDownloadConfig, outFile *os.File, r byteRange, reportProgress func(int64)) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", config.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for key, value := range config.Headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", r.Start, r.End))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("range request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// A 200 means the server ignored the Range header and is sending the whole file
+	if resp.StatusCode == http.StatusOK {
+		return ErrRangesNotSupported
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("range download failed with status: %d", resp.StatusCode)
+	}
+
+	buffer := make([]byte, 256*1024)
+	offset := r.Start
+	for {
+		n, err := resp.Body.Read(buffer)
+		if n > 0 {
+			if offset+int64(n) > r.End+1 {
+				return fmt.Errorf("server sent more data than requested for range %d-%d", r.Start, r.End)
+			}
+			if _, writeErr := outFile.WriteAt(buffer[:n], offset); writeErr != nil {
+				return fmt.Errorf("failed to write to file: %w", writeErr)
+			}
+			offset += int64(n)
+			reportProgress(int64(n))
+			if waitErr := WaitBandwidth(ctx, n, config.Limiters...); waitErr != nil {
+				return fmt.Errorf("bandwidth limit wait failed: %w", waitErr)
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading response: %w", err)
+		}
+	}
+
+	if offset != r.End+1 {
+		return fmt.Errorf("range %d-%d incomplete: got %d bytes", r.Start, r.End, offset-r.Start)
+	}
+
+	return nil
+}
+
+// splitRanges divides size bytes into at most parts contiguous ranges whose boundaries
+// fall on multiples of align. Small files get fewer ranges rather than sub-aligned ones.
+func splitRanges(size int64, parts int, align int64) []byteRange {
+	if parts < 1 {
+		parts = 1
+	}
+	if align < 1 {
+		align = 1
+	}
+
+	// Number of aligned blocks, counting a trailing partial block
+	blocks := (size + align - 1) / align
+	if int64(parts) > blocks {
+		parts = int(blocks)
+	}
+	if parts < 1 {
+		parts = 1
+	}
+
+	blocksPerPart := blocks / int64(parts)
+	extra := blocks % int64(parts)
+
+	ranges := make([]byteRange, 0, parts)
+	var start int64
+	for i := 0; i < parts; i++ {
+		n := blocksPerPart
+		if int64(i) < extra {
+			n++
+		}
+		end := start + n*align - 1
+		if end >= size {
+			end = size - 1
+		}
+		ranges = append(ranges, byteRange{Start: start, End: end})
+		start = end + 1
+	}
+
+	return ranges
+}
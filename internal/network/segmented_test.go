@@ -0,0 +1,94 @@
+package network
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSplitRanges(t *testing.T) {
+	const align = 6144
+
+	ranges := splitRanges(10*align+100, 4, align)
+	if len(ranges) != 4 {
+		t.Fatalf("Expected 4 ranges, got %d", len(ranges))
+	}
+
+	var next int64
+	for i, r := range ranges {
+		if r.Start != next {
+			t.Errorf("Range %d starts at %d, expected %d", i, r.Start, next)
+		}
+		if r.Start%align != 0 {
+			t.Errorf("Range %d start %d is not aligned to %d", i, r.Start, align)
+		}
+		next = r.End + 1
+	}
+	if next != 10*align+100 {
+		t.Errorf("Ranges cover %d bytes, expected %d", next, 10*align+100)
+	}
+
+	// A file smaller than one segment is fetched in a single range
+	if ranges := splitRanges(1000, 4, align); len(ranges) != 1 || ranges[0].End != 999 {
+		t.Errorf("Expected a single 0-999 range, got %v", ranges)
+	}
+}
+
+func TestSegmentedDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 5000) // 80000 bytes
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "track.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	outputPath := filepath.Join(t.TempDir(), "track.bin")
+	var lastProgress int64
+	size, err := SegmentedDownload(&SegmentedDownloadConfig{
+		URL:         server.URL,
+		OutputPath:  outputPath,
+		Connections: 4,
+		Alignment:   6144,
+		Timeout:     5 * time.Second,
+		ProgressCallback: func(downloaded, total int64) {
+			lastProgress = downloaded
+		},
+	})
+	if err != nil {
+		t.Fatalf("SegmentedDownload failed: %v", err)
+	}
+
+	if size != int64(len(content)) || lastProgress != size {
+		t.Errorf("Expected %d bytes downloaded, got size=%d progress=%d", len(content), size, lastProgress)
+	}
+
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("Downloaded file does not match source content")
+	}
+}
+
+func TestSegmentedDownloadWithoutRangeSupport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("no ranges here"))
+	}))
+	defer server.Close()
+
+	_, err := SegmentedDownload(&SegmentedDownloadConfig{
+		URL:         server.URL,
+		OutputPath:  filepath.Join(t.TempDir(), "track.bin"),
+		Connections: 4,
+		Timeout:     5 * time.Second,
+	})
+	if !errors.Is(err, ErrRangesNotSupported) {
+		t.Errorf("Expected ErrRangesNotSupported, got %v", err)
+	}
+}
@@ -132,6 +132,59 @@ CREATE INDEX IF NOT EXISTS idx_failed_tracks_date ON failed_tracks(failed_at DES
 -- These speed up the complex DELETE queries with subqueries
 CREATE INDEX IF NOT EXISTS idx_queue_type_status_completion ON queue_items(type, status, completed_tracks, total_tracks);
 CREATE INDEX IF NOT EXISTS idx_queue_parent_type_status ON queue_items(parent_id, type, status) WHERE parent_id IS NOT NULL;
+`,
+	},
+	{
+		Version: 6,
+		Name:    "add_tagged_flag",
+		Up: `
+-- Cleared when metadata tagging still fails after its retries, so untagged files can be found
+ALTER TABLE queue_items ADD COLUMN tagged INTEGER DEFAULT 1;
+`,
+	},
+	{
+		Version: 7,
+		Name:    "add_history_bitrate",
+		Up: `
+-- Bitrate read from the MP3 frame headers (0 when not measured) and a warning when it's below the requested quality
+ALTER TABLE download_history ADD COLUMN bitrate INTEGER DEFAULT 0;
+ALTER TABLE download_history ADD COLUMN quality_warning TEXT DEFAULT '';
+`,
+	},
+	{
+		Version: 8,
+		Name:    "add_queue_added_at",
+		Up: `
+-- When an item was added to the queue; the UI-facing queue queries sort by it
+ALTER TABLE queue_items ADD COLUMN added_at DATETIME;
+UPDATE queue_items SET added_at = created_at WHERE added_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_queue_type_added ON queue_items(type, added_at);
+`,
+	},
+	{
+		Version: 9,
+		Name:    "add_queue_item_overrides",
+		Up: `
+-- Per-item quality and output folder, set before an item starts; empty means use the config
+ALTER TABLE queue_items ADD COLUMN quality TEXT DEFAULT '';
+ALTER TABLE queue_items ADD COLUMN target_dir TEXT DEFAULT '';
+`,
+	},
+	{
+		Version: 10,
+		Name:    "add_history_isrc",
+		Up: `
+-- ISRC of the downloaded track, for download.deduplicate_mode to find an existing copy
+ALTER TABLE download_history ADD COLUMN isrc TEXT DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_history_isrc ON download_history(isrc);
+`,
+	},
+	{
+		Version: 11,
+		Name:    "add_queue_item_discography",
+		Up: `
+-- Albums queued from an artist's discography, for download.group_artist_singles
+ALTER TABLE queue_items ADD COLUMN discography INTEGER DEFAULT 0;
 `,
 	},
 }
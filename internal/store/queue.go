@@ -17,7 +17,7 @@ type QueueItem struct {
 	Title           string     `json:"title"`
 	Artist          string     `json:"artist"`
 	Album           string     `json:"album"`
-	Status          string     `json:"status"` // pending, downloading, completed, failed
+	Status          string     `json:"status"` // pending, downloading, completed, failed, cancelled
 	Progress        int        `json:"progress"`
 	DownloadURL     string     `json:"-"`
 	OutputPath      string     `json:"output_path"`
@@ -37,6 +37,10 @@ type QueueItem struct {
 	PlaylistID      string     `json:"playlist_id,omitempty"`   // For playlists
 	IsCustom        bool       `json:"is_custom"`               // True for custom/imported playlists
 	CustomTracks    []string   `json:"custom_tracks,omitempty"` // Track IDs for custom playlists
+	Tagged          bool       `json:"tagged"`                  // False when metadata tagging failed after download
+	Quality         string     `json:"quality,omitempty"`       // Overrides download.quality for this item and its tracks
+	TargetDir       string     `json:"target_dir,omitempty"`    // Overrides download.output_dir for this item and its tracks
+	Discography     bool       `json:"discography,omitempty"`   // Album queued from an artist's discography; see download.group_artist_singles
 }
 
 // QueueStats represents queue statistics
@@ -46,6 +50,18 @@ type QueueStats struct {
 	Downloading int `json:"downloading"`
 	Completed   int `json:"completed"`
 	Failed      int `json:"failed"`
+	Cancelled   int `json:"cancelled"`
+}
+
+// QueueSnapshotItem is the lightweight state of one album or playlist, for diffing the
+// queue between UI refreshes without loading full items
+type QueueSnapshotItem struct {
+	ID              string `json:"id"`
+	Type            string `json:"type"`
+	Status          string `json:"status"`
+	Progress        int    `json:"progress"`
+	CompletedTracks int    `json:"completed_tracks"`
+	TotalTracks     int    `json:"total_tracks"`
 }
 
 // QueueStore manages queue items in the database
@@ -66,13 +82,16 @@ func (qs *QueueStore) Add(item *QueueItem) error {
 			id, type, title, artist, album, status, progress,
 			download_url, output_path, error_message, retry_count,
 			metadata_json, parent_id, total_tracks, completed_tracks,
-			created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			created_at, updated_at, added_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	now := time.Now()
 	item.CreatedAt = now
 	item.UpdatedAt = now
+	if item.AddedAt.IsZero() {
+		item.AddedAt = now
+	}
 
 	_, err := qs.db.Exec(
 		query,
@@ -93,6 +112,7 @@ func (qs *QueueStore) Add(item *QueueItem) error {
 		item.CompletedTracks,
 		item.CreatedAt,
 		item.UpdatedAt,
+		item.AddedAt,
 	)
 
 	if err != nil {
@@ -123,8 +143,8 @@ func (qs *QueueStore) AddBatch(items []*QueueItem) error {
 			id, type, title, artist, album, status, progress,
 			download_url, output_path, error_message, retry_count,
 			metadata_json, parent_id, total_tracks, completed_tracks,
-			created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			created_at, updated_at, added_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	stmt, err := tx.Prepare(query)
@@ -137,6 +157,9 @@ func (qs *QueueStore) AddBatch(items []*QueueItem) error {
 	for _, item := range items {
 		item.CreatedAt = now
 		item.UpdatedAt = now
+		if item.AddedAt.IsZero() {
+			item.AddedAt = now
+		}
 
 		_, err := stmt.Exec(
 			item.ID,
@@ -156,6 +179,7 @@ func (qs *QueueStore) AddBatch(items []*QueueItem) error {
 			item.CompletedTracks,
 			item.CreatedAt,
 			item.UpdatedAt,
+			item.AddedAt,
 		)
 
 		if err != nil {
@@ -294,13 +318,14 @@ func (qs *QueueStore) GetByID(id string) (*QueueItem, error) {
 		SELECT id, type, title, artist, album, status, progress,
 		       download_url, output_path, error_message, retry_count,
 		       metadata_json, parent_id, total_tracks, completed_tracks,
-		       created_at, updated_at, completed_at
+		       created_at, updated_at, completed_at, COALESCE(tagged, 1),
+		       added_at, COALESCE(quality, ''), COALESCE(target_dir, ''), COALESCE(discography, 0)
 		FROM queue_items
 		WHERE id = ?
 	`
 
 	item := &QueueItem{}
-	var completedAt sql.NullTime
+	var completedAt, addedAt sql.NullTime
 	var parentID sql.NullString
 
 	err := qs.db.QueryRow(query, id).Scan(
@@ -322,6 +347,11 @@ func (qs *QueueStore) GetByID(id string) (*QueueItem, error) {
 		&item.CreatedAt,
 		&item.UpdatedAt,
 		&completedAt,
+		&item.Tagged,
+		&addedAt,
+		&item.Quality,
+		&item.TargetDir,
+		&item.Discography,
 	)
 
 	if err == sql.ErrNoRows {
@@ -337,6 +367,10 @@ func (qs *QueueStore) GetByID(id string) (*QueueItem, error) {
 	if parentID.Valid {
 		item.ParentID = parentID.String
 	}
+	item.AddedAt = item.CreatedAt
+	if addedAt.Valid {
+		item.AddedAt = addedAt.Time
+	}
 
 	return item, nil
 }
@@ -354,7 +388,8 @@ func (qs *QueueStore) GetPending(limit int) ([]*QueueItem, error) {
 		SELECT id, type, title, artist, album, status, progress,
 		       download_url, output_path, error_message, retry_count,
 		       metadata_json, parent_id, total_tracks, completed_tracks,
-		       created_at, updated_at, completed_at
+		       created_at, updated_at, completed_at, COALESCE(tagged, 1),
+		       added_at, COALESCE(quality, ''), COALESCE(target_dir, ''), COALESCE(discography, 0)
 		FROM queue_items
 		WHERE status = 'pending'
 		ORDER BY created_at ASC
@@ -370,8 +405,199 @@ func (qs *QueueStore) GetPending(limit int) ([]*QueueItem, error) {
 	return qs.scanItems(rows)
 }
 
-// GetAll retrieves all queue items with pagination
+// GetChildren retrieves all child tracks of an album or playlist in queue order
+func (qs *QueueStore) GetChildren(parentID string) ([]*QueueItem, error) {
+	query := `
+		SELECT id, type, title, artist, album, status, progress,
+		       download_url, output_path, error_message, retry_count,
+		       metadata_json, parent_id, total_tracks, completed_tracks,
+		       created_at, updated_at, completed_at, COALESCE(tagged, 1),
+		       added_at, COALESCE(quality, ''), COALESCE(target_dir, ''), COALESCE(discography, 0)
+		FROM queue_items
+		WHERE parent_id = ?
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := qs.db.Query(query, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child items: %w", err)
+	}
+	defer rows.Close()
+
+	return qs.scanItems(rows)
+}
+
+// SetTagged records whether metadata tagging succeeded for an item. It only touches the
+// tagged column, so it can't race with the full-row Update that completes the item.
+func (qs *QueueStore) SetTagged(id string, tagged bool) error {
+	_, err := qs.db.Exec("UPDATE queue_items SET tagged = ? WHERE id = ? AND tagged IS NOT ?", tagged, id, tagged)
+	if err != nil {
+		return fmt.Errorf("failed to set tagged flag: %w", err)
+	}
+	return nil
+}
+
+// SetQuality sets the quality an item downloads in instead of download.quality; empty
+// clears it. Items that are downloading or completed are left as they are.
+func (qs *QueueStore) SetQuality(id, quality string) error {
+	return qs.setBeforeStart(id, "quality", quality)
+}
+
+// SetTargetDir sets the folder an item downloads into instead of download.output_dir; empty
+// clears it. Items that are downloading or completed are left as they are.
+func (qs *QueueStore) SetTargetDir(id, dir string) error {
+	return qs.setBeforeStart(id, "target_dir", dir)
+}
+
+// SetDiscography records whether an album was queued as part of an artist's discography,
+// which download.group_artist_singles limits the shared Singles folder to
+func (qs *QueueStore) SetDiscography(id string, discography bool) error {
+	if _, err := qs.db.Exec("UPDATE queue_items SET discography = ?, updated_at = ? WHERE id = ?", discography, time.Now(), id); err != nil {
+		return fmt.Errorf("failed to set discography: %w", err)
+	}
+	return nil
+}
+
+// setBeforeStart sets column on an item that hasn't started downloading. The status check
+// is part of the UPDATE, so an item picked up by a worker in the meantime isn't changed.
+func (qs *QueueStore) setBeforeStart(id, column, value string) error {
+	result, err := qs.db.Exec(
+		"UPDATE queue_items SET "+column+" = ?, updated_at = ? WHERE id = ? AND status NOT IN ('downloading', 'completed')",
+		value, time.Now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set %s: %w", column, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		item, err := qs.GetByID(id)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%s is %s, only items that haven't started can be changed", id, item.Status)
+	}
+	return nil
+}
+
+// MarkCompleted moves an item to completed and reports whether this call did it. The status
+// check is part of the UPDATE, so when two goroutines finish the same album only one of them
+// sees the transition.
+func (qs *QueueStore) MarkCompleted(id string, completedAt time.Time) (bool, error) {
+	result, err := qs.db.Exec(
+		"UPDATE queue_items SET status = 'completed', completed_at = ?, updated_at = ? WHERE id = ? AND status != 'completed'",
+		completedAt, time.Now(), id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark item completed: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark item completed: %w", err)
+	}
+	return rows == 1, nil
+}
+
+// GetUntagged retrieves completed tracks whose metadata tagging failed
+func (qs *QueueStore) GetUntagged() ([]*QueueItem, error) {
+	query := `
+		SELECT id, type, title, artist, album, status, progress,
+		       download_url, output_path, error_message, retry_count,
+		       metadata_json, parent_id, total_tracks, completed_tracks,
+		       created_at, updated_at, completed_at, COALESCE(tagged, 1),
+		       added_at, COALESCE(quality, ''), COALESCE(target_dir, ''), COALESCE(discography, 0)
+		FROM queue_items
+		WHERE type = 'track' AND status = 'completed' AND tagged = 0
+		ORDER BY completed_at ASC, id ASC
+	`
+
+	rows, err := qs.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get untagged items: %w", err)
+	}
+	defer rows.Close()
+
+	return qs.scanItems(rows)
+}
+
+// GetTopLevelIDsByStatus returns the IDs of queue entries with the given status that are not
+// part of an album or playlist (albums, playlists and standalone tracks)
+func (qs *QueueStore) GetTopLevelIDsByStatus(status string) ([]string, error) {
+	rows, err := qs.db.Query(`
+		SELECT id FROM queue_items
+		WHERE status = ?
+		AND (parent_id IS NULL OR parent_id = '')
+		ORDER BY created_at ASC
+	`, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get items by status: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan item id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetIncompleteParents retrieves albums/playlists that have not finished all their tracks
+// and are not permanently done (completed or cancelled). Used on startup to re-enqueue interrupted parents.
+func (qs *QueueStore) GetIncompleteParents() ([]*QueueItem, error) {
+	query := `
+		SELECT id, type, title, artist, album, status, progress,
+		       download_url, output_path, error_message, retry_count,
+		       metadata_json, parent_id, total_tracks, completed_tracks,
+		       created_at, updated_at, completed_at, COALESCE(tagged, 1),
+		       added_at, COALESCE(quality, ''), COALESCE(target_dir, ''), COALESCE(discography, 0)
+		FROM queue_items
+		WHERE type IN ('album', 'playlist')
+		AND status NOT IN ('completed', 'cancelled')
+		AND (total_tracks = 0 OR completed_tracks < total_tracks)
+		ORDER BY created_at ASC
+	`
+
+	rows, err := qs.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incomplete parents: %w", err)
+	}
+	defer rows.Close()
+
+	return qs.scanItems(rows)
+}
+
+// ResetDownloadingTracks resets tracks left in "downloading" by a previous session back to pending.
+// Returns the number of tracks reset.
+func (qs *QueueStore) ResetDownloadingTracks() (int, error) {
+	query := `
+		UPDATE queue_items
+		SET status = 'pending', progress = 0, updated_at = ?
+		WHERE type = 'track' AND status = 'downloading'
+	`
+
+	result, err := qs.db.Exec(query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset downloading tracks: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// GetAll retrieves all queue items with pagination, oldest first
 func (qs *QueueStore) GetAll(offset, limit int) ([]*QueueItem, error) {
+	return qs.GetAllOrdered(offset, limit, false)
+}
+
+// GetAllOrdered retrieves all queue items with pagination, ordered by when they were added
+func (qs *QueueStore) GetAllOrdered(offset, limit int, newestFirst bool) ([]*QueueItem, error) {
 	// Enforce maximum limit to prevent memory issues
 	if limit > 1000 {
 		limit = 1000
@@ -381,10 +607,11 @@ func (qs *QueueStore) GetAll(offset, limit int) ([]*QueueItem, error) {
 		SELECT id, type, title, artist, album, status, progress,
 		       download_url, output_path, error_message, retry_count,
 		       metadata_json, parent_id, total_tracks, completed_tracks,
-		       created_at, updated_at, completed_at
+		       created_at, updated_at, completed_at, COALESCE(tagged, 1),
+		       added_at, COALESCE(quality, ''), COALESCE(target_dir, ''), COALESCE(discography, 0)
 		FROM queue_items
 		WHERE type IN ('album', 'playlist')
-		ORDER BY created_at ASC
+		` + queueOrder(newestFirst) + `
 		LIMIT ? OFFSET ?
 	`
 
@@ -397,9 +624,15 @@ func (qs *QueueStore) GetAll(offset, limit int) ([]*QueueItem, error) {
 	return qs.scanItems(rows)
 }
 
-// GetByStatus retrieves queue items filtered by status with pagination
+// GetByStatus retrieves queue items filtered by status with pagination, oldest first
 // Only returns albums and playlists (parent items), not individual tracks
 func (qs *QueueStore) GetByStatus(status string, offset, limit int) ([]*QueueItem, error) {
+	return qs.GetByStatusOrdered(status, offset, limit, false)
+}
+
+// GetByStatusOrdered retrieves queue items filtered by status with pagination, ordered by
+// when they were added
+func (qs *QueueStore) GetByStatusOrdered(status string, offset, limit int, newestFirst bool) ([]*QueueItem, error) {
 	// Enforce maximum limit to prevent memory issues
 	if limit > 1000 {
 		limit = 1000
@@ -409,10 +642,11 @@ func (qs *QueueStore) GetByStatus(status string, offset, limit int) ([]*QueueIte
 		SELECT id, type, title, artist, album, status, progress,
 		       download_url, output_path, error_message, retry_count,
 		       metadata_json, parent_id, total_tracks, completed_tracks,
-		       created_at, updated_at, completed_at
+		       created_at, updated_at, completed_at, COALESCE(tagged, 1),
+		       added_at, COALESCE(quality, ''), COALESCE(target_dir, ''), COALESCE(discography, 0)
 		FROM queue_items
 		WHERE status = ? AND type IN ('album', 'playlist')
-		ORDER BY created_at ASC
+		` + queueOrder(newestFirst) + `
 		LIMIT ? OFFSET ?
 	`
 
@@ -425,6 +659,50 @@ func (qs *QueueStore) GetByStatus(status string, offset, limit int) ([]*QueueIte
 	return qs.scanItems(rows)
 }
 
+// queueOrder is the ORDER BY shared by the UI-facing queue queries. Items added in one
+// batch share a timestamp, so the ID breaks ties to keep pages stable between refreshes.
+func queueOrder(newestFirst bool) string {
+	if newestFirst {
+		return "ORDER BY added_at DESC, id DESC"
+	}
+	return "ORDER BY added_at ASC, id ASC"
+}
+
+// GetSnapshot returns every album and playlist in queue order with only its status and
+// progress. Completed counts of unfinished items are recounted in the same query, as
+// scanItems does for full items.
+func (qs *QueueStore) GetSnapshot() ([]*QueueSnapshotItem, error) {
+	query := `
+		SELECT q.id, q.type, q.status, q.progress, q.total_tracks,
+		       CASE WHEN q.status IN ('downloading', 'pending')
+		            THEN (SELECT COUNT(*) FROM queue_items c WHERE c.parent_id = q.id AND c.status = 'completed')
+		            ELSE q.completed_tracks END
+		FROM queue_items q
+		WHERE q.type IN ('album', 'playlist')
+		ORDER BY q.added_at ASC, q.id ASC
+	`
+
+	rows, err := qs.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue snapshot: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*QueueSnapshotItem{}
+	for rows.Next() {
+		item := &QueueSnapshotItem{}
+		if err := rows.Scan(&item.ID, &item.Type, &item.Status, &item.Progress, &item.TotalTracks, &item.CompletedTracks); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return items, nil
+}
+
 // GetCount returns the total count of queue items
 func (qs *QueueStore) GetCount() (int, error) {
 	var count int
@@ -458,7 +736,8 @@ func (qs *QueueStore) GetStats() (*QueueStats, error) {
 			COALESCE(SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END), 0) as pending,
 			COALESCE(SUM(CASE WHEN status = 'downloading' THEN 1 ELSE 0 END), 0) as downloading,
 			COALESCE(SUM(CASE WHEN status = 'completed' THEN 1 ELSE 0 END), 0) as completed,
-			COALESCE(SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END), 0) as failed
+			COALESCE(SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END), 0) as failed,
+			COALESCE(SUM(CASE WHEN status = 'cancelled' THEN 1 ELSE 0 END), 0) as cancelled
 		FROM queue_items
 		WHERE type IN ('album', 'playlist')
 	`
@@ -470,6 +749,7 @@ func (qs *QueueStore) GetStats() (*QueueStats, error) {
 		&stats.Downloading,
 		&stats.Completed,
 		&stats.Failed,
+		&stats.Cancelled,
 	)
 
 	if err != nil {
@@ -559,6 +839,57 @@ func (qs *QueueStore) ClearCompleted() error {
 	return nil
 }
 
+// ClearCompletedParent removes a single completed album/playlist and its completed tracks
+// Uses the same safety rules as ClearCompleted: the parent is kept if it has partial failures
+// or any child that is still pending, downloading or failed. Returns true if it was removed.
+func (qs *QueueStore) ClearCompletedParent(parentID string) (bool, error) {
+	tx, err := qs.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		DELETE FROM queue_items 
+		WHERE id = ?
+		AND type IN ('album', 'playlist')
+		AND status = 'completed'
+		AND completed_tracks = total_tracks
+		AND NOT EXISTS (
+			SELECT 1 FROM queue_items 
+			WHERE parent_id = ? 
+			AND status IN ('pending', 'downloading', 'failed')
+		)
+	`, parentID, parentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to clear completed parent: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	// Parent is gone - remove its completed tracks (history already has them)
+	if _, err := tx.Exec(`
+		DELETE FROM queue_items 
+		WHERE parent_id = ? 
+		AND type = 'track' 
+		AND status = 'completed'
+	`, parentID); err != nil {
+		return false, fmt.Errorf("failed to clear completed tracks: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
 // FixIncompleteAlbums fixes albums/playlists that were incorrectly marked as completed
 // when they actually have 0 tracks downloaded. Returns the number of items fixed.
 func (qs *QueueStore) FixIncompleteAlbums() (int, error) {
@@ -684,7 +1015,7 @@ func (qs *QueueStore) scanItems(rows *sql.Rows) ([]*QueueItem, error) {
 
 	for rows.Next() {
 		item := &QueueItem{}
-		var completedAt sql.NullTime
+		var completedAt, addedAt sql.NullTime
 		var parentID sql.NullString
 
 		err := rows.Scan(
@@ -706,6 +1037,11 @@ func (qs *QueueStore) scanItems(rows *sql.Rows) ([]*QueueItem, error) {
 			&item.CreatedAt,
 			&item.UpdatedAt,
 			&completedAt,
+			&item.Tagged,
+			&addedAt,
+			&item.Quality,
+			&item.TargetDir,
+			&item.Discography,
 		)
 
 		if err != nil {
@@ -718,6 +1054,10 @@ func (qs *QueueStore) scanItems(rows *sql.Rows) ([]*QueueItem, error) {
 		if parentID.Valid {
 			item.ParentID = parentID.String
 		}
+		item.AddedAt = item.CreatedAt
+		if addedAt.Valid {
+			item.AddedAt = addedAt.Time
+		}
 
 		// For albums/playlists, dynamically calculate completed tracks count
 		// This ensures we always have accurate data even if the app was closed during downloads
@@ -759,13 +1099,19 @@ func (qs *QueueStore) scanItems(rows *sql.Rows) ([]*QueueItem, error) {
 
 // AddToHistory adds a completed download to history
 func (qs *QueueStore) AddToHistory(trackID, title, artist, album, filePath, quality string, fileSize int64) error {
+	return qs.AddToHistoryWithBitrate(trackID, title, artist, album, filePath, quality, fileSize, 0, "")
+}
+
+// AddToHistoryWithBitrate adds a completed download to history along with the bitrate measured
+// from the file (0 if unknown) and a warning when it's below the requested quality
+func (qs *QueueStore) AddToHistoryWithBitrate(trackID, title, artist, album, filePath, quality string, fileSize int64, bitrate int, qualityWarning string) error {
 	query := `
 		INSERT INTO download_history (
-			track_id, title, artist, album, file_path, file_size, quality
-		) VALUES (?, ?, ?, ?, ?, ?, ?)
+			track_id, title, artist, album, file_path, file_size, quality, bitrate, quality_warning
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := qs.db.Exec(query, trackID, title, artist, album, filePath, fileSize, quality)
+	_, err := qs.db.Exec(query, trackID, title, artist, album, filePath, fileSize, quality, bitrate, qualityWarning)
 	if err != nil {
 		return fmt.Errorf("failed to add to history: %w", err)
 	}
@@ -776,7 +1122,8 @@ func (qs *QueueStore) AddToHistory(trackID, title, artist, album, filePath, qual
 // GetHistory retrieves download history with pagination
 func (qs *QueueStore) GetHistory(offset, limit int) ([]map[string]interface{}, error) {
 	query := `
-		SELECT id, track_id, title, artist, album, file_path, file_size, quality, downloaded_at
+		SELECT id, track_id, title, artist, album, file_path, file_size, quality,
+		       COALESCE(bitrate, 0), COALESCE(quality_warning, ''), downloaded_at
 		FROM download_history
 		ORDER BY downloaded_at DESC
 		LIMIT ? OFFSET ?
@@ -794,14 +1141,16 @@ func (qs *QueueStore) GetHistory(offset, limit int) ([]map[string]interface{}, e
 		var id int
 		var trackID, title, artist, album, filePath, quality string
 		var fileSize int64
+		var bitrate int
+		var qualityWarning string
 		var downloadedAt time.Time
 
-		err := rows.Scan(&id, &trackID, &title, &artist, &album, &filePath, &fileSize, &quality, &downloadedAt)
+		err := rows.Scan(&id, &trackID, &title, &artist, &album, &filePath, &fileSize, &quality, &bitrate, &qualityWarning, &downloadedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan history row: %w", err)
 		}
 
-		history = append(history, map[string]interface{}{
+		entry := map[string]interface{}{
 			"id":            id,
 			"track_id":      trackID,
 			"title":         title,
@@ -810,13 +1159,152 @@ func (qs *QueueStore) GetHistory(offset, limit int) ([]map[string]interface{}, e
 			"file_path":     filePath,
 			"file_size":     fileSize,
 			"quality":       quality,
+			"bitrate":       bitrate,
 			"downloaded_at": downloadedAt,
-		})
+		}
+		if qualityWarning != "" {
+			entry["quality_warning"] = qualityWarning
+		}
+		history = append(history, entry)
 	}
 
 	return history, nil
 }
 
+// HistoryArtist is an artist found in the download history
+type HistoryArtist struct {
+	Artist     string `json:"artist"`
+	AlbumCount int    `json:"album_count"`
+	TrackCount int    `json:"track_count"`
+}
+
+// HistoryAlbum is an album found in the download history
+type HistoryAlbum struct {
+	Artist     string `json:"artist"`
+	Album      string `json:"album"`
+	TrackCount int    `json:"track_count"`
+}
+
+// GetHistoryArtists returns the distinct artists in the download history, sorted by name.
+// Tracks downloaded more than once are counted once.
+func (qs *QueueStore) GetHistoryArtists() ([]*HistoryArtist, error) {
+	query := `
+		SELECT COALESCE(artist, ''), COUNT(DISTINCT COALESCE(album, '')), COUNT(DISTINCT track_id)
+		FROM download_history
+		GROUP BY COALESCE(artist, '')
+		ORDER BY COALESCE(artist, '') COLLATE NOCASE
+	`
+
+	rows, err := qs.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history artists: %w", err)
+	}
+	defer rows.Close()
+
+	artists := []*HistoryArtist{}
+	for rows.Next() {
+		artist := &HistoryArtist{}
+		if err := rows.Scan(&artist.Artist, &artist.AlbumCount, &artist.TrackCount); err != nil {
+			return nil, fmt.Errorf("failed to scan history artist: %w", err)
+		}
+		artists = append(artists, artist)
+	}
+
+	return artists, rows.Err()
+}
+
+// GetHistoryAlbums returns the distinct albums in the download history, sorted by artist
+// and album. If artist is non-empty only that artist's albums are returned.
+func (qs *QueueStore) GetHistoryAlbums(artist string) ([]*HistoryAlbum, error) {
+	query := `
+		SELECT COALESCE(artist, ''), COALESCE(album, ''), COUNT(DISTINCT track_id)
+		FROM download_history
+		WHERE ? = '' OR artist = ?
+		GROUP BY COALESCE(artist, ''), COALESCE(album, '')
+		ORDER BY COALESCE(artist, '') COLLATE NOCASE, COALESCE(album, '') COLLATE NOCASE
+	`
+
+	rows, err := qs.db.Query(query, artist, artist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history albums: %w", err)
+	}
+	defer rows.Close()
+
+	albums := []*HistoryAlbum{}
+	for rows.Next() {
+		album := &HistoryAlbum{}
+		if err := rows.Scan(&album.Artist, &album.Album, &album.TrackCount); err != nil {
+			return nil, fmt.Errorf("failed to scan history album: %w", err)
+		}
+		albums = append(albums, album)
+	}
+
+	return albums, rows.Err()
+}
+
+// GetHistoryTrackIDByPath returns the track ID most recently downloaded to filePath,
+// or "" if the path isn't in the history
+func (qs *QueueStore) GetHistoryTrackIDByPath(filePath string) (string, error) {
+	query := `
+		SELECT track_id FROM download_history
+		WHERE file_path = ?
+		ORDER BY downloaded_at DESC, id DESC
+		LIMIT 1
+	`
+
+	var trackID string
+	err := qs.db.QueryRow(query, filePath).Scan(&trackID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up history: %w", err)
+	}
+
+	return trackID, nil
+}
+
+// SetHistoryISRC records the ISRC of the track downloaded to filePath, so later downloads of
+// the same recording can find the file with GetHistoryPathsByISRC
+func (qs *QueueStore) SetHistoryISRC(filePath, isrc string) error {
+	if _, err := qs.db.Exec("UPDATE download_history SET isrc = ? WHERE file_path = ?", isrc, filePath); err != nil {
+		return fmt.Errorf("failed to set history ISRC: %w", err)
+	}
+	return nil
+}
+
+// GetHistoryPathsByISRC returns the files downloaded for an ISRC, newest first. The files
+// may since have been moved or deleted.
+func (qs *QueueStore) GetHistoryPathsByISRC(isrc string) ([]string, error) {
+	rows, err := qs.db.Query(`
+		SELECT file_path FROM download_history
+		WHERE isrc = ? COLLATE NOCASE AND file_path != ''
+		ORDER BY downloaded_at DESC, id DESC
+	`, isrc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up history: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("failed to scan history path: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+// DeleteHistoryByPath removes the history entries of downloads written to filePath
+func (qs *QueueStore) DeleteHistoryByPath(filePath string) error {
+	if _, err := qs.db.Exec("DELETE FROM download_history WHERE file_path = ?", filePath); err != nil {
+		return fmt.Errorf("failed to delete history: %w", err)
+	}
+	return nil
+}
+
 // SetConfigCache sets a configuration cache value
 func (qs *QueueStore) SetConfigCache(key, value string) error {
 	query := `
@@ -882,7 +1370,8 @@ func (qs *QueueStore) GetResumableDownloads(limit int) ([]*QueueItem, error) {
 		SELECT id, type, title, artist, album, status, progress,
 		       download_url, output_path, error_message, retry_count,
 		       metadata_json, partial_file_path, bytes_downloaded, total_bytes,
-		       created_at, updated_at, completed_at
+		       created_at, updated_at, completed_at, COALESCE(tagged, 1),
+		       added_at, COALESCE(quality, ''), COALESCE(target_dir, ''), COALESCE(discography, 0)
 		FROM queue_items
 		WHERE status IN ('pending', 'failed') 
 		  AND partial_file_path IS NOT NULL 
@@ -918,6 +1407,174 @@ func (qs *QueueStore) ClearAll() error {
 	return nil
 }
 
+// queueExportVersion is the format version written by ExportQueue
+const queueExportVersion = 1
+
+// QueueExport is the portable form of the queue written by ExportQueue
+type QueueExport struct {
+	Version    int                `json:"version"`
+	ExportedAt time.Time          `json:"exported_at"`
+	Items      []*QueueExportItem `json:"items"`
+}
+
+// QueueExportItem is a top-level queue item with its metadata
+type QueueExportItem struct {
+	*QueueItem
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+// ExportQueue serializes every top-level item (albums, playlists and standalone tracks)
+// with its metadata to JSON. Album and playlist tracks are not included; they are
+// regenerated by the album/playlist jobs after ImportQueue.
+func (qs *QueueStore) ExportQueue() ([]byte, error) {
+	rows, err := qs.db.Query(`
+		SELECT id, type, title, artist, album, status, progress,
+		       download_url, output_path, error_message, retry_count,
+		       metadata_json, parent_id, total_tracks, completed_tracks,
+		       created_at, updated_at, completed_at, COALESCE(tagged, 1),
+		       added_at, COALESCE(quality, ''), COALESCE(target_dir, ''), COALESCE(discography, 0)
+		FROM queue_items
+		WHERE parent_id IS NULL OR parent_id = ''
+		ORDER BY created_at ASC, id ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := qs.scanItems(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &QueueExport{
+		Version:    queueExportVersion,
+		ExportedAt: time.Now(),
+		Items:      make([]*QueueExportItem, 0, len(items)),
+	}
+	for _, item := range items {
+		exportItem := &QueueExportItem{QueueItem: item}
+		if item.MetadataJSON != "" && json.Valid([]byte(item.MetadataJSON)) {
+			exportItem.Metadata = json.RawMessage(item.MetadataJSON)
+		}
+		export.Items = append(export.Items, exportItem)
+	}
+
+	return json.Marshal(export)
+}
+
+// ImportQueue re-inserts items written by ExportQueue, replacing items with the same ID.
+// Completed items stay completed; everything else is reset to pending so the normal
+// album/playlist jobs download it and regenerate its tracks. Returns the number of items imported.
+func (qs *QueueStore) ImportQueue(data []byte) (int, error) {
+	var export QueueExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return 0, fmt.Errorf("invalid queue export: %w", err)
+	}
+	if export.Version != queueExportVersion {
+		return 0, fmt.Errorf("unsupported queue export version: %d", export.Version)
+	}
+
+	for _, exportItem := range export.Items {
+		if exportItem == nil || exportItem.QueueItem == nil || exportItem.ID == "" {
+			return 0, fmt.Errorf("invalid queue export: item without an ID")
+		}
+		switch exportItem.Type {
+		case "track", "album", "playlist":
+		default:
+			return 0, fmt.Errorf("invalid queue export: item %s has unknown type %q", exportItem.ID, exportItem.Type)
+		}
+	}
+
+	qs.batchMu.Lock()
+	defer qs.batchMu.Unlock()
+
+	tx, err := qs.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Upsert directly rather than through Update, whose completion check would reject
+	// completed albums that have no tracks in this database
+	stmt, err := tx.Prepare(`
+		INSERT INTO queue_items (
+			id, type, title, artist, album, status, progress,
+			download_url, output_path, error_message, retry_count,
+			metadata_json, parent_id, total_tracks, completed_tracks,
+			created_at, updated_at, completed_at, added_at, quality, target_dir, discography
+		) VALUES (?, ?, ?, ?, ?, ?, ?, '', ?, ?, ?, ?, NULL, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			type = excluded.type, title = excluded.title, artist = excluded.artist,
+			album = excluded.album, status = excluded.status, progress = excluded.progress,
+			output_path = excluded.output_path, error_message = excluded.error_message,
+			retry_count = excluded.retry_count, metadata_json = excluded.metadata_json,
+			parent_id = NULL, total_tracks = excluded.total_tracks,
+			completed_tracks = excluded.completed_tracks, updated_at = excluded.updated_at,
+			completed_at = excluded.completed_at, quality = excluded.quality,
+			target_dir = excluded.target_dir, discography = excluded.discography
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, exportItem := range export.Items {
+		item := exportItem.QueueItem
+		if item.Status != "completed" {
+			item.Status = "pending"
+			item.Progress = 0
+			item.CompletedTracks = 0
+			item.RetryCount = 0
+			item.ErrorMessage = ""
+			item.CompletedAt = nil
+		}
+		if item.CreatedAt.IsZero() {
+			item.CreatedAt = now
+		}
+		if item.AddedAt.IsZero() {
+			item.AddedAt = item.CreatedAt
+		}
+
+		metadataJSON := ""
+		if len(exportItem.Metadata) > 0 && string(exportItem.Metadata) != "null" {
+			metadataJSON = string(exportItem.Metadata)
+		}
+
+		if _, err := stmt.Exec(
+			item.ID,
+			item.Type,
+			item.Title,
+			item.Artist,
+			item.Album,
+			item.Status,
+			item.Progress,
+			item.OutputPath,
+			item.ErrorMessage,
+			item.RetryCount,
+			metadataJSON,
+			item.TotalTracks,
+			item.CompletedTracks,
+			item.CreatedAt,
+			now,
+			item.CompletedAt,
+			item.AddedAt,
+			item.Quality,
+			item.TargetDir,
+			item.Discography,
+		); err != nil {
+			return 0, fmt.Errorf("failed to import %s: %w", item.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(export.Items), nil
+}
+
 // CountCompletedChildren counts how many child tracks of a parent are completed
 func (qs *QueueStore) CountCompletedChildren(parentID string) int {
 	query := `
@@ -935,15 +1592,15 @@ func (qs *QueueStore) CountCompletedChildren(parentID string) int {
 	return count
 }
 
-// CountFinishedChildren counts how many child tracks are finished (completed or failed)
-// Any track with status 'completed' or 'failed' is considered finished
+// CountFinishedChildren counts how many child tracks are finished (completed, failed or cancelled)
+// Any track with status 'completed', 'failed' or 'cancelled' is considered finished
 // This allows albums to complete even when tracks fail without exhausting all retries
 func (qs *QueueStore) CountFinishedChildren(parentID string, maxRetries int) int {
 	query := `
 		SELECT COUNT(*) 
 		FROM queue_items 
 		WHERE parent_id = ? 
-		AND status IN ('completed', 'failed')
+		AND status IN ('completed', 'failed', 'cancelled')
 	`
 	
 	var count int
@@ -955,6 +1612,73 @@ func (qs *QueueStore) CountFinishedChildren(parentID string, maxRetries int) int
 	return count
 }
 
+// ResetCancelledChildren puts the cancelled tracks of an album or playlist back to pending,
+// for when the parent is retried or resumed after a cancel. Returns the number of tracks reset.
+func (qs *QueueStore) ResetCancelledChildren(parentID string) (int, error) {
+	query := `
+		UPDATE queue_items
+		SET status = 'pending', progress = 0, error_message = '', updated_at = ?
+		WHERE parent_id = ? AND status = 'cancelled'
+	`
+
+	result, err := qs.db.Exec(query, time.Now(), parentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset cancelled tracks: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// ResetRetries zeroes the retry count of an item and, for an album or playlist, of its
+// tracks, so they get a fresh set of attempts. Returns the number of items reset.
+func (qs *QueueStore) ResetRetries(id string) (int, error) {
+	query := `
+		UPDATE queue_items
+		SET retry_count = 0, updated_at = ?
+		WHERE (id = ? OR parent_id = ?) AND retry_count > 0
+	`
+
+	result, err := qs.db.Exec(query, time.Now(), id, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset retries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// ResetFailedRetries zeroes the retry count of every failed item and of the tracks of
+// failed albums and playlists, e.g. after a network outage. Returns the number of items reset.
+func (qs *QueueStore) ResetFailedRetries() (int, error) {
+	query := `
+		UPDATE queue_items
+		SET retry_count = 0, updated_at = ?
+		WHERE retry_count > 0
+		  AND (status = 'failed' OR parent_id IN (SELECT id FROM queue_items WHERE status = 'failed'))
+	`
+
+	result, err := qs.db.Exec(query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset retries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
 // FailedTrack represents a failed track with error details
 type FailedTrack struct {
 	ID           int       `json:"id"`
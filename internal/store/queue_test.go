@@ -1,6 +1,7 @@
 package store
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -236,3 +237,667 @@ func TestQueueStore_ClearCompleted(t *testing.T) {
 		t.Errorf("Expected completed 0, got %d", stats.Completed)
 	}
 }
+
+func TestQueueStore_GetChildren(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	items := []*QueueItem{
+		{ID: "album_1", Type: "album", Title: "Album 1", Status: "pending", TotalTracks: 2},
+		{ID: "track_1_10", Type: "track", Title: "Track 1", Status: "pending", ParentID: "album_1"},
+		{ID: "track_1_11", Type: "track", Title: "Track 2", Status: "completed", ParentID: "album_1"},
+		{ID: "track_2_20", Type: "track", Title: "Other", Status: "pending", ParentID: "album_2"},
+	}
+
+	for _, item := range items {
+		if err := store.Add(item); err != nil {
+			t.Fatalf("Failed to add item: %v", err)
+		}
+		time.Sleep(time.Millisecond) // Ensure different timestamps
+	}
+
+	children, err := store.GetChildren("album_1")
+	if err != nil {
+		t.Fatalf("Failed to get children: %v", err)
+	}
+
+	if len(children) != 2 {
+		t.Fatalf("Expected 2 children, got %d", len(children))
+	}
+	if children[0].ID != "track_1_10" || children[1].ID != "track_1_11" {
+		t.Errorf("Unexpected child order: %s, %s", children[0].ID, children[1].ID)
+	}
+}
+
+func TestQueueStore_ClearCompletedParent(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	items := []*QueueItem{
+		{ID: "album_1", Type: "album", Title: "Done", Status: "completed", TotalTracks: 1, CompletedTracks: 1},
+		{ID: "track_1_10", Type: "track", Title: "Track", Status: "completed", ParentID: "album_1"},
+		{ID: "album_2", Type: "album", Title: "Partial", Status: "completed", TotalTracks: 2, CompletedTracks: 1},
+		{ID: "track_2_20", Type: "track", Title: "Track", Status: "completed", ParentID: "album_2"},
+		{ID: "track_2_21", Type: "track", Title: "Track", Status: "failed", ParentID: "album_2"},
+	}
+
+	for _, item := range items {
+		if err := store.Add(item); err != nil {
+			t.Fatalf("Failed to add item: %v", err)
+		}
+	}
+
+	cleared, err := store.ClearCompletedParent("album_1")
+	if err != nil {
+		t.Fatalf("Failed to clear parent: %v", err)
+	}
+	if !cleared {
+		t.Error("Expected fully completed album to be cleared")
+	}
+	if _, err := store.GetByID("track_1_10"); err == nil {
+		t.Error("Expected completed child track to be removed")
+	}
+
+	// Albums with failed tracks stay visible
+	cleared, err = store.ClearCompletedParent("album_2")
+	if err != nil {
+		t.Fatalf("Failed to clear parent: %v", err)
+	}
+	if cleared {
+		t.Error("Expected album with failed tracks to be kept")
+	}
+	if _, err := store.GetByID("album_2"); err != nil {
+		t.Errorf("Expected album_2 to remain: %v", err)
+	}
+}
+
+func TestQueueStore_HistoryArtistsAndAlbums(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	entries := []struct {
+		trackID, artist, album string
+	}{
+		{"1", "Beta", "First"},
+		{"2", "Beta", "First"},
+		{"2", "Beta", "First"}, // Re-download of the same track
+		{"3", "Beta", "Second"},
+		{"4", "alpha", "Only"},
+	}
+	for _, e := range entries {
+		if err := store.AddToHistory(e.trackID, "Title", e.artist, e.album, "/music/"+e.trackID+".mp3", "MP3_320", 1); err != nil {
+			t.Fatalf("Failed to add history: %v", err)
+		}
+	}
+
+	artists, err := store.GetHistoryArtists()
+	if err != nil {
+		t.Fatalf("GetHistoryArtists failed: %v", err)
+	}
+	if len(artists) != 2 || artists[0].Artist != "alpha" || artists[1].Artist != "Beta" {
+		t.Fatalf("Expected alpha and Beta sorted by name, got %+v", artists)
+	}
+	if artists[1].AlbumCount != 2 || artists[1].TrackCount != 3 {
+		t.Errorf("Expected Beta to have 2 albums and 3 tracks, got %+v", artists[1])
+	}
+
+	albums, err := store.GetHistoryAlbums("Beta")
+	if err != nil {
+		t.Fatalf("GetHistoryAlbums failed: %v", err)
+	}
+	if len(albums) != 2 || albums[0].Album != "First" || albums[0].TrackCount != 2 {
+		t.Errorf("Unexpected albums for Beta: %+v", albums)
+	}
+
+	if all, _ := store.GetHistoryAlbums(""); len(all) != 3 {
+		t.Errorf("Expected 3 albums across all artists, got %d", len(all))
+	}
+}
+
+func TestQueueStore_ExportImportQueue(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	custom := &QueueItem{ID: "playlist_custom", Type: "playlist", Title: "Mix", Status: "failed", TotalTracks: 2, RetryCount: 1, ErrorMessage: "boom"}
+	if err := custom.SetMetadata(map[string]interface{}{"is_custom": true, "custom_tracks": []string{"1", "2"}}); err != nil {
+		t.Fatalf("Failed to set metadata: %v", err)
+	}
+	items := []*QueueItem{
+		{ID: "album_1", Type: "album", Title: "Done", Status: "completed", Progress: 100, TotalTracks: 1, CompletedTracks: 1},
+		{ID: "track_1_10", Type: "track", Title: "Child", Status: "completed", ParentID: "album_1"},
+		{ID: "track_5", Type: "track", Title: "Single", Status: "downloading", Progress: 40},
+		custom,
+	}
+	for _, item := range items {
+		if err := store.Add(item); err != nil {
+			t.Fatalf("Failed to add %s: %v", item.ID, err)
+		}
+	}
+
+	data, err := store.ExportQueue()
+	if err != nil {
+		t.Fatalf("ExportQueue failed: %v", err)
+	}
+
+	// Import into a fresh database that already has a stale copy of one item
+	target, cleanupTarget := setupTestDB(t)
+	defer cleanupTarget()
+	if err := target.Add(&QueueItem{ID: "track_5", Type: "track", Title: "Old title", Status: "failed"}); err != nil {
+		t.Fatalf("Failed to add stale item: %v", err)
+	}
+
+	imported, err := target.ImportQueue(data)
+	if err != nil {
+		t.Fatalf("ImportQueue failed: %v", err)
+	}
+	if imported != 3 {
+		t.Errorf("Expected 3 top-level items imported, got %d", imported)
+	}
+	var count int
+	if err := target.GetDB().QueryRow("SELECT COUNT(*) FROM queue_items").Scan(&count); err != nil || count != 3 {
+		t.Errorf("Expected 3 items without duplicates or children, got %d (%v)", count, err)
+	}
+
+	album, err := target.GetByID("album_1")
+	if err != nil || album.Status != "completed" || album.CompletedTracks != 1 {
+		t.Errorf("Expected completed album to stay completed, got %+v (%v)", album, err)
+	}
+
+	single, err := target.GetByID("track_5")
+	if err != nil || single.Status != "pending" || single.Progress != 0 || single.Title != "Single" {
+		t.Errorf("Expected stale track replaced and reset to pending, got %+v (%v)", single, err)
+	}
+
+	playlist, err := target.GetByID("playlist_custom")
+	if err != nil {
+		t.Fatalf("Expected playlist to be imported: %v", err)
+	}
+	if playlist.Status != "pending" || playlist.RetryCount != 0 || playlist.ErrorMessage != "" {
+		t.Errorf("Expected failed playlist reset to pending, got %+v", playlist)
+	}
+	var metadata map[string]interface{}
+	if err := playlist.GetMetadata(&metadata); err != nil || metadata["is_custom"] != true {
+		t.Errorf("Expected custom playlist metadata to survive, got %v (%v)", metadata, err)
+	}
+
+	if _, err := target.ImportQueue([]byte(`{"version": 99, "items": []}`)); err == nil {
+		t.Error("Expected an error for an unknown export version")
+	}
+}
+
+func TestQueueStore_SetTagged(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	item := &QueueItem{ID: "track_7", Type: "track", Title: "Song", Status: "completed"}
+	if err := store.Add(item); err != nil {
+		t.Fatalf("Failed to add item: %v", err)
+	}
+
+	tagged := func() bool {
+		var flag bool
+		if err := store.GetDB().QueryRow("SELECT tagged FROM queue_items WHERE id = ?", item.ID).Scan(&flag); err != nil {
+			t.Fatalf("Failed to read tagged flag: %v", err)
+		}
+		return flag
+	}
+
+	if !tagged() {
+		t.Error("Expected new items to default to tagged")
+	}
+	if err := store.SetTagged(item.ID, false); err != nil {
+		t.Fatalf("SetTagged failed: %v", err)
+	}
+	if tagged() {
+		t.Error("Expected item to be flagged untagged")
+	}
+
+	// A later full-row update must not clear the flag
+	item.Progress = 100
+	if err := store.Update(item); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if tagged() {
+		t.Error("Expected Update to leave the tagged flag alone")
+	}
+
+	if err := store.SetTagged(item.ID, true); err != nil {
+		t.Fatalf("SetTagged failed: %v", err)
+	}
+	if !tagged() {
+		t.Error("Expected item to be flagged tagged again")
+	}
+}
+
+func TestQueueStore_FixStuckAlbums(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+	store.GetDB().SetMaxOpenConns(25) // Same pool size as the app; FixStuckAlbums queries while iterating rows
+
+	// album_1 has every track finished; album_2 only has the tracks it created finished and
+	// went stale; album_3 is the same but still being updated
+	for _, album := range []*QueueItem{
+		{ID: "album_1", Type: "album", Status: "downloading", TotalTracks: 2},
+		{ID: "album_2", Type: "album", Status: "downloading", TotalTracks: 10},
+		{ID: "album_3", Type: "album", Status: "downloading", TotalTracks: 10},
+	} {
+		if err := store.Add(album); err != nil {
+			t.Fatalf("Failed to add album: %v", err)
+		}
+		for i := 1; i <= 2; i++ {
+			track := &QueueItem{ID: fmt.Sprintf("track_%s_%d", album.ID, i), Type: "track", Status: "completed", ParentID: album.ID}
+			if err := store.Add(track); err != nil {
+				t.Fatalf("Failed to add track: %v", err)
+			}
+		}
+	}
+	if _, err := store.GetDB().Exec("UPDATE queue_items SET updated_at = ? WHERE id = 'album_2'", time.Now().Add(-10*time.Minute)); err != nil {
+		t.Fatalf("Failed to age album: %v", err)
+	}
+
+	fixed, err := store.FixStuckAlbums()
+	if err != nil {
+		t.Fatalf("FixStuckAlbums failed: %v", err)
+	}
+	if fixed != 2 {
+		t.Errorf("Expected 2 albums fixed, got %d", fixed)
+	}
+	for id, want := range map[string]string{"album_1": "completed", "album_2": "completed", "album_3": "downloading"} {
+		if item, _ := store.GetByID(id); item.Status != want {
+			t.Errorf("Expected %s to be %s, got %s", id, want, item.Status)
+		}
+	}
+}
+
+func TestQueueStore_MarkCompleted(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	item := &QueueItem{ID: "album_7", Type: "album", Title: "Album", Status: "downloading"}
+	if err := store.Add(item); err != nil {
+		t.Fatalf("Failed to add item: %v", err)
+	}
+
+	if ok, err := store.MarkCompleted(item.ID, time.Now()); err != nil || !ok {
+		t.Fatalf("Expected the first MarkCompleted to complete the item, got %v, %v", ok, err)
+	}
+	if ok, err := store.MarkCompleted(item.ID, time.Now()); err != nil || ok {
+		t.Errorf("Expected a second MarkCompleted to report no transition, got %v, %v", ok, err)
+	}
+
+	got, err := store.GetByID(item.ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if got.Status != "completed" || got.CompletedAt == nil {
+		t.Errorf("Expected a completed item with a completion time, got %s %v", got.Status, got.CompletedAt)
+	}
+}
+
+func TestQueueStore_SetQualityAndTargetDir(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	store.Add(&QueueItem{ID: "album_1", Type: "album", Status: "pending"})
+	store.Add(&QueueItem{ID: "album_2", Type: "album", Status: "downloading"})
+
+	if err := store.SetQuality("album_1", "FLAC"); err != nil {
+		t.Fatalf("SetQuality failed: %v", err)
+	}
+	if err := store.SetTargetDir("album_1", "/music/lossless"); err != nil {
+		t.Fatalf("SetTargetDir failed: %v", err)
+	}
+	item, err := store.GetByID("album_1")
+	if err != nil || item.Quality != "FLAC" || item.TargetDir != "/music/lossless" {
+		t.Fatalf("Expected the quality and folder to read back, got %+v (%v)", item, err)
+	}
+
+	// A later full-row update must not clear them
+	item.Progress = 10
+	if err := store.Update(item); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if item, _ := store.GetByID("album_1"); item.Quality != "FLAC" || item.TargetDir != "/music/lossless" {
+		t.Errorf("Expected Update to leave the overrides alone, got %+v", item)
+	}
+
+	if err := store.SetQuality("album_2", "FLAC"); err == nil {
+		t.Error("Expected an item that is downloading to be refused")
+	}
+	if item, _ := store.GetByID("album_2"); item.Quality != "" {
+		t.Errorf("Expected the downloading item to be unchanged, got %q", item.Quality)
+	}
+	if err := store.SetTargetDir("album_3", "/music"); err == nil {
+		t.Error("Expected an error for an unknown item")
+	}
+}
+
+func TestQueueStore_SetDiscography(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	store.Add(&QueueItem{ID: "album_1", Type: "album", Status: "pending"})
+	if item, _ := store.GetByID("album_1"); item.Discography {
+		t.Fatal("Expected a new album not to be marked as part of a discography")
+	}
+	if err := store.SetDiscography("album_1", true); err != nil {
+		t.Fatalf("SetDiscography failed: %v", err)
+	}
+
+	// A later full-row update must not clear it
+	item, _ := store.GetByID("album_1")
+	item.Status = "downloading"
+	if err := store.Update(item); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if item, _ := store.GetByID("album_1"); !item.Discography {
+		t.Errorf("Expected the album to stay marked, got %+v", item)
+	}
+
+	if err := store.SetDiscography("album_1", false); err != nil {
+		t.Fatalf("SetDiscography failed: %v", err)
+	}
+	if item, _ := store.GetByID("album_1"); item.Discography {
+		t.Error("Expected the mark to be cleared")
+	}
+}
+
+func TestQueueStore_GetUntagged(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, item := range []*QueueItem{
+		{ID: "track_1", Type: "track", Status: "completed"},
+		{ID: "track_2", Type: "track", Status: "completed"},
+		{ID: "track_3", Type: "track", Status: "failed"},
+		{ID: "album_4", Type: "album", Status: "completed"},
+	} {
+		if err := store.Add(item); err != nil {
+			t.Fatalf("Failed to add item: %v", err)
+		}
+		if item.ID != "track_1" {
+			if err := store.SetTagged(item.ID, false); err != nil {
+				t.Fatalf("SetTagged failed: %v", err)
+			}
+		}
+	}
+
+	items, err := store.GetUntagged()
+	if err != nil {
+		t.Fatalf("GetUntagged failed: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != "track_2" || items[0].Tagged {
+		t.Fatalf("Expected only the completed untagged track, got %+v", items)
+	}
+
+	item, err := store.GetByID("track_1")
+	if err != nil || !item.Tagged {
+		t.Errorf("Expected track_1 to read back as tagged, got %+v (%v)", item, err)
+	}
+}
+
+func TestQueueStore_HistoryRoundTrip(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := store.AddToHistory("1", "Song", "Artist", "Album", "/music/1.flac", "FLAC", 31457280); err != nil {
+		t.Fatalf("AddToHistory failed: %v", err)
+	}
+
+	history, err := store.GetHistory(0, 10)
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("Expected 1 history entry, got %d", len(history))
+	}
+	entry := history[0]
+	if entry["quality"] != "FLAC" || entry["file_size"] != int64(31457280) || entry["file_path"] != "/music/1.flac" {
+		t.Errorf("Expected quality, size and path to round-trip, got %v", entry)
+	}
+
+	// Read the columns directly, so a swap cancelled out by GetHistory's scan is caught too
+	var quality string
+	var fileSize int64
+	if err := store.GetDB().QueryRow("SELECT quality, file_size FROM download_history WHERE track_id = ?", "1").Scan(&quality, &fileSize); err != nil {
+		t.Fatalf("Failed to read history row: %v", err)
+	}
+	if quality != "FLAC" || fileSize != 31457280 {
+		t.Errorf("Expected quality FLAC and size 31457280 in their own columns, got %q and %d", quality, fileSize)
+	}
+}
+
+func TestQueueStore_HistoryBitrate(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := store.AddToHistory("1", "Plain", "Artist", "Album", "/music/1.mp3", "MP3_320", 1); err != nil {
+		t.Fatalf("AddToHistory failed: %v", err)
+	}
+	if err := store.AddToHistoryWithBitrate("2", "Fake", "Artist", "Album", "/music/2.mp3", "MP3_320", 1, 128, "LOW_QUALITY: file is 128 kbps"); err != nil {
+		t.Fatalf("AddToHistoryWithBitrate failed: %v", err)
+	}
+
+	history, err := store.GetHistory(0, 10)
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	byTrack := make(map[string]map[string]interface{})
+	for _, entry := range history {
+		byTrack[entry["track_id"].(string)] = entry
+	}
+
+	if byTrack["1"]["bitrate"] != 0 {
+		t.Errorf("Expected unmeasured bitrate 0, got %v", byTrack["1"]["bitrate"])
+	}
+	if _, ok := byTrack["1"]["quality_warning"]; ok {
+		t.Error("Expected no quality warning for a normal entry")
+	}
+	if byTrack["2"]["bitrate"] != 128 || byTrack["2"]["quality_warning"] != "LOW_QUALITY: file is 128 kbps" {
+		t.Errorf("Expected bitrate and warning on the history entry, got %v", byTrack["2"])
+	}
+}
+
+func TestQueueStore_Cancelled(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	items := []*QueueItem{
+		{ID: "album_1", Type: "album", Status: "cancelled", TotalTracks: 2},
+		{ID: "track_1_10", Type: "track", Status: "completed", ParentID: "album_1"},
+		{ID: "track_1_11", Type: "track", Status: "cancelled", ParentID: "album_1"},
+		{ID: "album_2", Type: "album", Status: "failed", TotalTracks: 1},
+	}
+	for _, item := range items {
+		if err := store.Add(item); err != nil {
+			t.Fatalf("Failed to add item: %v", err)
+		}
+	}
+
+	stats, err := store.GetStats()
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if stats.Cancelled != 1 || stats.Failed != 1 {
+		t.Errorf("Expected 1 cancelled and 1 failed album, got %+v", stats)
+	}
+
+	// A cancelled album isn't resumed on startup
+	parents, err := store.GetIncompleteParents()
+	if err != nil {
+		t.Fatalf("GetIncompleteParents failed: %v", err)
+	}
+	for _, parent := range parents {
+		if parent.ID == "album_1" {
+			t.Error("Expected the cancelled album not to be resumed")
+		}
+	}
+
+	if finished := store.CountFinishedChildren("album_1", 3); finished != 2 {
+		t.Errorf("Expected cancelled tracks to count as finished, got %d", finished)
+	}
+
+	reset, err := store.ResetCancelledChildren("album_1")
+	if err != nil || reset != 1 {
+		t.Fatalf("Expected 1 track reset, got %d (%v)", reset, err)
+	}
+	if item, _ := store.GetByID("track_1_11"); item.Status != "pending" {
+		t.Errorf("Expected the cancelled track to be pending again, got %s", item.Status)
+	}
+	if item, _ := store.GetByID("track_1_10"); item.Status != "completed" {
+		t.Errorf("Expected the completed track to be kept, got %s", item.Status)
+	}
+}
+
+func TestQueueStore_AddedAtOrder(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Failed items, so scanItems doesn't recount children while the rows hold the only connection.
+	// A batch shares one timestamp, so the ID keeps the order stable.
+	if err := store.AddBatch([]*QueueItem{
+		{ID: "album_b", Type: "album", Title: "B", Status: "failed"},
+		{ID: "album_a", Type: "album", Title: "A", Status: "failed"},
+	}); err != nil {
+		t.Fatalf("Failed to add batch: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := store.Add(&QueueItem{ID: "album_c", Type: "album", Title: "C", Status: "failed"}); err != nil {
+		t.Fatalf("Failed to add item: %v", err)
+	}
+
+	ids := func(items []*QueueItem) string {
+		var s string
+		for _, item := range items {
+			s += item.ID[len(item.ID)-1:]
+		}
+		return s
+	}
+
+	oldest, err := store.GetAll(0, 10)
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if got := ids(oldest); got != "abc" {
+		t.Errorf("Expected oldest first abc, got %s", got)
+	}
+	newest, _ := store.GetByStatusOrdered("failed", 0, 10, true)
+	if got := ids(newest); got != "cba" {
+		t.Errorf("Expected newest first cba, got %s", got)
+	}
+
+	// added_at survives status updates
+	item := oldest[0]
+	if item.AddedAt.IsZero() {
+		t.Fatal("Expected added_at to be set on insert")
+	}
+	item.Status = "cancelled"
+	store.Update(item)
+	if reread, _ := store.GetByID(item.ID); reread == nil || !reread.AddedAt.Equal(item.AddedAt) {
+		t.Errorf("Expected added_at to be kept, got %+v", reread)
+	}
+}
+
+func TestQueueStore_GetSnapshot(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	items := []*QueueItem{
+		{ID: "album_1", Type: "album", Title: "One", Status: "downloading", TotalTracks: 2, CompletedTracks: 0},
+		{ID: "track_1_1", Type: "track", Status: "completed", ParentID: "album_1"},
+		{ID: "track_1_2", Type: "track", Status: "pending", ParentID: "album_1"},
+		{ID: "playlist_2", Type: "playlist", Title: "Two", Status: "completed", TotalTracks: 5, CompletedTracks: 5, Progress: 100},
+	}
+	for _, item := range items {
+		if err := store.Add(item); err != nil {
+			t.Fatalf("Failed to add %s: %v", item.ID, err)
+		}
+	}
+
+	snapshot, err := store.GetSnapshot()
+	if err != nil {
+		t.Fatalf("GetSnapshot failed: %v", err)
+	}
+	if len(snapshot) != 2 {
+		t.Fatalf("Expected only the album and playlist, got %d items", len(snapshot))
+	}
+	if album := snapshot[0]; album.ID != "album_1" || album.CompletedTracks != 1 || album.TotalTracks != 2 {
+		t.Errorf("Expected the album's completed tracks to be recounted, got %+v", album)
+	}
+	if playlist := snapshot[1]; playlist.Status != "completed" || playlist.Progress != 100 {
+		t.Errorf("Unexpected playlist %+v", playlist)
+	}
+}
+
+func TestQueueStore_ResetRetries(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if err := store.AddBatch([]*QueueItem{
+		{ID: "album_1", Type: "album", Status: "failed", RetryCount: 1},
+		{ID: "track_1_1", Type: "track", ParentID: "album_1", Status: "failed", RetryCount: 3},
+		{ID: "track_1_2", Type: "track", ParentID: "album_1", Status: "completed", RetryCount: 2},
+		{ID: "track_9", Type: "track", Status: "failed", RetryCount: 3},
+		{ID: "track_8", Type: "track", Status: "completed", RetryCount: 1},
+	}); err != nil {
+		t.Fatalf("Failed to add batch: %v", err)
+	}
+
+	retries := func(id string) int {
+		item, err := store.GetByID(id)
+		if err != nil {
+			t.Fatalf("GetByID(%s) failed: %v", id, err)
+		}
+		return item.RetryCount
+	}
+
+	// A parent resets its tracks along with itself
+	reset, err := store.ResetRetries("album_1")
+	if err != nil {
+		t.Fatalf("ResetRetries failed: %v", err)
+	}
+	if reset != 3 || retries("album_1") != 0 || retries("track_1_1") != 0 || retries("track_1_2") != 0 {
+		t.Errorf("Expected the album and both tracks reset, got %d", reset)
+	}
+	if retries("track_9") != 3 {
+		t.Error("Expected other items to keep their retry count")
+	}
+
+	// Only failed items, and tracks of failed parents, are reset in bulk
+	store.db.Exec("UPDATE queue_items SET retry_count = 2 WHERE parent_id = 'album_1'")
+	reset, err = store.ResetFailedRetries()
+	if err != nil {
+		t.Fatalf("ResetFailedRetries failed: %v", err)
+	}
+	if reset != 3 || retries("track_9") != 0 || retries("track_1_2") != 0 {
+		t.Errorf("Expected failed items and failed album tracks reset, got %d", reset)
+	}
+	if retries("track_8") != 1 {
+		t.Error("Expected a completed standalone track to keep its retry count")
+	}
+}
+
+func TestQueueStore_HistoryISRC(t *testing.T) {
+	store, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	store.AddToHistory("1", "Song", "Artist", "Album", "/music/album/1.mp3", "MP3_320", 1)
+	store.AddToHistory("1", "Song", "Artist", "Playlist", "/music/playlist/1.mp3", "MP3_320", 1)
+	store.AddToHistory("2", "Other", "Artist", "Album", "/music/album/2.mp3", "MP3_320", 1)
+	for path, isrc := range map[string]string{"/music/album/1.mp3": "USRC17607839", "/music/playlist/1.mp3": "USRC17607839", "/music/album/2.mp3": "GBAYE0601498"} {
+		if err := store.SetHistoryISRC(path, isrc); err != nil {
+			t.Fatalf("SetHistoryISRC failed: %v", err)
+		}
+	}
+
+	paths, err := store.GetHistoryPathsByISRC("usrc17607839")
+	if err != nil {
+		t.Fatalf("GetHistoryPathsByISRC failed: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != "/music/playlist/1.mp3" {
+		t.Errorf("Expected both copies, newest first, got %v", paths)
+	}
+	if paths, _ := store.GetHistoryPathsByISRC("FRZ039800212"); len(paths) != 0 {
+		t.Errorf("Expected no paths for an unknown ISRC, got %v", paths)
+	}
+}